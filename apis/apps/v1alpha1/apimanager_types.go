@@ -23,7 +23,9 @@ import (
 	"github.com/RHsyseng/operator-utils/pkg/olm"
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -84,6 +86,27 @@ type APIManagerSpec struct {
 	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 	// +optional
 	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+	// WorkloadType selects the kind of workload used to deploy the 3scale components.
+	// Defaults to DeploymentConfig when not set.
+	// +optional
+	// +kubebuilder:validation:Enum=DeploymentConfig;Deployment
+	WorkloadType *WorkloadType `json:"workloadType,omitempty"`
+	// Ingress configures the networking.k8s.io/v1 Ingress objects
+	// created instead of OpenShift Routes
+	// +optional
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+	// NetworkPolicies configures the NetworkPolicies restricting
+	// the traffic to the 3scale components
+	// +optional
+	NetworkPolicies *NetworkPoliciesSpec `json:"networkPolicies,omitempty"`
+	// SecurityContextPreset sets default pod and container security contexts on all the components.
+	// The "restricted" preset complies with the restricted Pod Security Standard
+	// +optional
+	// +kubebuilder:validation:Enum=restricted
+	SecurityContextPreset *SecurityContextPreset `json:"securityContextPreset,omitempty"`
+	// Pruning configures the deletion of the objects no longer required by the current spec
+	// +optional
+	Pruning *PruningSpec `json:"pruning,omitempty"`
 }
 
 // APIManagerStatus defines the observed state of APIManager
@@ -101,6 +124,66 @@ type APIManagerStatus struct {
 	// APIManager Deployment Configs
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Deployments",xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses"
 	Deployments olm.DeploymentStatus `json:"deployments"`
+
+	// SecretRotation reports the last rotation of the internal credentials
+	// +optional
+	SecretRotation *SecretRotationStatus `json:"secretRotation,omitempty"`
+
+	// PreflightChecksHash identifies the settings of the external components which
+	// passed the preflight checks. The checks are run again when it changes.
+	// +optional
+	PreflightChecksHash string `json:"preflightChecksHash,omitempty"`
+
+	// UnmanagedObjects lists the objects, as Kind/name, opted out of the reconciliation
+	// with the apps.3scale.net/unmanaged annotation
+	// +optional
+	UnmanagedObjects []string `json:"unmanagedObjects,omitempty"`
+}
+
+// SecretRotationPhase is the step of a rotation of the internal credentials
+type SecretRotationPhase string
+
+const (
+	// SecretRotationPhaseBackend rotates the backend internal API password, and rolls
+	// out backend-listener, checking it, together with system-app and system-sidekiq,
+	// sending it
+	SecretRotationPhaseBackend SecretRotationPhase = "RotatingBackendCredentials"
+	// SecretRotationPhaseZync rotates the zync authentication token, and rolls out
+	// zync and zync-que, checking it, together with system-app and system-sidekiq,
+	// sending it
+	SecretRotationPhaseZync SecretRotationPhase = "RotatingZyncCredentials"
+	// SecretRotationPhaseCompleted is set once all the components are rolled out
+	SecretRotationPhaseCompleted SecretRotationPhase = "Completed"
+	// SecretRotationPhaseFailed is set when the components of a step are not
+	// rolled out in time. The rotation is not resumed until requested again
+	SecretRotationPhaseFailed SecretRotationPhase = "Failed"
+)
+
+// SecretRotationStatus reports the last rotation of the internal credentials
+// requested with the RotateSecretsAnnotation annotation
+type SecretRotationStatus struct {
+	// Request is the value of the annotation which requested the last rotation
+	Request string `json:"request"`
+	// Generation is incremented for every requested rotation, before any credential
+	// is changed. The rotated secrets are annotated with it, so each one is rotated
+	// once per request
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+	// Phase is the current step of the rotation
+	// +optional
+	Phase SecretRotationPhase `json:"phase,omitempty"`
+	// PhaseStartTime is the time the current step of the rotation started
+	// +optional
+	PhaseStartTime *metav1.Time `json:"phaseStartTime,omitempty"`
+	// Message reports why the rotation failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastRotationTime is the time the last rotation was completed
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// Secrets lists the secrets whose credentials were rotated
+	// +optional
+	Secrets []string `json:"secrets,omitempty"`
 }
 
 func (s *APIManagerStatus) Equals(other *APIManagerStatus, logger logr.Logger) bool {
@@ -121,6 +204,23 @@ func (s *APIManagerStatus) Equals(other *APIManagerStatus, logger logr.Logger) b
 		return false
 	}
 
+	if !reflect.DeepEqual(s.SecretRotation, other.SecretRotation) {
+		diff := cmp.Diff(s.SecretRotation, other.SecretRotation)
+		logger.V(1).Info("SecretRotation not equal", "difference", diff)
+		return false
+	}
+
+	if s.PreflightChecksHash != other.PreflightChecksHash {
+		logger.V(1).Info("PreflightChecksHash not equal", "current", s.PreflightChecksHash, "desired", other.PreflightChecksHash)
+		return false
+	}
+
+	if !reflect.DeepEqual(s.UnmanagedObjects, other.UnmanagedObjects) {
+		diff := cmp.Diff(s.UnmanagedObjects, other.UnmanagedObjects)
+		logger.V(1).Info("UnmanagedObjects not equal", "difference", diff)
+		return false
+	}
+
 	return true
 }
 
@@ -131,10 +231,17 @@ func (s *APIManagerStatus) Equals(other *APIManagerStatus, logger logr.Logger) b
 // +kubebuilder:resource:path=apimanagers,scope=Namespaced
 // +operator-sdk:csv:customresourcedefinitions:displayName="APIManager"
 // +operator-sdk:csv:customresourcedefinitions:resources={{"DeploymentConfig","apps.openshift.io/v1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"Deployment","apps/v1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"StatefulSet","apps/v1"}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{"PersistentVolumeClaim","v1"}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{"Service","v1"}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{"Route","route.openshift.io/v1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"Ingress","networking.k8s.io/v1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"NetworkPolicy","networking.k8s.io/v1"}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{"ImageStream","image.openshift.io/v1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"HorizontalPodAutoscaler","autoscaling/v2"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"ScaledObject","keda.sh/v1alpha1"}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{"TriggerAuthentication","keda.sh/v1alpha1"}}
 type APIManager struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -150,8 +257,89 @@ type APIManager struct {
 
 const (
 	APIManagerAvailableConditionType common.ConditionType = "Available"
+	// APIManagerMigratingConditionType is true while DeploymentConfigs are
+	// being migrated to Deployments
+	APIManagerMigratingConditionType common.ConditionType = "Migrating"
+	// APIManagerMigrationFailedConditionType is true when the migration of
+	// some DeploymentConfig to Deployment failed and was rolled back
+	APIManagerMigrationFailedConditionType common.ConditionType = "MigrationFailed"
+	// APIManagerPreflightConditionType reports the checks of the external
+	// components. The components are not deployed until it is true
+	APIManagerPreflightConditionType common.ConditionType = "Preflight"
+)
+
+// Conditions reporting the health of each component. The Available condition
+// is true when all of them but Monitoring are true.
+const (
+	APIManagerBackendAvailableConditionType    common.ConditionType = "BackendAvailable"
+	APIManagerSystemAvailableConditionType     common.ConditionType = "SystemAvailable"
+	APIManagerZyncAvailableConditionType       common.ConditionType = "ZyncAvailable"
+	APIManagerApicastAvailableConditionType    common.ConditionType = "ApicastAvailable"
+	APIManagerDatabasesAvailableConditionType  common.ConditionType = "DatabasesAvailable"
+	APIManagerRedisAvailableConditionType      common.ConditionType = "RedisAvailable"
+	APIManagerMemcachedAvailableConditionType  common.ConditionType = "MemcachedAvailable"
+	APIManagerSearchdAvailableConditionType    common.ConditionType = "SearchdAvailable"
+	APIManagerRoutesAvailableConditionType     common.ConditionType = "RoutesAvailable"
+	APIManagerMonitoringAvailableConditionType common.ConditionType = "MonitoringAvailable"
+)
+
+const (
+	// SkipPreflightAnnotation disables the checks of the external components
+	// when set to "true"
+	SkipPreflightAnnotation = "apps.3scale.net/skip-preflight"
+)
+
+const (
+	// MigrationReplicasAnnotation records the replicas of a DeploymentConfig
+	// scaled down to be migrated to a Deployment
+	MigrationReplicasAnnotation = "apps.3scale.net/migration-replicas"
+	// MigrationFailedAnnotation marks a DeploymentConfig whose migration to a
+	// Deployment failed and was rolled back. Remove it to retry the migration
+	MigrationFailedAnnotation = "apps.3scale.net/migration-failed"
+)
+
+const (
+	// RotateSecretsAnnotation requests the rotation of the internal credentials.
+	// Credentials are rotated again every time the annotation value changes
+	RotateSecretsAnnotation = "apps.3scale.net/rotate-secrets"
+	// RotateSecretKeyBaseAnnotation opts in the rotation of the SECRET_KEY_BASE
+	// of system and zync, which invalidates the sessions and signed cookies
+	RotateSecretKeyBaseAnnotation = "apps.3scale.net/rotate-secret-key-base"
+	// SecretsRotationGenerationAnnotation is set on the rotated secrets to the
+	// generation of the rotation
+	SecretsRotationGenerationAnnotation = "apps.3scale.net/secrets-rotation-generation"
+)
+
+// WorkloadType is the kind of workload used to deploy the 3scale components
+type WorkloadType string
+
+const (
+	WorkloadTypeDeploymentConfig WorkloadType = "DeploymentConfig"
+	WorkloadTypeDeployment       WorkloadType = "Deployment"
+)
+
+// SecurityContextPreset is a predefined set of pod and container security settings
+type SecurityContextPreset string
+
+const (
+	SecurityContextPresetRestricted SecurityContextPreset = "restricted"
+)
+
+type PrunePolicy string
+
+const (
+	PrunePolicyRetain PrunePolicy = "Retain"
+	PrunePolicyDelete PrunePolicy = "Delete"
 )
 
+type PruningSpec struct {
+	// PersistentVolumeClaims is the policy applied to the PersistentVolumeClaims
+	// no longer used by any component. Defaults to Retain
+	// +optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	PersistentVolumeClaims *PrunePolicy `json:"persistentVolumeClaims,omitempty"`
+}
+
 type APIManagerCommonSpec struct {
 	// Wildcard domain as configured in the API Manager object
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Wildcard Domain",xDescriptors="urn:alm:descriptor:com.tectonic.ui:label"
@@ -271,6 +459,19 @@ type ApicastProductionSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Autoscaling makes the operator create a HorizontalPodAutoscaler.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// PodTemplateOverrides are added to the apicast-production pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type ApicastStagingSpec struct {
@@ -337,6 +538,15 @@ type ApicastStagingSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodTemplateOverrides are added to the apicast-staging pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type BackendSpec struct {
@@ -360,6 +570,15 @@ type BackendSpec struct {
 	RedisLabels map[string]string `json:"redisLabels,omitempty"`
 	// +optional
 	RedisAnnotations map[string]string `json:"redisAnnotations,omitempty"`
+	// RedisSentinel deploys backend redis as a replicated StatefulSet monitored by Redis Sentinel
+	// +optional
+	RedisSentinel *RedisSentinelSpec `json:"redisSentinel,omitempty"`
+	// +optional
+	RedisPodSecurityContext *v1.PodSecurityContext `json:"redisPodSecurityContext,omitempty"`
+	// +optional
+	RedisSecurityContext *v1.SecurityContext `json:"redisSecurityContext,omitempty"`
+	// +optional
+	RedisProbes []ContainerProbesSpec `json:"redisProbes,omitempty"`
 
 	// +optional
 	ListenerSpec *BackendListenerSpec `json:"listenerSpec,omitempty"`
@@ -391,6 +610,19 @@ type BackendListenerSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Autoscaling makes the operator create a HorizontalPodAutoscaler.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// PodTemplateOverrides are added to the backend-listener pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type BackendWorkerSpec struct {
@@ -410,6 +642,24 @@ type BackendWorkerSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Autoscaling makes the operator create a HorizontalPodAutoscaler.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// QueueAutoscaling makes the operator create a KEDA ScaledObject scaling
+	// on the length of the backend queues. Requires KEDA installed in the cluster.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	QueueAutoscaling *QueueAutoscalingSpec `json:"queueAutoscaling,omitempty"`
+	// PodTemplateOverrides are added to the backend-worker pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type BackendCronSpec struct {
@@ -429,6 +679,15 @@ type BackendCronSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodTemplateOverrides are added to the backend-cron pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type SystemSpec struct {
@@ -452,6 +711,12 @@ type SystemSpec struct {
 	MemcachedLabels map[string]string `json:"memcachedLabels,omitempty"`
 	// +optional
 	MemcachedAnnotations map[string]string `json:"memcachedAnnotations,omitempty"`
+	// +optional
+	MemcachedPodSecurityContext *v1.PodSecurityContext `json:"memcachedPodSecurityContext,omitempty"`
+	// +optional
+	MemcachedSecurityContext *v1.SecurityContext `json:"memcachedSecurityContext,omitempty"`
+	// +optional
+	MemcachedProbes []ContainerProbesSpec `json:"memcachedProbes,omitempty"`
 
 	// +optional
 	RedisImage *string `json:"redisImage,omitempty"`
@@ -471,6 +736,15 @@ type SystemSpec struct {
 	RedisLabels map[string]string `json:"redisLabels,omitempty"`
 	// +optional
 	RedisAnnotations map[string]string `json:"redisAnnotations,omitempty"`
+	// RedisSentinel deploys system redis as a replicated StatefulSet monitored by Redis Sentinel
+	// +optional
+	RedisSentinel *RedisSentinelSpec `json:"redisSentinel,omitempty"`
+	// +optional
+	RedisPodSecurityContext *v1.PodSecurityContext `json:"redisPodSecurityContext,omitempty"`
+	// +optional
+	RedisSecurityContext *v1.SecurityContext `json:"redisSecurityContext,omitempty"`
+	// +optional
+	RedisProbes []ContainerProbesSpec `json:"redisProbes,omitempty"`
 
 	// TODO should this field be optional? We have different approaches in Kubernetes.
 	// For example, in v1.Volume it is optional and there's an implied behaviour
@@ -521,6 +795,19 @@ type SystemAppSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Autoscaling makes the operator create a HorizontalPodAutoscaler.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// PodTemplateOverrides are added to the system-app pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type SystemSidekiqSpec struct {
@@ -540,6 +827,15 @@ type SystemSidekiqSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodTemplateOverrides are added to the system-sidekiq pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type SystemSearchdSpec struct {
@@ -561,6 +857,15 @@ type SystemSearchdSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodTemplateOverrides are added to the system-searchd pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type SystemSphinxSpec struct {
@@ -657,6 +962,12 @@ type SystemMySQLSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type SystemPostgreSQLSpec struct {
@@ -679,6 +990,12 @@ type SystemPostgreSQLSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type ZyncSpec struct {
@@ -708,6 +1025,12 @@ type ZyncSpec struct {
 	DatabaseLabels map[string]string `json:"databaseLabels,omitempty"`
 	// +optional
 	DatabaseAnnotations map[string]string `json:"databaseAnnotations,omitempty"`
+	// +optional
+	DatabasePodSecurityContext *v1.PodSecurityContext `json:"databasePodSecurityContext,omitempty"`
+	// +optional
+	DatabaseSecurityContext *v1.SecurityContext `json:"databaseSecurityContext,omitempty"`
+	// +optional
+	DatabaseProbes []ContainerProbesSpec `json:"databaseProbes,omitempty"`
 }
 
 type ZyncAppSpec struct {
@@ -727,6 +1050,15 @@ type ZyncAppSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodTemplateOverrides are added to the zync pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type ZyncQueSpec struct {
@@ -746,6 +1078,20 @@ type ZyncQueSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// QueueAutoscaling makes the operator create a KEDA ScaledObject scaling
+	// on the number of pending que jobs. Requires KEDA installed in the cluster.
+	// When set, Replicas is only used as the initial replica count.
+	// +optional
+	QueueAutoscaling *QueueAutoscalingSpec `json:"queueAutoscaling,omitempty"`
+	// PodTemplateOverrides are added to the zync-que pod template
+	// +optional
+	PodTemplateOverrides *PodTemplateOverridesSpec `json:"podTemplateOverrides,omitempty"`
+	// +optional
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// +optional
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+	// +optional
+	Probes []ContainerProbesSpec `json:"probes,omitempty"`
 }
 
 type HighAvailabilitySpec struct {
@@ -768,6 +1114,10 @@ type ExternalSystemComponents struct {
 	Redis *bool `json:"redis,omitempty"`
 	// +optional
 	Database *bool `json:"database,omitempty"`
+	// Memcached, when enabled, makes system use the memcached servers listed
+	// in the system-memcache secret instead of deploying memcached
+	// +optional
+	Memcached *bool `json:"memcached,omitempty"`
 }
 
 type ExternalBackendComponents struct {
@@ -780,10 +1130,149 @@ type ExternalZyncComponents struct {
 	Database *bool `json:"database,omitempty"`
 }
 
+// AutoscalingSpec configures the HorizontalPodAutoscaler of a component
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit for the number of replicas. Defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit for the number of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// CPUTargetUtilization is the target average CPU utilization,
+	// as a percentage of the requested CPU
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CPUTargetUtilization *int32 `json:"cpuTargetUtilization,omitempty"`
+	// MemoryTargetUtilization is the target average memory utilization,
+	// as a percentage of the requested memory
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MemoryTargetUtilization *int32 `json:"memoryTargetUtilization,omitempty"`
+	// Metrics contains additional metric targets, like pods, object or external metrics
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+type QueueAutoscalingSpec struct {
+	// MinReplicas is the lower limit for the number of replicas. Defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit for the number of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetQueueLength is the number of pending jobs per replica. Defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetQueueLength *int64 `json:"targetQueueLength,omitempty"`
+	// PollingInterval is the interval, in seconds, to check the queue length
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// CooldownPeriod is the period, in seconds, to wait after the last
+	// active trigger before scaling back to MinReplicas
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+}
+
+type RedisSentinelSpec struct {
+	// Replicas is the number of Redis instances, each one running along with a Sentinel. Defaults to 3
+	// +kubebuilder:validation:Minimum=3
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// SentinelResources are the resource requirements of the Sentinel containers
+	// +optional
+	SentinelResources *v1.ResourceRequirements `json:"sentinelResources,omitempty"`
+}
+
+// ContainerProbesSpec replaces the probes of a container of the component.
+// Probes not set keep the operator defaults.
+type ContainerProbesSpec struct {
+	// Container is the name of the container of the component
+	Container string `json:"container"`
+	// +optional
+	LivenessProbe *v1.Probe `json:"livenessProbe,omitempty"`
+	// +optional
+	ReadinessProbe *v1.Probe `json:"readinessProbe,omitempty"`
+	// +optional
+	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
+}
+
+// PodTemplateOverridesSpec defines additions to the pod template of a component.
+// The entries are added to the ones defined by the operator and never replace them:
+// env vars, volumes and containers named like an operator defined one, and volume
+// mounts using an operator defined mount path, are ignored. The added entries are
+// recorded in the pod template, so entries removed from the overrides are removed
+// on the next reconciliation, while entries added manually are left untouched.
+// The redis, mysql, postgresql and memcached pods accept no overrides. They are
+// in-cluster data stores deployed for evaluation, to be replaced with external
+// ones, see ExternalComponentsSpec, when they need to be customised.
+type PodTemplateOverridesSpec struct {
+	// Env are extra environment variables added to the component containers
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Volumes are extra volumes added to the pod
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts are extra volume mounts added to the component containers
+	// +optional
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+	// SidecarContainers are extra containers added to the pod
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	SidecarContainers []v1.Container `json:"sidecarContainers,omitempty"`
+	// InitContainers are extra init containers added to the pod
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	InitContainers []v1.Container `json:"initContainers,omitempty"`
+}
+
 type PodDisruptionBudgetSpec struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+type IngressSpec struct {
+	// Enabled makes the operator expose the components with Ingress objects
+	// instead of OpenShift Routes
+	Enabled bool `json:"enabled,omitempty"`
+	// IngressClassName is the name of the IngressClass set on the Ingress objects
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations added to the Ingress objects
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// TLSSecretRef references the secret holding the TLS certificate
+	// used to terminate TLS for all the Ingress hosts
+	// +optional
+	TLSSecretRef *v1.LocalObjectReference `json:"tlsSecretRef,omitempty"`
+}
+
+type NetworkPoliciesSpec struct {
+	// Enabled makes the operator create NetworkPolicies allowing only
+	// the traffic required between the 3scale components
+	Enabled bool `json:"enabled,omitempty"`
+	// IngressSources are the peers allowed to reach the publicly exposed
+	// components: apicast, backend listener and system portals.
+	// When empty, traffic from any source is allowed
+	// +optional
+	IngressSources []networkingv1.NetworkPolicyPeer `json:"ingressSources,omitempty"`
+	// MonitoringSources are the peers allowed to scrape the metrics endpoints.
+	// Defaults to the OpenShift monitoring namespaces
+	// +optional
+	MonitoringSources []networkingv1.NetworkPolicyPeer `json:"monitoringSources,omitempty"`
+	// AutoscalerSources are the peers allowed to reach backend-redis and
+	// zync-database when queue autoscaling is used.
+	// Defaults to the KEDA operator pods in any namespace
+	// +optional
+	AutoscalerSources []networkingv1.NetworkPolicyPeer `json:"autoscalerSources,omitempty"`
+}
+
 type MonitoringSpec struct {
 	Enabled bool `json:"enabled,omitempty"`
 	// +optional
@@ -1142,6 +1631,10 @@ func SystemDatabase(e *ExternalComponentsSpec) bool {
 	return e != nil && e.System != nil && e.System.Database != nil && *e.System.Database
 }
 
+func SystemMemcached(e *ExternalComponentsSpec) bool {
+	return e != nil && e.System != nil && e.System.Memcached != nil && *e.System.Memcached
+}
+
 func SystemRedis(e *ExternalComponentsSpec) bool {
 	return e != nil && e.System != nil && e.System.Redis != nil && *e.System.Redis
 }
@@ -1168,6 +1661,45 @@ func (apimanager *APIManager) IsSystemMysqlEnabled() bool {
 	return !apimanager.IsExternal(SystemDatabase) && !apimanager.IsSystemPostgreSQLEnabled()
 }
 
+func (apimanager *APIManager) IsIngressEnabled() bool {
+	return apimanager.Spec.Ingress != nil && apimanager.Spec.Ingress.Enabled
+}
+
+func (apimanager *APIManager) IsNetworkPoliciesEnabled() bool {
+	return apimanager.Spec.NetworkPolicies != nil && apimanager.Spec.NetworkPolicies.Enabled
+}
+
+// IsQueueAutoscalingEnabled returns true when any component is configured
+// to be scaled on the length of its queue
+func (apimanager *APIManager) IsQueueAutoscalingEnabled() bool {
+	return (apimanager.Spec.Backend != nil && apimanager.Spec.Backend.WorkerSpec != nil &&
+		apimanager.Spec.Backend.WorkerSpec.QueueAutoscaling != nil) ||
+		(apimanager.Spec.Zync != nil && apimanager.Spec.Zync.QueSpec != nil &&
+			apimanager.Spec.Zync.QueSpec.QueueAutoscaling != nil)
+}
+
+func (apimanager *APIManager) IsBackendRedisSentinelEnabled() bool {
+	return !apimanager.IsExternal(BackendRedis) &&
+		apimanager.Spec.Backend != nil && apimanager.Spec.Backend.RedisSentinel != nil
+}
+
+func (apimanager *APIManager) IsSystemRedisSentinelEnabled() bool {
+	return !apimanager.IsExternal(SystemRedis) &&
+		apimanager.Spec.System != nil && apimanager.Spec.System.RedisSentinel != nil
+}
+
+func (apimanager *APIManager) IsSecurityContextPresetRestricted() bool {
+	return apimanager.Spec.SecurityContextPreset != nil &&
+		*apimanager.Spec.SecurityContextPreset == SecurityContextPresetRestricted
+}
+
+// IsPersistentVolumeClaimPruningEnabled returns true when the orphaned
+// PersistentVolumeClaims are deleted along with the rest of orphaned objects
+func (apimanager *APIManager) IsPersistentVolumeClaimPruningEnabled() bool {
+	return apimanager.Spec.Pruning != nil && apimanager.Spec.Pruning.PersistentVolumeClaims != nil &&
+		*apimanager.Spec.Pruning.PersistentVolumeClaims == PrunePolicyDelete
+}
+
 func (apimanager *APIManager) IsMonitoringEnabled() bool {
 	return apimanager.Spec.Monitoring != nil && apimanager.Spec.Monitoring.Enabled
 }
@@ -1191,6 +1723,34 @@ func (apimanager *APIManager) IsAPIcastStagingOpenTracingEnabled() bool {
 		*apimanager.Spec.Apicast.StagingSpec.OpenTracing.Enabled
 }
 
+// SecretRotationRequest returns the value of the RotateSecretsAnnotation annotation
+// when it requests a rotation not completed, nor failed, yet
+func (apimanager *APIManager) SecretRotationRequest() (string, bool) {
+	request, ok := apimanager.Annotations[RotateSecretsAnnotation]
+	if !ok || request == "" {
+		return "", false
+	}
+
+	status := apimanager.Status.SecretRotation
+	if status != nil && status.Request == request && (status.Phase == SecretRotationPhaseCompleted || status.Phase == SecretRotationPhaseFailed) {
+		return "", false
+	}
+
+	return request, true
+}
+
+func (apimanager *APIManager) IsSecretKeyBaseRotationEnabled() bool {
+	return apimanager.Annotations[RotateSecretKeyBaseAnnotation] == "true"
+}
+
+func (apimanager *APIManager) IsPreflightSkipped() bool {
+	return apimanager.Annotations[SkipPreflightAnnotation] == "true"
+}
+
+func (apimanager *APIManager) IsDeploymentWorkloadEnabled() bool {
+	return apimanager.Spec.WorkloadType != nil && *apimanager.Spec.WorkloadType == WorkloadTypeDeployment
+}
+
 func (apimanager *APIManager) IsS3Enabled() bool {
 	return apimanager.Spec.System.FileStorageSpec != nil &&
 		apimanager.Spec.System.FileStorageSpec.S3 != nil
@@ -1361,6 +1921,24 @@ func (apimanager *APIManager) Validate() field.ErrorList {
 		}
 	}
 
+	if apimanager.Spec.Backend != nil && apimanager.Spec.Backend.WorkerSpec != nil {
+		workerSpec := apimanager.Spec.Backend.WorkerSpec
+		if workerSpec.Autoscaling != nil && workerSpec.QueueAutoscaling != nil {
+			queueAutoscalingFldPath := specFldPath.Child("backend").Child("workerSpec").Child("queueAutoscaling")
+			fieldErrors = append(fieldErrors, field.Invalid(queueAutoscalingFldPath, workerSpec.QueueAutoscaling, "autoscaling and queueAutoscaling are mutually exclusive"))
+		}
+	}
+
+	if apimanager.Spec.Backend != nil && apimanager.Spec.Backend.RedisSentinel != nil && apimanager.IsExternal(BackendRedis) {
+		redisSentinelFldPath := specFldPath.Child("backend").Child("redisSentinel")
+		fieldErrors = append(fieldErrors, field.Invalid(redisSentinelFldPath, apimanager.Spec.Backend.RedisSentinel, "redis sentinel cannot be enabled with external backend redis"))
+	}
+
+	if apimanager.Spec.System != nil && apimanager.Spec.System.RedisSentinel != nil && apimanager.IsExternal(SystemRedis) {
+		redisSentinelFldPath := specFldPath.Child("system").Child("redisSentinel")
+		fieldErrors = append(fieldErrors, field.Invalid(redisSentinelFldPath, apimanager.Spec.System.RedisSentinel, "redis sentinel cannot be enabled with external system redis"))
+	}
+
 	return fieldErrors
 }
 
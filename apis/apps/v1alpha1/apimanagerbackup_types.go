@@ -17,7 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -30,6 +34,20 @@ type APIManagerBackupSpec struct {
 
 	// Backup data destination configuration
 	BackupDestination APIManagerBackupDestination `json:"backupDestination"`
+
+	// Encryption of the backup data. When set, every file of the backup is
+	// encrypted before it is written in the destination
+	// +optional
+	Encryption *BackupEncryptionSpec `json:"encryption,omitempty"`
+
+	// Size limit of the emptyDir volume the backup data of each job is staged
+	// in when the destination is S3 or the data is encrypted. The data is
+	// staged as a whole before it is encrypted or uploaded, so it has to fit
+	// the largest database and the system file storage PVC, plus the largest
+	// file when the data is encrypted. The backup fails when it is smaller
+	// than the system file storage PVC. Defaults to 20Gi
+	// +optional
+	StagingSizeLimit *resource.Quantity `json:"stagingSizeLimit,omitempty"`
 }
 
 // APIManagerBackupDestination defines the backup data destination
@@ -39,6 +57,10 @@ type APIManagerBackupDestination struct {
 	// PersistentVolumeClaim as backup data destination configuration
 	// +optional
 	PersistentVolumeClaim *PersistentVolumeClaimBackupDestination `json:"persistentVolumeClaim,omitempty"`
+	// S3-compatible object storage as backup data destination configuration.
+	// The backup data is stored under the <prefix>/<APIManagerBackup name>/ keys
+	// +optional
+	S3 *S3Location `json:"s3,omitempty"`
 }
 
 // PersistentVolumeClaimBackupDestination defines the configuration
@@ -62,6 +84,41 @@ type PersistentVolumeClaimBackupDestination struct {
 	StorageClass *string `json:"storageClass,omitempty"`
 }
 
+// S3Location defines a location in an S3-compatible object storage
+type S3Location struct {
+	// URL of the S3-compatible API endpoint, for example https://minio.example.com:9000.
+	// The AWS S3 endpoint of the region is used when not set
+	// +optional
+	Endpoint *string `json:"endpoint,omitempty"`
+	// Region of the bucket. Defaults to us-east-1
+	// +optional
+	Region *string `json:"region,omitempty"`
+	// Name of the bucket
+	Bucket string `json:"bucket"`
+	// Prefix of the object keys
+	// +optional
+	Prefix *string `json:"prefix,omitempty"`
+	// Use path-style requests instead of virtual-hosted-style ones. Required
+	// by S3-compatible storages without virtual host support, like MinIO
+	// +optional
+	ForcePathStyle *bool `json:"forcePathStyle,omitempty"`
+	// Secret with the credentials in the AWS_ACCESS_KEY_ID and
+	// AWS_SECRET_ACCESS_KEY keys
+	CredentialsSecretRef v1.LocalObjectReference `json:"credentialsSecretRef"`
+	// Secret with the PEM encoded certificates of the CAs trusted to verify
+	// the endpoint, in the ca.crt key
+	// +optional
+	CACertificateSecretRef *v1.LocalObjectReference `json:"caCertificateSecretRef,omitempty"`
+}
+
+// BackupEncryptionSpec references the key the backup data is encrypted with.
+// The files are encrypted with AES-256-CBC, with a key derived from the
+// secret key with PBKDF2. The same key is required to restore the backup
+type BackupEncryptionSpec struct {
+	// Secret with the encryption key in the ENCRYPTION_KEY key
+	KeySecretRef v1.LocalObjectReference `json:"keySecretRef"`
+}
+
 // APIManagerBackupStatus defines the observed state of APIManagerBackup
 type APIManagerBackupStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -92,8 +149,32 @@ type APIManagerBackupStatus struct {
 	// PersistentVolumeClaim is used as the backup data destination
 	// +optional
 	BackupPersistentVolumeClaimName *string `json:"backupPersistentVolumeClaimName,omitempty"`
+
+	// Location of the backup data in the s3://<bucket>/<key prefix> form.
+	// Only set when S3 is used as the backup data destination
+	// +optional
+	BackupS3URL *string `json:"backupS3URL,omitempty"`
+
+	// Current state of the backup.
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions common.Conditions `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
 }
 
+const (
+	// APIManagerBackupFailedConditionType is true when some step of the
+	// backup failed. Failed backups are not retried
+	APIManagerBackupFailedConditionType common.ConditionType = "Failed"
+
+	APIManagerBackupJobFailedReason common.ConditionReason = "JobFailed"
+	// APIManagerBackupStagingSizeLimitExceededReason is the reason of the
+	// Failed condition when the staging volume does not fit the system file
+	// storage PVC. The backup fails before any job is created
+	APIManagerBackupStagingSizeLimitExceededReason common.ConditionReason = "StagingSizeLimitExceeded"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -116,6 +197,10 @@ func (a *APIManagerBackup) BackupCompleted() bool {
 	return a.Status.Completed != nil && *a.Status.Completed
 }
 
+func (a *APIManagerBackup) BackupFailed() bool {
+	return a.Status.Conditions.IsTrueFor(APIManagerBackupFailedConditionType)
+}
+
 func (a *APIManagerBackup) MainStepsCompleted() bool {
 	return a.Status.MainStepsCompleted != nil && *a.Status.MainStepsCompleted
 }
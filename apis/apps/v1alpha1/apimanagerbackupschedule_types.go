@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Red Hat.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+)
+
+const (
+	// APIManagerBackupScheduleLabelKey is set in the APIManagerBackup objects
+	// created by an APIManagerBackupSchedule, with the name of the schedule
+	APIManagerBackupScheduleLabelKey = "apps.3scale.net/apimanagerbackupschedule"
+	// APIManagerBackupScheduledTimeAnnotation is set in the APIManagerBackup
+	// objects created by an APIManagerBackupSchedule, with the time the
+	// backup was scheduled at in RFC3339 form
+	APIManagerBackupScheduledTimeAnnotation = "apps.3scale.net/scheduled-time"
+)
+
+// APIManagerBackupScheduleSpec defines the desired state of APIManagerBackupSchedule
+type APIManagerBackupScheduleSpec struct {
+	// Schedule of the backups in cron format, like "0 2 * * *", evaluated in
+	// UTC. The @yearly, @monthly, @weekly, @daily and @hourly descriptors
+	// are supported too
+	Schedule string `json:"schedule"`
+
+	// Suspend stops the creation of new backups when set to true. Old
+	// backups are still pruned
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// Template of the APIManagerBackup objects created on schedule
+	BackupTemplate APIManagerBackupTemplate `json:"backupTemplate"`
+
+	// Retention rules of the backups. A backup is kept while any of the rules
+	// keeps it. All the backups are kept when not set
+	// +optional
+	Retention *APIManagerBackupRetention `json:"retention,omitempty"`
+}
+
+// APIManagerBackupTemplate describes the APIManagerBackup objects created on schedule
+type APIManagerBackupTemplate struct {
+	// Labels added to the APIManagerBackup objects
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Spec of the APIManagerBackup objects
+	Spec APIManagerBackupSpec `json:"spec"`
+}
+
+// APIManagerBackupRetention defines which completed backups are kept.
+// Backups still in progress are never pruned, and failed backups are pruned
+// once a later backup completes
+type APIManagerBackupRetention struct {
+	// Number of most recent completed backups to keep
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// Number of days to keep the last completed backup of. Days without
+	// completed backups are not counted
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+	// Number of weeks to keep the last completed backup of. Weeks without
+	// completed backups are not counted
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+}
+
+// APIManagerBackupScheduleStatus defines the observed state of APIManagerBackupSchedule
+type APIManagerBackupScheduleStatus struct {
+	// Time the last backup was scheduled at
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// Last backup completed successfully
+	// +optional
+	LastSuccessfulBackup *ScheduledBackupReference `json:"lastSuccessfulBackup,omitempty"`
+
+	// Last backup failed
+	// +optional
+	LastFailedBackup *ScheduledBackupReference `json:"lastFailedBackup,omitempty"`
+
+	// Current state of the schedule.
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions common.Conditions `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+}
+
+// ScheduledBackupReference references an APIManagerBackup created on schedule.
+// The referenced backup might have been pruned
+type ScheduledBackupReference struct {
+	// Name of the APIManagerBackup
+	Name string `json:"name"`
+	// Time the backup completed or failed at
+	Time metav1.Time `json:"time"`
+}
+
+const (
+	// APIManagerBackupScheduleInvalidConditionType is true when the schedule
+	// cannot be parsed. No backup is created while it is true
+	APIManagerBackupScheduleInvalidConditionType common.ConditionType = "Invalid"
+	// APIManagerBackupSchedulePruneFailedConditionType is true when the data
+	// of a backup could not be removed. The backup is not pruned while it is true
+	APIManagerBackupSchedulePruneFailedConditionType common.ConditionType = "PruneFailed"
+
+	APIManagerBackupScheduleInvalidScheduleReason common.ConditionReason = "InvalidSchedule"
+	APIManagerBackupSchedulePruneJobFailedReason  common.ConditionReason = "PruneJobFailed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// APIManagerBackupSchedule creates APIManager backups on schedule and prunes them
+// +kubebuilder:resource:path=apimanagerbackupschedules,scope=Namespaced
+// +kubebuilder:printcolumn:JSONPath=".spec.schedule",name=Schedule,type=string
+// +kubebuilder:printcolumn:JSONPath=".spec.suspend",name=Suspend,type=boolean
+// +kubebuilder:printcolumn:JSONPath=".status.lastScheduleTime",name="Last Schedule",type=date
+// +operator-sdk:csv:customresourcedefinitions:displayName="APIManagerBackupSchedule"
+type APIManagerBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   APIManagerBackupScheduleSpec   `json:"spec,omitempty"`
+	Status APIManagerBackupScheduleStatus `json:"status,omitempty"`
+}
+
+func (a *APIManagerBackupSchedule) Suspended() bool {
+	return a.Spec.Suspend != nil && *a.Spec.Suspend
+}
+
+// +kubebuilder:object:root=true
+
+// APIManagerBackupScheduleList contains a list of APIManagerBackupSchedule
+type APIManagerBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []APIManagerBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&APIManagerBackupSchedule{}, &APIManagerBackupScheduleList{})
+}
@@ -18,7 +18,10 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -30,6 +33,24 @@ type APIManagerRestoreSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	RestoreSource APIManagerRestoreSource `json:"restoreSource"`
+
+	// Decryption of the backup data. Required to restore backups taken with
+	// encryption, it references the key they were encrypted with
+	// +optional
+	Decryption *BackupEncryptionSpec `json:"decryption,omitempty"`
+
+	// Size limit of the emptyDir volume the backup data of each job is staged
+	// in when the source is S3 or the data is encrypted. It has to fit the
+	// largest database or, when the source is S3, the whole backup, which is
+	// downloaded to verify it. Defaults to 20Gi
+	// +optional
+	StagingSizeLimit *resource.Quantity `json:"stagingSizeLimit,omitempty"`
+
+	// Restores backups without manifest, like those taken by previous operator
+	// versions, unverified. Backups with a manifest are always verified, and
+	// encrypted backups without manifest are always rejected. Defaults to false
+	// +optional
+	AllowUnverifiedBackup bool `json:"allowUnverifiedBackup,omitempty"`
 }
 
 // APIManagerRestoreSource defines the backup data restore source
@@ -39,6 +60,10 @@ type APIManagerRestoreSource struct {
 	// +optional
 	// Restore data soure configuration
 	PersistentVolumeClaim *PersistentVolumeClaimRestoreSource `json:"persistentVolumeClaim,omitempty"`
+	// S3-compatible object storage location of the backup data. The prefix
+	// is the one of the backup, <prefix>/<APIManagerBackup name>
+	// +optional
+	S3 *S3Location `json:"s3,omitempty"`
 }
 
 // PersistentVolumeClaimRestoreSource defines the configuration
@@ -75,8 +100,34 @@ type APIManagerRestoreStatus struct {
 	// Restore completion time. It is represented in RFC3339 form and is in UTC.
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Current state of the restore.
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions common.Conditions `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
 }
 
+const (
+	// APIManagerRestoreBackupVerifiedConditionType is true when the backup data
+	// matches its manifest and can be restored by the operator. It is false
+	// when the backup is rejected, in which case nothing is restored, and
+	// unknown when the backup has no manifest and allowUnverifiedBackup is set,
+	// in which case it is restored unverified
+	APIManagerRestoreBackupVerifiedConditionType common.ConditionType = "BackupVerified"
+
+	APIManagerRestoreManifestVerifiedReason    common.ConditionReason = "ManifestVerified"
+	APIManagerRestoreManifestNotFoundReason    common.ConditionReason = "ManifestNotFound"
+	APIManagerRestoreBackupCorruptedReason     common.ConditionReason = "BackupCorrupted"
+	APIManagerRestoreIncompatibleVersionReason common.ConditionReason = "IncompatibleVersion"
+	APIManagerRestoreDecryptionFailedReason    common.ConditionReason = "DecryptionFailed"
+	// The manifest of an encrypted backup does not match its MAC, either
+	// because the backup was tampered with or the decryption key is not the
+	// encryption key
+	APIManagerRestoreManifestNotAuthenticReason common.ConditionReason = "ManifestNotAuthentic"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -103,6 +154,10 @@ func (a *APIManagerRestore) MainStepsCompleted() bool {
 	return a.Status.MainStepsCompleted != nil && *a.Status.MainStepsCompleted
 }
 
+func (a *APIManagerRestore) BackupRejected() bool {
+	return a.Status.Conditions.IsFalseFor(APIManagerRestoreBackupVerifiedConditionType)
+}
+
 // +kubebuilder:object:root=true
 
 // APIManagerRestoreList contains a list of APIManagerRestore
@@ -22,23 +22,25 @@ import (
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	apimachinerymetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/operator"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
 	"github.com/3scale/3scale-operator/pkg/handlers"
 
 	"github.com/3scale/3scale-operator/pkg/helper"
@@ -49,8 +51,7 @@ import (
 // APIManagerReconciler reconciles a APIManager object
 type APIManagerReconciler struct {
 	*reconcilers.BaseReconciler
-	SecretLabelSelector apimachinerymetav1.LabelSelector
-	WatchedNamespace    string
+	WatchedNamespace string
 }
 
 // blank assignment to verify that APIManagerReconciler implements reconcile.Reconciler
@@ -68,10 +69,14 @@ var _ reconcile.Reconciler = &APIManagerReconciler{}
 // +kubebuilder:rbac:groups=route.openshift.io,namespace=placeholder,resources=routes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=route.openshift.io,namespace=placeholder,resources=routes/custom-host,verbs=create
 // +kubebuilder:rbac:groups=route.openshift.io,namespace=placeholder,resources=routes/status,verbs=get
+// +kubebuilder:rbac:groups=networking.k8s.io,namespace=placeholder,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.openshift.io,namespace=placeholder,resources=deploymentconfigs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,namespace=placeholder,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,namespace=placeholder,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy,namespace=placeholder,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,namespace=placeholder,resources=podmonitors;servicemonitors;prometheusrules,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=integreatly.org,namespace=placeholder,resources=grafanadashboards,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=keda.sh,namespace=placeholder,resources=scaledobjects;triggerauthentications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
 
 func (r *APIManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -90,6 +95,18 @@ func (r *APIManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	// Paused APIManagers keep reporting their status, nothing else is reconciled
+	if common.IsPaused(instance) {
+		logger.Info("Reconciliation paused")
+		// Defaults are required to calculate the status, they are not persisted
+		instance.UpdateExternalComponentsFromHighAvailability()
+		_, err = instance.SetDefaults()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return r.reconcileAPIManagerStatus(instance)
+	}
+
 	err = r.validateCR(instance)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -126,14 +143,27 @@ func (r *APIManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return statusResult, nil
 	}
 
+	if specResult.RequeueAfter > 0 {
+		logger.Info("Reconciling not finished. Requeueing.", "after", specResult.RequeueAfter)
+		return ctrl.Result{RequeueAfter: specResult.RequeueAfter}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
 func (r *APIManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	secretToApimanagerEventMapper := &SecretToApimanagerEventMapper{
-		K8sClient: r.Client(),
-		Logger:    r.Logger().WithName("secretToApimanagerEventMapper"),
-		Namespace: r.WatchedNamespace,
+	secretToApimanagerEventMapper := &ObjectToApimanagerEventMapper{
+		K8sClient:   r.Client(),
+		Logger:      r.Logger().WithName("secretToApimanagerEventMapper"),
+		Namespace:   r.WatchedNamespace,
+		LabelPrefix: operator.APIManagerSecretLabelPrefix,
+	}
+
+	configMapToApimanagerEventMapper := &ObjectToApimanagerEventMapper{
+		K8sClient:   r.Client(),
+		Logger:      r.Logger().WithName("configMapToApimanagerEventMapper"),
+		Namespace:   r.WatchedNamespace,
+		LabelPrefix: operator.APIManagerConfigMapLabelPrefix,
 	}
 
 	handlers := &handlers.APIManagerRoutesEventMapper{
@@ -141,21 +171,44 @@ func (r *APIManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Logger:    r.Logger().WithName("APIManagerRoutesHandler"),
 	}
 
-	labelSelectorPredicate, err := predicate.LabelSelectorPredicate(r.SecretLabelSelector)
+	hasDeploymentConfigs, err := r.HasDeploymentConfigs()
+	if err != nil {
+		return err
+	}
+
+	hasRoutes, err := r.HasRoutes()
 	if err != nil {
-		return nil
+		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1alpha1.APIManager{}).
 		Watches(
 			&source.Kind{Type: &v1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(secretToApimanagerEventMapper.Map),
-			builder.WithPredicates(labelSelectorPredicate),
 		).
-		Owns(&appsv1.DeploymentConfig{}).
-		Watches(&source.Kind{Type: &routev1.Route{}}, handler.EnqueueRequestsFromMapFunc(handlers.Map)).
-		Complete(r)
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(configMapToApimanagerEventMapper.Map),
+		).
+		Owns(&k8sappsv1.Deployment{}).
+		Owns(&k8sappsv1.StatefulSet{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&batchv1.Job{})
+
+	// DeploymentConfigs are not available on clusters other than OpenShift
+	if hasDeploymentConfigs {
+		controllerBuilder = controllerBuilder.Owns(&appsv1.DeploymentConfig{})
+	}
+
+	// Routes are not available on clusters other than OpenShift
+	if hasRoutes {
+		controllerBuilder = controllerBuilder.Watches(&source.Kind{Type: &routev1.Route{}}, handler.EnqueueRequestsFromMapFunc(handlers.Map))
+	}
+
+	return controllerBuilder.Complete(r)
 }
 
 func (r *APIManagerReconciler) validateCR(cr *appsv1alpha1.APIManager) error {
@@ -213,12 +266,36 @@ func (r *APIManagerReconciler) reconcileAPIManagerLogic(cr *appsv1alpha1.APIMana
 		return result, err
 	}
 
+	// A rotation in progress holds the reconciliation of the components until it
+	// completes or times out
+	secretRotationReconciler := operator.NewSecretRotationReconciler(baseAPIManagerLogicReconciler)
+	result, err = secretRotationReconciler.Reconcile()
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
 	dependencyReconciler := r.dependencyReconcilerForComponents(cr, baseAPIManagerLogicReconciler)
 	result, err = dependencyReconciler.Reconcile()
 	if err != nil || result.Requeue {
 		return result, err
 	}
 
+	// The components are not deployed or updated until the external components pass the checks
+	preflightReconciler := operator.NewPreflightReconciler(baseAPIManagerLogicReconciler)
+	result, err = preflightReconciler.Reconcile()
+	if err != nil || result.Requeue || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	// Backend reads secrets owned by system. They exist before the backend
+	// workloads are created for the content hashes of the pod templates to be
+	// final on creation
+	systemReconciler := operator.NewSystemReconciler(baseAPIManagerLogicReconciler)
+	err = systemReconciler.ReconcileSecretsAndConfigMaps()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	backendReconciler := operator.NewBackendReconciler(baseAPIManagerLogicReconciler)
 	result, err = backendReconciler.Reconcile()
 	if err != nil || result.Requeue {
@@ -237,14 +314,14 @@ func (r *APIManagerReconciler) reconcileAPIManagerLogic(cr *appsv1alpha1.APIMana
 		return result, err
 	}
 
-	systemReconciler := operator.NewSystemReconciler(baseAPIManagerLogicReconciler)
-	result, err = systemReconciler.Reconcile()
+	// System reads the zync secret
+	zyncReconciler := operator.NewZyncReconciler(baseAPIManagerLogicReconciler)
+	result, err = zyncReconciler.Reconcile()
 	if err != nil || result.Requeue {
 		return result, err
 	}
 
-	zyncReconciler := operator.NewZyncReconciler(baseAPIManagerLogicReconciler)
-	result, err = zyncReconciler.Reconcile()
+	result, err = systemReconciler.Reconcile()
 	if err != nil || result.Requeue {
 		return result, err
 	}
@@ -255,12 +332,32 @@ func (r *APIManagerReconciler) reconcileAPIManagerLogic(cr *appsv1alpha1.APIMana
 		return result, err
 	}
 
+	networkPolicyReconciler := operator.NewNetworkPolicyReconciler(baseAPIManagerLogicReconciler)
+	result, err = networkPolicyReconciler.Reconcile()
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
 	genericMonitoringReconciler := operator.NewGenericMonitoringReconciler(baseAPIManagerLogicReconciler)
 	result, err = genericMonitoringReconciler.Reconcile()
 	if err != nil || result.Requeue {
 		return result, err
 	}
 
+	// Secrets and configmaps consumed by all the components are known at this point
+	result, err = baseAPIManagerLogicReconciler.ReconcileWatchedObjectsLabels()
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
+	// The objects required by the current spec are known at this point
+	result, err = baseAPIManagerLogicReconciler.PruneOrphanedObjects()
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
+	baseAPIManagerLogicReconciler.SetUnmanagedObjectsStatus()
+
 	return ctrl.Result{}, nil
 }
 
@@ -0,0 +1,456 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ComponentAvailableReason           common.ConditionReason = "Available"
+	ComponentsUnavailableReason        common.ConditionReason = "ComponentsUnavailable"
+	WorkloadNotFoundReason             common.ConditionReason = "NotFound"
+	WorkloadReplicasNotReadyReason     common.ConditionReason = "ReplicasNotReady"
+	PersistentVolumeClaimPendingReason common.ConditionReason = "PersistentVolumeClaimPending"
+	RouteNotFoundReason                common.ConditionReason = "RouteNotFound"
+	RouteNotAdmittedReason             common.ConditionReason = "RouteNotAdmitted"
+	IngressNotFoundReason              common.ConditionReason = "IngressNotFound"
+	IngressNotReadyReason              common.ConditionReason = "IngressNotReady"
+	MonitoringKindsMissingReason       common.ConditionReason = "MonitoringKindsMissing"
+)
+
+// apimanagerComponent groups the workloads whose health is reported by a condition
+type apimanagerComponent struct {
+	name          string
+	conditionType common.ConditionType
+	workloads     []string
+}
+
+var apimanagerComponents = []apimanagerComponent{
+	{
+		name:          "Backend",
+		conditionType: appsv1alpha1.APIManagerBackendAvailableConditionType,
+		workloads:     []string{component.BackendListenerName, component.BackendWorkerName, component.BackendCronName},
+	},
+	{
+		name:          "System",
+		conditionType: appsv1alpha1.APIManagerSystemAvailableConditionType,
+		workloads:     []string{component.SystemAppDeploymentName, component.SystemSidekiqName},
+	},
+	{
+		name:          "Zync",
+		conditionType: appsv1alpha1.APIManagerZyncAvailableConditionType,
+		workloads:     []string{component.ZyncName, component.ZyncQueDeploymentName},
+	},
+	{
+		name:          "Apicast",
+		conditionType: appsv1alpha1.APIManagerApicastAvailableConditionType,
+		workloads:     []string{component.ApicastStagingName, component.ApicastProductionName},
+	},
+	{
+		name:          "Databases",
+		conditionType: appsv1alpha1.APIManagerDatabasesAvailableConditionType,
+		workloads:     []string{component.SystemMySQLDeploymentName, component.SystemPostgreSQLDeploymentName, component.ZyncDatabaseDeploymentName},
+	},
+	{
+		name:          "Redis",
+		conditionType: appsv1alpha1.APIManagerRedisAvailableConditionType,
+		workloads: []string{
+			component.BackendRedisDeploymentName, component.SystemRedisDeploymentName,
+			component.BackendRedisSentinelStatefulSetName, component.SystemRedisSentinelStatefulSetName,
+		},
+	},
+	{
+		name:          "Memcached",
+		conditionType: appsv1alpha1.APIManagerMemcachedAvailableConditionType,
+		workloads:     []string{component.SystemMemcachedDeploymentName},
+	},
+	{
+		name:          "Searchd",
+		conditionType: appsv1alpha1.APIManagerSearchdAvailableConditionType,
+		workloads:     []string{component.SystemSearchdDeploymentName},
+	},
+}
+
+// podWaitingFailureReasons are the reasons of waiting containers which
+// will not start without an intervention
+var podWaitingFailureReasons = []string{
+	"ImagePullBackOff",
+	"ErrImagePull",
+	"InvalidImageName",
+	"CrashLoopBackOff",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+	"RunContainerError",
+}
+
+type workloadHealth struct {
+	available bool
+	reason    common.ConditionReason
+	message   string
+}
+
+// workloadsHealth returns the health of the expected workloads by name.
+// Components whose migration to Deployment was rolled back are checked on the DeploymentConfig
+func (s *APIManagerStatusReconciler) workloadsHealth(deployments []k8sappsv1.Deployment, dcs []appsv1.DeploymentConfig) (map[string]workloadHealth, error) {
+	deploymentKind := "DeploymentConfig"
+	if s.apimanagerResource.IsDeploymentWorkloadEnabled() {
+		deploymentKind = "Deployment"
+	}
+
+	workloads := map[string]workloadHealth{}
+	for _, name := range s.expectedDeploymentNames(s.apimanagerResource) {
+		var health workloadHealth
+		var err error
+		if idx := deploymentConfigIndex(dcs, name); idx != -1 {
+			dc := &dcs[idx]
+			health, err = s.workloadHealth("DeploymentConfig", name, helper.IsDeploymentConfigAvailable(dc),
+				dc.Spec.Selector, dc.Status.ReadyReplicas, dc.Spec.Replicas)
+		} else if idx := deploymentIndex(deployments, name); idx != -1 {
+			deployment := &deployments[idx]
+			health, err = s.workloadHealth("Deployment", name, helper.IsDeploymentAvailable(deployment),
+				labelSelectorMatchLabels(deployment.Spec.Selector), deployment.Status.ReadyReplicas, replicasOrDefault(deployment.Spec.Replicas))
+		} else {
+			health = workloadNotFound(deploymentKind, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		workloads[name] = health
+	}
+
+	deploymentLister := s.deploymentsLister(s.apimanagerResource)
+	for _, name := range deploymentLister.StatefulSetNames() {
+		statefulSet := &k8sappsv1.StatefulSet{}
+		err := s.Client().Get(context.Background(), types.NamespacedName{Namespace: s.apimanagerResource.Namespace, Name: name}, statefulSet)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if errors.IsNotFound(err) {
+			workloads[name] = workloadNotFound("StatefulSet", name)
+			continue
+		}
+		workloads[name], err = s.workloadHealth("StatefulSet", name, helper.IsStatefulSetAvailable(statefulSet),
+			labelSelectorMatchLabels(statefulSet.Spec.Selector), statefulSet.Status.ReadyReplicas, replicasOrDefault(statefulSet.Spec.Replicas))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return workloads, nil
+}
+
+// workloadHealth looks into the pods of an unavailable workload for the cause:
+// containers failing to start, pending volume claims or scheduling failures
+func (s *APIManagerStatusReconciler) workloadHealth(kind, name string, available bool, selector map[string]string, ready, desired int32) (workloadHealth, error) {
+	if available {
+		return workloadHealth{available: true}, nil
+	}
+
+	unavailable := func(reason common.ConditionReason, format string, a ...interface{}) workloadHealth {
+		return workloadHealth{reason: reason, message: fmt.Sprintf("%s %s: %s", kind, name, fmt.Sprintf(format, a...))}
+	}
+
+	var pods []v1.Pod
+	if len(selector) > 0 {
+		podList := &v1.PodList{}
+		err := s.Client().List(context.Background(), podList, client.InNamespace(s.apimanagerResource.Namespace), client.MatchingLabels(selector))
+		if err != nil {
+			return workloadHealth{}, err
+		}
+		pods = podList.Items
+		sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	}
+
+	for _, pod := range pods {
+		statuses := append(append([]v1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			waiting := status.State.Waiting
+			if waiting == nil || !helper.ArrayContains(podWaitingFailureReasons, waiting.Reason) {
+				continue
+			}
+			message := fmt.Sprintf("pod %s container %s is waiting", pod.Name, status.Name)
+			if waiting.Message != "" {
+				message = fmt.Sprintf("pod %s container %s: %s", pod.Name, status.Name, waiting.Message)
+			}
+			return unavailable(common.ConditionReason(waiting.Reason), "%s", message), nil
+		}
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodPending {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			pvc := &v1.PersistentVolumeClaim{}
+			err := s.Client().Get(context.Background(), types.NamespacedName{Namespace: pod.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}, pvc)
+			if err != nil && !errors.IsNotFound(err) {
+				return workloadHealth{}, err
+			}
+			if errors.IsNotFound(err) {
+				return unavailable(PersistentVolumeClaimPendingReason, "pod %s: persistentvolumeclaim %s not found", pod.Name, volume.PersistentVolumeClaim.ClaimName), nil
+			}
+			if pvc.Status.Phase == v1.ClaimPending {
+				return unavailable(PersistentVolumeClaimPendingReason, "pod %s: persistentvolumeclaim %s is pending", pod.Name, pvc.Name), nil
+			}
+		}
+	}
+
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse && condition.Reason != "" {
+				return unavailable(common.ConditionReason(condition.Reason), "pod %s: %s", pod.Name, condition.Message), nil
+			}
+		}
+	}
+
+	return unavailable(WorkloadReplicasNotReadyReason, "%d/%d replicas ready", ready, desired), nil
+}
+
+// componentCondition reports the health of the workloads of the component.
+// It returns false when none of the workloads is expected, the component being external.
+func componentCondition(c apimanagerComponent, workloads map[string]workloadHealth) (common.Condition, bool) {
+	condition := common.Condition{
+		Type:   c.conditionType,
+		Status: v1.ConditionTrue,
+		Reason: ComponentAvailableReason,
+	}
+
+	expected := false
+	messages := []string{}
+	for _, name := range c.workloads {
+		health, ok := workloads[name]
+		if !ok {
+			continue
+		}
+		expected = true
+		if health.available {
+			continue
+		}
+		if condition.Status == v1.ConditionTrue {
+			condition.Status = v1.ConditionFalse
+			condition.Reason = health.reason
+		}
+		messages = append(messages, health.message)
+	}
+	condition.Message = strings.Join(messages, "; ")
+
+	return condition, expected
+}
+
+func (s *APIManagerStatusReconciler) routesCondition() (common.Condition, error) {
+	wildcardDomain := s.apimanagerResource.Spec.WildcardDomain
+	expectedRouteHosts := []string{
+		fmt.Sprintf("backend-%s.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                // Backend Listener route
+		fmt.Sprintf("api-%s-apicast-production.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain), // Apicast Production default tenant Route
+		fmt.Sprintf("api-%s-apicast-staging.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),    // Apicast Staging default tenant Route
+		fmt.Sprintf("master.%s", wildcardDomain),                                                           // System's Master Portal Route
+		fmt.Sprintf("%s.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                        // System's default tenant Developer Portal Route
+		fmt.Sprintf("%s-admin.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                  // System's default tenant Admin Portal Route
+	}
+
+	var health []workloadHealth
+	var err error
+	if s.apimanagerResource.IsIngressEnabled() {
+		health, err = s.defaultIngressesHealth(expectedRouteHosts)
+	} else {
+		health, err = s.defaultRoutesHealth(expectedRouteHosts)
+	}
+	if err != nil {
+		return common.Condition{}, err
+	}
+
+	condition := common.Condition{
+		Type:   appsv1alpha1.APIManagerRoutesAvailableConditionType,
+		Status: v1.ConditionTrue,
+		Reason: ComponentAvailableReason,
+	}
+	if len(health) > 0 {
+		messages := make([]string, 0, len(health))
+		for _, h := range health {
+			messages = append(messages, h.message)
+		}
+		condition.Status = v1.ConditionFalse
+		condition.Reason = health[0].reason
+		condition.Message = strings.Join(messages, "; ")
+	}
+
+	return condition, nil
+}
+
+// defaultRoutesHealth returns the problems found on the routes of the default tenant
+func (s *APIManagerStatusReconciler) defaultRoutesHealth(expectedHosts []string) ([]workloadHealth, error) {
+	routeList := &routev1.RouteList{}
+	err := s.Client().List(context.TODO(), routeList, client.InNamespace(s.apimanagerResource.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list routes: %w", err)
+	}
+
+	routes := append([]routev1.Route(nil), routeList.Items...)
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Name < routes[j].Name })
+
+	var problems []workloadHealth
+	for _, expectedHost := range expectedHosts {
+		routeIdx := helper.RouteFindByHost(routes, expectedHost)
+		if routeIdx == -1 {
+			problems = append(problems, workloadHealth{
+				reason:  RouteNotFoundReason,
+				message: fmt.Sprintf("route for host %s not found", expectedHost),
+			})
+			continue
+		}
+		route := &routes[routeIdx]
+		if helper.IsRouteReady(route) {
+			continue
+		}
+		message := fmt.Sprintf("route %s for host %s not admitted", route.Name, expectedHost)
+		if admissionMessage := routeAdmissionMessage(route); admissionMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, admissionMessage)
+		}
+		problems = append(problems, workloadHealth{reason: RouteNotAdmittedReason, message: message})
+	}
+
+	return problems, nil
+}
+
+// defaultIngressesHealth returns the problems found on the ingresses of the default tenant
+func (s *APIManagerStatusReconciler) defaultIngressesHealth(expectedHosts []string) ([]workloadHealth, error) {
+	ingressList := &networkingv1.IngressList{}
+	err := s.Client().List(context.TODO(), ingressList, client.InNamespace(s.apimanagerResource.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list ingresses: %w", err)
+	}
+
+	ingresses := append([]networkingv1.Ingress(nil), ingressList.Items...)
+	sort.Slice(ingresses, func(i, j int) bool { return ingresses[i].Name < ingresses[j].Name })
+
+	var problems []workloadHealth
+	for _, expectedHost := range expectedHosts {
+		ingressIdx := helper.IngressFindByHost(ingresses, expectedHost)
+		if ingressIdx == -1 {
+			problems = append(problems, workloadHealth{
+				reason:  IngressNotFoundReason,
+				message: fmt.Sprintf("ingress for host %s not found", expectedHost),
+			})
+		} else if !helper.IsIngressReady(&ingresses[ingressIdx]) {
+			problems = append(problems, workloadHealth{
+				reason:  IngressNotReadyReason,
+				message: fmt.Sprintf("ingress %s for host %s has no load balancer address", ingresses[ingressIdx].Name, expectedHost),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// monitoringCondition reports the monitoring kinds missing in the cluster,
+// for which the monitoring resources are not created.
+// It returns nil when monitoring is not enabled.
+func (s *APIManagerStatusReconciler) monitoringCondition() (*common.Condition, error) {
+	if !s.apimanagerResource.IsMonitoringEnabled() {
+		return nil, nil
+	}
+
+	kinds := []struct {
+		name    string
+		enabled bool
+		exists  func() (bool, error)
+	}{
+		{"PodMonitor", true, s.HasPodMonitors},
+		{"ServiceMonitor", true, s.HasServiceMonitors},
+		{"GrafanaDashboard", true, s.HasGrafanaDashboards},
+		{"PrometheusRule", s.apimanagerResource.IsPrometheusRulesEnabled(), s.HasPrometheusRules},
+	}
+
+	missing := []string{}
+	for _, kind := range kinds {
+		if !kind.enabled {
+			continue
+		}
+		exists, err := kind.exists()
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, kind.name)
+		}
+	}
+
+	condition := &common.Condition{
+		Type:   appsv1alpha1.APIManagerMonitoringAvailableConditionType,
+		Status: v1.ConditionTrue,
+		Reason: ComponentAvailableReason,
+	}
+	if len(missing) > 0 {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = MonitoringKindsMissingReason
+		condition.Message = fmt.Sprintf("Kinds not available in the cluster: %s", strings.Join(missing, ", "))
+	}
+
+	return condition, nil
+}
+
+func routeAdmissionMessage(route *routev1.Route) string {
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status != v1.ConditionTrue && condition.Message != "" {
+				return condition.Message
+			}
+		}
+	}
+	return ""
+}
+
+func workloadNotFound(kind, name string) workloadHealth {
+	return workloadHealth{reason: WorkloadNotFoundReason, message: fmt.Sprintf("%s %s not found", kind, name)}
+}
+
+func deploymentConfigIndex(dcs []appsv1.DeploymentConfig, name string) int {
+	for idx := range dcs {
+		if dcs[idx].Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func deploymentIndex(deployments []k8sappsv1.Deployment, name string) int {
+	for idx := range deployments {
+		if deployments[idx].Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func labelSelectorMatchLabels(selector *metav1.LabelSelector) map[string]string {
+	if selector == nil {
+		return nil
+	}
+	return selector.MatchLabels
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
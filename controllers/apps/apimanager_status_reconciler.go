@@ -4,18 +4,19 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
 	"github.com/3scale/3scale-operator/pkg/apispkg/common"
-	"github.com/3scale/3scale-operator/pkg/helper"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 	"github.com/RHsyseng/operator-utils/pkg/olm"
 	"github.com/go-logr/logr"
 	appsv1 "github.com/openshift/api/apps/v1"
-	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -68,26 +69,132 @@ func (s *APIManagerStatusReconciler) Reconcile() (reconcile.Result, error) {
 func (s *APIManagerStatusReconciler) calculateStatus() (*appsv1alpha1.APIManagerStatus, error) {
 	newStatus := &appsv1alpha1.APIManagerStatus{}
 
-	deployments, err := s.existingDeployments()
+	newStatus.Conditions = s.apimanagerResource.Status.Conditions.Copy()
+	newStatus.Conditions.SetPausedCondition(s.apimanagerResource)
+
+	var deployments []k8sappsv1.Deployment
+	var deploymentConfigs []appsv1.DeploymentConfig
+	var deploymentStatus olm.DeploymentStatus
+	if s.apimanagerResource.IsDeploymentWorkloadEnabled() {
+		var err error
+		deployments, err = s.existingK8sDeployments()
+		if err != nil {
+			return nil, err
+		}
+
+		// DeploymentConfigs still existing are either being migrated or rolled back
+		existingDCs, err := s.existingDeployments()
+		if err != nil {
+			return nil, err
+		}
+		var migratingDCs, rolledBackDCs []appsv1.DeploymentConfig
+		for _, dc := range existingDCs {
+			if _, ok := dc.Annotations[appsv1alpha1.MigrationFailedAnnotation]; ok {
+				rolledBackDCs = append(rolledBackDCs, dc)
+			} else {
+				migratingDCs = append(migratingDCs, dc)
+			}
+		}
+
+		newStatus.Conditions.SetCondition(migrationCondition(appsv1alpha1.APIManagerMigratingConditionType, "MigrationInProgress",
+			"DeploymentConfigs being migrated to Deployments", migratingDCs))
+		newStatus.Conditions.SetCondition(migrationCondition(appsv1alpha1.APIManagerMigrationFailedConditionType, "MigrationRolledBack",
+			"DeploymentConfigs rolled back after failed migration to Deployments", rolledBackDCs))
+
+		deploymentConfigs = rolledBackDCs
+		deploymentStatus = olm.GetDeploymentStatus(deployments)
+		rolledBackStatus := olm.GetDeploymentConfigStatus(rolledBackDCs)
+		deploymentStatus.Ready = append(deploymentStatus.Ready, rolledBackStatus.Ready...)
+		deploymentStatus.Starting = append(deploymentStatus.Starting, rolledBackStatus.Starting...)
+		deploymentStatus.Stopped = append(deploymentStatus.Stopped, rolledBackStatus.Stopped...)
+		sort.Strings(deploymentStatus.Ready)
+		sort.Strings(deploymentStatus.Starting)
+		sort.Strings(deploymentStatus.Stopped)
+	} else {
+		var err error
+		deploymentConfigs, err = s.existingDeployments()
+		if err != nil {
+			return nil, err
+		}
+		deploymentStatus = olm.GetDeploymentConfigStatus(deploymentConfigs)
+		newStatus.Conditions.RemoveCondition(appsv1alpha1.APIManagerMigratingConditionType)
+		newStatus.Conditions.RemoveCondition(appsv1alpha1.APIManagerMigrationFailedConditionType)
+	}
+
+	workloads, err := s.workloadsHealth(deployments, deploymentConfigs)
 	if err != nil {
 		return nil, err
 	}
 
-	newStatus.Conditions = s.apimanagerResource.Status.Conditions.Copy()
+	unavailableComponents := []string{}
+	for _, c := range apimanagerComponents {
+		condition, expected := componentCondition(c, workloads)
+		if !expected {
+			newStatus.Conditions.RemoveCondition(c.conditionType)
+			continue
+		}
+		newStatus.Conditions.SetCondition(condition)
+		if condition.Status != v1.ConditionTrue {
+			unavailableComponents = append(unavailableComponents, c.name)
+		}
+	}
+
+	routesCondition, err := s.routesCondition()
+	if err != nil {
+		return nil, err
+	}
+	newStatus.Conditions.SetCondition(routesCondition)
+	if routesCondition.Status != v1.ConditionTrue {
+		unavailableComponents = append(unavailableComponents, "Routes")
+	}
 
-	availableCondition, err := s.apimanagerAvailableCondition(deployments)
+	// Monitoring does not affect the availability of 3scale
+	monitoringCondition, err := s.monitoringCondition()
 	if err != nil {
 		return nil, err
 	}
-	newStatus.Conditions.SetCondition(availableCondition)
+	if monitoringCondition == nil {
+		newStatus.Conditions.RemoveCondition(appsv1alpha1.APIManagerMonitoringAvailableConditionType)
+	} else {
+		newStatus.Conditions.SetCondition(*monitoringCondition)
+	}
+
+	s.logger.V(1).Info("Status apimanagerAvailableCondition", "unavailableComponents", unavailableComponents)
+	newStatus.Conditions.SetCondition(apimanagerAvailableCondition(unavailableComponents))
 
-	deploymentStatus := olm.GetDeploymentConfigStatus(deployments)
 	newStatus.Deployments = deploymentStatus
+	newStatus.SecretRotation = s.apimanagerResource.Status.SecretRotation
+	newStatus.PreflightChecksHash = s.apimanagerResource.Status.PreflightChecksHash
+	newStatus.UnmanagedObjects = s.apimanagerResource.Status.UnmanagedObjects
 
 	return newStatus, nil
 }
 
+func migrationCondition(conditionType common.ConditionType, reason common.ConditionReason, message string, dcs []appsv1.DeploymentConfig) common.Condition {
+	condition := common.Condition{
+		Type:   conditionType,
+		Status: v1.ConditionFalse,
+	}
+
+	if len(dcs) > 0 {
+		names := make([]string, 0, len(dcs))
+		for _, dc := range dcs {
+			names = append(names, dc.Name)
+		}
+		condition.Status = v1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = fmt.Sprintf("%s: %s", message, strings.Join(names, ", "))
+	}
+
+	return condition
+}
+
 func (s *APIManagerStatusReconciler) expectedDeploymentNames(instance *appsv1alpha1.APIManager) []string {
+	deploymentLister := s.deploymentsLister(instance)
+	return deploymentLister.DeploymentNames()
+}
+
+func (s *APIManagerStatusReconciler) deploymentsLister(instance *appsv1alpha1.APIManager) component.DeploymentsLister {
 	var systemDatabaseType component.SystemDatabaseType
 	var externalRedisDatabases bool
 	var externalZyncDatabase bool
@@ -108,31 +215,14 @@ func (s *APIManagerStatusReconciler) expectedDeploymentNames(instance *appsv1alp
 		externalZyncDatabase = true
 	}
 
-	deploymentLister := component.DeploymentsLister{
+	return component.DeploymentsLister{
 		SystemDatabaseType:     systemDatabaseType,
 		ExternalRedisDatabases: externalRedisDatabases,
 		ExternalZyncDatabase:   externalZyncDatabase,
+		ExternalMemcached:      instance.IsExternal(appsv1alpha1.SystemMemcached),
+		BackendRedisSentinel:   instance.IsBackendRedisSentinelEnabled(),
+		SystemRedisSentinel:    instance.IsSystemRedisSentinelEnabled(),
 	}
-
-	return deploymentLister.DeploymentNames()
-}
-
-func (s *APIManagerStatusReconciler) deploymentsAvailable(existingDeployments []appsv1.DeploymentConfig) bool {
-	expectedDeploymentNames := s.expectedDeploymentNames(s.apimanagerResource)
-	for _, deploymentName := range expectedDeploymentNames {
-		foundExistingDCIdx := -1
-		for idx, existingDC := range existingDeployments {
-			if existingDC.Name == deploymentName {
-				foundExistingDCIdx = idx
-				break
-			}
-		}
-		if foundExistingDCIdx == -1 || !helper.IsDeploymentConfigAvailable(&existingDeployments[foundExistingDCIdx]) {
-			return false
-		}
-	}
-
-	return true
 }
 
 func (s *APIManagerStatusReconciler) existingDeployments() ([]appsv1.DeploymentConfig, error) {
@@ -142,6 +232,10 @@ func (s *APIManagerStatusReconciler) existingDeployments() ([]appsv1.DeploymentC
 	for _, dcName := range expectedDeploymentNames {
 		existingDeploymentConfig := &appsv1.DeploymentConfig{}
 		err := s.Client().Get(context.Background(), types.NamespacedName{Namespace: s.apimanagerResource.Namespace, Name: dcName}, existingDeploymentConfig)
+		// DeploymentConfig kind might not be available in the cluster
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
 		if err != nil && !errors.IsNotFound(err) {
 			return nil, err
 		}
@@ -161,66 +255,45 @@ func (s *APIManagerStatusReconciler) existingDeployments() ([]appsv1.DeploymentC
 	return dcs, nil
 }
 
-func (s *APIManagerStatusReconciler) apimanagerAvailableCondition(existingDeployments []appsv1.DeploymentConfig) (common.Condition, error) {
-	deploymentsAvailable := s.deploymentsAvailable(existingDeployments)
-
-	defaultRoutesReady, err := s.defaultRoutesReady()
-	if err != nil {
-		return common.Condition{}, err
-	}
+func (s *APIManagerStatusReconciler) existingK8sDeployments() ([]k8sappsv1.Deployment, error) {
+	expectedDeploymentNames := s.expectedDeploymentNames(s.apimanagerResource)
 
-	newAvailableCondition := common.Condition{
-		Type:   appsv1alpha1.APIManagerAvailableConditionType,
-		Status: v1.ConditionFalse,
-	}
+	var deployments []k8sappsv1.Deployment
+	for _, deploymentName := range expectedDeploymentNames {
+		existingDeployment := &k8sappsv1.Deployment{}
+		err := s.Client().Get(context.Background(), types.NamespacedName{Namespace: s.apimanagerResource.Namespace, Name: deploymentName}, existingDeployment)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err != nil && errors.IsNotFound(err) {
+			continue
+		}
 
-	s.logger.V(1).Info("Status apimanagerAvailableCondition", "deploymentsAvailable", deploymentsAvailable, "defaultRoutesReady", defaultRoutesReady)
-	if deploymentsAvailable && defaultRoutesReady {
-		newAvailableCondition.Status = v1.ConditionTrue
+		for _, ownerRef := range existingDeployment.GetOwnerReferences() {
+			if ownerRef.UID == s.apimanagerResource.UID {
+				deployments = append(deployments, *existingDeployment)
+				break
+			}
+		}
 	}
+	sort.Slice(deployments, func(i, j int) bool { return deployments[i].Name < deployments[j].Name })
 
-	return newAvailableCondition, nil
+	return deployments, nil
 }
 
-func (s *APIManagerStatusReconciler) defaultRoutesReady() (bool, error) {
-	wildcardDomain := s.apimanagerResource.Spec.WildcardDomain
-	expectedRouteHosts := []string{
-		fmt.Sprintf("backend-%s.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                // Backend Listener route
-		fmt.Sprintf("api-%s-apicast-production.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain), // Apicast Production default tenant Route
-		fmt.Sprintf("api-%s-apicast-staging.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),    // Apicast Staging default tenant Route
-		fmt.Sprintf("master.%s", wildcardDomain),                                                           // System's Master Portal Route
-		fmt.Sprintf("%s.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                        // System's default tenant Developer Portal Route
-		fmt.Sprintf("%s-admin.%s", *s.apimanagerResource.Spec.TenantName, wildcardDomain),                  // System's default tenant Admin Portal Route
-	}
-
-	listOps := []client.ListOption{
-		client.InNamespace(s.apimanagerResource.Namespace),
-	}
-
-	routeList := &routev1.RouteList{}
-	err := s.Client().List(context.TODO(), routeList, listOps...)
-	if err != nil {
-		return false, fmt.Errorf("Failed to list routes: %w", err)
+// apimanagerAvailableCondition is true when all the components are available,
+// otherwise its message names the unavailable components
+func apimanagerAvailableCondition(unavailableComponents []string) common.Condition {
+	condition := common.Condition{
+		Type:   appsv1alpha1.APIManagerAvailableConditionType,
+		Status: v1.ConditionTrue,
 	}
 
-	routes := append([]routev1.Route(nil), routeList.Items...)
-	sort.Slice(routes, func(i, j int) bool { return routes[i].Name < routes[j].Name })
-
-	allDefaultRoutesReady := true
-	for _, expectedRouteHost := range expectedRouteHosts {
-		routeIdx := helper.RouteFindByHost(routes, expectedRouteHost)
-		if routeIdx == -1 {
-			s.logger.V(1).Info("Status defaultRoutesReady: route not found", "expectedRouteHost", expectedRouteHost)
-			allDefaultRoutesReady = false
-		} else {
-			matchedRoute := &routes[routeIdx]
-			routeReady := helper.IsRouteReady(matchedRoute)
-			if !routeReady {
-				s.logger.V(1).Info("Status defaultRoutesReady: route not ready", "expectedRouteHost", expectedRouteHost)
-				allDefaultRoutesReady = false
-			}
-		}
+	if len(unavailableComponents) > 0 {
+		condition.Status = v1.ConditionFalse
+		condition.Reason = ComponentsUnavailableReason
+		condition.Message = fmt.Sprintf("Unavailable components: %s", strings.Join(unavailableComponents, ", "))
 	}
 
-	return allDefaultRoutesReady, nil
+	return condition
 }
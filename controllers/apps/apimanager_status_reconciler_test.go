@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAPIManagerStatusReconcilerComponentConditions(t *testing.T) {
+	namespace := "test"
+	tenantName := "tenant"
+	workloadType := appsv1alpha1.WorkloadTypeDeployment
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: namespace, UID: types.UID("apimanager-uid")},
+		Spec: appsv1alpha1.APIManagerSpec{
+			APIManagerCommonSpec: appsv1alpha1.APIManagerCommonSpec{
+				WildcardDomain: "example.com",
+				TenantName:     &tenantName,
+			},
+			WorkloadType: &workloadType,
+			Backend:      &appsv1alpha1.BackendSpec{},
+			System:       &appsv1alpha1.SystemSpec{},
+		},
+	}
+	ownerReferences := []metav1.OwnerReference{{APIVersion: "apps.3scale.net/v1alpha1", Kind: "APIManager", Name: apimanager.Name, UID: apimanager.UID}}
+
+	deployment := func(name string, available bool) *k8sappsv1.Deployment {
+		status := v1.ConditionFalse
+		if available {
+			status = v1.ConditionTrue
+		}
+		return &k8sappsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, OwnerReferences: ownerReferences},
+			Spec:       k8sappsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"deployment": name}}},
+			Status: k8sappsv1.DeploymentStatus{
+				Conditions: []k8sappsv1.DeploymentCondition{{Type: k8sappsv1.DeploymentAvailable, Status: status}},
+			},
+		}
+	}
+
+	objs := []runtime.Object{apimanager}
+	for _, name := range []string{
+		component.ApicastStagingName, component.ApicastProductionName,
+		component.BackendWorkerName, component.BackendCronName,
+		component.SystemAppDeploymentName, component.SystemSidekiqName, component.SystemSearchdDeploymentName,
+		component.ZyncQueDeploymentName, component.ZyncDatabaseDeploymentName,
+		component.BackendRedisDeploymentName, component.SystemRedisDeploymentName, component.SystemMemcachedDeploymentName,
+	} {
+		objs = append(objs, deployment(name, true))
+	}
+	// zync is missing, backend-listener cannot pull its image and system-mysql waits for its volume
+	objs = append(objs,
+		deployment(component.BackendListenerName, false),
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend-listener-abcde", Namespace: namespace, Labels: map[string]string{"deployment": component.BackendListenerName}},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:  "backend-listener",
+					State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			},
+		},
+		deployment(component.SystemMySQLDeploymentName, false),
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "system-mysql-abcde", Namespace: namespace, Labels: map[string]string{"deployment": component.SystemMySQLDeploymentName}},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{{
+					Name:         "mysql-storage",
+					VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "mysql-storage"}},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodPending},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql-storage", Namespace: namespace},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+		},
+	)
+
+	for idx, host := range []string{
+		"backend-tenant.example.com", "api-tenant-apicast-production.example.com", "api-tenant-apicast-staging.example.com",
+		"master.example.com", "tenant.example.com", "tenant-admin.example.com",
+	} {
+		admitted := v1.ConditionTrue
+		if host == "master.example.com" {
+			admitted = v1.ConditionFalse
+		}
+		objs = append(objs, &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("route-%d", idx), Namespace: namespace},
+			Spec:       routev1.RouteSpec{Host: host},
+			Status: routev1.RouteStatus{Ingress: []routev1.RouteIngress{{
+				Host:       host,
+				Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: admitted, Message: "host already claimed"}},
+			}}},
+		})
+	}
+
+	baseReconciler, _ := testBaseReconciler(t, "apimanager status test", objs...)
+
+	status, err := NewAPIManagerStatusReconciler(baseReconciler, apimanager).calculateStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedConditions := []common.Condition{
+		{
+			Type: appsv1alpha1.APIManagerAvailableConditionType, Status: v1.ConditionFalse, Reason: ComponentsUnavailableReason,
+			Message: "Unavailable components: Backend, Zync, Databases, Routes",
+		},
+		{
+			Type: appsv1alpha1.APIManagerBackendAvailableConditionType, Status: v1.ConditionFalse, Reason: "ImagePullBackOff",
+			Message: "Deployment backend-listener: pod backend-listener-abcde container backend-listener: Back-off pulling image",
+		},
+		{
+			Type: appsv1alpha1.APIManagerZyncAvailableConditionType, Status: v1.ConditionFalse, Reason: WorkloadNotFoundReason,
+			Message: "Deployment zync not found",
+		},
+		{
+			Type: appsv1alpha1.APIManagerDatabasesAvailableConditionType, Status: v1.ConditionFalse, Reason: PersistentVolumeClaimPendingReason,
+			Message: "Deployment system-mysql: pod system-mysql-abcde: persistentvolumeclaim mysql-storage is pending",
+		},
+		{
+			Type: appsv1alpha1.APIManagerRoutesAvailableConditionType, Status: v1.ConditionFalse, Reason: RouteNotAdmittedReason,
+			Message: "route route-3 for host master.example.com not admitted: host already claimed",
+		},
+		{Type: appsv1alpha1.APIManagerSystemAvailableConditionType, Status: v1.ConditionTrue, Reason: ComponentAvailableReason},
+		{Type: appsv1alpha1.APIManagerApicastAvailableConditionType, Status: v1.ConditionTrue, Reason: ComponentAvailableReason},
+		{Type: appsv1alpha1.APIManagerRedisAvailableConditionType, Status: v1.ConditionTrue, Reason: ComponentAvailableReason},
+		{Type: appsv1alpha1.APIManagerMemcachedAvailableConditionType, Status: v1.ConditionTrue, Reason: ComponentAvailableReason},
+		{Type: appsv1alpha1.APIManagerSearchdAvailableConditionType, Status: v1.ConditionTrue, Reason: ComponentAvailableReason},
+	}
+	for _, expected := range expectedConditions {
+		condition := status.Conditions.GetCondition(expected.Type)
+		if condition == nil {
+			t.Fatalf("condition %s not found", expected.Type)
+		}
+		if condition.Status != expected.Status || condition.Reason != expected.Reason || condition.Message != expected.Message {
+			t.Errorf("condition %s: expected %s %s '%s', got %s %s '%s'", expected.Type,
+				expected.Status, expected.Reason, expected.Message, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+	if status.Conditions.GetCondition(appsv1alpha1.APIManagerMonitoringAvailableConditionType) != nil {
+		t.Error("expected no monitoring condition when monitoring is disabled")
+	}
+}
+
+func TestAPIManagerStatusReconcilerPausedCondition(t *testing.T) {
+	tenantName := "tenant"
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "example", Namespace: "test",
+			Annotations: map[string]string{common.PausedAnnotation: "true"},
+		},
+		Spec: appsv1alpha1.APIManagerSpec{
+			APIManagerCommonSpec: appsv1alpha1.APIManagerCommonSpec{WildcardDomain: "example.com", TenantName: &tenantName},
+			Backend:              &appsv1alpha1.BackendSpec{},
+			System:               &appsv1alpha1.SystemSpec{},
+		},
+	}
+
+	baseReconciler, _ := testBaseReconciler(t, "apimanager status test", apimanager)
+
+	status, err := NewAPIManagerStatusReconciler(baseReconciler, apimanager).calculateStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Conditions.IsTrueFor(common.PausedConditionType) {
+		t.Fatalf("expected Paused condition, got %v", status.Conditions)
+	}
+	if !status.Conditions.IsFalseFor(appsv1alpha1.APIManagerAvailableConditionType) {
+		t.Fatal("expected the status of paused APIManager to be calculated")
+	}
+
+	apimanager.Status = *status
+	apimanager.Annotations = nil
+	status, err = NewAPIManagerStatusReconciler(baseReconciler, apimanager).calculateStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Conditions.GetCondition(common.PausedConditionType) != nil {
+		t.Fatal("expected Paused condition to be removed once resumed")
+	}
+}
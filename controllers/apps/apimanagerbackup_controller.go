@@ -56,6 +56,19 @@ func (r *APIManagerBackupReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// Paused backups report the Paused condition and the failure of their jobs
+	paused, err := r.ReconcilePausedCondition(instance, &instance.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		apiManagerBackupLogicReconciler, err := r.apiManagerBackupLogicReconciler(instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return apiManagerBackupLogicReconciler.ReconcilePausedStatus()
+	}
+
 	res, err := r.setAPIManagerBackupDefaults(instance)
 	if err != nil {
 		logger.Error(err, "Error")
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -15,6 +16,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	apispkgcommon "github.com/3scale/3scale-operator/pkg/apispkg/common"
 	"github.com/3scale/3scale-operator/pkg/backup"
 	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
@@ -36,7 +39,7 @@ func NewAPIManagerBackupLogicReconciler(b *reconcilers.BaseReconciler, cr *appsv
 		cr:             cr,
 	}
 
-	if cr.BackupCompleted() {
+	if cr.BackupCompleted() || cr.BackupFailed() {
 		return res, nil
 	}
 
@@ -70,6 +73,11 @@ func (r *APIManagerBackupLogicReconciler) Reconcile() (reconcile.Result, error)
 		return reconcile.Result{}, nil
 	}
 
+	if r.cr.BackupFailed() {
+		r.Logger().Info("Backup failed. End of reconciliation")
+		return reconcile.Result{}, nil
+	}
+
 	if !r.cr.MainStepsCompleted() {
 		r.Logger().Info("Reconciling backup steps")
 		result, err := r.reconcileMainSteps()
@@ -88,6 +96,38 @@ func (r *APIManagerBackupLogicReconciler) Reconcile() (reconcile.Result, error)
 	return reconcile.Result{}, nil
 }
 
+// ReconcilePausedStatus reports the jobs of a paused backup that have failed.
+// No job is created nor deleted while the backup is paused
+func (r *APIManagerBackupLogicReconciler) ReconcilePausedStatus() (reconcile.Result, error) {
+	if r.cr.BackupCompleted() || r.cr.BackupFailed() {
+		return reconcile.Result{}, nil
+	}
+
+	for _, job := range r.backupJobs() {
+		existing := &batchv1.Job{}
+		err := r.GetResource(common.ObjectKey(job), existing)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+
+		if failedCondition := jobFailedCondition(existing); failedCondition != nil {
+			r.Logger().Info("Job failed", "Job Name", job.Name, "Reason", failedCondition.Reason)
+			r.cr.Status.Conditions.SetCondition(apispkgcommon.Condition{
+				Type:    appsv1alpha1.APIManagerBackupFailedConditionType,
+				Status:  v1.ConditionTrue,
+				Reason:  appsv1alpha1.APIManagerBackupJobFailedReason,
+				Message: fmt.Sprintf("Job %s failed: %s", job.Name, failedCondition.Message),
+			})
+			return reconcile.Result{}, r.UpdateResourceStatus(r.cr)
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
 func (r *APIManagerBackupLogicReconciler) reconcileMainSteps() (reconcile.Result, error) {
 	result, err := r.reconcileAPIManagerSourceStatusField()
 	if result.Requeue || err != nil {
@@ -99,7 +139,7 @@ func (r *APIManagerBackupLogicReconciler) reconcileMainSteps() (reconcile.Result
 		return result, err
 	}
 
-	result, err = r.reconcileBackupInPVCDestination()
+	result, err = r.reconcileBackupInDestination()
 	if result.Requeue || err != nil {
 		return result, err
 	}
@@ -140,7 +180,7 @@ func (r *APIManagerBackupLogicReconciler) reconcileSetMainStepsCompleted() (reco
 	return reconcile.Result{}, nil
 }
 
-func (r *APIManagerBackupLogicReconciler) reconcileBackupInPVCDestination() (reconcile.Result, error) {
+func (r *APIManagerBackupLogicReconciler) reconcileBackupInDestination() (reconcile.Result, error) {
 	var res reconcile.Result
 	var err error
 
@@ -154,22 +194,42 @@ func (r *APIManagerBackupLogicReconciler) reconcileBackupInPVCDestination() (rec
 		return res, err
 	}
 
+	res, err = r.reconcileBackupDestinationS3Status()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	res, err = r.reconcileStagingSizeLimit()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
 	res, err = r.reconcileBackupJobsPermissions()
 	if res.Requeue || err != nil {
 		return res, err
 	}
 
-	res, err = r.reconcileBackupSecretsAndConfigMapsToPVCJob()
+	res, err = r.reconcileBackupSecretsAndConfigMapsJob()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	res, err = r.reconcileAPIManagerCustomResourceBackupJob()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	res, err = r.reconcileBackupSystemFileStoragePVCJob()
 	if res.Requeue || err != nil {
 		return res, err
 	}
 
-	res, err = r.reconcileAPIManagerCustomResourceBackupToPVCJob()
+	res, err = r.reconcileBackupDatabasesJobs()
 	if res.Requeue || err != nil {
 		return res, err
 	}
 
-	res, err = r.reconcileBackupSystemFileStoragePVCToPVCJob()
+	res, err = r.reconcileBackupManifestJob()
 	if res.Requeue || err != nil {
 		return res, err
 	}
@@ -223,6 +283,20 @@ func (r *APIManagerBackupLogicReconciler) reconcileJob(desired *batchv1.Job) (re
 	// Jobs ownerReference or labels nor annotations not reconciled
 	// Jobs are one-shot so there's not much point on making updates to them
 
+	if failedCondition := jobFailedCondition(existing); failedCondition != nil {
+		// The failed job is kept to allow inspecting the logs of its pods
+		r.Logger().Info("Job failed", "Job Name", desired.Name, "Reason", failedCondition.Reason)
+		r.cr.Status.Conditions.SetCondition(apispkgcommon.Condition{
+			Type:    appsv1alpha1.APIManagerBackupFailedConditionType,
+			Status:  v1.ConditionTrue,
+			Reason:  appsv1alpha1.APIManagerBackupJobFailedReason,
+			Message: fmt.Sprintf("Job %s failed: %s", desired.Name, failedCondition.Message),
+		})
+		err := r.UpdateResourceStatus(r.cr)
+		// Stop the reconciliation of the remaining steps
+		return reconcile.Result{Requeue: err == nil}, err
+	}
+
 	if existing.Status.Succeeded != *desired.Spec.Completions {
 		r.Logger().Info("Job has still not finished", "Job Name", desired.Name, "Actively running Pods", existing.Status.Active, "Failed pods", existing.Status.Failed)
 		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
@@ -232,8 +306,20 @@ func (r *APIManagerBackupLogicReconciler) reconcileJob(desired *batchv1.Job) (re
 	return reconcile.Result{}, nil
 }
 
-func (r *APIManagerBackupLogicReconciler) reconcileBackupSecretsAndConfigMapsToPVCJob() (reconcile.Result, error) {
-	desired := r.apiManagerBackup.BackupSecretsAndConfigMapsToPVCJob()
+// jobFailedCondition returns the Failed condition of the job when it has
+// failed, after exhausting its retries
+func jobFailedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for idx := range job.Status.Conditions {
+		condition := &job.Status.Conditions[idx]
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return condition
+		}
+	}
+	return nil
+}
+
+func (r *APIManagerBackupLogicReconciler) reconcileBackupSecretsAndConfigMapsJob() (reconcile.Result, error) {
+	desired := r.apiManagerBackup.BackupSecretsAndConfigMapsJob()
 	if desired == nil {
 		return reconcile.Result{}, nil
 	}
@@ -241,8 +327,8 @@ func (r *APIManagerBackupLogicReconciler) reconcileBackupSecretsAndConfigMapsToP
 	return r.reconcileJob(desired)
 }
 
-func (r *APIManagerBackupLogicReconciler) reconcileAPIManagerCustomResourceBackupToPVCJob() (reconcile.Result, error) {
-	desired := r.apiManagerBackup.BackupAPIManagerCustomResourceToPVCJob()
+func (r *APIManagerBackupLogicReconciler) reconcileAPIManagerCustomResourceBackupJob() (reconcile.Result, error) {
+	desired := r.apiManagerBackup.BackupAPIManagerCustomResourceJob()
 	if desired == nil {
 		return reconcile.Result{}, nil
 	}
@@ -250,8 +336,62 @@ func (r *APIManagerBackupLogicReconciler) reconcileAPIManagerCustomResourceBacku
 	return r.reconcileJob(desired)
 }
 
-func (r *APIManagerBackupLogicReconciler) reconcileBackupSystemFileStoragePVCToPVCJob() (reconcile.Result, error) {
-	desired := r.apiManagerBackup.BackupSystemFileStoragePVCToPVCJob()
+// The backup fails before any job is created when the staging volume is
+// smaller than the system file storage PVC, instead of the pod of its job
+// being evicted once the data written exceeds the size limit
+func (r *APIManagerBackupLogicReconciler) reconcileStagingSizeLimit() (reconcile.Result, error) {
+	pvc := &v1.PersistentVolumeClaim{}
+	err := r.GetResource(types.NamespacedName{Name: component.SystemFileStoragePVCName, Namespace: r.cr.Namespace}, pvc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	validationErr := r.apiManagerBackup.ValidateStagingSizeLimit(pvc)
+	if validationErr == nil {
+		return reconcile.Result{}, nil
+	}
+
+	r.Logger().Info("Staging volume too small", "Error", validationErr.Error())
+	r.cr.Status.Conditions.SetCondition(apispkgcommon.Condition{
+		Type:    appsv1alpha1.APIManagerBackupFailedConditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  appsv1alpha1.APIManagerBackupStagingSizeLimitExceededReason,
+		Message: validationErr.Error(),
+	})
+	err = r.UpdateResourceStatus(r.cr)
+	// Stop the reconciliation of the remaining steps
+	return reconcile.Result{Requeue: err == nil}, err
+}
+
+func (r *APIManagerBackupLogicReconciler) reconcileBackupSystemFileStoragePVCJob() (reconcile.Result, error) {
+	desired := r.apiManagerBackup.BackupSystemFileStoragePVCJob()
+	if desired == nil {
+		return reconcile.Result{}, nil
+	}
+
+	return r.reconcileJob(desired)
+}
+
+// Databases and Redis instances deployed by the operator are backed up one at a
+// time. External ones have no job and are expected to be backed up by the user
+func (r *APIManagerBackupLogicReconciler) reconcileBackupDatabasesJobs() (reconcile.Result, error) {
+	for _, desired := range r.databasesBackupJobs() {
+		res, err := r.reconcileJob(desired)
+		if res.Requeue || err != nil {
+			return res, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// The manifest is written once all the other backup data has been written,
+// as it lists the checksums of all the backup files
+func (r *APIManagerBackupLogicReconciler) reconcileBackupManifestJob() (reconcile.Result, error) {
+	desired := r.apiManagerBackup.BackupManifestJob()
 	if desired == nil {
 		return reconcile.Result{}, nil
 	}
@@ -259,6 +399,37 @@ func (r *APIManagerBackupLogicReconciler) reconcileBackupSystemFileStoragePVCToP
 	return r.reconcileJob(desired)
 }
 
+func (r *APIManagerBackupLogicReconciler) databasesBackupJobs() []*batchv1.Job {
+	jobs := []*batchv1.Job{}
+	for _, job := range []*batchv1.Job{
+		r.apiManagerBackup.BackupSystemMySQLJob(),
+		r.apiManagerBackup.BackupSystemPostgreSQLJob(),
+		r.apiManagerBackup.BackupZyncDatabaseJob(),
+		r.apiManagerBackup.BackupBackendRedisJob(),
+		r.apiManagerBackup.BackupSystemRedisJob(),
+	} {
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func (r *APIManagerBackupLogicReconciler) backupJobs() []*batchv1.Job {
+	jobs := []*batchv1.Job{}
+	for _, job := range []*batchv1.Job{
+		r.apiManagerBackup.BackupSecretsAndConfigMapsJob(),
+		r.apiManagerBackup.BackupAPIManagerCustomResourceJob(),
+		r.apiManagerBackup.BackupSystemFileStoragePVCJob(),
+		r.apiManagerBackup.BackupManifestJob(),
+	} {
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return append(jobs, r.databasesBackupJobs()...)
+}
+
 func (r *APIManagerBackupLogicReconciler) reconcileBackupCompletion() (reconcile.Result, error) {
 	if !r.cr.BackupCompleted() {
 		// TODO make this more robust only setting it in case all substeps have been completed?
@@ -322,19 +493,26 @@ func (r *APIManagerBackupLogicReconciler) reconcileBackupDestinationPVCStatus()
 	return reconcile.Result{}, nil
 }
 
+func (r *APIManagerBackupLogicReconciler) reconcileBackupDestinationS3Status() (reconcile.Result, error) {
+	if r.cr.Spec.BackupDestination.S3 == nil {
+		return reconcile.Result{}, nil
+	}
+
+	if r.cr.Status.BackupS3URL == nil {
+		r.cr.Status.BackupS3URL = r.apiManagerBackup.BackupS3URL()
+		err := r.UpdateResourceStatus(r.cr)
+		return reconcile.Result{Requeue: true}, err
+	}
+	return reconcile.Result{}, nil
+}
+
 // Delete all K8s jobs created during the backup. The reason for this is that
 // some PVCs are referenced in the K8s Jobs and those PVCs cannot be deleted
 // while some pods reference them, even if in state Completed. By deleting the
 // K8s jobs we allow the cleanup to be possible
 func (r *APIManagerBackupLogicReconciler) reconcileJobsCleanup() (reconcile.Result, error) {
-	jobsToDelete := []*batchv1.Job{
-		r.apiManagerBackup.BackupSecretsAndConfigMapsToPVCJob(),
-		r.apiManagerBackup.BackupAPIManagerCustomResourceToPVCJob(),
-		r.apiManagerBackup.BackupSystemFileStoragePVCToPVCJob(),
-	}
-
 	existingJobFound := false
-	for _, job := range jobsToDelete {
+	for _, job := range r.backupJobs() {
 		existingJob := &batchv1.Job{}
 		err := r.GetResource(types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, existingJob)
 		if err != nil && !errors.IsNotFound(err) {
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/backup"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAPIManagerBackupS3DestinationJobs(t *testing.T) {
+	namespace := "test"
+	prefix := "/backups/"
+
+	apimanager := testAPIManager(t, namespace, nil)
+
+	cr := &appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-backup", Namespace: namespace, UID: "backup-uid"},
+		Spec: appsv1alpha1.APIManagerBackupSpec{
+			BackupDestination: appsv1alpha1.APIManagerBackupDestination{
+				S3: &appsv1alpha1.S3Location{
+					Bucket:               "bucket",
+					Prefix:               &prefix,
+					CredentialsSecretRef: v1.LocalObjectReference{Name: "s3-credentials"},
+				},
+			},
+		},
+	}
+
+	baseReconciler, _ := testBaseReconciler(t, "apimanager backup test", apimanager, cr)
+
+	r, err := NewAPIManagerBackupLogicReconciler(baseReconciler, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.apiManagerBackup.BackupDestinationPVC() != nil {
+		t.Error("unexpected backup destination PVC")
+	}
+	if url := r.apiManagerBackup.BackupS3URL(); url == nil || *url != "s3://bucket/backups/example-backup" {
+		t.Errorf("unexpected backup S3 URL %v", url)
+	}
+
+	jobs := append(r.databasesBackupJobs(),
+		r.apiManagerBackup.BackupSecretsAndConfigMapsJob(),
+		r.apiManagerBackup.BackupAPIManagerCustomResourceJob(),
+		r.apiManagerBackup.BackupSystemFileStoragePVCJob(),
+	)
+	for _, job := range jobs {
+		podSpec := job.Spec.Template.Spec
+		if len(podSpec.InitContainers) != 2 || podSpec.InitContainers[1].Name != "list-backup-artifacts" {
+			t.Fatalf("job %s: expected the backup and listing containers as init containers, got %d init containers", job.Name, len(podSpec.InitContainers))
+		}
+		if len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != "upload-to-s3" {
+			t.Fatalf("job %s: expected a single upload container", job.Name)
+		}
+		for _, volume := range podSpec.Volumes {
+			// The system storage PVC is the only one mounted, as backup data source
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName != "system-storage" {
+				t.Errorf("job %s: unexpected PVC volume %s", job.Name, volume.Name)
+			}
+		}
+	}
+
+	// The database jobs are allowed by the NetworkPolicies of the databases
+	for _, job := range r.databasesBackupJobs() {
+		if job.Spec.Template.Labels[component.DatabaseClientLabelKey] != component.DatabaseClientLabelValue {
+			t.Errorf("job %s: expected the database client label, got %v", job.Name, job.Spec.Template.Labels)
+		}
+	}
+
+	// The manifest is computed from the listings of the uploaded data, and uploaded alone
+	podSpec := r.apiManagerBackup.BackupManifestJob().Spec.Template.Spec
+	if len(podSpec.InitContainers) != 2 || podSpec.InitContainers[0].Name != "download-from-s3" || podSpec.InitContainers[1].Name != "backup-manifest" {
+		t.Fatalf("unexpected manifest job init containers %v", podSpec.InitContainers)
+	}
+	if len(podSpec.Containers) != 1 || !strings.Contains(podSpec.Containers[0].Args[2], backup.BackupManifestFileName) {
+		t.Fatal("expected the manifest job to upload the manifest only")
+	}
+	for _, env := range podSpec.InitContainers[1].Env {
+		if env.Name != "BACKUP_MANIFEST" {
+			continue
+		}
+		manifest := &backup.BackupManifest{}
+		if err := json.Unmarshal([]byte(env.Value), manifest); err != nil {
+			t.Fatal(err)
+		}
+		if manifest.ThreescaleVersion != apimanager.Annotations[appsv1alpha1.ThreescaleVersionAnnotation] || manifest.APIManager.SystemDatabase != "mysql" {
+			t.Errorf("unexpected backup manifest %v", manifest)
+		}
+	}
+}
+
+func TestAPIManagerBackupEncryptedPVCDestinationJobs(t *testing.T) {
+	namespace := "test"
+
+	apimanager := testAPIManager(t, namespace, nil)
+
+	cr := &appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-backup", Namespace: namespace, UID: "backup-uid"},
+		Spec: appsv1alpha1.APIManagerBackupSpec{
+			BackupDestination: appsv1alpha1.APIManagerBackupDestination{
+				PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimBackupDestination{},
+			},
+			Encryption: &appsv1alpha1.BackupEncryptionSpec{
+				KeySecretRef: v1.LocalObjectReference{Name: "backup-encryption-key"},
+			},
+		},
+	}
+
+	baseReconciler, _ := testBaseReconciler(t, "apimanager backup test", apimanager, cr)
+
+	r, err := NewAPIManagerBackupLogicReconciler(baseReconciler, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	destinationPVC := r.apiManagerBackup.BackupDestinationPVC()
+	if destinationPVC == nil {
+		t.Fatal("expected a backup destination PVC")
+	}
+
+	jobs := append(r.databasesBackupJobs(),
+		r.apiManagerBackup.BackupSecretsAndConfigMapsJob(),
+		r.apiManagerBackup.BackupAPIManagerCustomResourceJob(),
+		r.apiManagerBackup.BackupSystemFileStoragePVCJob(),
+	)
+	for _, job := range jobs {
+		podSpec := job.Spec.Template.Spec
+		if len(podSpec.InitContainers) != 1 {
+			t.Fatalf("job %s: expected the backup container as init container, got %d init containers", job.Name, len(podSpec.InitContainers))
+		}
+		// The backup container writes the plaintext data in the staging volume only
+		for _, mount := range podSpec.InitContainers[0].VolumeMounts {
+			if mount.MountPath == backup.BackupPVCMountPath && volumeClaimName(podSpec.Volumes, mount.Name) == destinationPVC.Name {
+				t.Errorf("job %s: backup container writes in the destination PVC", job.Name)
+			}
+		}
+		if len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != "encrypt-backup-data" {
+			t.Fatalf("job %s: expected a single encryption container", job.Name)
+		}
+		container := podSpec.Containers[0]
+		if len(container.Env) != 1 || container.Env[0].ValueFrom == nil || container.Env[0].ValueFrom.SecretKeyRef == nil ||
+			container.Env[0].ValueFrom.SecretKeyRef.Name != "backup-encryption-key" || container.Env[0].ValueFrom.SecretKeyRef.Key != backup.EncryptionKeySecretKey {
+			t.Errorf("job %s: unexpected encryption container env %v", job.Name, container.Env)
+		}
+		writesPVC := false
+		for _, mount := range container.VolumeMounts {
+			writesPVC = writesPVC || volumeClaimName(podSpec.Volumes, mount.Name) == destinationPVC.Name
+		}
+		if !writesPVC {
+			t.Errorf("job %s: expected the encryption container to mount the destination PVC", job.Name)
+		}
+	}
+
+	// The manifest lists the encrypted files in the destination PVC
+	podSpec := r.apiManagerBackup.BackupManifestJob().Spec.Template.Spec
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].PersistentVolumeClaim == nil || podSpec.Volumes[0].PersistentVolumeClaim.ClaimName != destinationPVC.Name {
+		t.Fatalf("unexpected manifest job volumes %v", podSpec.Volumes)
+	}
+	manifest := &backup.BackupManifest{}
+	if err := json.Unmarshal([]byte(podSpec.Containers[0].Env[0].Value), manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Encryption != backup.EncryptionAlgorithm {
+		t.Errorf("unexpected backup manifest encryption '%s'", manifest.Encryption)
+	}
+}
+
+func TestAPIManagerBackupPausedStatus(t *testing.T) {
+	namespace := "test"
+
+	apimanager := testAPIManager(t, namespace, nil)
+
+	cr := &appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-backup", Namespace: namespace, UID: "backup-uid"},
+		Spec: appsv1alpha1.APIManagerBackupSpec{
+			BackupDestination: appsv1alpha1.APIManagerBackupDestination{
+				PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimBackupDestination{},
+			},
+		},
+	}
+
+	baseReconciler, cl := testBaseReconciler(t, "apimanager backup test", apimanager, cr)
+
+	r, err := NewAPIManagerBackupLogicReconciler(baseReconciler, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No job is created while paused
+	if _, err := r.ReconcilePausedStatus(); err != nil {
+		t.Fatal(err)
+	}
+	jobs := &batchv1.JobList{}
+	if err := cl.List(context.TODO(), jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected no jobs, got %d", len(jobs.Items))
+	}
+
+	// Jobs failed before the backup was paused are reported
+	job := r.apiManagerBackup.BackupSecretsAndConfigMapsJob()
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	if err := cl.Create(context.TODO(), job); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReconcilePausedStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if !cr.BackupFailed() {
+		t.Fatalf("expected the backup to be failed, got %v", cr.Status.Conditions)
+	}
+}
+
+func TestAPIManagerBackupStagingSizeLimitExceeded(t *testing.T) {
+	namespace := "test"
+
+	apimanager := testAPIManager(t, namespace, nil)
+
+	stagingSizeLimit := resource.MustParse("1Gi")
+	cr := &appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-backup", Namespace: namespace, UID: "backup-uid"},
+		Spec: appsv1alpha1.APIManagerBackupSpec{
+			BackupDestination: appsv1alpha1.APIManagerBackupDestination{
+				S3: &appsv1alpha1.S3Location{
+					Bucket:               "bucket",
+					CredentialsSecretRef: v1.LocalObjectReference{Name: "s3-credentials"},
+				},
+			},
+			StagingSizeLimit: &stagingSizeLimit,
+		},
+	}
+
+	systemStoragePVC := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: component.SystemFileStoragePVCName, Namespace: namespace},
+		Status: v1.PersistentVolumeClaimStatus{
+			Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+
+	baseReconciler, cl := testBaseReconciler(t, "apimanager backup test", apimanager, cr, systemStoragePVC)
+
+	r, err := NewAPIManagerBackupLogicReconciler(baseReconciler, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5 && !cr.BackupFailed(); i++ {
+		if _, err := r.Reconcile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	condition := cr.Status.Conditions.GetCondition(appsv1alpha1.APIManagerBackupFailedConditionType)
+	if condition == nil || condition.Reason != appsv1alpha1.APIManagerBackupStagingSizeLimitExceededReason {
+		t.Fatalf("expected the backup to fail on the staging size limit, got %v", cr.Status.Conditions)
+	}
+
+	// The backup fails before any job is created
+	jobs := &batchv1.JobList{}
+	if err := cl.List(context.TODO(), jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected no jobs, got %d", len(jobs.Items))
+	}
+}
+
+func volumeClaimName(volumes []v1.Volume, name string) string {
+	for _, volume := range volumes {
+		if volume.Name == name && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,418 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclock "k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	apispkgcommon "github.com/3scale/3scale-operator/pkg/apispkg/common"
+	"github.com/3scale/3scale-operator/pkg/backup"
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+var apimanagerbackupscheduleClock kubeclock.Clock = &kubeclock.RealClock{}
+
+// Upper bound of the schedule times iterated to find the last missed one.
+// When more runs have been missed, the backup is created for the current time
+const maxMissedScheduleTimes = 10000
+
+type APIManagerBackupScheduleLogicReconciler struct {
+	*reconcilers.BaseReconciler
+	logger logr.Logger
+	cr     *appsv1alpha1.APIManagerBackupSchedule
+}
+
+func NewAPIManagerBackupScheduleLogicReconciler(b *reconcilers.BaseReconciler, cr *appsv1alpha1.APIManagerBackupSchedule) *APIManagerBackupScheduleLogicReconciler {
+	return &APIManagerBackupScheduleLogicReconciler{
+		BaseReconciler: b,
+		logger:         b.Logger().WithValues("APIManagerBackupSchedule Controller", cr.Name),
+		cr:             cr,
+	}
+}
+
+func (r *APIManagerBackupScheduleLogicReconciler) Logger() logr.Logger {
+	return r.logger
+}
+
+func (r *APIManagerBackupScheduleLogicReconciler) Reconcile() (reconcile.Result, error) {
+	backups, err := r.scheduledBackups()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	result, err := r.reconcileLastBackupsStatus(backups)
+	if result.Requeue || err != nil {
+		return result, err
+	}
+
+	result, err = r.reconcilePrune(backups)
+	if result.Requeue || err != nil {
+		return result, err
+	}
+
+	return r.reconcileSchedule(backups)
+}
+
+// ReconcilePausedStatus reports the last completed and the last failed backups of a
+// paused schedule. No backup is created nor pruned while the schedule is paused
+func (r *APIManagerBackupScheduleLogicReconciler) ReconcilePausedStatus() (reconcile.Result, error) {
+	backups, err := r.scheduledBackups()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return r.reconcileLastBackupsStatus(backups)
+}
+
+// scheduledBackups returns the backups created by the schedule
+func (r *APIManagerBackupScheduleLogicReconciler) scheduledBackups() ([]appsv1alpha1.APIManagerBackup, error) {
+	backupList := &appsv1alpha1.APIManagerBackupList{}
+	err := r.Client().List(r.Context(), backupList,
+		client.InNamespace(r.cr.Namespace),
+		client.MatchingLabels{appsv1alpha1.APIManagerBackupScheduleLabelKey: r.cr.Name},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []appsv1alpha1.APIManagerBackup{}
+	for _, item := range backupList.Items {
+		if metav1.IsControlledBy(&item, r.cr) {
+			backups = append(backups, item)
+		}
+	}
+	return backups, nil
+}
+
+// reconcileLastBackupsStatus reports the last completed and the last failed
+// backups. They are kept in the status after being pruned
+func (r *APIManagerBackupScheduleLogicReconciler) reconcileLastBackupsStatus(backups []appsv1alpha1.APIManagerBackup) (reconcile.Result, error) {
+	changed := false
+	for idx := range backups {
+		item := &backups[idx]
+		if item.BackupCompleted() && item.Status.CompletionTime != nil {
+			if updateScheduledBackupReference(&r.cr.Status.LastSuccessfulBackup, item.Name, *item.Status.CompletionTime) {
+				changed = true
+			}
+		}
+		if item.BackupFailed() {
+			failedCondition := item.Status.Conditions.GetCondition(appsv1alpha1.APIManagerBackupFailedConditionType)
+			if updateScheduledBackupReference(&r.cr.Status.LastFailedBackup, item.Name, failedCondition.LastTransitionTime) {
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		err := r.UpdateResourceStatus(r.cr)
+		return reconcile.Result{Requeue: true}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func updateScheduledBackupReference(reference **appsv1alpha1.ScheduledBackupReference, name string, t metav1.Time) bool {
+	if *reference != nil && !(*reference).Time.Before(&t) {
+		return false
+	}
+	*reference = &appsv1alpha1.ScheduledBackupReference{Name: name, Time: t}
+	return true
+}
+
+// reconcilePrune removes the backups not kept by the retention rules together
+// with their data. Backups whose data could not be removed are reported in the
+// PruneFailed condition and kept
+func (r *APIManagerBackupScheduleLogicReconciler) reconcilePrune(backups []appsv1alpha1.APIManagerBackup) (reconcile.Result, error) {
+	failures := []string{}
+	for _, item := range backupsToPrune(backups, r.cr.Spec.Retention) {
+		if item.DeletionTimestamp != nil {
+			continue
+		}
+
+		failedCondition, res, err := r.reconcileRemoveBackupData(item)
+		if res.Requeue || err != nil {
+			return res, err
+		}
+		if failedCondition != nil {
+			failures = append(failures, fmt.Sprintf("backup %s: %s", item.Name, failedCondition.Message))
+			continue
+		}
+
+		r.Logger().Info("Pruning backup", "APIManagerBackup", item.Name)
+		err = r.DeleteResource(item)
+		if err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
+	changed := false
+	if len(failures) > 0 {
+		changed = r.cr.Status.Conditions.SetCondition(apispkgcommon.Condition{
+			Type:    appsv1alpha1.APIManagerBackupSchedulePruneFailedConditionType,
+			Status:  v1.ConditionTrue,
+			Reason:  appsv1alpha1.APIManagerBackupSchedulePruneJobFailedReason,
+			Message: fmt.Sprintf("Failed removing the data of %s", strings.Join(failures, "; ")),
+		})
+	} else {
+		changed = r.cr.Status.Conditions.RemoveCondition(appsv1alpha1.APIManagerBackupSchedulePruneFailedConditionType)
+	}
+	if changed {
+		err := r.UpdateResourceStatus(r.cr)
+		return reconcile.Result{Requeue: true}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileRemoveBackupData removes the data of the backup from its
+// destination. S3 objects are removed by a job owned by the backup. The job
+// is kept when it fails to allow inspecting the logs of its pod, and its
+// Failed condition is returned. Deleting it retries the removal
+func (r *APIManagerBackupScheduleLogicReconciler) reconcileRemoveBackupData(item *appsv1alpha1.APIManagerBackup) (*batchv1.JobCondition, reconcile.Result, error) {
+	if item.Status.BackupS3URL != nil {
+		job, err := backup.RemoveS3BackupDataJob(item)
+		if err != nil {
+			return nil, reconcile.Result{}, err
+		}
+		if err := r.SetControllerOwnerReference(item, job); err != nil {
+			return nil, reconcile.Result{}, err
+		}
+
+		existing := &batchv1.Job{}
+		err = r.GetResource(types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, existing)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, reconcile.Result{}, err
+		}
+
+		if errors.IsNotFound(err) {
+			err := r.CreateResource(job)
+			return nil, reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, err
+		}
+
+		if failedCondition := jobFailedCondition(existing); failedCondition != nil {
+			r.Logger().Info("Job failed", "Job Name", job.Name, "Reason", failedCondition.Reason)
+			return failedCondition, reconcile.Result{}, nil
+		}
+
+		if existing.Status.Succeeded != *job.Spec.Completions {
+			r.Logger().Info("Job has still not finished", "Job Name", job.Name, "Actively running Pods", existing.Status.Active, "Failed pods", existing.Status.Failed)
+			return nil, reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+		}
+	}
+
+	if item.Status.BackupPersistentVolumeClaimName != nil {
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      *item.Status.BackupPersistentVolumeClaimName,
+				Namespace: item.Namespace,
+			},
+		}
+		common.TagToObjectDeleteWithPropagationPolicy(pvc, metav1.DeletePropagationForeground)
+		err := r.ReconcileResource(&v1.PersistentVolumeClaim{}, pvc, reconcilers.CreateOnlyMutator)
+		if err != nil {
+			return nil, reconcile.Result{}, err
+		}
+	}
+
+	return nil, reconcile.Result{}, nil
+}
+
+// reconcileSchedule creates the backup of the last schedule time reached and
+// requeues the reconciliation for the next one. Missed schedule times are
+// not caught up, and no backup is created while another one is in progress
+func (r *APIManagerBackupScheduleLogicReconciler) reconcileSchedule(backups []appsv1alpha1.APIManagerBackup) (reconcile.Result, error) {
+	schedule, err := helper.ParseCronSchedule(r.cr.Spec.Schedule)
+	if err != nil {
+		r.Logger().Info("Invalid schedule", "error", err.Error())
+		changed := r.cr.Status.Conditions.SetCondition(apispkgcommon.Condition{
+			Type:    appsv1alpha1.APIManagerBackupScheduleInvalidConditionType,
+			Status:  v1.ConditionTrue,
+			Reason:  appsv1alpha1.APIManagerBackupScheduleInvalidScheduleReason,
+			Message: err.Error(),
+		})
+		if changed {
+			return reconcile.Result{}, r.UpdateResourceStatus(r.cr)
+		}
+		// Fixing the schedule triggers a new reconciliation
+		return reconcile.Result{}, nil
+	}
+	if r.cr.Status.Conditions.RemoveCondition(appsv1alpha1.APIManagerBackupScheduleInvalidConditionType) {
+		err := r.UpdateResourceStatus(r.cr)
+		return reconcile.Result{Requeue: true}, err
+	}
+
+	if r.cr.Suspended() {
+		r.Logger().Info("Schedule suspended")
+		return reconcile.Result{}, nil
+	}
+
+	now := apimanagerbackupscheduleClock.Now().UTC()
+	scheduleTime := lastScheduleTime(schedule, r.scheduleStartTime(), now)
+	if scheduleTime.IsZero() {
+		return requeueAtNextScheduleTime(schedule, now), nil
+	}
+
+	if active := activeBackup(backups); active != nil {
+		r.Logger().Info("Backup still in progress. Skipping scheduled backup", "APIManagerBackup", active.Name, "Schedule time", scheduleTime)
+		r.EventRecorder().Eventf(r.cr, v1.EventTypeWarning, "BackupSkipped", "Backup scheduled at %s skipped: backup %s still in progress", scheduleTime.Format(time.RFC3339), active.Name)
+	} else {
+		err := r.createBackup(scheduleTime)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
+	r.cr.Status.LastScheduleTime = &metav1.Time{Time: scheduleTime}
+	err = r.UpdateResourceStatus(r.cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return requeueAtNextScheduleTime(schedule, now), nil
+}
+
+func (r *APIManagerBackupScheduleLogicReconciler) scheduleStartTime() time.Time {
+	if r.cr.Status.LastScheduleTime != nil {
+		return r.cr.Status.LastScheduleTime.Time.UTC()
+	}
+	return r.cr.CreationTimestamp.Time.UTC()
+}
+
+func (r *APIManagerBackupScheduleLogicReconciler) createBackup(scheduleTime time.Time) error {
+	labels := map[string]string{}
+	for key, value := range r.cr.Spec.BackupTemplate.Labels {
+		labels[key] = value
+	}
+	labels[appsv1alpha1.APIManagerBackupScheduleLabelKey] = r.cr.Name
+
+	desired := &appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			// Unique per schedule time, like the jobs of a CronJob
+			Name:      fmt.Sprintf("%s-%d", r.cr.Name, scheduleTime.Unix()/60),
+			Namespace: r.cr.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				appsv1alpha1.APIManagerBackupScheduledTimeAnnotation: scheduleTime.Format(time.RFC3339),
+			},
+		},
+		Spec: *r.cr.Spec.BackupTemplate.Spec.DeepCopy(),
+	}
+
+	if err := r.SetControllerOwnerReference(r.cr, desired); err != nil {
+		return err
+	}
+
+	r.EventRecorder().Eventf(r.cr, v1.EventTypeNormal, "BackupCreated", "Created backup %s", desired.Name)
+	return r.CreateResource(desired)
+}
+
+// lastScheduleTime returns the last schedule time after the start time and not
+// after now. The zero time is returned when there is none
+func lastScheduleTime(schedule *helper.CronSchedule, start, now time.Time) time.Time {
+	last := time.Time{}
+	for idx, t := 0, schedule.Next(start); !t.IsZero() && !t.After(now); idx, t = idx+1, schedule.Next(t) {
+		if idx == maxMissedScheduleTimes {
+			return now.Truncate(time.Minute)
+		}
+		last = t
+	}
+	return last
+}
+
+func requeueAtNextScheduleTime(schedule *helper.CronSchedule, now time.Time) reconcile.Result {
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{RequeueAfter: next.Sub(now)}
+}
+
+// activeBackup returns a backup neither completed nor failed, if any
+func activeBackup(backups []appsv1alpha1.APIManagerBackup) *appsv1alpha1.APIManagerBackup {
+	for idx := range backups {
+		if !backups[idx].BackupCompleted() && !backups[idx].BackupFailed() {
+			return &backups[idx]
+		}
+	}
+	return nil
+}
+
+// backupsToPrune returns the backups not kept by the retention rules. The
+// completed backups are kept while any of the rules keeps them, failed ones
+// while there is no later completed backup and the ones in progress always.
+// Nothing is pruned when no rule is set
+func backupsToPrune(backups []appsv1alpha1.APIManagerBackup, retention *appsv1alpha1.APIManagerBackupRetention) []*appsv1alpha1.APIManagerBackup {
+	if retention == nil || (retention.KeepLast == nil && retention.KeepDaily == nil && retention.KeepWeekly == nil) {
+		return nil
+	}
+
+	completed := []*appsv1alpha1.APIManagerBackup{}
+	for idx := range backups {
+		if backups[idx].BackupCompleted() {
+			completed = append(completed, &backups[idx])
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return backupTime(completed[i]).After(backupTime(completed[j]))
+	})
+
+	kept := map[string]bool{}
+	if retention.KeepLast != nil {
+		for idx := 0; idx < len(completed) && idx < int(*retention.KeepLast); idx++ {
+			kept[completed[idx].Name] = true
+		}
+	}
+	keepPerPeriod := func(keep *int32, period func(time.Time) string) {
+		if keep == nil {
+			return
+		}
+		periods := map[string]bool{}
+		for _, item := range completed {
+			if len(periods) == int(*keep) {
+				return
+			}
+			key := period(backupTime(item))
+			if !periods[key] {
+				periods[key] = true
+				kept[item.Name] = true
+			}
+		}
+	}
+	keepPerPeriod(retention.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") })
+	keepPerPeriod(retention.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+
+	res := []*appsv1alpha1.APIManagerBackup{}
+	for idx := range backups {
+		item := &backups[idx]
+		switch {
+		case item.BackupCompleted() && !kept[item.Name]:
+			res = append(res, item)
+		case item.BackupFailed() && len(completed) > 0 && item.CreationTimestamp.Before(&completed[0].CreationTimestamp):
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// backupTime returns the time the backup completed at, in UTC
+func backupTime(item *appsv1alpha1.APIManagerBackup) time.Time {
+	if item.Status.CompletionTime != nil {
+		return item.Status.CompletionTime.Time.UTC()
+	}
+	return item.CreationTimestamp.Time.UTC()
+}
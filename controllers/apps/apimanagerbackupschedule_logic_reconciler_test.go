@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	apispkgcommon "github.com/3scale/3scale-operator/pkg/apispkg/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclock "k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func testScheduledBackup(name string, created time.Time, completed bool, failed bool) appsv1alpha1.APIManagerBackup {
+	res := appsv1alpha1.APIManagerBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+	}
+	if completed {
+		res.Status.Completed = &completed
+		completionTime := metav1.NewTime(created.Add(10 * time.Minute))
+		res.Status.CompletionTime = &completionTime
+	}
+	if failed {
+		res.Status.Conditions.SetCondition(apispkgcommon.Condition{
+			Type:   appsv1alpha1.APIManagerBackupFailedConditionType,
+			Status: v1.ConditionTrue,
+		})
+	}
+	return res
+}
+
+func TestAPIManagerBackupScheduleBackupsToPrune(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	// Monday
+	start := time.Date(2023, time.March, 13, 2, 0, 0, 0, time.UTC)
+
+	// Two backups a day during 15 days, the last one in progress
+	backups := []appsv1alpha1.APIManagerBackup{}
+	for day := 0; day < 15; day++ {
+		for _, hour := range []int{0, 12} {
+			created := start.Add(time.Duration(day*24+hour) * time.Hour)
+			name := created.Format("0102-15")
+			backups = append(backups, testScheduledBackup(name, created, !(day == 14 && hour == 12), false))
+		}
+	}
+	backups = append(backups,
+		testScheduledBackup("failed-old", start.Add(-time.Hour), false, true),
+		testScheduledBackup("failed-last", start.Add(15*24*time.Hour), false, true),
+	)
+
+	cases := []struct {
+		name      string
+		retention *appsv1alpha1.APIManagerBackupRetention
+		expected  []string
+	}{
+		{"NoRetention", nil, nil},
+		{"NoRules", &appsv1alpha1.APIManagerBackupRetention{}, nil},
+		{"KeepLast", &appsv1alpha1.APIManagerBackupRetention{KeepLast: int32Ptr(28)}, []string{"0313-02", "failed-old"}},
+		{"KeepDaily", &appsv1alpha1.APIManagerBackupRetention{KeepDaily: int32Ptr(14)}, []string{
+			"0313-02", "0313-14", "0314-02", "0315-02", "0316-02", "0317-02", "0318-02", "0319-02", "0320-02",
+			"0321-02", "0322-02", "0323-02", "0324-02", "0325-02", "0326-02", "failed-old",
+		}},
+		{"KeepWeekly", &appsv1alpha1.APIManagerBackupRetention{KeepWeekly: int32Ptr(2)}, []string{
+			"0313-02", "0313-14", "0314-02", "0314-14", "0315-02", "0315-14", "0316-02", "0316-14", "0317-02",
+			"0317-14", "0318-02", "0318-14", "0319-02", "0319-14", "0320-02", "0320-14", "0321-02", "0321-14",
+			"0322-02", "0322-14", "0323-02", "0323-14", "0324-02", "0324-14", "0325-02", "0325-14", "0326-02",
+			"failed-old",
+		}},
+		{"Combined", &appsv1alpha1.APIManagerBackupRetention{KeepLast: int32Ptr(2), KeepDaily: int32Ptr(2), KeepWeekly: int32Ptr(3)}, []string{
+			"0313-02", "0313-14", "0314-02", "0314-14", "0315-02", "0315-14", "0316-02", "0316-14", "0317-02",
+			"0317-14", "0318-02", "0318-14", "0319-02", "0320-02", "0320-14", "0321-02", "0321-14", "0322-02",
+			"0322-14", "0323-02", "0323-14", "0324-02", "0324-14", "0325-02", "0325-14", "0326-02", "failed-old",
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			var pruned []string
+			for _, item := range backupsToPrune(backups, tc.retention) {
+				pruned = append(pruned, item.Name)
+			}
+			sort.Strings(pruned)
+			if !reflect.DeepEqual(pruned, tc.expected) {
+				subT.Errorf("expected %v, got %v", tc.expected, pruned)
+			}
+		})
+	}
+}
+
+func TestAPIManagerBackupScheduleCreatesBackups(t *testing.T) {
+	namespace := "test"
+
+	created := time.Date(2023, time.March, 15, 10, 30, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakeClock(created.Add(time.Minute))
+	apimanagerbackupscheduleClock = fakeClock
+	defer func() { apimanagerbackupscheduleClock = &kubeclock.RealClock{} }()
+
+	cr := &appsv1alpha1.APIManagerBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: namespace, UID: "schedule-uid", CreationTimestamp: metav1.NewTime(created)},
+		Spec: appsv1alpha1.APIManagerBackupScheduleSpec{
+			Schedule: "0 2 * * *",
+			BackupTemplate: appsv1alpha1.APIManagerBackupTemplate{
+				Labels: map[string]string{"app": "3scale"},
+				Spec: appsv1alpha1.APIManagerBackupSpec{
+					BackupDestination: appsv1alpha1.APIManagerBackupDestination{
+						PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimBackupDestination{},
+					},
+				},
+			},
+		},
+	}
+
+	baseReconciler, cl := testBaseReconciler(t, "apimanager backup schedule test", cr)
+
+	reconcileSchedule := func() time.Duration {
+		for {
+			if err := cl.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr); err != nil {
+				t.Fatal(err)
+			}
+			res, err := NewAPIManagerBackupScheduleLogicReconciler(baseReconciler, cr).Reconcile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Requeue {
+				return res.RequeueAfter
+			}
+		}
+	}
+
+	// Nothing to do until the first schedule time
+	if requeueAfter := reconcileSchedule(); requeueAfter != 15*time.Hour+29*time.Minute {
+		t.Fatalf("unexpected requeue after %s", requeueAfter)
+	}
+
+	// Missed schedule times are not caught up
+	fakeClock.SetTime(time.Date(2023, time.March, 18, 2, 0, 30, 0, time.UTC))
+	if requeueAfter := reconcileSchedule(); requeueAfter != 24*time.Hour-30*time.Second {
+		t.Fatalf("unexpected requeue after %s", requeueAfter)
+	}
+
+	backupList := &appsv1alpha1.APIManagerBackupList{}
+	if err := cl.List(context.TODO(), backupList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backupList.Items) != 1 {
+		t.Fatalf("expected 1 backup, got %d", len(backupList.Items))
+	}
+	backup := backupList.Items[0]
+	if backup.Name != "nightly-27985080" {
+		t.Errorf("unexpected backup name %s", backup.Name)
+	}
+	if backup.Labels["app"] != "3scale" || backup.Labels[appsv1alpha1.APIManagerBackupScheduleLabelKey] != "nightly" {
+		t.Errorf("unexpected backup labels %v", backup.Labels)
+	}
+	if !metav1.IsControlledBy(&backup, cr) {
+		t.Error("expected backup to be controlled by the schedule")
+	}
+	if backup.Spec.BackupDestination.PersistentVolumeClaim == nil {
+		t.Error("expected backup spec from the template")
+	}
+	if cr.Status.LastScheduleTime == nil || !cr.Status.LastScheduleTime.Time.Equal(time.Date(2023, time.March, 18, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected last schedule time %v", cr.Status.LastScheduleTime)
+	}
+
+	// The next backup is skipped while the previous one is in progress
+	fakeClock.SetTime(time.Date(2023, time.March, 19, 2, 0, 0, 0, time.UTC))
+	reconcileSchedule()
+	if err := cl.List(context.TODO(), backupList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backupList.Items) != 1 {
+		t.Fatalf("expected 1 backup, got %d", len(backupList.Items))
+	}
+
+	// Completed backups are reported in the status
+	backup = backupList.Items[0]
+	completed := true
+	completionTime := metav1.NewTime(time.Date(2023, time.March, 18, 2, 30, 0, 0, time.UTC))
+	backup.Status.Completed = &completed
+	backup.Status.CompletionTime = &completionTime
+	if err := cl.Status().Update(context.TODO(), &backup); err != nil {
+		t.Fatal(err)
+	}
+	fakeClock.SetTime(time.Date(2023, time.March, 20, 2, 0, 0, 0, time.UTC))
+	reconcileSchedule()
+	if cr.Status.LastSuccessfulBackup == nil || cr.Status.LastSuccessfulBackup.Name != backup.Name {
+		t.Errorf("unexpected last successful backup %v", cr.Status.LastSuccessfulBackup)
+	}
+	reconcileSchedule()
+	if err := cl.List(context.TODO(), backupList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backupList.Items) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backupList.Items))
+	}
+}
+
+func TestAPIManagerBackupScheduleInvalidSchedule(t *testing.T) {
+	cr := &appsv1alpha1.APIManagerBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "test"},
+		Spec:       appsv1alpha1.APIManagerBackupScheduleSpec{Schedule: "0 25 * * *"},
+	}
+
+	baseReconciler, _ := testBaseReconciler(t, "apimanager backup schedule test", cr)
+
+	res, err := NewAPIManagerBackupScheduleLogicReconciler(baseReconciler, cr).Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requeue || res.RequeueAfter != 0 {
+		t.Errorf("unexpected requeue %v", res)
+	}
+	if !cr.Status.Conditions.IsTrueFor(appsv1alpha1.APIManagerBackupScheduleInvalidConditionType) {
+		t.Error("expected Invalid condition")
+	}
+}
+
+func TestAPIManagerBackupSchedulePausedStatus(t *testing.T) {
+	namespace := "test"
+	created := time.Date(2023, time.March, 15, 10, 30, 0, 0, time.UTC)
+
+	cr := &appsv1alpha1.APIManagerBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nightly", Namespace: namespace, UID: "schedule-uid", CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{apispkgcommon.PausedAnnotation: "true"},
+		},
+		Spec: appsv1alpha1.APIManagerBackupScheduleSpec{Schedule: "* * * * *"},
+	}
+
+	isController := true
+	scheduledBackup := func(name string, completed, failed bool) *appsv1alpha1.APIManagerBackup {
+		backup := testScheduledBackup(name, created, completed, failed)
+		backup.Namespace = namespace
+		backup.Labels = map[string]string{appsv1alpha1.APIManagerBackupScheduleLabelKey: cr.Name}
+		backup.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: appsv1alpha1.GroupVersion.String(), Kind: "APIManagerBackupSchedule", Name: cr.Name, UID: cr.UID, Controller: &isController,
+		}}
+		return &backup
+	}
+
+	baseReconciler, cl := testBaseReconciler(t, "apimanager backup schedule test", cr,
+		scheduledBackup("nightly-completed", true, false), scheduledBackup("nightly-failed", false, true))
+
+	for {
+		if err := cl.Get(context.TODO(), client.ObjectKeyFromObject(cr), cr); err != nil {
+			t.Fatal(err)
+		}
+		res, err := NewAPIManagerBackupScheduleLogicReconciler(baseReconciler, cr).ReconcilePausedStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Requeue {
+			break
+		}
+	}
+
+	if cr.Status.LastSuccessfulBackup == nil || cr.Status.LastSuccessfulBackup.Name != "nightly-completed" {
+		t.Errorf("unexpected last successful backup %v", cr.Status.LastSuccessfulBackup)
+	}
+	if cr.Status.LastFailedBackup == nil || cr.Status.LastFailedBackup.Name != "nightly-failed" {
+		t.Errorf("unexpected last failed backup %v", cr.Status.LastFailedBackup)
+	}
+
+	// No backup is created while paused
+	backupList := &appsv1alpha1.APIManagerBackupList{}
+	if err := cl.List(context.TODO(), backupList); err != nil {
+		t.Fatal(err)
+	}
+	if len(backupList.Items) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backupList.Items))
+	}
+}
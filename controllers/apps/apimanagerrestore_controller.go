@@ -54,6 +54,22 @@ func (r *APIManagerRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return reconcile.Result{}, err
 	}
 
+	// Paused restores report the Paused condition and the verification of the backup
+	paused, err := r.ReconcilePausedCondition(instance, &instance.Status.Conditions)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if paused {
+		if instance.RestoreCompleted() || instance.BackupRejected() {
+			return reconcile.Result{}, nil
+		}
+		apiManagerRestoreLogicReconciler, err := r.apiManagerRestoreLogicReconciler(instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		return apiManagerRestoreLogicReconciler.ReconcilePausedStatus()
+	}
+
 	res, err := r.setAPIManagerRestoreDefaults(instance)
 	if err != nil {
 		logger.Error(err, "Error")
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	appsv1 "github.com/openshift/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -17,6 +18,7 @@ import (
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/operator"
 	"github.com/3scale/3scale-operator/pkg/backup"
 	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/helper"
@@ -50,6 +52,11 @@ func (r *APIManagerRestoreLogicReconciler) Reconcile() (reconcile.Result, error)
 		return reconcile.Result{}, nil
 	}
 
+	if r.cr.BackupRejected() {
+		r.Logger().Info("Backup rejected. End of reconciliation")
+		return reconcile.Result{}, nil
+	}
+
 	if !r.cr.MainStepsCompleted() {
 		r.Logger().Info("Reconciling restore steps")
 		result, err := r.reconcileMainSteps()
@@ -69,13 +76,55 @@ func (r *APIManagerRestoreLogicReconciler) Reconcile() (reconcile.Result, error)
 	return result, err
 }
 
+// ReconcilePausedStatus reports the verification of the backup of a paused
+// restore once its verification job has finished. No job is created nor
+// deleted while the restore is paused
+func (r *APIManagerRestoreLogicReconciler) ReconcilePausedStatus() (reconcile.Result, error) {
+	if r.cr.RestoreCompleted() || r.cr.BackupRejected() {
+		return reconcile.Result{}, nil
+	}
+
+	if r.cr.Status.Conditions.GetCondition(appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType) != nil {
+		return reconcile.Result{}, nil
+	}
+
+	desired := r.apiManagerRestore.VerifyBackupJob()
+	if desired == nil {
+		return reconcile.Result{}, nil
+	}
+
+	existing := &batchv1.Job{}
+	err := r.GetResource(common.ObjectKey(desired), existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if existing.Status.Succeeded != *desired.Spec.Completions {
+		return reconcile.Result{}, nil
+	}
+
+	manifestSecret := &v1.Secret{}
+	err = r.GetResource(types.NamespacedName{Name: r.apiManagerRestore.BackupManifestSecretName(), Namespace: r.cr.Namespace}, manifestSecret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	r.cr.Status.Conditions.SetCondition(r.apiManagerRestore.BackupVerifiedCondition(manifestSecret))
+	return reconcile.Result{}, r.UpdateResourceStatus(r.cr)
+}
+
 func (r *APIManagerRestoreLogicReconciler) reconcileMainSteps() (reconcile.Result, error) {
 	result, err := r.reconcileStartTimeField()
 	if result.Requeue || err != nil {
 		return result, err
 	}
 
-	result, err = r.reconcileRestoreFromPVCSource()
+	result, err = r.reconcileRestoreFromSource()
 	if result.Requeue || err != nil {
 		return result, err
 	}
@@ -126,7 +175,7 @@ func (r *APIManagerRestoreLogicReconciler) reconcileStartTimeField() (reconcile.
 	return reconcile.Result{}, nil
 }
 
-func (r *APIManagerRestoreLogicReconciler) reconcileRestoreFromPVCSource() (reconcile.Result, error) {
+func (r *APIManagerRestoreLogicReconciler) reconcileRestoreFromSource() (reconcile.Result, error) {
 	var res reconcile.Result
 	var err error
 
@@ -135,7 +184,12 @@ func (r *APIManagerRestoreLogicReconciler) reconcileRestoreFromPVCSource() (reco
 		return res, err
 	}
 
-	res, err = r.reconcileRestoreSecretsAndConfigMapsFromPVCJob()
+	res, err = r.reconcileBackupVerification()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	res, err = r.reconcileRestoreSecretsAndConfigMapsJob()
 	if res.Requeue || err != nil {
 		return res, err
 	}
@@ -145,7 +199,12 @@ func (r *APIManagerRestoreLogicReconciler) reconcileRestoreFromPVCSource() (reco
 		return res, err
 	}
 
-	res, err = r.reconcileRestoreSystemFileStoragePVCFromPVCJob()
+	res, err = r.reconcileRestoreSystemFileStoragePVCJob()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	res, err = r.reconcileRestoreDatabasesJobs()
 	if res.Requeue || err != nil {
 		return res, err
 	}
@@ -155,6 +214,11 @@ func (r *APIManagerRestoreLogicReconciler) reconcileRestoreFromPVCSource() (reco
 		return res, err
 	}
 
+	res, err = r.reconcileRestoreZyncDatabaseJob()
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
 	res, err = r.reconcileResynchronizeZyncDomains()
 	if res.Requeue || err != nil {
 		return res, err
@@ -216,8 +280,59 @@ func (r *APIManagerRestoreLogicReconciler) reconcileJob(desired *batchv1.Job) (r
 	return reconcile.Result{}, nil
 }
 
-func (r *APIManagerRestoreLogicReconciler) reconcileRestoreSecretsAndConfigMapsFromPVCJob() (reconcile.Result, error) {
-	desired := r.apiManagerRestore.RestoreSecretsAndConfigMapsFromPVCJob()
+// The backup is verified against its manifest before anything is restored.
+// Rejected backups stop the restore with the BackupVerified condition set to false.
+// Backups without manifest allowed to be restored unverified are restored with
+// the condition set to unknown
+func (r *APIManagerRestoreLogicReconciler) reconcileBackupVerification() (reconcile.Result, error) {
+	desired := r.apiManagerRestore.VerifyBackupJob()
+	if desired == nil {
+		return reconcile.Result{}, nil
+	}
+
+	manifestSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.apiManagerRestore.BackupManifestSecretName(),
+			Namespace: r.cr.Namespace,
+		},
+	}
+
+	if verified := r.cr.Status.Conditions.GetCondition(appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType); verified != nil && !verified.IsFalse() {
+		common.TagObjectToDelete(manifestSecret)
+		err := r.ReconcileResource(&v1.Secret{}, manifestSecret, reconcilers.CreateOnlyMutator)
+		return reconcile.Result{}, err
+	}
+
+	res, err := r.reconcileJob(desired)
+	if res.Requeue || err != nil {
+		return res, err
+	}
+
+	err = r.GetResource(common.ObjectKey(manifestSecret), manifestSecret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.Logger().Info("Backup manifest secret not found. Waiting", "Secret Name", manifestSecret.Name)
+			return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	condition := r.apiManagerRestore.BackupVerifiedCondition(manifestSecret)
+	switch {
+	case condition.IsFalse():
+		r.Logger().Info("Backup rejected", "Reason", condition.Reason, "Message", condition.Message)
+		r.EventRecorder().Event(r.cr, v1.EventTypeWarning, string(condition.Reason), condition.Message)
+	case condition.IsUnknown():
+		r.Logger().Info("Backup not verified", "Reason", condition.Reason, "Message", condition.Message)
+		r.EventRecorder().Event(r.cr, v1.EventTypeWarning, string(condition.Reason), condition.Message)
+	}
+	r.cr.Status.Conditions.SetCondition(condition)
+	err = r.UpdateResourceStatus(r.cr)
+	return reconcile.Result{Requeue: true}, err
+}
+
+func (r *APIManagerRestoreLogicReconciler) reconcileRestoreSecretsAndConfigMapsJob() (reconcile.Result, error) {
+	desired := r.apiManagerRestore.RestoreSecretsAndConfigMapsJob()
 	if desired == nil {
 		return reconcile.Result{}, nil
 	}
@@ -258,11 +373,89 @@ func (r *APIManagerRestoreLogicReconciler) runtimeRestoreInfoFromAPIManager(apim
 	restoreInfo := &restore.RuntimeAPIManagerRestoreInfo{
 		PVCStorageClass: storageClass,
 	}
+
+	err := r.setRuntimeDatabasesRestoreInfo(restoreInfo, apimanager)
+	if err != nil {
+		return nil, err
+	}
+
 	return restoreInfo, nil
 }
 
-func (r *APIManagerRestoreLogicReconciler) reconcileRestoreSystemFileStoragePVCFromPVCJob() (reconcile.Result, error) {
-	desired := r.apiManagerRestore.RestoreSystemFileStoragePVCFromPVCJob()
+// setRuntimeDatabasesRestoreInfo sets the workloads and PVCs of the databases and Redis
+// instances deployed by the operator for the APIManager, as the APIManager would create them
+func (r *APIManagerRestoreLogicReconciler) setRuntimeDatabasesRestoreInfo(restoreInfo *restore.RuntimeAPIManagerRestoreInfo, apimanager *appsv1alpha1.APIManager) error {
+	images, err := operator.DeploymentImages(apimanager)
+	if err != nil {
+		return err
+	}
+	databaseRestoreInfo := func(dc *appsv1.DeploymentConfig, pvc *v1.PersistentVolumeClaim) (*restore.RuntimeDatabaseRestoreInfo, error) {
+		deployment, err := helper.DeploymentFromDeploymentConfig(dc, images)
+		if err != nil {
+			return nil, err
+		}
+		return &restore.RuntimeDatabaseRestoreInfo{PodTemplate: deployment.Spec.Template, PVC: pvc}, nil
+	}
+
+	if apimanager.IsSystemMysqlEnabled() {
+		systemMySQL, err := operator.SystemMySQL(apimanager, r.Client())
+		if err != nil {
+			return err
+		}
+		restoreInfo.SystemMySQL, err = databaseRestoreInfo(systemMySQL.DeploymentConfig(), systemMySQL.PersistentVolumeClaim())
+		if err != nil {
+			return err
+		}
+	}
+
+	if apimanager.IsSystemPostgreSQLEnabled() {
+		systemPostgreSQL, err := operator.SystemPostgreSQL(apimanager, r.Client())
+		if err != nil {
+			return err
+		}
+		restoreInfo.SystemPostgreSQL, err = databaseRestoreInfo(systemPostgreSQL.DeploymentConfig(), systemPostgreSQL.DataPersistentVolumeClaim())
+		if err != nil {
+			return err
+		}
+	}
+
+	if !apimanager.IsExternal(appsv1alpha1.ZyncDatabase) {
+		zync, err := operator.Zync(apimanager, r.Client())
+		if err != nil {
+			return err
+		}
+		restoreInfo.ZyncDatabase, err = databaseRestoreInfo(zync.DatabaseDeploymentConfig(), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	backendRedisEnabled := !apimanager.IsExternal(appsv1alpha1.BackendRedis) && !apimanager.IsBackendRedisSentinelEnabled()
+	systemRedisEnabled := !apimanager.IsExternal(appsv1alpha1.SystemRedis) && !apimanager.IsSystemRedisSentinelEnabled()
+	if backendRedisEnabled || systemRedisEnabled {
+		redis, err := operator.Redis(apimanager, r.Client())
+		if err != nil {
+			return err
+		}
+		if backendRedisEnabled {
+			restoreInfo.BackendRedis, err = databaseRestoreInfo(redis.BackendDeploymentConfig(), redis.BackendPVC())
+			if err != nil {
+				return err
+			}
+		}
+		if systemRedisEnabled {
+			restoreInfo.SystemRedis, err = databaseRestoreInfo(redis.SystemDeploymentConfig(), redis.SystemPVC())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *APIManagerRestoreLogicReconciler) reconcileRestoreSystemFileStoragePVCJob() (reconcile.Result, error) {
+	desired := r.apiManagerRestore.RestoreSystemFileStoragePVCJob()
 	if desired == nil {
 		return reconcile.Result{}, nil
 	}
@@ -275,6 +468,128 @@ func (r *APIManagerRestoreLogicReconciler) reconcileRestoreSystemFileStoragePVCF
 	return r.reconcileJob(desired)
 }
 
+// The databases and Redis instances deployed by the operator are loaded in their
+// volumes before the APIManager is created, so 3scale starts with the restored data
+func (r *APIManagerRestoreLogicReconciler) reconcileRestoreDatabasesJobs() (reconcile.Result, error) {
+	restoredAPIManager, err := r.restoredAPIManager()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if restoredAPIManager != nil {
+		return reconcile.Result{}, nil
+	}
+
+	apimanager, err := r.apiManagerFromSharedBackupSecret()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	restoreInfo, err := r.runtimeRestoreInfoFromAPIManager(apimanager)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	for _, databaseRestore := range []struct {
+		databaseInfo *restore.RuntimeDatabaseRestoreInfo
+		job          *batchv1.Job
+	}{
+		{restoreInfo.SystemMySQL, r.apiManagerRestore.RestoreSystemMySQLJob(restoreInfo)},
+		{restoreInfo.SystemPostgreSQL, r.apiManagerRestore.RestoreSystemPostgreSQLJob(restoreInfo)},
+		{restoreInfo.BackendRedis, r.apiManagerRestore.RestoreBackendRedisJob(restoreInfo)},
+		{restoreInfo.SystemRedis, r.apiManagerRestore.RestoreSystemRedisJob(restoreInfo)},
+	} {
+		if databaseRestore.job == nil {
+			continue
+		}
+
+		if pvc := r.apiManagerRestore.DatabasePVC(databaseRestore.databaseInfo); pvc != nil {
+			err := r.ReconcileResource(&v1.PersistentVolumeClaim{}, pvc, reconcilers.CreateOnlyMutator)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		res, err := r.reconcileJob(databaseRestore.job)
+		if res.Requeue || err != nil {
+			return res, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *APIManagerRestoreLogicReconciler) reconcileRestoreZyncDatabaseJob() (reconcile.Result, error) {
+	restoredAPIManager, err := r.restoredAPIManager()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if restoredAPIManager == nil {
+		r.Logger().Info("APIManager not found. Waiting until it exists", "APIManager", r.cr.Status.APIManagerToRestoreRef.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+
+	restoreInfo, err := r.runtimeRestoreInfoFromAPIManager(restoredAPIManager)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	desired := r.apiManagerRestore.RestoreZyncDatabaseJob(restoreInfo)
+	if desired == nil {
+		return reconcile.Result{}, nil
+	}
+
+	if !helper.ArrayContains(restoredAPIManager.Status.Deployments.Ready, component.ZyncDatabaseDeploymentName) {
+		r.Logger().Info("zync-database deployment not ready. Waiting", "APIManager", restoredAPIManager.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+
+	return r.reconcileJob(desired)
+}
+
+// databasesRestoreJobs returns the jobs loading the databases of the restored APIManager
+func (r *APIManagerRestoreLogicReconciler) databasesRestoreJobs() ([]*batchv1.Job, error) {
+	restoredAPIManager, err := r.restoredAPIManager()
+	if err != nil || restoredAPIManager == nil {
+		return nil, err
+	}
+
+	restoreInfo, err := r.runtimeRestoreInfoFromAPIManager(restoredAPIManager)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []*batchv1.Job{}
+	for _, job := range []*batchv1.Job{
+		r.apiManagerRestore.RestoreSystemMySQLJob(restoreInfo),
+		r.apiManagerRestore.RestoreSystemPostgreSQLJob(restoreInfo),
+		r.apiManagerRestore.RestoreBackendRedisJob(restoreInfo),
+		r.apiManagerRestore.RestoreSystemRedisJob(restoreInfo),
+		r.apiManagerRestore.RestoreZyncDatabaseJob(restoreInfo),
+	} {
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// restoredAPIManager returns the APIManager created by the restore, nil when it
+// has not been created yet
+func (r *APIManagerRestoreLogicReconciler) restoredAPIManager() (*appsv1alpha1.APIManager, error) {
+	if r.cr.Status.APIManagerToRestoreRef == nil {
+		return nil, nil
+	}
+
+	apimanager := &appsv1alpha1.APIManager{}
+	err := r.GetResource(types.NamespacedName{Name: r.cr.Status.APIManagerToRestoreRef.Name, Namespace: r.cr.Namespace}, apimanager)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return apimanager, nil
+}
+
 func (r *APIManagerRestoreLogicReconciler) systemStoragePVCExists() (bool, error) {
 	pvc := &v1.PersistentVolumeClaim{}
 	err := r.GetResource(types.NamespacedName{Name: component.SystemFileStoragePVCName, Namespace: r.cr.Namespace}, pvc)
@@ -510,11 +825,17 @@ func (r *APIManagerRestoreLogicReconciler) waitForSystemSidekiq() (reconcile.Res
 // K8s jobs we allow the cleanup to be possible
 func (r *APIManagerRestoreLogicReconciler) reconcileJobsCleanup() (reconcile.Result, error) {
 	jobsToDelete := []*batchv1.Job{
-		r.apiManagerRestore.RestoreSecretsAndConfigMapsFromPVCJob(),
-		r.apiManagerRestore.RestoreSystemFileStoragePVCFromPVCJob(),
+		r.apiManagerRestore.VerifyBackupJob(),
+		r.apiManagerRestore.RestoreSecretsAndConfigMapsJob(),
+		r.apiManagerRestore.RestoreSystemFileStoragePVCJob(),
 		r.apiManagerRestore.CreateAPIManagerSharedSecretJob(),
 		r.apiManagerRestore.ZyncResyncDomainsJob(),
 	}
+	databasesRestoreJobs, err := r.databasesRestoreJobs()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	jobsToDelete = append(jobsToDelete, databasesRestoreJobs...)
 
 	existingJobFound := false
 	for _, job := range jobsToDelete {
@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
+	"github.com/3scale/3scale-operator/pkg/backup"
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/restore"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestAPIManagerRestoreDatabasesRestoreJobs(t *testing.T) {
+	namespace := "test"
+	trueValue := true
+
+	cases := []struct {
+		name               string
+		externalComponents *appsv1alpha1.ExternalComponentsSpec
+		expectedJobs       []string
+	}{
+		{"InternalDatabases", nil, []string{"restore-system-mysql", "restore-backend-redis", "restore-system-redis", "restore-zync-database"}},
+		{"ExternalDatabases", &appsv1alpha1.ExternalComponentsSpec{
+			System:  &appsv1alpha1.ExternalSystemComponents{Database: &trueValue, Redis: &trueValue},
+			Backend: &appsv1alpha1.ExternalBackendComponents{Redis: &trueValue},
+			Zync:    &appsv1alpha1.ExternalZyncComponents{Database: &trueValue},
+		}, []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			apimanager := testAPIManager(subT, namespace, tc.externalComponents)
+
+			cr := &appsv1alpha1.APIManagerRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "example-restore", Namespace: namespace, UID: "restore-uid"},
+				Spec: appsv1alpha1.APIManagerRestoreSpec{
+					RestoreSource: appsv1alpha1.APIManagerRestoreSource{
+						PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimRestoreSource{
+							ClaimSource: v1.PersistentVolumeClaimVolumeSource{ClaimName: "example-backup"},
+						},
+					},
+				},
+				Status: appsv1alpha1.APIManagerRestoreStatus{APIManagerToRestoreRef: &v1.LocalObjectReference{Name: apimanager.Name}},
+			}
+
+			baseReconciler, cl := testBaseReconciler(subT, "apimanager restore test", apimanager, cr)
+
+			options, err := restore.NewAPIManagerRestoreOptionsProvider(cr, cl).Options()
+			if err != nil {
+				subT.Fatal(err)
+			}
+			r := NewAPIManagerRestoreLogicReconciler(baseReconciler, cr, restore.NewAPIManagerRestore(options))
+
+			jobs, err := r.databasesRestoreJobs()
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if len(jobs) != len(tc.expectedJobs) {
+				subT.Fatalf("expected %d jobs, got %d", len(tc.expectedJobs), len(jobs))
+			}
+			for idx, job := range jobs {
+				container := job.Spec.Template.Spec.Containers[0]
+				if container.Name != tc.expectedJobs[idx] {
+					subT.Errorf("expected job %s, got %s", tc.expectedJobs[idx], container.Name)
+				}
+				// The restore of zync-database connects to its service
+				if job.Spec.Template.Labels[component.DatabaseClientLabelKey] != component.DatabaseClientLabelValue {
+					subT.Errorf("job %s: expected the database client label, got %v", container.Name, job.Spec.Template.Labels)
+				}
+				// Images are resolved from the APIManager, not from the ImageStreams
+				if !strings.Contains(container.Image, "/") {
+					subT.Errorf("job %s: unexpected image '%s'", container.Name, container.Image)
+				}
+				for _, env := range container.Env {
+					if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && (env.ValueFrom.SecretKeyRef.Optional == nil || !*env.ValueFrom.SecretKeyRef.Optional) {
+						subT.Errorf("job %s: expected secret of env var %s to be optional", container.Name, env.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAPIManagerRestoreBackupVerification(t *testing.T) {
+	namespace := "test"
+
+	verification := func(threescaleVersion string) []byte {
+		return []byte(`{"manifestFound": true, "formatVersion": 1, "threescaleVersion": "` + threescaleVersion + `", "operatorVersion": "0.11.0", "errors": []}`)
+	}
+	encryptedVerification := func(decryptionError string) []byte {
+		return []byte(`{"manifestFound": true, "formatVersion": 1, "threescaleVersion": "` + product.ThreescaleRelease + `", "operatorVersion": "0.11.0", "encryption": "` + backup.EncryptionAlgorithm + `", "errors": [], "decryptionError": "` + decryptionError + `"}`)
+	}
+	decryption := &appsv1alpha1.BackupEncryptionSpec{KeySecretRef: v1.LocalObjectReference{Name: "backup-encryption-key"}}
+
+	cases := []struct {
+		name            string
+		decryption      *appsv1alpha1.BackupEncryptionSpec
+		allowUnverified bool
+		verification    []byte
+		expectedStatus  v1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{"Verified", nil, false, verification(product.ThreescaleRelease),
+			v1.ConditionTrue, string(appsv1alpha1.APIManagerRestoreManifestVerifiedReason), ""},
+		{"ManifestNotFound", nil, false, []byte(`{"manifestFound": false, "errors": []}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreManifestNotFoundReason), ""},
+		{"UnverifiedManifestNotFound", nil, true, []byte(`{"manifestFound": false, "errors": []}`),
+			v1.ConditionUnknown, string(appsv1alpha1.APIManagerRestoreManifestNotFoundReason), ""},
+		{"Corrupted", nil, false, []byte(`{"manifestFound": true, "formatVersion": 1, "errors": ["secrets/system-seed.json: checksum mismatch"], "errorCount": 1}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreBackupCorruptedReason),
+			"Backup data does not match its manifest: secrets/system-seed.json: checksum mismatch"},
+		{"CorruptedErrorsBounded", nil, false, []byte(`{"manifestFound": true, "formatVersion": 1, "errors": ["a: not found", "b: not found", "c: not found", "d: not found", "e: not found"], "errorCount": 12}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreBackupCorruptedReason),
+			"Backup data does not match its manifest: a: not found; b: not found; c: not found; d: not found; e: not found; 7 more"},
+		{"IncompatibleVersion", nil, false, verification("2.13"),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreIncompatibleVersionReason), ""},
+		{"EncryptedVerified", decryption, false, encryptedVerification(""),
+			v1.ConditionTrue, string(appsv1alpha1.APIManagerRestoreManifestVerifiedReason), ""},
+		{"EncryptedWithoutDecryptionKey", nil, false, encryptedVerification(""),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreDecryptionFailedReason), ""},
+		{"WrongDecryptionKey", decryption, false, encryptedVerification("secrets/system-seed.json could not be decrypted"),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreDecryptionFailedReason), ""},
+		{"NotEncrypted", decryption, false, verification(product.ThreescaleRelease),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreDecryptionFailedReason), ""},
+		{"ManifestNotAuthentic", decryption, false, []byte(`{"manifestFound": true, "errors": [], "authenticationError": "manifest does not match its MAC"}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreManifestNotAuthenticReason),
+			"Backup manifest cannot be authenticated with the decryption key: manifest does not match its MAC"},
+		{"EncryptedManifestNotFound", decryption, false, []byte(`{"manifestFound": false, "errors": []}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreManifestNotFoundReason), ""},
+		{"UnverifiedEncryptedManifestNotFound", decryption, true, []byte(`{"manifestFound": false, "errors": []}`),
+			v1.ConditionFalse, string(appsv1alpha1.APIManagerRestoreManifestNotFoundReason), ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			cr := &appsv1alpha1.APIManagerRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "example-restore", Namespace: namespace, UID: "restore-uid"},
+				Spec: appsv1alpha1.APIManagerRestoreSpec{
+					RestoreSource: appsv1alpha1.APIManagerRestoreSource{
+						PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimRestoreSource{
+							ClaimSource: v1.PersistentVolumeClaimVolumeSource{ClaimName: "example-backup"},
+						},
+					},
+					Decryption:            tc.decryption,
+					AllowUnverifiedBackup: tc.allowUnverified,
+				},
+			}
+
+			options, err := restore.NewAPIManagerRestoreOptionsProvider(cr, nil).Options()
+			if err != nil {
+				subT.Fatal(err)
+			}
+			apiManagerRestore := restore.NewAPIManagerRestore(options)
+
+			job := apiManagerRestore.VerifyBackupJob()
+			job.Status.Succeeded = 1
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: apiManagerRestore.BackupManifestSecretName(), Namespace: namespace},
+				Data:       map[string][]byte{restore.BackupVerificationFileName: tc.verification},
+			}
+
+			baseReconciler, cl := testBaseReconciler(subT, "apimanager restore test", cr, job, secret)
+			r := NewAPIManagerRestoreLogicReconciler(baseReconciler, cr, apiManagerRestore)
+
+			if _, err := r.reconcileBackupVerification(); err != nil {
+				subT.Fatal(err)
+			}
+
+			condition := cr.Status.Conditions.GetCondition(appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType)
+			if condition == nil || condition.Status != tc.expectedStatus || string(condition.Reason) != tc.expectedReason {
+				subT.Fatalf("unexpected BackupVerified condition %v", condition)
+			}
+			if tc.expectedMessage != "" && condition.Message != tc.expectedMessage {
+				subT.Errorf("expected message '%s', got '%s'", tc.expectedMessage, condition.Message)
+			}
+			if cr.BackupRejected() != (tc.expectedStatus == v1.ConditionFalse) {
+				subT.Errorf("unexpected backup rejected %t", cr.BackupRejected())
+			}
+
+			// Nothing is restored from rejected backups
+			res, err := r.Reconcile()
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if cr.BackupRejected() && (res.Requeue || res.RequeueAfter != 0) {
+				subT.Errorf("unexpected requeue %v", res)
+			}
+			jobs := &batchv1.JobList{}
+			if err := cl.List(context.TODO(), jobs); err != nil {
+				subT.Fatal(err)
+			}
+			if cr.BackupRejected() && len(jobs.Items) != 1 {
+				subT.Errorf("expected only the verification job, got %d jobs", len(jobs.Items))
+			}
+			if cr.BackupRejected() {
+				return
+			}
+
+			// The restore goes on with verified and unverified backups
+			res, err = r.reconcileBackupVerification()
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if res.Requeue {
+				subT.Errorf("unexpected requeue %v", res)
+			}
+			err = cl.Get(context.TODO(), client.ObjectKeyFromObject(secret), &v1.Secret{})
+			if !errors.IsNotFound(err) {
+				subT.Errorf("expected the verification secret to be deleted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIManagerRestorePausedStatus(t *testing.T) {
+	namespace := "test"
+
+	cr := &appsv1alpha1.APIManagerRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-restore", Namespace: namespace, UID: "restore-uid"},
+		Spec: appsv1alpha1.APIManagerRestoreSpec{
+			RestoreSource: appsv1alpha1.APIManagerRestoreSource{
+				PersistentVolumeClaim: &appsv1alpha1.PersistentVolumeClaimRestoreSource{
+					ClaimSource: v1.PersistentVolumeClaimVolumeSource{ClaimName: "example-backup"},
+				},
+			},
+		},
+	}
+
+	options, err := restore.NewAPIManagerRestoreOptionsProvider(cr, nil).Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiManagerRestore := restore.NewAPIManagerRestore(options)
+
+	baseReconciler, cl := testBaseReconciler(t, "apimanager restore test", cr)
+	r := NewAPIManagerRestoreLogicReconciler(baseReconciler, cr, apiManagerRestore)
+
+	// The verification job is not created while paused
+	if _, err := r.ReconcilePausedStatus(); err != nil {
+		t.Fatal(err)
+	}
+	jobs := &batchv1.JobList{}
+	if err := cl.List(context.TODO(), jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected no jobs, got %d", len(jobs.Items))
+	}
+
+	// The verification finished before the restore was paused is reported
+	job := apiManagerRestore.VerifyBackupJob()
+	job.Status.Succeeded = 1
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: apiManagerRestore.BackupManifestSecretName(), Namespace: namespace},
+		Data: map[string][]byte{
+			restore.BackupVerificationFileName: []byte(`{"manifestFound": true, "formatVersion": 1, "threescaleVersion": "2.13", "operatorVersion": "0.11.0", "errors": []}`),
+		},
+	}
+	for _, obj := range []client.Object{job, secret} {
+		if err := cl.Create(context.TODO(), obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.ReconcilePausedStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if !cr.BackupRejected() {
+		t.Fatalf("expected the backup to be rejected, got %v", cr.Status.Conditions)
+	}
+	// The manifest secret is not deleted while paused
+	if err := cl.Get(context.TODO(), common.ObjectKey(secret), &v1.Secret{}); err != nil {
+		t.Fatal(err)
+	}
+}
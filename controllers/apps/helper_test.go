@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// testAPIManager returns an APIManager with its defaults set
+func testAPIManager(t *testing.T, namespace string, externalComponents *appsv1alpha1.ExternalComponentsSpec) *appsv1alpha1.APIManager {
+	t.Helper()
+	tenantName := "tenant"
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: namespace},
+		Spec: appsv1alpha1.APIManagerSpec{
+			APIManagerCommonSpec: appsv1alpha1.APIManagerCommonSpec{WildcardDomain: "example.com", TenantName: &tenantName},
+			ExternalComponents:   externalComponents,
+		},
+	}
+	if _, err := apimanager.SetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	return apimanager
+}
+
+// testBaseReconciler returns a BaseReconciler backed by a fake client holding the given objects
+func testBaseReconciler(t *testing.T, name string, objs ...runtime.Object) (*reconcilers.BaseReconciler, client.Client) {
+	t.Helper()
+	s := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, appsv1alpha1.AddToScheme, appsv1.AddToScheme, routev1.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+	clientset := fakeclientset.NewSimpleClientset()
+	baseReconciler := reconcilers.NewBaseReconciler(context.TODO(), cl, s, cl, logf.Log.WithName(name), clientset.Discovery(), record.NewFakeRecorder(100))
+	return baseReconciler, cl
+}
@@ -4,34 +4,29 @@ import (
 	"context"
 	"fmt"
 
-	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-)
 
-const (
-	APImanagerSecretLabelPrefix = "secret.apimanager.apps.3scale.net/"
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 )
 
-// SecretToApimanagerEventMapper is an EventHandler that maps secret object to apimanager CR's
-type SecretToApimanagerEventMapper struct {
-	K8sClient client.Client
-	Logger    logr.Logger
-	Namespace string
-}
-
-func apimanagerSecretLabelKey(uid string) string {
-	return fmt.Sprintf("%s%s", APImanagerSecretLabelPrefix, uid)
+// ObjectToApimanagerEventMapper is an EventHandler that maps secret and configmap objects to apimanager CR's.
+// APIManagers are labeled with the UIDs of the objects consumed by their components, using LabelPrefix.
+type ObjectToApimanagerEventMapper struct {
+	K8sClient   client.Client
+	Logger      logr.Logger
+	Namespace   string
+	LabelPrefix string
 }
 
-func (s *SecretToApimanagerEventMapper) Map(obj client.Object) []reconcile.Request {
+func (s *ObjectToApimanagerEventMapper) Map(obj client.Object) []reconcile.Request {
 
 	apimanagerList := &appsv1alpha1.APIManagerList{}
 
-	// filter by Secret UID
-	opts := []client.ListOption{client.HasLabels{apimanagerSecretLabelKey(string(obj.GetUID()))}}
+	// filter by object UID
+	opts := []client.ListOption{client.HasLabels{fmt.Sprintf("%s%s", s.LabelPrefix, obj.GetUID())}}
 
 	// Support namespace scope or cluster scoped
 	if s.Namespace != "" {
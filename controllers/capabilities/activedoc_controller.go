@@ -73,6 +73,15 @@ func (r *ActiveDocReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the validation of the spec
+	paused, err := r.ReconcilePausedCondition(activeDocCR, &activeDocCR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewActiveDocStatusReconciler(r.BaseReconciler, activeDocCR, activeDocCR.Status.ProviderAccountHost, nil, r.validateSpec(activeDocCR)).Reconcile()
+	}
+
 	// Ignore deleted resource, this can happen when foregroundDeletion is enabled
 	// https://kubernetes.io/docs/concepts/workloads/controllers/garbage-collection/#foreground-cascading-deletion
 	if activeDocCR.DeletionTimestamp != nil {
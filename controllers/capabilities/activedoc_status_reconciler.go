@@ -47,7 +47,7 @@ func (s *ActiveDocStatusReconciler) Reconcile() (reconcile.Result, error) {
 	equalStatus := s.resource.Status.Equals(newStatus, s.logger)
 	s.logger.V(1).Info("Status", "status is different", !equalStatus)
 	s.logger.V(1).Info("Status", "generation is different", s.resource.Generation != s.resource.Status.ObservedGeneration)
-	if equalStatus && s.resource.Generation == s.resource.Status.ObservedGeneration {
+	if equalStatus && (s.resource.Generation == s.resource.Status.ObservedGeneration || common.IsPaused(s.resource)) {
 		// Steady state
 		s.logger.V(1).Info("Status steady state, status was not updated")
 		return reconcile.Result{}, nil
@@ -57,7 +57,9 @@ func (s *ActiveDocStatusReconciler) Reconcile() (reconcile.Result, error) {
 	// that we've seen a spec update when we retry.
 	// TODO: This can clobber an update if we allow multiple agents to write to the
 	// same status.
-	newStatus.ObservedGeneration = s.resource.Generation
+	if !common.IsPaused(s.resource) {
+		newStatus.ObservedGeneration = s.resource.Generation
+	}
 
 	s.logger.V(1).Info("Updating Status", "sequence no:", fmt.Sprintf("sequence No: %v->%v", s.resource.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
@@ -76,6 +78,14 @@ func (s *ActiveDocStatusReconciler) Reconcile() (reconcile.Result, error) {
 }
 
 func (s *ActiveDocStatusReconciler) calculateStatus() (*capabilitiesv1beta1.ActiveDocStatus, error) {
+	// Paused resources are not synchronized with 3scale. The status of the
+	// last synchronization is kept, only the validation of the spec is updated
+	if common.IsPaused(s.resource) {
+		newStatus := s.resource.Status.DeepCopy()
+		newStatus.Conditions.SetCondition(s.invalidCondition())
+		return newStatus, nil
+	}
+
 	newStatus := &capabilitiesv1beta1.ActiveDocStatus{}
 
 	if s.activeDoc != nil {
@@ -72,6 +72,15 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 		reqLogger.V(1).Info(string(jsonData))
 	}
+
+	// Paused resources report the Paused condition and keep the status of the last synchronization
+	paused, err := r.ReconcilePausedCondition(application, &application.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewApplicationStatusReconciler(r.BaseReconciler, application, nil, application.Status.ProviderAccountHost, nil).Reconcile()
+	}
 	// get Account
 	accountResource := &capabilitiesv1beta1.DeveloperAccount{}
 	projectMeta := types.NamespacedName{
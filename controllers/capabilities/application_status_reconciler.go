@@ -40,7 +40,7 @@ func (s *ApplicationStatusReconciler) Reconcile() (reconcile.Result, error) {
 	equalStatus := s.applicationResource.Status.Equals(newStatus, s.logger)
 	s.logger.V(1).Info("Status", "status is different", !equalStatus)
 	s.logger.V(1).Info("Status", "generation is different", s.applicationResource.Generation != s.applicationResource.Status.ObservedGeneration)
-	if equalStatus && s.applicationResource.Generation == s.applicationResource.Status.ObservedGeneration {
+	if equalStatus && (s.applicationResource.Generation == s.applicationResource.Status.ObservedGeneration || common.IsPaused(s.applicationResource)) {
 		// Steady state
 		s.logger.V(1).Info("Status was not updated")
 		return reconcile.Result{}, nil
@@ -50,7 +50,9 @@ func (s *ApplicationStatusReconciler) Reconcile() (reconcile.Result, error) {
 	// that we've seen a spec update when we retry.
 	// TODO: This can clobber an update if we allow multiple agents to write to the
 	// same status.
-	newStatus.ObservedGeneration = s.applicationResource.Generation
+	if !common.IsPaused(s.applicationResource) {
+		newStatus.ObservedGeneration = s.applicationResource.Generation
+	}
 
 	s.logger.V(1).Info("Updating Status", "sequence no:", fmt.Sprintf("sequence No: %v->%v", s.applicationResource.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
@@ -69,6 +71,12 @@ func (s *ApplicationStatusReconciler) Reconcile() (reconcile.Result, error) {
 }
 
 func (s *ApplicationStatusReconciler) calculateStatus() *capabilitiesv1beta1.ApplicationStatus {
+	// Paused resources are not synchronized with 3scale. The status of the
+	// last synchronization is kept
+	if common.IsPaused(s.applicationResource) {
+		return s.applicationResource.Status.DeepCopy()
+	}
+
 	newStatus := &capabilitiesv1beta1.ApplicationStatus{}
 	if s.entity != nil {
 		tmpID := s.entity.ID()
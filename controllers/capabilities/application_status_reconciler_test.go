@@ -459,3 +459,28 @@ func TestApplicationStatusReconciler_calculateStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestApplicationStatusReconciler_paused(t *testing.T) {
+	applicationCR := getApplicationCR()
+	applicationCR.Annotations = map[string]string{common.PausedAnnotation: "true"}
+	applicationCR.Generation = 2
+	applicationCR.Status.ObservedGeneration = 1
+	applicationCR.Status.ProviderAccountHost = "https://3scale-admin.example.com"
+	applicationCR.Status.Conditions = common.Conditions{
+		{Type: capabilitiesv1beta1.ApplicationReadyConditionType, Status: corev1.ConditionFalse, Message: "last sync error"},
+	}
+	expected := applicationCR.Status.DeepCopy()
+
+	statusReconciler := NewApplicationStatusReconciler(getBaseReconciler(applicationCR), applicationCR, nil, applicationCR.Status.ProviderAccountHost, nil)
+	result, err := statusReconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (reconcile.Result{}) {
+		t.Fatalf("unexpected result %v", result)
+	}
+	// The status of the last synchronization is kept, the generation is not observed
+	if !reflect.DeepEqual(applicationCR.Status, *expected) {
+		t.Fatalf("expected paused application status not to change, got %v", applicationCR.Status)
+	}
+}
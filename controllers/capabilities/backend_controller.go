@@ -82,6 +82,15 @@ func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the validation of the spec
+	paused, err := r.ReconcilePausedCondition(backend, &backend.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewBackendStatusReconciler(r.BaseReconciler, backend, nil, backend.Status.ProviderAccountHost, r.validateSpec(backend)).Reconcile()
+	}
+
 	// Ignore deleted Backends, this can happen when foregroundDeletion is enabled
 	// https://kubernetes.io/docs/concepts/workloads/controllers/garbage-collection/#foreground-cascading-deletion
 	if backend.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(backend, backendFinalizer) {
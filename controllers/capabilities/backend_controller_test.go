@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	capabilitiesv1beta1 "github.com/3scale/3scale-operator/apis/capabilities/v1beta1"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestBackendReconciler_paused(t *testing.T) {
+	backend := &capabilitiesv1beta1.Backend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "backend",
+			Namespace:   "test",
+			Annotations: map[string]string{common.PausedAnnotation: "true"},
+		},
+		Spec: capabilitiesv1beta1.BackendSpec{
+			Name:           "backend",
+			PrivateBaseURL: "https://api.example.com",
+		},
+	}
+	r := &BackendReconciler{BaseReconciler: getBaseReconciler(backend)}
+	request := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: backend.Name, Namespace: backend.Namespace}}
+
+	getBackend := func() *capabilitiesv1beta1.Backend {
+		existing := &capabilitiesv1beta1.Backend{}
+		if err := r.Client().Get(context.TODO(), request.NamespacedName, existing); err != nil {
+			t.Fatal(err)
+		}
+		return existing
+	}
+
+	// Paused backends report the Paused condition and are not mutated
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal(err)
+	}
+	existing := getBackend()
+	if !existing.Status.Conditions.IsTrueFor(common.PausedConditionType) {
+		t.Fatalf("expected Paused condition, got %v", existing.Status.Conditions)
+	}
+	if controllerutil.ContainsFinalizer(existing, backendFinalizer) {
+		t.Fatal("expected paused backend not to be mutated")
+	}
+	// The spec has no metrics, the validation keeps being reported while paused
+	if !existing.Status.Conditions.IsTrueFor(capabilitiesv1beta1.BackendInvalidConditionType) {
+		t.Fatalf("expected Invalid condition, got %v", existing.Status.Conditions)
+	}
+	if existing.Status.ObservedGeneration != 0 {
+		t.Fatalf("expected paused backend generation not to be observed, got %d", existing.Status.ObservedGeneration)
+	}
+
+	// Removing the annotation resumes the reconciliation
+	existing.Annotations = nil
+	if err := r.Client().Update(context.TODO(), existing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal(err)
+	}
+	existing = getBackend()
+	if existing.Status.Conditions.GetCondition(common.PausedConditionType) != nil {
+		t.Fatalf("expected Paused condition to be removed, got %v", existing.Status.Conditions)
+	}
+	if !controllerutil.ContainsFinalizer(existing, backendFinalizer) {
+		t.Fatal("expected resumed backend to be reconciled")
+	}
+}
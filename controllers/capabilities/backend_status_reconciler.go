@@ -43,7 +43,7 @@ func (s *BackendStatusReconciler) Reconcile() (reconcile.Result, error) {
 	equalStatus := s.backendResource.Status.Equals(newStatus, s.logger)
 	s.logger.V(1).Info("Status", "status is different", !equalStatus)
 	s.logger.V(1).Info("Status", "generation is different", s.backendResource.Generation != s.backendResource.Status.ObservedGeneration)
-	if equalStatus && s.backendResource.Generation == s.backendResource.Status.ObservedGeneration {
+	if equalStatus && (s.backendResource.Generation == s.backendResource.Status.ObservedGeneration || common.IsPaused(s.backendResource)) {
 		// Steady state
 		s.logger.V(1).Info("Status was not updated")
 		return reconcile.Result{}, nil
@@ -53,7 +53,9 @@ func (s *BackendStatusReconciler) Reconcile() (reconcile.Result, error) {
 	// that we've seen a spec update when we retry.
 	// TODO: This can clobber an update if we allow multiple agents to write to the
 	// same status.
-	newStatus.ObservedGeneration = s.backendResource.Generation
+	if !common.IsPaused(s.backendResource) {
+		newStatus.ObservedGeneration = s.backendResource.Generation
+	}
 
 	s.logger.V(1).Info("Updating Status", "sequence no:", fmt.Sprintf("sequence No: %v->%v", s.backendResource.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
@@ -72,6 +74,14 @@ func (s *BackendStatusReconciler) Reconcile() (reconcile.Result, error) {
 }
 
 func (s *BackendStatusReconciler) calculateStatus() *capabilitiesv1beta1.BackendStatus {
+	// Paused resources are not synchronized with 3scale. The status of the
+	// last synchronization is kept, only the validation of the spec is updated
+	if common.IsPaused(s.backendResource) {
+		newStatus := s.backendResource.Status.DeepCopy()
+		newStatus.Conditions.SetCondition(s.invalidCondition())
+		return newStatus
+	}
+
 	newStatus := &capabilitiesv1beta1.BackendStatus{}
 	if s.backendAPIEntity != nil {
 		tmp := s.backendAPIEntity.ID()
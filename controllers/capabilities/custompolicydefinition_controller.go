@@ -72,6 +72,15 @@ func (r *CustomPolicyDefinitionReconciler) Reconcile(ctx context.Context, req ct
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and keep the status of the last synchronization
+	paused, err := r.ReconcilePausedCondition(customPolicyDefinitionCR, &customPolicyDefinitionCR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewCustomPolicyDefinitionStatusReconciler(r.BaseReconciler, customPolicyDefinitionCR, customPolicyDefinitionCR.Status.ProviderAccountHost, nil, nil).Reconcile()
+	}
+
 	// Ignore deleted resource, this can happen when foregroundDeletion is enabled
 	// https://kubernetes.io/docs/concepts/workloads/controllers/garbage-collection/#foreground-cascading-deletion
 	if customPolicyDefinitionCR.DeletionTimestamp != nil {
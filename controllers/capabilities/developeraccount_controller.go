@@ -81,6 +81,15 @@ func (r *DeveloperAccountReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the validation of the spec
+	paused, err := r.ReconcilePausedCondition(developerAccountCR, &developerAccountCR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewDeveloperAccountStatusReconciler(r.BaseReconciler, developerAccountCR, developerAccountCR.Status.ProviderAccountHost, nil, r.validateSpec(developerAccountCR)).Reconcile()
+	}
+
 	// DeveloperAccount has been marked for deletion
 	if developerAccountCR.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(developerAccountCR, developerAccountFinalizer) {
 		err = r.removeDeveloperAccountFrom3scale(developerAccountCR)
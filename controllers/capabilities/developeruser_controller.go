@@ -82,6 +82,15 @@ func (r *DeveloperUserReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the validation of the spec
+	paused, err := r.ReconcilePausedCondition(developerUserCR, &developerUserCR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewDeveloperUserStatusReconciler(r.BaseReconciler, developerUserCR, nil, developerUserCR.Status.ProviderAccountHost, nil, r.validateSpec(developerUserCR)).Reconcile()
+	}
+
 	// DeveloperUser has been marked for deletion
 	if developerUserCR.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(developerUserCR, developerUserFinalizer) {
 		err = r.removeDeveloperUserFrom3scale(developerUserCR)
@@ -54,7 +54,7 @@ func (s *DeveloperUserStatusReconciler) Reconcile() (reconcile.Result, error) {
 	equalStatus := s.userCR.Status.Equals(newStatus, s.logger)
 	s.logger.V(1).Info("Status", "status is different", !equalStatus)
 	s.logger.V(1).Info("Status", "generation is different", s.userCR.Generation != s.userCR.Status.ObservedGeneration)
-	if equalStatus && s.userCR.Generation == s.userCR.Status.ObservedGeneration {
+	if equalStatus && (s.userCR.Generation == s.userCR.Status.ObservedGeneration || common.IsPaused(s.userCR)) {
 		// Steady state
 		s.logger.V(1).Info("Status steady state, status was not updated")
 		return reconcile.Result{}, nil
@@ -64,7 +64,9 @@ func (s *DeveloperUserStatusReconciler) Reconcile() (reconcile.Result, error) {
 	// that we've seen a spec update when we retry.
 	// TODO: This can clobber an update if we allow multiple agents to write to the
 	// same status.
-	newStatus.ObservedGeneration = s.userCR.Generation
+	if !common.IsPaused(s.userCR) {
+		newStatus.ObservedGeneration = s.userCR.Generation
+	}
 
 	s.logger.V(1).Info("Updating Status", "sequence no:", fmt.Sprintf("sequence No: %v->%v", s.userCR.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
@@ -83,6 +85,14 @@ func (s *DeveloperUserStatusReconciler) Reconcile() (reconcile.Result, error) {
 }
 
 func (s *DeveloperUserStatusReconciler) calculateStatus() (*capabilitiesv1beta1.DeveloperUserStatus, error) {
+	// Paused resources are not synchronized with 3scale. The status of the
+	// last synchronization is kept, only the validation of the spec is updated
+	if common.IsPaused(s.userCR) {
+		newStatus := s.userCR.Status.DeepCopy()
+		newStatus.Conditions.SetCondition(s.invalidCondition())
+		return newStatus, nil
+	}
+
 	// If there is an error and s.remoteDeveloperUser is nil, do not change status fields read from it
 	// Initialize with existing data for data coming from 3scale
 	// just in case in this reconciliation loop something goes wrong and avoid replacing right data with nil
@@ -77,6 +77,15 @@ func (r *OpenAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the validation of the spec
+	paused, err := r.ReconcilePausedCondition(openapiCR, &openapiCR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewOpenAPIStatusReconciler(r.BaseReconciler, openapiCR, openapiCR.Status.ProviderAccountHost, r.validateSpec(openapiCR), false).Reconcile()
+	}
+
 	// Ignore deleted OpenAPI, this can happen when foregroundDeletion is enabled
 	// https://kubernetes.io/docs/concepts/workloads/controllers/garbage-collection/#foreground-cascading-deletion
 	if openapiCR.DeletionTimestamp != nil {
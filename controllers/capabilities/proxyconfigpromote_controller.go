@@ -64,6 +64,15 @@ func (r *ProxyConfigPromoteReconciler) Reconcile(ctx context.Context, req ctrl.R
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and keep the status of the last promotion
+	paused, err := r.ReconcilePausedCondition(proxyConfigPromote, &proxyConfigPromote.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewProxyConfigPromoteStatusReconciler(r.BaseReconciler, proxyConfigPromote, proxyConfigPromote.Status.ProductId, proxyConfigPromote.Status.LatestProductionVersion, proxyConfigPromote.Status.LatestStagingVersion, nil).Reconcile()
+	}
+
 	product := &capabilitiesv1beta1.Product{}
 
 	// Retrieve product CR, on failed retrieval update status and requeue
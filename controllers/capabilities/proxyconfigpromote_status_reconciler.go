@@ -65,6 +65,12 @@ func (s *ProxyConfigPromoteStatusReconciler) Reconcile() (reconcile.Result, erro
 }
 
 func (s *ProxyConfigPromoteStatusReconciler) calculateStatus() (*capabilitiesv1beta1.ProxyConfigPromoteStatus, error) {
+	// Paused resources are not synchronized with 3scale. The status of the
+	// last synchronization is kept
+	if common.IsPaused(s.resource) {
+		return s.resource.Status.DeepCopy(), nil
+	}
+
 	newStatus := &capabilitiesv1beta1.ProxyConfigPromoteStatus{}
 
 	newStatus.ProductId = s.productID
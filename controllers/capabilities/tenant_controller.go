@@ -95,6 +95,15 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		reqLogger.V(1).Info(string(jsonData))
 	}
 
+	// Paused resources report the Paused condition and the tenant ID of the annotation
+	paused, err := r.ReconcilePausedCondition(tenantR, &tenantR.Status.Conditions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		return NewTenantInternalReconciler(r.BaseReconciler, tenantR, nil, reqLogger).ReconcilePausedStatus()
+	}
+
 	masterAccessToken, err := r.fetchMasterCredentials(tenantR)
 	if err != nil {
 		reqLogger.Error(err, "Error fetching master credentials secret")
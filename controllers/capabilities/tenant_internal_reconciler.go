@@ -66,6 +66,24 @@ func (r *TenantInternalReconciler) Run() (ctrl.Result, error) {
 	return ctrl.Result{}, err
 }
 
+// ReconcilePausedStatus reports the tenant ID of the annotation when the status has none,
+// for instance on a Tenant restored from a backup. 3scale is not queried while paused
+func (r *TenantInternalReconciler) ReconcilePausedStatus() (ctrl.Result, error) {
+	tenantID, err := r.retrieveTenantID()
+	if err != nil {
+		return ctrl.Result{}, errors.New("failed to convert tenantID annotation to int64")
+	}
+
+	newStatus := &apiv1alpha1.TenantStatus{
+		AdminId:    r.tenantR.Status.AdminId,
+		TenantId:   tenantID,
+		Conditions: r.tenantR.Status.Conditions,
+	}
+
+	_, err = r.reconcileStatus(newStatus)
+	return ctrl.Result{}, err
+}
+
 // This method makes sure that tenant exists, otherwise it will create one
 // On method completion:
 // * tenant will exist
@@ -98,8 +116,9 @@ func (r *TenantInternalReconciler) reconcileTenant() (ctrl.Result, error) {
 		// Early update status with the new tenantID
 		newStatus := &apiv1alpha1.TenantStatus{
 			// reset adminID. It could keep old stale value
-			AdminId:  0,
-			TenantId: tenantDef.Signup.Account.ID,
+			AdminId:    0,
+			TenantId:   tenantDef.Signup.Account.ID,
+			Conditions: r.tenantR.Status.Conditions,
 		}
 
 		updated, err := r.reconcileStatus(newStatus)
@@ -155,8 +174,9 @@ func (r *TenantInternalReconciler) reconcileAdminUser() (ctrl.Result, error) {
 	}
 
 	newStatus := &apiv1alpha1.TenantStatus{
-		AdminId:  *adminUser.Element.ID,
-		TenantId: tenantID,
+		AdminId:    *adminUser.Element.ID,
+		TenantId:   tenantID,
+		Conditions: r.tenantR.Status.Conditions,
 	}
 
 	updated, err := r.reconcileStatus(newStatus)
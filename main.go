@@ -32,7 +32,6 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/prometheus/client_golang/prometheus"
-	apimachinerymetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
@@ -48,6 +47,7 @@ import (
 	appscontroller "github.com/3scale/3scale-operator/controllers/apps"
 	capabilitiescontroller "github.com/3scale/3scale-operator/controllers/capabilities"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 	"github.com/3scale/3scale-operator/version"
 	// +kubebuilder:scaffold:imports
@@ -77,6 +77,7 @@ func init() {
 	utilruntime.Must(monitoringv1.AddToScheme(scheme))
 	utilruntime.Must(grafanav1alpha1.AddToScheme(scheme))
 	utilruntime.Must(configv1.AddToScheme(scheme))
+	utilruntime.Must(kedav1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -120,17 +121,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	secretLabelSelector, err := apimachinerymetav1.ParseToLabelSelector("apimanager.apps.3scale.net/watched-by=apimanager")
-	if err != nil {
-		setupLog.Error(err, "unable parse apimanager secrets label")
-		os.Exit(1)
-	}
-
-	if secretLabelSelector == nil {
-		setupLog.Info("secretLabelSelector is empty")
-		os.Exit(1)
-	}
-
 	discoveryClientAPIManager, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
 	if err != nil {
 		setupLog.Error(err, "unable to create discovery client")
@@ -142,8 +132,7 @@ func main() {
 			ctrl.Log.WithName("controllers").WithName("APIManager"),
 			discoveryClientAPIManager,
 			mgr.GetEventRecorderFor("APIManager")),
-		SecretLabelSelector: *secretLabelSelector,
-		WatchedNamespace:    namespace,
+		WatchedNamespace: namespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "APIManager")
 		os.Exit(1)
@@ -165,6 +154,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	discoveryClientAPIManagerBackupSchedule, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	if err = (&appscontroller.APIManagerBackupScheduleReconciler{
+		BaseReconciler: reconcilers.NewBaseReconciler(
+			context.Background(), mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
+			ctrl.Log.WithName("controllers").WithName("APIManagerBackupSchedule"),
+			discoveryClientAPIManagerBackupSchedule,
+			mgr.GetEventRecorderFor("APIManagerBackupSchedule")),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "APIManagerBackupSchedule")
+		os.Exit(1)
+	}
+
 	discoveryClientAPIManagerRestore, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
 	if err != nil {
 		setupLog.Error(err, "unable to create discovery client")
@@ -11,7 +11,9 @@ import (
 	"github.com/3scale/3scale-operator/pkg/helper"
 
 	appsv1 "github.com/openshift/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -84,8 +86,30 @@ func (apicast *Apicast) ProductionService() *v1.Service {
 	}
 }
 
+func (apicast *Apicast) StagingIngress() *networkingv1.Ingress {
+	return ingress(
+		ApicastStagingName,
+		fmt.Sprintf("api-%s-apicast-staging.%s", apicast.Options.TenantName, apicast.Options.WildcardDomain),
+		ApicastStagingName,
+		"gateway",
+		apicast.Options.CommonStagingLabels,
+		apicast.Options.IngressOptions,
+	)
+}
+
+func (apicast *Apicast) ProductionIngress() *networkingv1.Ingress {
+	return ingress(
+		ApicastProductionName,
+		fmt.Sprintf("api-%s-apicast-production.%s", apicast.Options.TenantName, apicast.Options.WildcardDomain),
+		ApicastProductionName,
+		"gateway",
+		apicast.Options.CommonProductionLabels,
+		apicast.Options.IngressOptions,
+	)
+}
+
 func (apicast *Apicast) StagingDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        ApicastStagingName,
@@ -168,18 +192,24 @@ func (apicast *Apicast) StagingDeploymentConfig() *appsv1.DeploymentConfig {
 								TimeoutSeconds:      5,
 								PeriodSeconds:       30,
 							},
+							SecurityContext: apicast.Options.StagingContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         apicast.Options.PriorityClassNameStaging,
 					TopologySpreadConstraints: apicast.Options.TopologySpreadConstraintsStaging,
+					SecurityContext:           apicast.Options.StagingPodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, apicast.Options.StagingProbes)
+
+	return applyPodTemplateOverrides(dc, apicast.Options.StagingPodTemplateOverrides)
 }
 
 func (apicast *Apicast) ProductionDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        ApicastProductionName,
@@ -247,6 +277,7 @@ func (apicast *Apicast) ProductionDeploymentConfig() *appsv1.DeploymentConfig {
 									Value: "1",
 								},
 							},
+							SecurityContext: apicast.Options.ProductionContainerSecurityContext,
 						},
 					},
 					Containers: []v1.Container{
@@ -276,14 +307,20 @@ func (apicast *Apicast) ProductionDeploymentConfig() *appsv1.DeploymentConfig {
 								TimeoutSeconds:      5,
 								PeriodSeconds:       30,
 							},
+							SecurityContext: apicast.Options.ProductionContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         apicast.Options.PriorityClassNameProduction,
 					TopologySpreadConstraints: apicast.Options.TopologySpreadConstraintsProduction,
+					SecurityContext:           apicast.Options.ProductionPodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, apicast.Options.ProductionProbes)
+
+	return applyPodTemplateOverrides(dc, apicast.Options.ProductionPodTemplateOverrides)
 }
 
 func (apicast *Apicast) buildApicastCommonEnv() []v1.EnvVar {
@@ -507,6 +544,10 @@ func (apicast *Apicast) ProductionPodDisruptionBudget() *policyv1.PodDisruptionB
 	}
 }
 
+func (apicast *Apicast) ProductionHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return horizontalPodAutoscaler(ApicastProductionName, apicast.Options.CommonProductionLabels, apicast.Options.ProductionHPA)
+}
+
 func (apicast *Apicast) productionVolumeMounts() []v1.VolumeMount {
 	var volumeMounts []v1.VolumeMount
 
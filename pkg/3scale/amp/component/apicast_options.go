@@ -84,6 +84,16 @@ type ApicastOptions struct {
 	TopologySpreadConstraintsProduction []v1.TopologySpreadConstraint `validate:"-"`
 	StagingPodTemplateAnnotations       map[string]string             `validate:"-"`
 	ProductionPodTemplateAnnotations    map[string]string             `validate:"-"`
+	StagingPodTemplateOverrides         *PodTemplateOverrides         `validate:"-"`
+	ProductionPodTemplateOverrides      *PodTemplateOverrides         `validate:"-"`
+
+	StagingPodSecurityContext          *v1.PodSecurityContext `validate:"-"`
+	StagingContainerSecurityContext    *v1.SecurityContext    `validate:"-"`
+	ProductionPodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ProductionContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	StagingProbes    []ContainerProbes `validate:"-"`
+	ProductionProbes []ContainerProbes `validate:"-"`
 
 	// Used for monitoring objects
 	// Those objects are namespaced. However, objects includes labels, rules and expressions
@@ -100,6 +110,13 @@ type ApicastOptions struct {
 	ProductionTracingConfig *APIcastTracingConfig `validate:"required"`
 	StagingTracingConfig    *APIcastTracingConfig `validate:"required"`
 
+	// Used for the default tenant Ingress hosts
+	TenantName     string          `validate:"required"`
+	WildcardDomain string          `validate:"required"`
+	IngressOptions *IngressOptions `validate:"-"`
+
+	ProductionHPA *HorizontalPodAutoscalerOptions `validate:"-"`
+
 	ProductionCustomEnvironments []*v1.Secret `validate:"-"`
 	StagingCustomEnvironments    []*v1.Secret `validate:"-"`
 
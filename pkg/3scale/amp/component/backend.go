@@ -2,10 +2,14 @@ package component
 
 import (
 	"fmt"
+	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/helper"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 	appsv1 "github.com/openshift/api/apps/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -28,6 +32,15 @@ const (
 	BackendSecretBackendRedisQueuesSentinelRoleFieldName   = "REDIS_QUEUES_SENTINEL_ROLE"
 )
 
+const (
+	// BackendWorkerRedisQueuesSecretName is the secret generated with the
+	// credentials of the REDIS_QUEUES_URL of the backend-redis secret, read by
+	// the backend-worker queue scaler
+	BackendWorkerRedisQueuesSecretName              = "backend-worker-redis-queues"
+	BackendWorkerRedisQueuesSecretUsernameFieldName = "username"
+	BackendWorkerRedisQueuesSecretPasswordFieldName = "password"
+)
+
 const (
 	BackendSecretInternalApiSecretName        = "backend-internal-api"
 	BackendSecretInternalApiUsernameFieldName = "username"
@@ -45,6 +58,9 @@ const (
 	BackendListenerMetricsPort = 9394
 )
 
+// Resque queues processed by the backend worker
+var backendWorkerQueues = []string{"priority", "main", "stats"}
+
 var (
 	BackendWorkerMetricsPortStr   = strconv.FormatInt(BackendWorkerMetricsPort, 10)
 	BackendListenerMetricsPortStr = strconv.FormatInt(BackendListenerMetricsPort, 10)
@@ -59,7 +75,7 @@ func NewBackend(options *BackendOptions) *Backend {
 }
 
 func (backend *Backend) WorkerDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -116,6 +132,7 @@ func (backend *Backend) WorkerDeploymentConfig() *appsv1.DeploymentConfig {
 								"-c",
 								"until rake connectivity:redis_storage_queue_check; do sleep $SLEEP_SECONDS; done",
 							}, Env: append(backend.buildBackendCommonEnv(), helper.EnvVarFromValue("SLEEP_SECONDS", "1")),
+							SecurityContext: backend.Options.WorkerContainerSecurityContext,
 						},
 					},
 					Containers: []v1.Container{
@@ -141,17 +158,24 @@ func (backend *Backend) WorkerDeploymentConfig() *appsv1.DeploymentConfig {
 								SuccessThreshold:    1,
 								TimeoutSeconds:      60,
 							},
+							SecurityContext: backend.Options.WorkerContainerSecurityContext,
 						},
 					},
 					ServiceAccountName:        "amp",
 					PriorityClassName:         backend.Options.PriorityClassNameWorker,
+					SecurityContext:           backend.Options.WorkerPodSecurityContext,
 					TopologySpreadConstraints: backend.Options.TopologySpreadConstraintsWorker}},
 		},
 	}
+
+	addTLSVolume(&dc.Spec.Template.Spec, backend.redisTLSVolume(), BackendRedisTLSMountPath)
+	applyProbesOverrides(&dc.Spec.Template.Spec, backend.Options.WorkerProbes)
+
+	return applyPodTemplateOverrides(dc, backend.Options.WorkerPodTemplateOverrides)
 }
 
 func (backend *Backend) CronDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -208,6 +232,7 @@ func (backend *Backend) CronDeploymentConfig() *appsv1.DeploymentConfig {
 								"-c",
 								"until rake connectivity:redis_storage_queue_check; do sleep $SLEEP_SECONDS; done",
 							}, Env: append(backend.buildBackendCommonEnv(), helper.EnvVarFromValue("SLEEP_SECONDS", "1")),
+							SecurityContext: backend.Options.CronContainerSecurityContext,
 						},
 					},
 					Containers: []v1.Container{
@@ -227,18 +252,25 @@ func (backend *Backend) CronDeploymentConfig() *appsv1.DeploymentConfig {
 								InitialDelaySeconds: 30,
 								PeriodSeconds:       5,
 							},
+							SecurityContext: backend.Options.CronContainerSecurityContext,
 						},
 					},
 					ServiceAccountName:        "amp",
 					PriorityClassName:         backend.Options.PriorityClassNameCron,
 					TopologySpreadConstraints: backend.Options.TopologySpreadConstraintsCron,
+					SecurityContext:           backend.Options.CronPodSecurityContext,
 				}},
 		},
 	}
+
+	addTLSVolume(&dc.Spec.Template.Spec, backend.redisTLSVolume(), BackendRedisTLSMountPath)
+	applyProbesOverrides(&dc.Spec.Template.Spec, backend.Options.CronProbes)
+
+	return applyPodTemplateOverrides(dc, backend.Options.CronPodTemplateOverrides)
 }
 
 func (backend *Backend) ListenerDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -319,14 +351,21 @@ func (backend *Backend) ListenerDeploymentConfig() *appsv1.DeploymentConfig {
 								FailureThreshold:    0,
 							},
 							ImagePullPolicy: v1.PullIfNotPresent,
+							SecurityContext: backend.Options.ListenerContainerSecurityContext,
 						},
 					},
 					ServiceAccountName:        "amp",
 					PriorityClassName:         backend.Options.PriorityClassNameListener,
 					TopologySpreadConstraints: backend.Options.TopologySpreadConstraintsListener,
+					SecurityContext:           backend.Options.ListenerPodSecurityContext,
 				}},
 		},
 	}
+
+	addTLSVolume(&dc.Spec.Template.Spec, backend.redisTLSVolume(), BackendRedisTLSMountPath)
+	applyProbesOverrides(&dc.Spec.Template.Spec, backend.Options.ListenerProbes)
+
+	return applyPodTemplateOverrides(dc, backend.Options.ListenerPodTemplateOverrides)
 }
 
 func (backend *Backend) ListenerService() *v1.Service {
@@ -382,6 +421,17 @@ func (backend *Backend) ListenerRoute() *routev1.Route {
 	}
 }
 
+func (backend *Backend) ListenerIngress() *networkingv1.Ingress {
+	return ingress(
+		"backend",
+		"backend-"+backend.Options.TenantName+"."+backend.Options.WildcardDomain,
+		BackendListenerName,
+		"http",
+		backend.Options.CommonLabels,
+		backend.Options.IngressOptions,
+	)
+}
+
 func (backend *Backend) EnvironmentConfigMap() *v1.ConfigMap {
 	return &v1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
@@ -399,7 +449,7 @@ func (backend *Backend) EnvironmentConfigMap() *v1.ConfigMap {
 }
 
 func (backend *Backend) buildBackendCommonEnv() []v1.EnvVar {
-	return []v1.EnvVar{
+	result := []v1.EnvVar{
 		helper.EnvVarFromSecret("CONFIG_REDIS_PROXY", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageURLFieldName),
 		helper.EnvVarFromSecret("CONFIG_REDIS_SENTINEL_HOSTS", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageSentinelHostsFieldName),
 		helper.EnvVarFromSecret("CONFIG_REDIS_SENTINEL_ROLE", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageSentinelRoleFieldName),
@@ -408,6 +458,13 @@ func (backend *Backend) buildBackendCommonEnv() []v1.EnvVar {
 		helper.EnvVarFromSecret("CONFIG_QUEUES_SENTINEL_ROLE", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisQueuesSentinelRoleFieldName),
 		helper.EnvVarFromConfigMap("RACK_ENV", "backend-environment", "RACK_ENV"),
 	}
+	result = append(result, redisTLSEnvVars(backend.Options.StorageRedisTLS, backendStorageRedisTLSEnvVarNames, BackendRedisTLSMountPath)...)
+	result = append(result, redisTLSEnvVars(backend.Options.QueuesRedisTLS, backendQueuesRedisTLSEnvVarNames, BackendRedisTLSMountPath)...)
+	return result
+}
+
+func (backend *Backend) redisTLSVolume() *v1.Volume {
+	return redisTLSVolume(BackendRedisTLSVolumeName, backend.Options.StorageRedisTLS, backend.Options.QueuesRedisTLS)
 }
 
 func (backend *Backend) buildBackendWorkerEnv() []v1.EnvVar {
@@ -545,6 +602,112 @@ func (backend *Backend) ListenerPodDisruptionBudget() *policyv1.PodDisruptionBud
 	}
 }
 
+func (backend *Backend) ListenerHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return horizontalPodAutoscaler(BackendListenerName, backend.Options.CommonListenerLabels, backend.Options.ListenerHPA)
+}
+
+func (backend *Backend) WorkerHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return horizontalPodAutoscaler(BackendWorkerName, backend.Options.CommonWorkerLabels, backend.Options.WorkerHPA)
+}
+
+func (backend *Backend) WorkerScaledObject() *kedav1alpha1.ScaledObject {
+	return scaledObject(BackendWorkerName, backend.Options.CommonWorkerLabels, backend.Options.WorkerScaledObject, backend.workerScaleTriggers())
+}
+
+// WorkerRedisQueuesSecret holds the credentials of the queues Redis database for
+// the backend-worker queue scaler. KEDA reads them from secret keys, so they are
+// copied from the user info of the queues URL
+func (backend *Backend) WorkerRedisQueuesSecret() *v1.Secret {
+	data := map[string][]byte{
+		BackendWorkerRedisQueuesSecretPasswordFieldName: []byte(backend.Options.WorkerRedisQueues.Password),
+	}
+	if backend.Options.WorkerRedisQueues.Username != "" {
+		data[BackendWorkerRedisQueuesSecretUsernameFieldName] = []byte(backend.Options.WorkerRedisQueues.Username)
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   BackendWorkerRedisQueuesSecretName,
+			Labels: backend.Options.CommonWorkerLabels,
+		},
+		Data: data,
+		Type: v1.SecretTypeOpaque,
+	}
+
+	if !backend.workerRedisQueuesAuthenticated() {
+		common.TagObjectToDelete(secret)
+	}
+
+	return secret
+}
+
+func (backend *Backend) WorkerTriggerAuthentication() *kedav1alpha1.TriggerAuthentication {
+	secretTargetRefs := []kedav1alpha1.AuthSecretTargetRef{
+		{
+			Parameter: "password",
+			Name:      BackendWorkerRedisQueuesSecretName,
+			Key:       BackendWorkerRedisQueuesSecretPasswordFieldName,
+		},
+	}
+	if backend.Options.WorkerRedisQueues.Username != "" {
+		secretTargetRefs = append(secretTargetRefs, kedav1alpha1.AuthSecretTargetRef{
+			Parameter: "username",
+			Name:      BackendWorkerRedisQueuesSecretName,
+			Key:       BackendWorkerRedisQueuesSecretUsernameFieldName,
+		})
+	}
+
+	return triggerAuthentication(BackendWorkerName, backend.Options.CommonWorkerLabels, backend.workerRedisQueuesAuthenticated(), secretTargetRefs)
+}
+
+// workerRedisQueuesAuthenticated is true when the queue scaler is enabled and
+// the queues URL has a password
+func (backend *Backend) workerRedisQueuesAuthenticated() bool {
+	return backend.Options.WorkerScaledObject != nil && backend.Options.WorkerRedisQueues.Password != ""
+}
+
+func (backend *Backend) workerScaleTriggers() []kedav1alpha1.ScaleTriggers {
+	if backend.Options.WorkerScaledObject == nil {
+		return nil
+	}
+
+	var authenticationRef *kedav1alpha1.ScaledObjectAuthRef
+	if backend.workerRedisQueuesAuthenticated() {
+		authenticationRef = &kedav1alpha1.ScaledObjectAuthRef{Name: BackendWorkerName}
+	}
+
+	triggers := make([]kedav1alpha1.ScaleTriggers, 0, len(backendWorkerQueues))
+	for _, queue := range backendWorkerQueues {
+		trigger := kedav1alpha1.ScaleTriggers{
+			Type: "redis",
+			Name: fmt.Sprintf("queue-%s", queue),
+			Metadata: map[string]string{
+				"address":       backend.Options.WorkerRedisQueues.Address,
+				"databaseIndex": backend.Options.WorkerRedisQueues.DatabaseIndex,
+				"enableTLS":     strconv.FormatBool(backend.Options.WorkerRedisQueues.EnableTLS),
+				"listName":      fmt.Sprintf("resque:queue:%s", queue),
+				"listLength":    strconv.FormatInt(backend.Options.WorkerScaledObject.TargetQueueLength, 10),
+			},
+			AuthenticationRef: authenticationRef,
+		}
+
+		if backend.Options.WorkerRedisQueues.SentinelMaster != "" {
+			trigger.Type = "redis-sentinel"
+			delete(trigger.Metadata, "address")
+			trigger.Metadata["addresses"] = backend.Options.WorkerRedisQueues.Address
+			trigger.Metadata["sentinelMaster"] = backend.Options.WorkerRedisQueues.SentinelMaster
+		}
+
+		triggers = append(triggers, trigger)
+	}
+
+	return triggers
+}
+
 func (backend *Backend) listenerPorts() []v1.ContainerPort {
 	ports := []v1.ContainerPort{
 		v1.ContainerPort{HostPort: 0, ContainerPort: 3000, Protocol: v1.ProtocolTCP},
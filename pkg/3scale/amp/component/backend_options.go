@@ -35,6 +35,13 @@ type BackendOptions struct {
 	WorkerPodTemplateLabels      map[string]string `validate:"required"`
 	CronPodTemplateLabels        map[string]string `validate:"required"`
 	WorkerMetrics                bool
+	IngressOptions               *IngressOptions                 `validate:"-"`
+	ListenerHPA                  *HorizontalPodAutoscalerOptions `validate:"-"`
+	WorkerHPA                    *HorizontalPodAutoscalerOptions `validate:"-"`
+	WorkerScaledObject           *ScaledObjectOptions            `validate:"-"`
+	WorkerRedisQueues            BackendRedisQueuesOptions       `validate:"-"`
+	StorageRedisTLS              *RedisTLSOptions                `validate:"-"`
+	QueuesRedisTLS               *RedisTLSOptions                `validate:"-"`
 	ListenerMetrics              bool
 
 	PriorityClassNameListener string `validate:"-"`
@@ -49,6 +56,21 @@ type BackendOptions struct {
 	WorkerPodTemplateAnnotations   map[string]string `validate:"-"`
 	CronPodTemplateAnnotations     map[string]string `validate:"-"`
 
+	ListenerPodTemplateOverrides *PodTemplateOverrides `validate:"-"`
+	WorkerPodTemplateOverrides   *PodTemplateOverrides `validate:"-"`
+	CronPodTemplateOverrides     *PodTemplateOverrides `validate:"-"`
+
+	ListenerPodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ListenerContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+	WorkerPodSecurityContext         *v1.PodSecurityContext `validate:"-"`
+	WorkerContainerSecurityContext   *v1.SecurityContext    `validate:"-"`
+	CronPodSecurityContext           *v1.PodSecurityContext `validate:"-"`
+	CronContainerSecurityContext     *v1.SecurityContext    `validate:"-"`
+
+	ListenerProbes []ContainerProbes `validate:"-"`
+	WorkerProbes   []ContainerProbes `validate:"-"`
+	CronProbes     []ContainerProbes `validate:"-"`
+
 	// Used for monitoring objects
 	// Those objects are namespaced. However, objects includes labels, rules and expressions
 	// that need namespace filtering because they are "global" once imported
@@ -56,6 +78,20 @@ type BackendOptions struct {
 	Namespace string `validate:"required"`
 }
 
+// BackendRedisQueuesOptions holds the connection details of the backend
+// queues Redis database, used by the backend-worker queue scaler
+type BackendRedisQueuesOptions struct {
+	// Address is a comma separated list of the sentinels
+	// addresses when SentinelMaster is set
+	Address        string
+	SentinelMaster string
+	DatabaseIndex  string
+	EnableTLS      bool
+	// Credentials of the user info of the queues URL
+	Username string
+	Password string
+}
+
 func NewBackendOptions() *BackendOptions {
 	return &BackendOptions{}
 }
@@ -0,0 +1,169 @@
+package component
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	SystemSecretSystemDatabaseSSLModeFieldName = "SSL_MODE"
+	SystemSecretSystemDatabaseSSLCAFieldName   = "SSL_CA"
+	SystemSecretSystemDatabaseSSLCertFieldName = "SSL_CERT"
+	SystemSecretSystemDatabaseSSLKeyFieldName  = "SSL_KEY"
+
+	ZyncSecretDatabaseSSLModeFieldName = "DATABASE_SSL_MODE"
+	ZyncSecretDatabaseSSLCAFieldName   = "DATABASE_SSL_CA"
+	ZyncSecretDatabaseSSLCertFieldName = "DATABASE_SSL_CERT"
+	ZyncSecretDatabaseSSLKeyFieldName  = "DATABASE_SSL_KEY"
+)
+
+const (
+	SystemDatabaseTLSVolumeName = "system-database-tls"
+	SystemDatabaseTLSMountPath  = "/tls/system-database"
+	ZyncDatabaseTLSVolumeName   = "zync-database-tls"
+	ZyncDatabaseTLSMountPath    = "/tls/zync-database"
+)
+
+const (
+	DatabaseEngineMySQL      = "mysql"
+	DatabaseEnginePostgreSQL = "postgresql"
+)
+
+// TLSClientKeyFileMode is the mode of the client key files. libpq refuses keys
+// readable by others. Files of secret volumes are owned by root, so the key is
+// readable by the pod fsGroup, which libpq accepts for keys owned by root
+const TLSClientKeyFileMode int32 = 0640
+
+// DatabaseBaseURLEnvVarName holds the database URL read from the secret, when
+// the DATABASE_URL environment variable appends the TLS parameters to it
+const DatabaseBaseURLEnvVarName = "DATABASE_BASE_URL"
+
+// DatabaseSSLModes are the accepted SSL modes, named after the PostgreSQL ones.
+// They are translated to the equivalent MySQL modes.
+var DatabaseSSLModes = []string{"disable", "prefer", "require", "verify-ca", "verify-full"}
+
+var mysqlSSLModes = map[string]string{
+	"disable":     "disabled",
+	"prefer":      "preferred",
+	"require":     "required",
+	"verify-ca":   "verify_ca",
+	"verify-full": "verify_identity",
+}
+
+// DatabaseTLSOptions holds the TLS settings of the connection to an external database.
+// The CA and the client certificate are optional and read from fields of the database secret.
+type DatabaseTLSOptions struct {
+	Engine     string
+	SecretName string
+	SSLMode    string
+	// Secret fields holding the PEM encoded material. Empty when not provided.
+	CAField   string
+	CertField string
+	KeyField  string
+	// Whether the database URL already has query parameters
+	URLHasQuery bool
+}
+
+// DatabaseURLEnvVarNames returns the environment variables holding the database URL.
+// They are reconciled together, as DATABASE_URL may reference the base URL.
+func DatabaseURLEnvVarNames() []string {
+	return []string{DatabaseBaseURLEnvVarName, "DATABASE_URL"}
+}
+
+// databaseURLEnvVars returns the DATABASE_URL environment variable read from the secret field.
+// With TLS, the URL is read into the base URL variable and DATABASE_URL appends the
+// TLS parameters of the database engine to it.
+func databaseURLEnvVars(secretName, secretField string, options *DatabaseTLSOptions, mountPath string) []v1.EnvVar {
+	if options == nil {
+		return []v1.EnvVar{helper.EnvVarFromSecret("DATABASE_URL", secretName, secretField)}
+	}
+
+	separator := "?"
+	if options.URLHasQuery {
+		separator = "&"
+	}
+
+	return []v1.EnvVar{
+		helper.EnvVarFromSecret(DatabaseBaseURLEnvVarName, secretName, secretField),
+		helper.EnvVarFromValue("DATABASE_URL",
+			fmt.Sprintf("$(%s)%s%s", DatabaseBaseURLEnvVarName, separator, databaseTLSURLParams(options, mountPath))),
+	}
+}
+
+func databaseTLSURLParams(options *DatabaseTLSOptions, mountPath string) string {
+	modeParam, caParam := "sslmode", "sslrootcert"
+	sslMode := options.SSLMode
+	if options.Engine == DatabaseEngineMySQL {
+		modeParam, caParam = "ssl_mode", "sslca"
+		sslMode = mysqlSSLModes[sslMode]
+	}
+
+	params := []string{fmt.Sprintf("%s=%s", modeParam, sslMode)}
+	if options.CAField != "" {
+		params = append(params, fmt.Sprintf("%s=%s", caParam, path.Join(mountPath, options.CAField)))
+	}
+	if options.CertField != "" && options.KeyField != "" {
+		params = append(params,
+			fmt.Sprintf("sslcert=%s", path.Join(mountPath, options.CertField)),
+			fmt.Sprintf("sslkey=%s", path.Join(mountPath, options.KeyField)),
+		)
+	}
+	return strings.Join(params, "&")
+}
+
+// databaseTLSVolume returns the volume with the TLS material of the database connection,
+// or nil when it provides none
+func databaseTLSVolume(volumeName string, options *DatabaseTLSOptions) *v1.Volume {
+	if options == nil {
+		return nil
+	}
+
+	items := []v1.KeyToPath{}
+	for _, field := range []string{options.CAField, options.CertField} {
+		if field != "" {
+			items = append(items, v1.KeyToPath{Key: field, Path: field})
+		}
+	}
+	if options.KeyField != "" {
+		keyFileMode := TLSClientKeyFileMode
+		items = append(items, v1.KeyToPath{Key: options.KeyField, Path: options.KeyField, Mode: &keyFileMode})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	return &v1.Volume{
+		Name: volumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: options.SecretName,
+				Items:      items,
+			},
+		},
+	}
+}
+
+// ValidateDatabaseTLS validates the TLS settings of an external database connection.
+// The verify modes need the CA to check the server certificate.
+func ValidateDatabaseTLS(sslMode, ca, cert, key string) error {
+	if sslMode == "" {
+		if ca != "" || cert != "" || key != "" {
+			return fmt.Errorf("SSL mode is required along with the TLS material")
+		}
+		return nil
+	}
+
+	if !helper.ArrayContains(DatabaseSSLModes, sslMode) {
+		return fmt.Errorf("unknown SSL mode '%s', expected one of %v", sslMode, DatabaseSSLModes)
+	}
+	if strings.HasPrefix(sslMode, "verify-") && ca == "" {
+		return fmt.Errorf("SSL mode '%s' requires the CA", sslMode)
+	}
+
+	return validateTLSMaterial(ca, cert, key)
+}
@@ -12,6 +12,9 @@ type DeploymentsLister struct {
 	SystemDatabaseType     SystemDatabaseType
 	ExternalRedisDatabases bool
 	ExternalZyncDatabase   bool
+	ExternalMemcached      bool
+	BackendRedisSentinel   bool
+	SystemRedisSentinel    bool
 }
 
 func (d *DeploymentsLister) DeploymentNames() []string {
@@ -22,7 +25,6 @@ func (d *DeploymentsLister) DeploymentNames() []string {
 		BackendListenerName,
 		BackendWorkerName,
 		BackendCronName,
-		SystemMemcachedDeploymentName,
 		SystemAppDeploymentName,
 		SystemSidekiqName,
 		SystemSearchdDeploymentName,
@@ -38,10 +40,16 @@ func (d *DeploymentsLister) DeploymentNames() []string {
 	}
 
 	if !d.ExternalRedisDatabases {
-		deployments = append(deployments,
-			BackendRedisDeploymentName,
-			SystemRedisDeploymentName,
-		)
+		if !d.BackendRedisSentinel {
+			deployments = append(deployments, BackendRedisDeploymentName)
+		}
+		if !d.SystemRedisSentinel {
+			deployments = append(deployments, SystemRedisDeploymentName)
+		}
+	}
+
+	if !d.ExternalMemcached {
+		deployments = append(deployments, SystemMemcachedDeploymentName)
 	}
 
 	if !d.ExternalZyncDatabase {
@@ -50,3 +58,19 @@ func (d *DeploymentsLister) DeploymentNames() []string {
 
 	return deployments
 }
+
+// StatefulSetNames returns the redis sentinel StatefulSets replacing the redis DeploymentConfigs
+func (d *DeploymentsLister) StatefulSetNames() []string {
+	var statefulSets []string
+
+	if !d.ExternalRedisDatabases {
+		if d.BackendRedisSentinel {
+			statefulSets = append(statefulSets, BackendRedisSentinelStatefulSetName)
+		}
+		if d.SystemRedisSentinel {
+			statefulSets = append(statefulSets, SystemRedisSentinelStatefulSetName)
+		}
+	}
+
+	return statefulSets
+}
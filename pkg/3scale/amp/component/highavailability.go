@@ -37,7 +37,7 @@ func (ha *HighAvailability) SystemDatabaseSecret() *v1.Secret {
 }
 
 func (ha *HighAvailability) BackendRedisSecret() *v1.Secret {
-	return &v1.Secret{
+	secret := &v1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Secret",
@@ -56,10 +56,20 @@ func (ha *HighAvailability) BackendRedisSecret() *v1.Secret {
 		},
 		Type: v1.SecretTypeOpaque,
 	}
+
+	setOptionalStringData(secret, map[string]string{
+		BackendSecretBackendRedisStorageSSLCAFieldName:   ha.Options.BackendRedisStorageSSLCA,
+		BackendSecretBackendRedisStorageSSLCertFieldName: ha.Options.BackendRedisStorageSSLCert,
+		BackendSecretBackendRedisStorageSSLKeyFieldName:  ha.Options.BackendRedisStorageSSLKey,
+		BackendSecretBackendRedisQueuesSSLCAFieldName:    ha.Options.BackendRedisQueuesSSLCA,
+		BackendSecretBackendRedisQueuesSSLCertFieldName:  ha.Options.BackendRedisQueuesSSLCert,
+		BackendSecretBackendRedisQueuesSSLKeyFieldName:   ha.Options.BackendRedisQueuesSSLKey,
+	})
+	return secret
 }
 
 func (ha *HighAvailability) SystemRedisSecret() *v1.Secret {
-	return &v1.Secret{
+	secret := &v1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Secret",
@@ -76,4 +86,20 @@ func (ha *HighAvailability) SystemRedisSecret() *v1.Secret {
 		},
 		Type: v1.SecretTypeOpaque,
 	}
+
+	setOptionalStringData(secret, map[string]string{
+		SystemSecretSystemRedisSSLCAFieldName:   ha.Options.SystemRedisSSLCA,
+		SystemSecretSystemRedisSSLCertFieldName: ha.Options.SystemRedisSSLCert,
+		SystemSecretSystemRedisSSLKeyFieldName:  ha.Options.SystemRedisSSLKey,
+	})
+	return secret
+}
+
+// setOptionalStringData adds the fields with non empty values to the secret
+func setOptionalStringData(secret *v1.Secret, fields map[string]string) {
+	for key, value := range fields {
+		if value != "" {
+			secret.StringData[key] = value
+		}
+	}
 }
@@ -1,6 +1,10 @@
 package component
 
-import "github.com/go-playground/validator/v10"
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type HighAvailabilityOptions struct {
 	BackendRedisQueuesEndpoint       string
@@ -15,6 +19,17 @@ type HighAvailabilityOptions struct {
 	SystemRedisSentinelsRole         string
 	SystemRedisNamespace             string
 
+	// Optional TLS material of the connections to Redis, PEM encoded
+	BackendRedisStorageSSLCA   string
+	BackendRedisStorageSSLCert string
+	BackendRedisStorageSSLKey  string
+	BackendRedisQueuesSSLCA    string
+	BackendRedisQueuesSSLCert  string
+	BackendRedisQueuesSSLKey   string
+	SystemRedisSSLCA           string
+	SystemRedisSSLCert         string
+	SystemRedisSSLKey          string
+
 	BackendRedisLabels   map[string]string `validate:"required"`
 	SystemRedisLabels    map[string]string `validate:"required"`
 	SystemDatabaseLabels map[string]string `validate:"required"`
@@ -26,5 +41,39 @@ func NewHighAvailabilityOptions() *HighAvailabilityOptions {
 
 func (h *HighAvailabilityOptions) Validate() error {
 	validate := validator.New()
-	return validate.Struct(h)
+	err := validate.Struct(h)
+	if err != nil {
+		return err
+	}
+
+	redisConnections := []struct {
+		secretName string
+		urlField   string
+		url        string
+		ca         string
+		cert       string
+		key        string
+	}{
+		{
+			BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageURLFieldName,
+			h.BackendRedisStorageEndpoint, h.BackendRedisStorageSSLCA, h.BackendRedisStorageSSLCert, h.BackendRedisStorageSSLKey,
+		},
+		{
+			BackendSecretBackendRedisSecretName, BackendSecretBackendRedisQueuesURLFieldName,
+			h.BackendRedisQueuesEndpoint, h.BackendRedisQueuesSSLCA, h.BackendRedisQueuesSSLCert, h.BackendRedisQueuesSSLKey,
+		},
+		{
+			SystemSecretSystemRedisSecretName, SystemSecretSystemRedisURLFieldName,
+			h.SystemRedisURL, h.SystemRedisSSLCA, h.SystemRedisSSLCert, h.SystemRedisSSLKey,
+		},
+	}
+
+	for _, conn := range redisConnections {
+		err = ValidateRedisTLS(conn.url, conn.ca, conn.cert, conn.key)
+		if err != nil {
+			return fmt.Errorf("secret %s: TLS settings of %s: %w", conn.secretName, conn.urlField, err)
+		}
+	}
+
+	return nil
 }
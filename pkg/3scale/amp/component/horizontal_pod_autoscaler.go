@@ -0,0 +1,48 @@
+package component
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+)
+
+// HorizontalPodAutoscalerOptions holds the autoscaling configuration of a component
+type HorizontalPodAutoscalerOptions struct {
+	MinReplicas *int32
+	MaxReplicas int32
+	Metrics     []autoscalingv2.MetricSpec
+}
+
+// horizontalPodAutoscaler returns the HorizontalPodAutoscaler scaling the named DeploymentConfig.
+// When options are not provided, autoscaling is disabled and the object is tagged to be deleted.
+func horizontalPodAutoscaler(name string, labels map[string]string, options *HorizontalPodAutoscalerOptions) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps.openshift.io/v1",
+				Kind:       "DeploymentConfig",
+				Name:       name,
+			},
+		},
+	}
+
+	if options == nil {
+		common.TagObjectToDelete(hpa)
+		return hpa
+	}
+
+	hpa.Spec.MinReplicas = options.MinReplicas
+	hpa.Spec.MaxReplicas = options.MaxReplicas
+	hpa.Spec.Metrics = options.Metrics
+
+	return hpa
+}
@@ -47,3 +47,7 @@ func ZyncPostgreSQLImageURL() string {
 func OCCLIImageURL() string {
 	return "quay.io/openshift/origin-cli:4.7"
 }
+
+func AWSCLIImageURL() string {
+	return "docker.io/amazon/aws-cli:2.13.0"
+}
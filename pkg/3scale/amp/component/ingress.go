@@ -0,0 +1,69 @@
+package component
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressOptions holds the configuration shared by all the
+// Ingress objects exposing the 3scale components
+type IngressOptions struct {
+	IngressClassName *string
+	Annotations      map[string]string
+	TLSSecretName    *string
+}
+
+func ingress(name, host, serviceName, servicePortName string, labels map[string]string, options *IngressOptions) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+
+	obj := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{Name: servicePortName},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if options == nil {
+		return obj
+	}
+
+	obj.Annotations = options.Annotations
+	obj.Spec.IngressClassName = options.IngressClassName
+	if options.TLSSecretName != nil {
+		obj.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{host},
+				SecretName: *options.TLSSecretName,
+			},
+		}
+	}
+
+	return obj
+}
@@ -7,6 +7,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/3scale/3scale-operator/pkg/common"
 )
 
 const (
@@ -22,7 +24,7 @@ func NewMemcached(options *MemcachedOptions) *Memcached {
 }
 
 func (m *Memcached) DeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -111,11 +113,21 @@ func (m *Memcached) DeploymentConfig() *appsv1.DeploymentConfig {
 								FailureThreshold:    0,
 							},
 							ImagePullPolicy: v1.PullIfNotPresent,
+							SecurityContext: m.Options.ContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         m.Options.PriorityClassName,
 					TopologySpreadConstraints: m.Options.TopologySpreadConstraints,
+					SecurityContext:           m.Options.PodSecurityContext,
 				}},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, m.Options.Probes)
+
+	if m.Options.External {
+		common.TagObjectToDelete(dc)
+	}
+
+	return dc
 }
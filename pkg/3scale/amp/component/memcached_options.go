@@ -19,6 +19,14 @@ type MemcachedOptions struct {
 	PriorityClassName         string                        `validate:"-"`
 	TopologySpreadConstraints []v1.TopologySpreadConstraint `validate:"-"`
 	PodTemplateAnnotations    map[string]string             `validate:"-"`
+
+	PodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	Probes []ContainerProbes `validate:"-"`
+
+	// External is set when system uses an externally managed memcached
+	External bool `validate:"-"`
 }
 
 func NewMemcachedOptions() *MemcachedOptions {
@@ -0,0 +1,248 @@
+package component
+
+import (
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+)
+
+const (
+	// DatabaseClientLabelKey labels the pods of the jobs run by the operator against
+	// the databases and redis instances, like preflight checks, backups and restores
+	DatabaseClientLabelKey   = "apps.3scale.net/database-client"
+	DatabaseClientLabelValue = "true"
+)
+
+var (
+	backendRedisClients = []string{BackendListenerName, BackendWorkerName, BackendCronName, SystemAppDeploymentName, SystemSidekiqName}
+	systemRedisClients  = []string{SystemAppDeploymentName, SystemSidekiqName}
+)
+
+// NetworkPolicy generates the NetworkPolicies allowing only the traffic
+// required between the 3scale components. Each policy selects the pods
+// of a single component and lists the allowed ingress flows.
+type NetworkPolicy struct {
+	Options *NetworkPolicyOptions
+}
+
+func NewNetworkPolicy(options *NetworkPolicyOptions) *NetworkPolicy {
+	return &NetworkPolicy{Options: options}
+}
+
+func (n *NetworkPolicy) ApicastStagingNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(ApicastStagingName, true,
+		n.publicRule(tcpPorts(intstr.FromInt(8080), intstr.FromString("httpsproxy"))),
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) ApicastProductionNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(ApicastProductionName, true,
+		n.publicRule(tcpPorts(intstr.FromInt(8080), intstr.FromString("httpsproxy"))),
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) BackendListenerNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(BackendListenerName, true,
+		// apicast authorizations and system internal API
+		n.publicRule(tcpPorts(intstr.FromInt(3000)),
+			ApicastStagingName, ApicastProductionName, SystemAppDeploymentName, SystemSidekiqName),
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) BackendWorkerNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(BackendWorkerName, true,
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) BackendCronNetworkPolicy() *networkingv1.NetworkPolicy {
+	// backend-cron does not accept any connection
+	return n.networkPolicy(BackendCronName, true)
+}
+
+func (n *NetworkPolicy) BackendRedisNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalBackendRedis && !n.Options.BackendRedisSentinel
+	return n.networkPolicy(BackendRedisDeploymentName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(6379)), n.Options.AutoscalerSources, backendRedisClients...),
+	)
+}
+
+func (n *NetworkPolicy) BackendRedisSentinelNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalBackendRedis && n.Options.BackendRedisSentinel
+	// replication and sentinels gossip between the redis replicas
+	return n.networkPolicy(BackendRedisSentinelStatefulSetName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(6379), intstr.FromInt(RedisSentinelPort)), n.Options.AutoscalerSources,
+			append([]string{BackendRedisSentinelStatefulSetName}, backendRedisClients...)...),
+	)
+}
+
+func (n *NetworkPolicy) SystemAppNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(SystemAppDeploymentName, true,
+		// apicast configuration and zync synchronization
+		n.publicRule(tcpPorts(intstr.FromString("master"), intstr.FromString("provider"), intstr.FromString("developer")),
+			ApicastStagingName, ApicastProductionName, SystemSidekiqName, ZyncName, ZyncQueDeploymentName),
+		n.metricsRule(tcpPorts(
+			intstr.FromString(SystemAppMasterContainerMetricsPortName),
+			intstr.FromString(SystemAppProviderContainerMetricsPortName),
+			intstr.FromString(SystemAppDeveloperContainerMetricsPortName),
+		)),
+	)
+}
+
+func (n *NetworkPolicy) SystemSidekiqNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(SystemSidekiqName, true,
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) SystemRedisNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalSystemRedis && !n.Options.SystemRedisSentinel
+	return n.networkPolicy(SystemRedisDeploymentName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(6379)), nil, systemRedisClients...),
+	)
+}
+
+func (n *NetworkPolicy) SystemRedisSentinelNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalSystemRedis && n.Options.SystemRedisSentinel
+	// replication and sentinels gossip between the redis replicas
+	return n.networkPolicy(SystemRedisSentinelStatefulSetName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(6379), intstr.FromInt(RedisSentinelPort)), nil,
+			append([]string{SystemRedisSentinelStatefulSetName}, systemRedisClients...)...),
+	)
+}
+
+func (n *NetworkPolicy) SystemMySQLNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalSystemDatabase && !n.Options.SystemDatabasePostgreSQL
+	return n.networkPolicy(SystemMySQLDeploymentName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(3306)), nil, SystemAppDeploymentName, SystemSidekiqName),
+	)
+}
+
+func (n *NetworkPolicy) SystemPostgreSQLNetworkPolicy() *networkingv1.NetworkPolicy {
+	enabled := !n.Options.ExternalSystemDatabase && n.Options.SystemDatabasePostgreSQL
+	return n.networkPolicy(SystemPostgreSQLDeploymentName, enabled,
+		n.databaseRule(tcpPorts(intstr.FromInt(5432)), nil, SystemAppDeploymentName, SystemSidekiqName),
+	)
+}
+
+func (n *NetworkPolicy) SystemMemcachedNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(SystemMemcachedDeploymentName, !n.Options.ExternalSystemMemcached,
+		internalRule(tcpPorts(intstr.FromInt(11211)), SystemAppDeploymentName, SystemSidekiqName),
+	)
+}
+
+func (n *NetworkPolicy) SystemSearchdNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(SystemSearchdDeploymentName, true,
+		internalRule(tcpPorts(intstr.FromInt(9306)), SystemAppDeploymentName, SystemSidekiqName),
+	)
+}
+
+func (n *NetworkPolicy) ZyncNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(ZyncName, true,
+		internalRule(tcpPorts(intstr.FromInt(8080)), SystemAppDeploymentName, SystemSidekiqName),
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) ZyncQueNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(ZyncQueDeploymentName, true,
+		n.metricsRule(tcpPorts(intstr.FromString("metrics"))),
+	)
+}
+
+func (n *NetworkPolicy) ZyncDatabaseNetworkPolicy() *networkingv1.NetworkPolicy {
+	return n.networkPolicy(ZyncDatabaseDeploymentName, !n.Options.ExternalZyncDatabase,
+		n.databaseRule(tcpPorts(intstr.FromInt(5432)), n.Options.AutoscalerSources, ZyncName, ZyncQueDeploymentName),
+	)
+}
+
+// networkPolicy returns the NetworkPolicy selecting the pods of the named component.
+// When the component is not deployed, the object is tagged to be deleted.
+func (n *NetworkPolicy) networkPolicy(name string, enabled bool, rules ...networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+	obj := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: n.Options.CommonLabels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: componentPodSelector(name),
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	if !enabled {
+		common.TagObjectToDelete(obj)
+	}
+
+	return obj
+}
+
+// publicRule allows the configured ingress sources, and the named
+// components, to reach the given ports. When no ingress source is
+// configured, traffic from any source is allowed.
+func (n *NetworkPolicy) publicRule(ports []networkingv1.NetworkPolicyPort, internalComponents ...string) networkingv1.NetworkPolicyIngressRule {
+	if len(n.Options.IngressSources) == 0 {
+		return networkingv1.NetworkPolicyIngressRule{Ports: ports}
+	}
+
+	from := append([]networkingv1.NetworkPolicyPeer{}, n.Options.IngressSources...)
+	from = append(from, componentPeers(internalComponents...)...)
+	return networkingv1.NetworkPolicyIngressRule{Ports: ports, From: from}
+}
+
+func (n *NetworkPolicy) metricsRule(ports []networkingv1.NetworkPolicyPort) networkingv1.NetworkPolicyIngressRule {
+	return networkingv1.NetworkPolicyIngressRule{Ports: ports, From: n.Options.MonitoringSources}
+}
+
+// internalRule allows the named components to reach the given ports
+func internalRule(ports []networkingv1.NetworkPolicyPort, components ...string) networkingv1.NetworkPolicyIngressRule {
+	return networkingv1.NetworkPolicyIngressRule{Ports: ports, From: componentPeers(components...)}
+}
+
+// databaseRule allows the named components, the operator jobs connecting to the
+// databases and the given peers to reach the given ports
+func (n *NetworkPolicy) databaseRule(ports []networkingv1.NetworkPolicyPort, peers []networkingv1.NetworkPolicyPeer, components ...string) networkingv1.NetworkPolicyIngressRule {
+	rule := internalRule(ports, components...)
+	rule.From = append(rule.From, networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{DatabaseClientLabelKey: DatabaseClientLabelValue},
+		},
+	})
+	rule.From = append(rule.From, peers...)
+	return rule
+}
+
+func componentPeers(components ...string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(components))
+	for _, component := range components {
+		podSelector := componentPodSelector(component)
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &podSelector})
+	}
+	return peers
+}
+
+func componentPodSelector(component string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{"deploymentConfig": component},
+	}
+}
+
+func tcpPorts(ports ...intstr.IntOrString) []networkingv1.NetworkPolicyPort {
+	protocol := v1.ProtocolTCP
+	result := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for idx := range ports {
+		result = append(result, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &ports[idx]})
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package component
+
+import (
+	"github.com/go-playground/validator/v10"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type NetworkPolicyOptions struct {
+	CommonLabels map[string]string `validate:"required"`
+
+	// IngressSources are the peers allowed to reach the publicly exposed components.
+	// When empty, traffic from any source is allowed
+	IngressSources    []networkingv1.NetworkPolicyPeer `validate:"-"`
+	MonitoringSources []networkingv1.NetworkPolicyPeer `validate:"required"`
+	// AutoscalerSources are the peers allowed to reach the databases and redis
+	// instances polled by the queue autoscaling triggers. Empty when disabled
+	AutoscalerSources []networkingv1.NetworkPolicyPeer `validate:"-"`
+
+	SystemDatabasePostgreSQL bool
+	ExternalSystemDatabase   bool
+	ExternalSystemRedis      bool
+	ExternalBackendRedis     bool
+	ExternalZyncDatabase     bool
+	ExternalSystemMemcached  bool
+	BackendRedisSentinel     bool
+	SystemRedisSentinel      bool
+}
+
+func NewNetworkPolicyOptions() *NetworkPolicyOptions {
+	return &NetworkPolicyOptions{}
+}
+
+func (n *NetworkPolicyOptions) Validate() error {
+	validate := validator.New()
+	return validate.Struct(n)
+}
+
+// DefaultNetworkPolicyMonitoringSources returns the peers matching
+// the OpenShift cluster and user workload monitoring namespaces
+func DefaultNetworkPolicyMonitoringSources() []networkingv1.NetworkPolicyPeer {
+	return []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"network.openshift.io/policy-group": "monitoring"},
+			},
+		},
+	}
+}
+
+// DefaultNetworkPolicyAutoscalerSources returns the peer matching the KEDA
+// operator pods, which run the scalers, in any namespace
+func DefaultNetworkPolicyAutoscalerSources() []networkingv1.NetworkPolicyPeer {
+	return []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{},
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "keda-operator"},
+			},
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package component
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// PodTemplateOverrides holds the user provided additions to the pod template of a component
+type PodTemplateOverrides struct {
+	Env               []v1.EnvVar
+	Volumes           []v1.Volume
+	VolumeMounts      []v1.VolumeMount
+	SidecarContainers []v1.Container
+	InitContainers    []v1.Container
+}
+
+// applyPodTemplateOverrides adds the overrides to the pod template of the DeploymentConfig.
+// Env vars and volume mounts are added to every container of the component.
+// Entries conflicting with the ones defined by the operator are skipped.
+// Added entries are recorded in the PodTemplateOverridesAnnotation annotation.
+func applyPodTemplateOverrides(dc *appsv1.DeploymentConfig, overrides *PodTemplateOverrides) *appsv1.DeploymentConfig {
+	if overrides == nil {
+		return dc
+	}
+
+	podSpec := &dc.Spec.Template.Spec
+	record := &helper.PodTemplateOverridesRecord{}
+
+	for _, envVar := range overrides.Env {
+		if containersDefineEnvVar(podSpec.Containers, envVar.Name) || helper.ArrayContains(record.Env, envVar.Name) {
+			continue
+		}
+		for idx := range podSpec.Containers {
+			podSpec.Containers[idx].Env = append(podSpec.Containers[idx].Env, envVar)
+		}
+		record.Env = append(record.Env, envVar.Name)
+	}
+
+	for _, volumeMount := range overrides.VolumeMounts {
+		if containersDefineVolumeMount(podSpec.Containers, volumeMount) || helper.ArrayContains(record.VolumeMounts, volumeMount.MountPath) {
+			continue
+		}
+		for idx := range podSpec.Containers {
+			podSpec.Containers[idx].VolumeMounts = append(podSpec.Containers[idx].VolumeMounts, volumeMount)
+		}
+		record.VolumeMounts = append(record.VolumeMounts, volumeMount.MountPath)
+	}
+
+	for _, volume := range overrides.Volumes {
+		if helper.FindVolumeByName(podSpec.Volumes, volume.Name) >= 0 {
+			continue
+		}
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		record.Volumes = append(record.Volumes, volume.Name)
+	}
+
+	for _, container := range overrides.SidecarContainers {
+		if podSpecDefinesContainer(podSpec, container.Name) {
+			continue
+		}
+		podSpec.Containers = append(podSpec.Containers, container)
+		record.Containers = append(record.Containers, container.Name)
+	}
+
+	for _, container := range overrides.InitContainers {
+		if podSpecDefinesContainer(podSpec, container.Name) {
+			continue
+		}
+		podSpec.InitContainers = append(podSpec.InitContainers, container)
+		record.InitContainers = append(record.InitContainers, container.Name)
+	}
+
+	record.SetAnnotation(&dc.Annotations)
+
+	return dc
+}
+
+func containersDefineEnvVar(containers []v1.Container, name string) bool {
+	for idx := range containers {
+		if helper.FindEnvVar(containers[idx].Env, name) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containersDefineVolumeMount(containers []v1.Container, volumeMount v1.VolumeMount) bool {
+	for idx := range containers {
+		if helper.FindVolumeMountByMountPath(containers[idx].VolumeMounts, volumeMount) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func podSpecDefinesContainer(podSpec *v1.PodSpec, name string) bool {
+	for _, container := range append(append([]v1.Container{}, podSpec.Containers...), podSpec.InitContainers...) {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
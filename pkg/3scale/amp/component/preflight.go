@@ -0,0 +1,380 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	PreflightCheckBackendRedisStorage = "backend-redis-storage"
+	PreflightCheckBackendRedisQueues  = "backend-redis-queues"
+	PreflightCheckSystemRedis         = "system-redis"
+	PreflightCheckSystemDatabase      = "system-database"
+	PreflightCheckZyncDatabase        = "zync-database"
+)
+
+const (
+	PreflightComponentName  = "preflight"
+	PreflightCheckLabelName = "threescale_component_element"
+	PreflightTLSMountPath   = "/preflight/tls"
+
+	preflightTLSVolumeName = "tls"
+	preflightCAFileName    = "ca.crt"
+	preflightCertFileName  = "tls.crt"
+	preflightKeyFileName   = "tls.key"
+)
+
+const (
+	// PreflightJobBackoffLimit is the number of retries of a check before it is failed
+	PreflightJobBackoffLimit int32 = 2
+	// PreflightJobActiveDeadlineSeconds bounds the time spent by a check, retries included
+	PreflightJobActiveDeadlineSeconds int64 = 300
+)
+
+// The check scripts write their outcome to the termination message of the container:
+// the server version when the check passes, the cause of the failure otherwise.
+
+const preflightRedisScript = `fail() { echo "$1" | tee /dev/termination-log; exit 1; }
+cli() { local password="$1"; shift; if [ -n "$password" ]; then REDISCLI_AUTH="$password" redis-cli "$@"; else redis-cli "$@"; fi; }
+tls=()
+if [ "${REDIS_TLS:-}" = "true" ]; then
+  tls=(--tls)
+  [ -n "${REDIS_CA_FILE:-}" ] && tls+=(--cacert "$REDIS_CA_FILE")
+  [ -n "${REDIS_CERT_FILE:-}" ] && tls+=(--cert "$REDIS_CERT_FILE" --key "$REDIS_KEY_FILE")
+fi
+host="$REDIS_HOST"
+port="$REDIS_PORT"
+if [ -n "${REDIS_SENTINEL_HOSTS:-}" ]; then
+  sentinel_tls=()
+  [ "${REDIS_SENTINEL_TLS:-}" = "true" ] && sentinel_tls=("${tls[@]}")
+  master=()
+  for sentinel in $REDIS_SENTINEL_HOSTS; do
+    master=($(cli "${REDIS_SENTINEL_PASSWORD:-}" "${sentinel_tls[@]}" -h "${sentinel%:*}" -p "${sentinel##*:}" SENTINEL get-master-addr-by-name "$REDIS_HOST" 2>/dev/null))
+    [ "${#master[@]}" -eq 2 ] && break
+  done
+  [ "${#master[@]}" -eq 2 ] || fail "cannot resolve Redis master ${REDIS_HOST} from sentinels ${REDIS_SENTINEL_HOSTS}"
+  host="${master[0]}"
+  port="${master[1]}"
+fi
+user=()
+[ -n "${REDIS_USERNAME:-}" ] && user=(--user "$REDIS_USERNAME")
+info=$(cli "${REDIS_PASSWORD:-}" "${tls[@]}" "${user[@]}" -h "$host" -p "$port" INFO server 2>&1)
+version=$(echo "$info" | sed -n 's/^redis_version:\([0-9.]*\).*/\1/p')
+[ -n "$version" ] || fail "cannot query Redis at ${host}:${port}: $(echo $info)"
+[ "${version%%.*}" -ge 6 ] || fail "Redis ${version} is not supported, 6 or newer is required"
+echo "Redis ${version}" | tee /dev/termination-log
+`
+
+const preflightMySQLScript = `fail() { echo "$1" | tee /dev/termination-log; exit 1; }
+args=(--host="$MYSQL_HOST" --port="$MYSQL_PORT" --user="$MYSQL_USER" --connect-timeout=10 --batch --skip-column-names)
+[ -n "${MYSQL_SSL_MODE:-}" ] && args+=(--ssl-mode="$MYSQL_SSL_MODE")
+[ -n "${MYSQL_SSL_CA:-}" ] && args+=(--ssl-ca="$MYSQL_SSL_CA")
+[ -n "${MYSQL_SSL_CERT:-}" ] && args+=(--ssl-cert="$MYSQL_SSL_CERT" --ssl-key="$MYSQL_SSL_KEY")
+output=$(mysql "${args[@]}" --execute="SELECT VERSION()" "$MYSQL_DATABASE" 2>&1) || fail "cannot connect to MySQL at ${MYSQL_HOST}:${MYSQL_PORT}: $(echo $output)"
+version=$(echo "$output" | tail -n 1)
+major="${version%%.*}"
+[[ "$major" =~ ^[0-9]+$ ]] || fail "unexpected MySQL version: $(echo $output)"
+[ "$major" -ge 8 ] || fail "MySQL ${version} is not supported, 8 or newer is required"
+echo "MySQL ${version}" | tee /dev/termination-log
+`
+
+const preflightPostgreSQLScript = `fail() { echo "$1" | tee /dev/termination-log; exit 1; }
+output=$(psql --no-psqlrc --tuples-only --no-align --command="SHOW server_version_num" 2>&1) || fail "cannot connect to PostgreSQL at ${PGHOST}:${PGPORT}: $(echo $output)"
+version=$(echo "$output" | tail -n 1)
+[[ "$version" =~ ^[0-9]+$ ]] || fail "unexpected PostgreSQL version: $(echo $output)"
+major=$((version / 10000))
+[ "$major" -ge 13 ] || fail "PostgreSQL ${major} is not supported, 13 or newer is required"
+echo "PostgreSQL ${major}.$((version % 10000))" | tee /dev/termination-log
+`
+
+// Preflight builds the Jobs checking that the external components are reachable,
+// accept the credentials and run a supported server version
+type Preflight struct {
+	Options *PreflightOptions
+}
+
+func NewPreflight(options *PreflightOptions) *Preflight {
+	return &Preflight{Options: options}
+}
+
+// PreflightSecretName is the secret holding the connection settings of a check
+func PreflightSecretName(checkName string) string {
+	return fmt.Sprintf("%s-%s", PreflightComponentName, checkName)
+}
+
+// Secret returns the secret with the environment and the TLS material of the check
+func (p *Preflight) Secret(check *PreflightCheck) *v1.Secret {
+	env, files := check.settings()
+	data := map[string][]byte{}
+	for key, value := range env {
+		data[key] = []byte(value)
+	}
+	for key, value := range files {
+		data[key] = []byte(value)
+	}
+
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   PreflightSecretName(check.Name),
+			Labels: p.checkLabels(check),
+		},
+		Data: data,
+		Type: v1.SecretTypeOpaque,
+	}
+}
+
+// Job returns the Job running the check. Its name includes the hash of the check,
+// so a check is run again whenever its settings change.
+func (p *Preflight) Job(check *PreflightCheck) *batchv1.Job {
+	env, files := check.settings()
+	secretName := PreflightSecretName(check.Name)
+
+	envVars := []v1.EnvVar{}
+	for _, key := range sortedKeys(env) {
+		envVars = append(envVars, helper.EnvVarFromSecret(key, secretName, key))
+	}
+
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name:            PreflightComponentName,
+				Image:           check.Image,
+				Command:         []string{"/bin/bash", "-c", check.script()},
+				Env:             envVars,
+				SecurityContext: p.Options.ContainerSecurityContext,
+			},
+		},
+		RestartPolicy:    v1.RestartPolicyNever,
+		ImagePullSecrets: p.Options.ImagePullSecrets,
+		SecurityContext:  p.Options.PodSecurityContext,
+	}
+
+	if len(files) > 0 {
+		items := []v1.KeyToPath{}
+		for _, key := range sortedKeys(files) {
+			item := v1.KeyToPath{Key: key, Path: key}
+			if key == preflightKeyFileName {
+				keyFileMode := TLSClientKeyFileMode
+				item.Mode = &keyFileMode
+			}
+			items = append(items, item)
+		}
+		podSpec.Volumes = []v1.Volume{
+			{
+				Name: preflightTLSVolumeName,
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{SecretName: secretName, Items: items},
+				},
+			},
+		}
+		podSpec.Containers[0].VolumeMounts = []v1.VolumeMount{
+			{Name: preflightTLSVolumeName, MountPath: PreflightTLSMountPath, ReadOnly: true},
+		}
+	}
+
+	backoffLimit := PreflightJobBackoffLimit
+	activeDeadlineSeconds := PreflightJobActiveDeadlineSeconds
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s-%s", PreflightComponentName, check.Name, check.Hash()[:10]),
+			Labels: p.checkLabels(check),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: p.podLabels(check),
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
+
+func (p *Preflight) checkLabels(check *PreflightCheck) map[string]string {
+	labels := map[string]string{}
+	for key, value := range p.Options.Labels {
+		labels[key] = value
+	}
+	labels[PreflightCheckLabelName] = check.Name
+	return labels
+}
+
+// podLabels are the labels of the check pods, allowed by the NetworkPolicies of the databases
+func (p *Preflight) podLabels(check *PreflightCheck) map[string]string {
+	labels := p.checkLabels(check)
+	labels[DatabaseClientLabelKey] = DatabaseClientLabelValue
+	return labels
+}
+
+// PreflightChecksHash identifies the checks and their settings, it is empty without checks
+func PreflightChecksHash(checks []PreflightCheck) string {
+	if len(checks) == 0 {
+		return ""
+	}
+
+	hashes := []string{}
+	for idx := range checks {
+		hashes = append(hashes, fmt.Sprintf("%s=%s", checks[idx].Name, checks[idx].Hash()))
+	}
+	sort.Strings(hashes)
+	h := sha256.Sum256([]byte(strings.Join(hashes, "\n")))
+	return hex.EncodeToString(h[:])
+}
+
+// Hash identifies the image, the script and the settings of the check
+func (c *PreflightCheck) Hash() string {
+	env, files := c.settings()
+	h := sha256.New()
+	h.Write([]byte(c.Image))
+	h.Write([]byte{0})
+	h.Write([]byte(c.script()))
+	for _, values := range []map[string]string{env, files} {
+		for _, key := range sortedKeys(values) {
+			h.Write([]byte{0})
+			h.Write([]byte(key))
+			h.Write([]byte{0})
+			h.Write([]byte(values[key]))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *PreflightCheck) script() string {
+	if c.Redis != nil {
+		return preflightRedisScript
+	}
+	if c.Database.Engine == DatabaseEngineMySQL {
+		return preflightMySQLScript
+	}
+	return preflightPostgreSQLScript
+}
+
+// settings returns the environment read by the check script and the files
+// with the TLS material
+func (c *PreflightCheck) settings() (map[string]string, map[string]string) {
+	if c.Redis != nil {
+		return c.Redis.settings()
+	}
+	return c.Database.settings()
+}
+
+func (r *PreflightRedisConnection) settings() (map[string]string, map[string]string) {
+	env := map[string]string{
+		"REDIS_HOST": r.Host,
+		"REDIS_PORT": r.Port,
+	}
+	optional := map[string]string{
+		"REDIS_USERNAME":          r.Username,
+		"REDIS_PASSWORD":          r.Password,
+		"REDIS_SENTINEL_HOSTS":    strings.Join(r.SentinelHosts, " "),
+		"REDIS_SENTINEL_PASSWORD": r.SentinelPassword,
+	}
+	for key, value := range optional {
+		if value != "" {
+			env[key] = value
+		}
+	}
+	if r.SentinelTLS {
+		env["REDIS_SENTINEL_TLS"] = "true"
+	}
+
+	files := map[string]string{}
+	if r.TLS {
+		env["REDIS_TLS"] = "true"
+		files = tlsFiles(r.CA, r.Cert, r.Key)
+		setTLSFileEnvVars(env, files, "REDIS_CA_FILE", "REDIS_CERT_FILE", "REDIS_KEY_FILE")
+	}
+
+	return env, files
+}
+
+func (d *PreflightDatabaseConnection) settings() (map[string]string, map[string]string) {
+	files := tlsFiles(d.CA, d.Cert, d.Key)
+
+	if d.Engine == DatabaseEngineMySQL {
+		env := map[string]string{
+			"MYSQL_HOST":     d.Host,
+			"MYSQL_PORT":     d.Port,
+			"MYSQL_USER":     d.Username,
+			"MYSQL_PWD":      d.Password,
+			"MYSQL_DATABASE": d.Database,
+		}
+		if d.SSLMode != "" {
+			env["MYSQL_SSL_MODE"] = strings.ToUpper(mysqlSSLModes[d.SSLMode])
+		}
+		setTLSFileEnvVars(env, files, "MYSQL_SSL_CA", "MYSQL_SSL_CERT", "MYSQL_SSL_KEY")
+		return env, files
+	}
+
+	// libpq reads the connection settings from its environment variables
+	env := map[string]string{
+		"PGHOST":            d.Host,
+		"PGPORT":            d.Port,
+		"PGUSER":            d.Username,
+		"PGPASSWORD":        d.Password,
+		"PGDATABASE":        d.Database,
+		"PGCONNECT_TIMEOUT": "10",
+	}
+	if d.SSLMode != "" {
+		env["PGSSLMODE"] = d.SSLMode
+	}
+	setTLSFileEnvVars(env, files, "PGSSLROOTCERT", "PGSSLCERT", "PGSSLKEY")
+	return env, files
+}
+
+func tlsFiles(ca, cert, key string) map[string]string {
+	files := map[string]string{}
+	if ca != "" {
+		files[preflightCAFileName] = ca
+	}
+	if cert != "" && key != "" {
+		files[preflightCertFileName] = cert
+		files[preflightKeyFileName] = key
+	}
+	return files
+}
+
+func setTLSFileEnvVars(env, files map[string]string, caEnvVar, certEnvVar, keyEnvVar string) {
+	cases := []struct {
+		envVar   string
+		fileName string
+	}{
+		{caEnvVar, preflightCAFileName},
+		{certEnvVar, preflightCertFileName},
+		{keyEnvVar, preflightKeyFileName},
+	}
+	for _, file := range cases {
+		if _, ok := files[file.fileName]; ok {
+			env[file.envVar] = path.Join(PreflightTLSMountPath, file.fileName)
+		}
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
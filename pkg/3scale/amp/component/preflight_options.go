@@ -0,0 +1,75 @@
+package component
+
+import (
+	"github.com/go-playground/validator/v10"
+	v1 "k8s.io/api/core/v1"
+)
+
+// PreflightOptions holds the checks of the connections to the external components
+type PreflightOptions struct {
+	Labels map[string]string `validate:"required"`
+
+	ImagePullSecrets         []v1.LocalObjectReference `validate:"-"`
+	PodSecurityContext       *v1.PodSecurityContext    `validate:"-"`
+	ContainerSecurityContext *v1.SecurityContext       `validate:"-"`
+
+	Checks []PreflightCheck `validate:"dive"`
+}
+
+// PreflightCheck describes the check of the connection to one external component.
+// Exactly one of the Redis and Database connections is set.
+type PreflightCheck struct {
+	// Name of the checked connection, e.g. system-database
+	Name string `validate:"required"`
+	// Image providing the client of the checked server
+	Image string `validate:"required"`
+
+	Redis    *PreflightRedisConnection    `validate:"required_without=Database"`
+	Database *PreflightDatabaseConnection `validate:"required_without=Redis"`
+}
+
+// PreflightRedisConnection holds the settings of the connection to an external Redis.
+// With sentinels, Host is the name of the monitored master.
+type PreflightRedisConnection struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+
+	SentinelHosts    []string
+	SentinelPassword string
+	SentinelTLS      bool
+
+	TLS bool
+	// PEM encoded TLS material. Empty when not provided.
+	CA   string
+	Cert string
+	Key  string
+}
+
+// PreflightDatabaseConnection holds the settings of the connection to an external database
+type PreflightDatabaseConnection struct {
+	// DatabaseEngineMySQL or DatabaseEnginePostgreSQL
+	Engine   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Database string
+
+	// One of DatabaseSSLModes, or empty for the client default
+	SSLMode string
+	// PEM encoded TLS material. Empty when not provided.
+	CA   string
+	Cert string
+	Key  string
+}
+
+func NewPreflightOptions() *PreflightOptions {
+	return &PreflightOptions{}
+}
+
+func (p *PreflightOptions) Validate() error {
+	validate := validator.New()
+	return validate.Struct(p)
+}
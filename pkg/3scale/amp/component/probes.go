@@ -0,0 +1,55 @@
+package component
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// startup probes give the containers up to 5 minutes to start
+	startupProbePeriodSeconds    = 10
+	startupProbeFailureThreshold = 30
+)
+
+// ContainerProbes holds the user provided probes of a container of a component.
+// Nil probes keep the ones defined by the operator.
+type ContainerProbes struct {
+	ContainerName  string
+	LivenessProbe  *v1.Probe
+	ReadinessProbe *v1.Probe
+	StartupProbe   *v1.Probe
+}
+
+// startupProbe returns the probe checking the container has started,
+// before liveness and readiness probes take over
+func startupProbe(handler v1.ProbeHandler) *v1.Probe {
+	return &v1.Probe{
+		ProbeHandler:     handler,
+		TimeoutSeconds:   10,
+		PeriodSeconds:    startupProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: startupProbeFailureThreshold,
+	}
+}
+
+// applyProbesOverrides replaces the probes of the containers of the pod with the user provided ones.
+// Overrides of containers not found in the pod are ignored.
+func applyProbesOverrides(podSpec *v1.PodSpec, overrides []ContainerProbes) {
+	for _, override := range overrides {
+		for idx := range podSpec.Containers {
+			container := &podSpec.Containers[idx]
+			if container.Name != override.ContainerName {
+				continue
+			}
+
+			if override.LivenessProbe != nil {
+				container.LivenessProbe = override.LivenessProbe
+			}
+			if override.ReadinessProbe != nil {
+				container.ReadinessProbe = override.ReadinessProbe
+			}
+			if override.StartupProbe != nil {
+				container.StartupProbe = override.StartupProbe
+			}
+		}
+	}
+}
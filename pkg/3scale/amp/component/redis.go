@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path"
 
+	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/helper"
 	appsv1 "github.com/openshift/api/apps/v1"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -27,11 +28,20 @@ func NewRedis(options *RedisOptions) *Redis {
 }
 
 func (redis *Redis) BackendDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta:   redis.buildDeploymentConfigTypeMeta(),
 		ObjectMeta: redis.buildDeploymentConfigObjectMeta(),
 		Spec:       redis.buildDeploymentConfigSpec(),
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, redis.Options.BackendRedisProbes)
+
+	// Replaced by the redis sentinel StatefulSet
+	if redis.Options.BackendRedisSentinel != nil {
+		common.TagObjectToDelete(dc)
+	}
+
+	return dc
 }
 
 func (redis *Redis) buildDeploymentConfigTypeMeta() metav1.TypeMeta {
@@ -116,6 +126,7 @@ func (redis *Redis) buildPodTemplateSpec() *v1.PodTemplateSpec {
 			Containers:                redis.buildPodContainers(),
 			PriorityClassName:         redis.Options.BackendRedisPriorityClassName,
 			TopologySpreadConstraints: redis.Options.BackendRedisTopologySpreadConstraints,
+			SecurityContext:           redis.Options.BackendRedisPodSecurityContext,
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:      redis.Options.BackendRedisPodTemplateLabels,
@@ -164,6 +175,7 @@ func (redis *Redis) buildPodContainers() []v1.Container {
 			ReadinessProbe:  redis.buildPodContainerReadinessProbe(),
 			LivenessProbe:   redis.buildPodContainerLivenessProbe(),
 			VolumeMounts:    redis.buildPodContainerVolumeMounts(),
+			SecurityContext: redis.Options.BackendRedisContainerSecurityContext,
 		},
 	}
 }
@@ -218,11 +230,18 @@ func (redis *Redis) buildPodContainerVolumeMounts() []v1.VolumeMount {
 }
 
 func (redis *Redis) BackendService() *v1.Service {
-	return &v1.Service{
+	svc := &v1.Service{
 		ObjectMeta: redis.buildServiceObjectMeta(),
 		TypeMeta:   redis.buildServiceTypeMeta(),
 		Spec:       redis.buildServiceSpec(),
 	}
+
+	// Clients connect through the sentinels
+	if redis.Options.BackendRedisSentinel != nil {
+		common.TagObjectToDelete(svc)
+	}
+
+	return svc
 }
 
 func (redis *Redis) buildServiceObjectMeta() metav1.ObjectMeta {
@@ -431,7 +450,7 @@ func (redis *Redis) BackendRedisSecret() *v1.Secret {
 ////// Begin System Redis
 
 func (redis *Redis) SystemDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -531,17 +550,28 @@ func (redis *Redis) SystemDeploymentConfig() *appsv1.DeploymentConfig {
 							},
 							TerminationMessagePath: "/dev/termination-log",
 							ImagePullPolicy:        v1.PullIfNotPresent,
+							SecurityContext:        redis.Options.SystemRedisContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         redis.Options.SystemRedisPriorityClassName,
 					TopologySpreadConstraints: redis.Options.SystemRedisTopologySpreadConstraints,
+					SecurityContext:           redis.Options.SystemRedisPodSecurityContext,
 				}},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, redis.Options.SystemRedisProbes)
+
+	// Replaced by the redis sentinel StatefulSet
+	if redis.Options.SystemRedisSentinel != nil {
+		common.TagObjectToDelete(dc)
+	}
+
+	return dc
 }
 
 func (redis *Redis) SystemService() *v1.Service {
-	return &v1.Service{
+	svc := &v1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
@@ -562,6 +592,13 @@ func (redis *Redis) SystemService() *v1.Service {
 			Selector: map[string]string{"deploymentConfig": "system-redis"},
 		},
 	}
+
+	// Clients connect through the sentinels
+	if redis.Options.SystemRedisSentinel != nil {
+		common.TagObjectToDelete(svc)
+	}
+
+	return svc
 }
 
 func (redis *Redis) SystemPVC() *v1.PersistentVolumeClaim {
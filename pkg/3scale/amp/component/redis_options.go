@@ -1,6 +1,8 @@
 package component
 
 import (
+	"fmt"
+
 	"github.com/go-playground/validator/v10"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -37,6 +39,22 @@ type RedisOptions struct {
 	SystemRedisPodTemplateAnnotations     map[string]string             `validate:"-"`
 	BackendRedisPodTemplateAnnotations    map[string]string             `validate:"-"`
 
+	SystemRedisPodSecurityContext        *v1.PodSecurityContext `validate:"-"`
+	SystemRedisContainerSecurityContext  *v1.SecurityContext    `validate:"-"`
+	BackendRedisPodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	BackendRedisContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	SystemRedisProbes  []ContainerProbes `validate:"-"`
+	BackendRedisProbes []ContainerProbes `validate:"-"`
+
+	// Redis Sentinel topology, nil when a single redis instance is deployed
+	BackendRedisSentinel *RedisSentinelOptions `validate:"-"`
+	SystemRedisSentinel  *RedisSentinelOptions `validate:"-"`
+	// The connection fields of the secrets are reconciled to point
+	// to the sentinels managed by the operator, or back to the single instance
+	BackendRedisSentinelManagedSecret bool
+	SystemRedisSentinelManagedSecret  bool
+
 	// secrets
 	BackendStorageURL                string `validate:"required"`
 	BackendQueuesURL                 string `validate:"required"`
@@ -50,12 +68,25 @@ type RedisOptions struct {
 	SystemRedisNamespace             string
 }
 
+type RedisSentinelOptions struct {
+	Replicas                              int32                    `validate:"gte=3"`
+	SentinelContainerResourceRequirements *v1.ResourceRequirements `validate:"required"`
+}
+
 func NewRedisOptions() *RedisOptions {
 	return &RedisOptions{}
 }
 
 func (r *RedisOptions) Validate() error {
 	validate := validator.New()
+	for _, sentinel := range []*RedisSentinelOptions{r.BackendRedisSentinel, r.SystemRedisSentinel} {
+		if sentinel == nil {
+			continue
+		}
+		if err := validate.Struct(sentinel); err != nil {
+			return err
+		}
+	}
 	return validate.Struct(r)
 }
 
@@ -85,6 +116,23 @@ func DefaultSystemRedisContainerResourceRequirements() *v1.ResourceRequirements
 	}
 }
 
+func DefaultRedisSentinelContainerResourceRequirements() *v1.ResourceRequirements {
+	return &v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("200m"),
+			v1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("50m"),
+			v1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+	}
+}
+
+func DefaultRedisSentinelReplicas() int32 {
+	return 3
+}
+
 func DefaultBackendRedisStorageURL() string {
 	return "redis://backend-redis:6379/0"
 }
@@ -124,3 +172,29 @@ func DefaultBackendQueuesSentinelHosts() string {
 func DefaultBackendQueuesSentinelRole() string {
 	return ""
 }
+
+// With Redis Sentinel, the host of the redis URL is the name of the master group
+
+func DefaultBackendRedisSentinelStorageURL() string {
+	return fmt.Sprintf("redis://%s/0", BackendRedisSentinelMasterName)
+}
+
+func DefaultBackendRedisSentinelQueuesURL() string {
+	return fmt.Sprintf("redis://%s/1", BackendRedisSentinelMasterName)
+}
+
+func DefaultBackendRedisSentinelServiceHosts() string {
+	return fmt.Sprintf("redis://%s:%d", BackendRedisSentinelServiceName, RedisSentinelPort)
+}
+
+func DefaultSystemRedisSentinelURL() string {
+	return fmt.Sprintf("redis://%s/1", SystemRedisSentinelMasterName)
+}
+
+func DefaultSystemRedisSentinelServiceHosts() string {
+	return fmt.Sprintf("redis://%s:%d", SystemRedisSentinelServiceName, RedisSentinelPort)
+}
+
+func DefaultRedisSentinelRole() string {
+	return "master"
+}
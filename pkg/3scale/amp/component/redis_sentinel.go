@@ -0,0 +1,687 @@
+package component
+
+import (
+	"fmt"
+	"strconv"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	BackendRedisSentinelStatefulSetName = "backend-redis-ha"
+	BackendRedisSentinelServiceName     = "backend-redis-sentinel"
+	BackendRedisSentinelMasterName      = "backend-redis"
+	SystemRedisSentinelStatefulSetName  = "system-redis-ha"
+	SystemRedisSentinelServiceName      = "system-redis-sentinel"
+	SystemRedisSentinelMasterName       = "system-redis"
+	BackendRedisSentinelConfigMapName   = "backend-redis-sentinel-config"
+	SystemRedisSentinelConfigMapName    = "system-redis-sentinel-config"
+	RedisSentinelPort                   = 26379
+)
+
+const (
+	redisSentinelContainerName         = "sentinel"
+	redisSentinelRedisContainerName    = "redis"
+	redisSentinelScriptsVolumeName     = "redis-sentinel-scripts"
+	redisSentinelScriptsPath           = "/opt/redis-sentinel/"
+	redisSentinelRuntimeVolumeName     = "redis-sentinel-runtime"
+	redisSentinelRuntimePath           = "/etc/redis-ha.d/"
+	redisSentinelRedisScriptKey        = "redis.sh"
+	redisSentinelSentinelScriptKey     = "sentinel.sh"
+	redisSentinelDataPath              = "/var/lib/redis/data"
+	redisSentinelMasterNameEnvVar      = "REDIS_SENTINEL_MASTER_NAME"
+	redisSentinelServiceEnvVar         = "REDIS_SENTINEL_SERVICE"
+	redisSentinelQuorumEnvVar          = "REDIS_SENTINEL_QUORUM"
+	redisSentinelStatefulSetEnvVar     = "REDIS_STATEFULSET"
+	redisSentinelHeadlessServiceEnvVar = "REDIS_HEADLESS_SERVICE"
+	redisSentinelMigrationSourcePath   = "/var/lib/redis/source"
+)
+
+// redisSentinelInstance holds the settings of one of the redis instances,
+// backend or system, deployed as a StatefulSet of Redis replicas, each one
+// running along with a Sentinel.
+type redisSentinelInstance struct {
+	name              string
+	serviceName       string
+	masterName        string
+	configMapName     string
+	storageVolumeName string
+	// Name of the single instance deployment replaced by the StatefulSet,
+	// which data is migrated to the first replica
+	singleInstanceName        string
+	image                     string
+	labels                    map[string]string
+	podTemplateLabels         map[string]string
+	podTemplateAnnotations    map[string]string
+	affinity                  *v1.Affinity
+	tolerations               []v1.Toleration
+	resources                 *v1.ResourceRequirements
+	priorityClassName         string
+	podSecurityContext        *v1.PodSecurityContext
+	securityContext           *v1.SecurityContext
+	probes                    []ContainerProbes
+	topologySpreadConstraints []v1.TopologySpreadConstraint
+	storageClass              *string
+	options                   *RedisSentinelOptions
+}
+
+func (redis *Redis) backendSentinelInstance() *redisSentinelInstance {
+	return &redisSentinelInstance{
+		name:                      BackendRedisSentinelStatefulSetName,
+		serviceName:               BackendRedisSentinelServiceName,
+		masterName:                BackendRedisSentinelMasterName,
+		configMapName:             BackendRedisSentinelConfigMapName,
+		storageVolumeName:         backendRedisStorageVolumeName,
+		singleInstanceName:        backendRedisDCSelectorName,
+		image:                     redis.Options.BackendImage,
+		labels:                    redis.Options.BackendRedisLabels,
+		podTemplateLabels:         redis.Options.BackendRedisPodTemplateLabels,
+		podTemplateAnnotations:    redis.Options.BackendRedisPodTemplateAnnotations,
+		affinity:                  redis.Options.BackendRedisAffinity,
+		tolerations:               redis.Options.BackendRedisTolerations,
+		resources:                 redis.Options.BackendRedisContainerResourceRequirements,
+		priorityClassName:         redis.Options.BackendRedisPriorityClassName,
+		podSecurityContext:        redis.Options.BackendRedisPodSecurityContext,
+		securityContext:           redis.Options.BackendRedisContainerSecurityContext,
+		probes:                    redis.Options.BackendRedisProbes,
+		topologySpreadConstraints: redis.Options.BackendRedisTopologySpreadConstraints,
+		storageClass:              redis.Options.BackendRedisPVCStorageClass,
+		options:                   redis.Options.BackendRedisSentinel,
+	}
+}
+
+func (redis *Redis) systemSentinelInstance() *redisSentinelInstance {
+	return &redisSentinelInstance{
+		name:                      SystemRedisSentinelStatefulSetName,
+		serviceName:               SystemRedisSentinelServiceName,
+		masterName:                SystemRedisSentinelMasterName,
+		configMapName:             SystemRedisSentinelConfigMapName,
+		storageVolumeName:         "system-redis-storage",
+		singleInstanceName:        SystemRedisDeploymentName,
+		image:                     redis.Options.SystemImage,
+		labels:                    redis.Options.SystemRedisLabels,
+		podTemplateLabels:         redis.Options.SystemRedisPodTemplateLabels,
+		podTemplateAnnotations:    redis.Options.SystemRedisPodTemplateAnnotations,
+		affinity:                  redis.Options.SystemRedisAffinity,
+		tolerations:               redis.Options.SystemRedisTolerations,
+		resources:                 redis.Options.SystemRedisContainerResourceRequirements,
+		priorityClassName:         redis.Options.SystemRedisPriorityClassName,
+		podSecurityContext:        redis.Options.SystemRedisPodSecurityContext,
+		securityContext:           redis.Options.SystemRedisContainerSecurityContext,
+		probes:                    redis.Options.SystemRedisProbes,
+		topologySpreadConstraints: redis.Options.SystemRedisTopologySpreadConstraints,
+		storageClass:              redis.Options.SystemRedisPVCStorageClass,
+		options:                   redis.Options.SystemRedisSentinel,
+	}
+}
+
+func (redis *Redis) BackendSentinelStatefulSet() *k8sappsv1.StatefulSet {
+	return redis.backendSentinelInstance().statefulSet()
+}
+
+func (redis *Redis) BackendSentinelHeadlessService() *v1.Service {
+	return redis.backendSentinelInstance().headlessService()
+}
+
+func (redis *Redis) BackendSentinelService() *v1.Service {
+	return redis.backendSentinelInstance().sentinelService()
+}
+
+func (redis *Redis) BackendSentinelPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	return redis.backendSentinelInstance().podDisruptionBudget()
+}
+
+func (redis *Redis) SystemSentinelStatefulSet() *k8sappsv1.StatefulSet {
+	return redis.systemSentinelInstance().statefulSet()
+}
+
+func (redis *Redis) SystemSentinelHeadlessService() *v1.Service {
+	return redis.systemSentinelInstance().headlessService()
+}
+
+func (redis *Redis) SystemSentinelService() *v1.Service {
+	return redis.systemSentinelInstance().sentinelService()
+}
+
+func (redis *Redis) SystemSentinelPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	return redis.systemSentinelInstance().podDisruptionBudget()
+}
+
+func (redis *Redis) BackendSentinelConfigMap() *v1.ConfigMap {
+	return redis.backendSentinelInstance().configMap()
+}
+
+func (redis *Redis) BackendSentinelMigrationPVC() *v1.PersistentVolumeClaim {
+	return redis.backendSentinelInstance().migrationPVC()
+}
+
+func (redis *Redis) BackendSentinelMigrationJob() *batchv1.Job {
+	return redis.backendSentinelInstance().migrationJob()
+}
+
+func (redis *Redis) SystemSentinelConfigMap() *v1.ConfigMap {
+	return redis.systemSentinelInstance().configMap()
+}
+
+func (redis *Redis) SystemSentinelMigrationPVC() *v1.PersistentVolumeClaim {
+	return redis.systemSentinelInstance().migrationPVC()
+}
+
+func (redis *Redis) SystemSentinelMigrationJob() *batchv1.Job {
+	return redis.systemSentinelInstance().migrationJob()
+}
+
+// BackendRedisSecretManagedFields returns the fields of the backend-redis secret
+// kept in sync with the redis topology deployed by the operator
+func (redis *Redis) BackendRedisSecretManagedFields() []string {
+	if !redis.Options.BackendRedisSentinelManagedSecret {
+		return nil
+	}
+	return []string{
+		BackendSecretBackendRedisStorageURLFieldName,
+		BackendSecretBackendRedisQueuesURLFieldName,
+		BackendSecretBackendRedisStorageSentinelHostsFieldName,
+		BackendSecretBackendRedisStorageSentinelRoleFieldName,
+		BackendSecretBackendRedisQueuesSentinelHostsFieldName,
+		BackendSecretBackendRedisQueuesSentinelRoleFieldName,
+	}
+}
+
+// SystemRedisSecretManagedFields returns the fields of the system-redis secret
+// kept in sync with the redis topology deployed by the operator
+func (redis *Redis) SystemRedisSecretManagedFields() []string {
+	if !redis.Options.SystemRedisSentinelManagedSecret {
+		return nil
+	}
+	return []string{
+		SystemSecretSystemRedisURLFieldName,
+		SystemSecretSystemRedisSentinelHosts,
+		SystemSecretSystemRedisSentinelRole,
+	}
+}
+
+func (r *redisSentinelInstance) enabled() bool {
+	return r.options != nil
+}
+
+func (r *redisSentinelInstance) replicas() int32 {
+	if !r.enabled() {
+		return DefaultRedisSentinelReplicas()
+	}
+	return r.options.Replicas
+}
+
+func (r *redisSentinelInstance) selector() map[string]string {
+	return map[string]string{"deploymentConfig": r.name}
+}
+
+func (r *redisSentinelInstance) statefulSet() *k8sappsv1.StatefulSet {
+	replicas := r.replicas()
+
+	podTemplateLabels := map[string]string{}
+	for k, v := range r.podTemplateLabels {
+		podTemplateLabels[k] = v
+	}
+	podTemplateLabels["deploymentConfig"] = r.name
+
+	sts := &k8sappsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.name,
+			Labels: r.labels,
+		},
+		Spec: k8sappsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: r.name,
+			Selector:    &metav1.LabelSelector{MatchLabels: r.selector()},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podTemplateLabels,
+					Annotations: r.podTemplateAnnotations,
+				},
+				Spec: v1.PodSpec{
+					Affinity:                  r.podAffinity(),
+					Tolerations:               r.tolerations,
+					ServiceAccountName:        "amp", //TODO make this configurable via flag
+					Volumes:                   r.volumes(),
+					Containers:                r.containers(),
+					PriorityClassName:         r.priorityClassName,
+					TopologySpreadConstraints: r.topologySpreadConstraints,
+					SecurityContext:           r.podSecurityContext,
+				},
+			},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   r.storageVolumeName,
+						Labels: r.labels,
+					},
+					Spec: v1.PersistentVolumeClaimSpec{
+						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceStorage: resource.MustParse("1Gi"),
+							},
+						},
+						StorageClassName: r.storageClass,
+					},
+				},
+			},
+		},
+	}
+
+	applyProbesOverrides(&sts.Spec.Template.Spec, r.probes)
+
+	if !r.enabled() {
+		common.TagObjectToDelete(sts)
+	}
+
+	return sts
+}
+
+// configMap holds the startup scripts of the replicas and sentinels
+func (r *redisSentinelInstance) configMap() *v1.ConfigMap {
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.configMapName,
+			Labels: r.labels,
+		},
+		Data: map[string]string{
+			redisSentinelRedisScriptKey:    redisSentinelRedisScript,
+			redisSentinelSentinelScriptKey: redisSentinelSentinelScript,
+		},
+	}
+
+	if !r.enabled() {
+		common.TagObjectToDelete(cm)
+	}
+
+	return cm
+}
+
+// migrationPVC is the claim of the data volume of the first replica, claimed
+// before the StatefulSet is created so the data of the single instance is
+// migrated to it. The first replica bootstraps as master, and the other
+// replicas sync from it
+func (r *redisSentinelInstance) migrationPVC() *v1.PersistentVolumeClaim {
+	claimTemplate := r.statefulSet().Spec.VolumeClaimTemplates[0]
+	return &v1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			// Name given by the StatefulSet controller to the claim of the first replica
+			Name:   fmt.Sprintf("%s-%s-0", claimTemplate.Name, r.name),
+			Labels: r.labels,
+		},
+		Spec: claimTemplate.Spec,
+	}
+}
+
+// migrationJob copies the data of the single instance, the claim named as the
+// data volume of the replicas, to the claim of the first replica
+func (r *redisSentinelInstance) migrationJob() *batchv1.Job {
+	var backoffLimit int32 = 3
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-migration", r.name),
+			Labels: r.labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: r.labels,
+				},
+				Spec: v1.PodSpec{
+					ServiceAccountName: "amp", //TODO make this configurable via flag
+					Tolerations:        r.tolerations,
+					PriorityClassName:  r.priorityClassName,
+					SecurityContext:    r.podSecurityContext,
+					RestartPolicy:      v1.RestartPolicyNever,
+					Volumes: []v1.Volume{
+						{
+							Name: "source",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: r.storageVolumeName, ReadOnly: true},
+							},
+						},
+						{
+							Name: r.storageVolumeName,
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: r.migrationPVC().Name},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:            "migrate-redis-data",
+							Image:           r.image,
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Command:         []string{"/bin/bash", "-c", "-e", redisSentinelMigrationScript},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "source", MountPath: redisSentinelMigrationSourcePath, ReadOnly: true},
+								{Name: r.storageVolumeName, MountPath: redisSentinelDataPath},
+							},
+							SecurityContext: r.securityContext,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// podAffinity spreads the redis replicas across nodes, unless an affinity is provided
+func (r *redisSentinelInstance) podAffinity() *v1.Affinity {
+	if r.affinity != nil {
+		return r.affinity
+	}
+
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: v1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: r.selector()},
+						TopologyKey:   v1.LabelHostname,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *redisSentinelInstance) volumes() []v1.Volume {
+	scriptsMode := int32(0755)
+	return []v1.Volume{
+		{
+			Name: redisConfigVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: redisConfigVolumeName},
+					Items: []v1.KeyToPath{
+						{Key: backendRedisConfigMapKey, Path: backendRedisConfigMapKey},
+					},
+				},
+			},
+		},
+		{
+			Name: redisSentinelScriptsVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: r.configMapName},
+					DefaultMode:          &scriptsMode,
+				},
+			},
+		},
+		{
+			Name:         redisSentinelRuntimeVolumeName,
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		},
+	}
+}
+
+func (r *redisSentinelInstance) env() []v1.EnvVar {
+	quorum := r.replicas()/2 + 1
+	return []v1.EnvVar{
+		helper.EnvVarFromValue(redisSentinelMasterNameEnvVar, r.masterName),
+		helper.EnvVarFromValue(redisSentinelServiceEnvVar, r.serviceName),
+		helper.EnvVarFromValue(redisSentinelQuorumEnvVar, strconv.Itoa(int(quorum))),
+		helper.EnvVarFromValue(redisSentinelStatefulSetEnvVar, r.name),
+		helper.EnvVarFromValue(redisSentinelHeadlessServiceEnvVar, r.name),
+	}
+}
+
+func (r *redisSentinelInstance) containers() []v1.Container {
+	sentinelResources := v1.ResourceRequirements{}
+	if r.enabled() {
+		sentinelResources = *r.options.SentinelContainerResourceRequirements
+	}
+
+	scriptsMount := v1.VolumeMount{Name: redisSentinelScriptsVolumeName, MountPath: redisSentinelScriptsPath}
+	runtimeMount := v1.VolumeMount{Name: redisSentinelRuntimeVolumeName, MountPath: redisSentinelRuntimePath}
+
+	return []v1.Container{
+		{
+			Name:            redisSentinelRedisContainerName,
+			Image:           r.image,
+			ImagePullPolicy: v1.PullIfNotPresent,
+			Command:         []string{"container-entrypoint", "bash", redisSentinelScriptsPath + redisSentinelRedisScriptKey},
+			Env:             r.env(),
+			Ports: []v1.ContainerPort{
+				{Name: "redis", ContainerPort: 6379, Protocol: v1.ProtocolTCP},
+			},
+			Resources: *r.resources,
+			VolumeMounts: []v1.VolumeMount{
+				{
+					Name: r.storageVolumeName,
+					// https://github.com/sclorg/redis-container/ images have
+					// redis data directory hardcoded on /var/lib/redis/data
+					MountPath: redisSentinelDataPath,
+				},
+				{Name: redisConfigVolumeName, MountPath: backendRedisConfigPath},
+				scriptsMount,
+				runtimeMount,
+			},
+			ReadinessProbe: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{
+					Exec: &v1.ExecAction{
+						Command: []string{"container-entrypoint", "bash", "-c", "redis-cli ping | grep PONG"},
+					},
+				},
+				InitialDelaySeconds: 10,
+				PeriodSeconds:       10,
+				TimeoutSeconds:      5,
+			},
+			LivenessProbe: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{
+					TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(6379)},
+				},
+				InitialDelaySeconds: 10,
+				PeriodSeconds:       10,
+			},
+			SecurityContext: r.securityContext,
+		},
+		{
+			Name:            redisSentinelContainerName,
+			Image:           r.image,
+			ImagePullPolicy: v1.PullIfNotPresent,
+			Command:         []string{"container-entrypoint", "bash", redisSentinelScriptsPath + redisSentinelSentinelScriptKey},
+			Env:             r.env(),
+			Ports: []v1.ContainerPort{
+				{Name: "sentinel", ContainerPort: RedisSentinelPort, Protocol: v1.ProtocolTCP},
+			},
+			Resources:    sentinelResources,
+			VolumeMounts: []v1.VolumeMount{scriptsMount, runtimeMount},
+			ReadinessProbe: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{
+					Exec: &v1.ExecAction{
+						Command: []string{"container-entrypoint", "bash", "-c", "redis-cli -p " + strconv.Itoa(RedisSentinelPort) + " ping | grep PONG"},
+					},
+				},
+				InitialDelaySeconds: 10,
+				PeriodSeconds:       10,
+				TimeoutSeconds:      5,
+			},
+			LivenessProbe: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{
+					TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(RedisSentinelPort)},
+				},
+				InitialDelaySeconds: 10,
+				PeriodSeconds:       10,
+			},
+			SecurityContext: r.securityContext,
+		},
+	}
+}
+
+// headlessService is the governing service of the StatefulSet, giving a stable
+// hostname to every redis replica
+func (r *redisSentinelInstance) headlessService() *v1.Service {
+	svc := &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.name,
+			Labels: r.labels,
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP:                v1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Ports: []v1.ServicePort{
+				{Name: "redis", Protocol: v1.ProtocolTCP, Port: 6379, TargetPort: intstr.FromInt(6379)},
+				{Name: "sentinel", Protocol: v1.ProtocolTCP, Port: RedisSentinelPort, TargetPort: intstr.FromInt(RedisSentinelPort)},
+			},
+			Selector: r.selector(),
+		},
+	}
+
+	if !r.enabled() {
+		common.TagObjectToDelete(svc)
+	}
+
+	return svc
+}
+
+// sentinelService is the endpoint used by redis clients to discover the current master
+func (r *redisSentinelInstance) sentinelService() *v1.Service {
+	svc := &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.serviceName,
+			Labels: r.labels,
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "sentinel", Protocol: v1.ProtocolTCP, Port: RedisSentinelPort, TargetPort: intstr.FromInt(RedisSentinelPort)},
+			},
+			Selector: r.selector(),
+		},
+	}
+
+	if !r.enabled() {
+		common.TagObjectToDelete(svc)
+	}
+
+	return svc
+}
+
+func (r *redisSentinelInstance) podDisruptionBudget() *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.name,
+			Labels: r.labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: r.selector()},
+			MaxUnavailable: &intstr.IntOrString{IntVal: PDB_MAX_UNAVAILABLE_POD_NUMBER},
+		},
+	}
+
+	if !r.enabled() {
+		common.TagObjectToDelete(pdb)
+	}
+
+	return pdb
+}
+
+// The snapshot and the append only file of the single instance, written on
+// shutdown, are copied along with a marker, so a job interrupted before the
+// marker is written starts over. The StatefulSet is not created before the job
+// has succeeded, so the data of the first replica is only written by the job
+const redisSentinelMigrationScript = `
+SOURCE='` + redisSentinelMigrationSourcePath + `';
+TARGET='` + redisSentinelDataPath + `';
+if [ -e "${TARGET}/.migrated" ]; then echo "data already migrated"; exit 0; fi;
+for f in dump.rdb appendonly.aof appendonlydir; do
+  rm -rf "${TARGET}/${f}";
+  if [ -e "${SOURCE}/${f}" ]; then cp -R "${SOURCE}/${f}" "${TARGET}/${f}"; fi;
+done;
+sync;
+touch "${TARGET}/.migrated";
+`
+
+// The replicas ask the sentinels for the current master. When no sentinel
+// is monitoring the master yet, the first replica bootstraps as master.
+// Replicas announce their stable StatefulSet hostname, so that sentinels keep
+// track of them when the pod IP changes.
+const redisSentinelRedisScript = `#!/bin/bash
+set -e
+
+ANNOUNCE_HOST="$(hostname).${REDIS_HEADLESS_SERVICE}"
+CONF=/etc/redis-ha.d/redis.conf
+
+MASTER="$(timeout 5 redis-cli -h "${REDIS_SENTINEL_SERVICE}" -p 26379 sentinel get-master-addr-by-name "${REDIS_SENTINEL_MASTER_NAME}" 2>/dev/null | head -n 1 || true)"
+if [ -z "${MASTER}" ]; then
+  MASTER="${REDIS_STATEFULSET}-0.${REDIS_HEADLESS_SERVICE}"
+fi
+
+{
+  echo "include /etc/redis.d/redis.conf"
+  echo "replica-announce-ip ${ANNOUNCE_HOST}"
+  echo "replica-announce-port 6379"
+  if [ "${MASTER}" != "${ANNOUNCE_HOST}" ]; then
+    echo "replicaof ${MASTER} 6379"
+  fi
+} > "${CONF}"
+
+exec redis-server "${CONF}"
+`
+
+const redisSentinelSentinelScript = `#!/bin/bash
+set -e
+
+ANNOUNCE_HOST="$(hostname).${REDIS_HEADLESS_SERVICE}"
+CONF=/etc/redis-ha.d/sentinel.conf
+
+MASTER="$(timeout 5 redis-cli -h "${REDIS_SENTINEL_SERVICE}" -p 26379 sentinel get-master-addr-by-name "${REDIS_SENTINEL_MASTER_NAME}" 2>/dev/null | head -n 1 || true)"
+if [ -z "${MASTER}" ]; then
+  MASTER="${REDIS_STATEFULSET}-0.${REDIS_HEADLESS_SERVICE}"
+fi
+
+cat > "${CONF}" <<EOF
+port 26379
+sentinel resolve-hostnames yes
+sentinel announce-hostnames yes
+sentinel announce-ip ${ANNOUNCE_HOST}
+sentinel announce-port 26379
+sentinel monitor ${REDIS_SENTINEL_MASTER_NAME} ${MASTER} 6379 ${REDIS_SENTINEL_QUORUM}
+sentinel down-after-milliseconds ${REDIS_SENTINEL_MASTER_NAME} 5000
+sentinel failover-timeout ${REDIS_SENTINEL_MASTER_NAME} 60000
+sentinel parallel-syncs ${REDIS_SENTINEL_MASTER_NAME} 1
+EOF
+
+exec redis-server "${CONF}" --sentinel
+`
@@ -0,0 +1,184 @@
+package component
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"path"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	BackendSecretBackendRedisStorageSSLCAFieldName   = "REDIS_STORAGE_SSL_CA"
+	BackendSecretBackendRedisStorageSSLCertFieldName = "REDIS_STORAGE_SSL_CERT"
+	BackendSecretBackendRedisStorageSSLKeyFieldName  = "REDIS_STORAGE_SSL_KEY"
+	BackendSecretBackendRedisQueuesSSLCAFieldName    = "REDIS_QUEUES_SSL_CA"
+	BackendSecretBackendRedisQueuesSSLCertFieldName  = "REDIS_QUEUES_SSL_CERT"
+	BackendSecretBackendRedisQueuesSSLKeyFieldName   = "REDIS_QUEUES_SSL_KEY"
+
+	SystemSecretSystemRedisSSLCAFieldName   = "SSL_CA"
+	SystemSecretSystemRedisSSLCertFieldName = "SSL_CERT"
+	SystemSecretSystemRedisSSLKeyFieldName  = "SSL_KEY"
+)
+
+const (
+	BackendRedisTLSVolumeName = "backend-redis-tls"
+	BackendRedisTLSMountPath  = "/tls/backend-redis"
+	SystemRedisTLSVolumeName  = "system-redis-tls"
+	SystemRedisTLSMountPath   = "/tls/system-redis"
+)
+
+// RedisTLSOptions holds the TLS settings of the connection to an external Redis.
+// Redis URLs with the rediss:// scheme enable TLS. The CA and the client certificate
+// are optional and read from fields of the Redis secret.
+type RedisTLSOptions struct {
+	SecretName string
+	// Secret fields holding the PEM encoded material. Empty when not provided.
+	CAField   string
+	CertField string
+	KeyField  string
+}
+
+// redisTLSEnvVarNames are the environment variables used by an image to
+// configure the TLS connection to a Redis database
+type redisTLSEnvVarNames struct {
+	ssl    string
+	caFile string
+	cert   string
+	key    string
+}
+
+var (
+	backendStorageRedisTLSEnvVarNames = redisTLSEnvVarNames{"CONFIG_REDIS_SSL", "CONFIG_REDIS_CA_FILE", "CONFIG_REDIS_CERT", "CONFIG_REDIS_PRIVATE_KEY"}
+	backendQueuesRedisTLSEnvVarNames  = redisTLSEnvVarNames{"CONFIG_QUEUES_SSL", "CONFIG_QUEUES_CA_FILE", "CONFIG_QUEUES_CERT", "CONFIG_QUEUES_PRIVATE_KEY"}
+	systemRedisTLSEnvVarNames         = redisTLSEnvVarNames{"REDIS_SSL", "REDIS_CA_FILE", "REDIS_CLIENT_CERT", "REDIS_PRIVATE_KEY"}
+	systemBackendRedisTLSEnvVarNames  = redisTLSEnvVarNames{"BACKEND_REDIS_SSL", "BACKEND_REDIS_CA_FILE", "BACKEND_REDIS_CLIENT_CERT", "BACKEND_REDIS_PRIVATE_KEY"}
+)
+
+func (n redisTLSEnvVarNames) all() []string {
+	return []string{n.ssl, n.caFile, n.cert, n.key}
+}
+
+// BackendRedisTLSEnvVarNames returns the environment variables of the backend containers
+// configuring the TLS connections to Redis
+func BackendRedisTLSEnvVarNames() []string {
+	return append(backendStorageRedisTLSEnvVarNames.all(), backendQueuesRedisTLSEnvVarNames.all()...)
+}
+
+// SystemRedisTLSEnvVarNames returns the environment variables of the system containers
+// configuring the TLS connections to Redis
+func SystemRedisTLSEnvVarNames() []string {
+	return append(systemRedisTLSEnvVarNames.all(), systemBackendRedisTLSEnvVarNames.all()...)
+}
+
+func redisTLSEnvVars(options *RedisTLSOptions, names redisTLSEnvVarNames, mountPath string) []v1.EnvVar {
+	if options == nil {
+		return nil
+	}
+
+	result := []v1.EnvVar{helper.EnvVarFromValue(names.ssl, "true")}
+	if options.CAField != "" {
+		result = append(result, helper.EnvVarFromValue(names.caFile, path.Join(mountPath, options.CAField)))
+	}
+	if options.CertField != "" && options.KeyField != "" {
+		result = append(result,
+			helper.EnvVarFromValue(names.cert, path.Join(mountPath, options.CertField)),
+			helper.EnvVarFromValue(names.key, path.Join(mountPath, options.KeyField)),
+		)
+	}
+	return result
+}
+
+// redisTLSVolume returns the volume with the TLS material of the given connections to the same Redis secret,
+// or nil when none of them provides any
+func redisTLSVolume(volumeName string, connections ...*RedisTLSOptions) *v1.Volume {
+	var secretName string
+	items := []v1.KeyToPath{}
+	for _, options := range connections {
+		if options == nil {
+			continue
+		}
+		secretName = options.SecretName
+		for _, field := range []string{options.CAField, options.CertField, options.KeyField} {
+			if field != "" {
+				items = append(items, v1.KeyToPath{Key: field, Path: field})
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	return &v1.Volume{
+		Name: volumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: secretName,
+				Items:      items,
+			},
+		},
+	}
+}
+
+// addTLSVolume adds the volume to the pod and mounts it in every container
+func addTLSVolume(podSpec *v1.PodSpec, volume *v1.Volume, mountPath string) {
+	if volume == nil {
+		return
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, *volume)
+	mount := v1.VolumeMount{Name: volume.Name, MountPath: mountPath, ReadOnly: true}
+	for idx := range podSpec.InitContainers {
+		podSpec.InitContainers[idx].VolumeMounts = append(podSpec.InitContainers[idx].VolumeMounts, mount)
+	}
+	for idx := range podSpec.Containers {
+		podSpec.Containers[idx].VolumeMounts = append(podSpec.Containers[idx].VolumeMounts, mount)
+	}
+}
+
+// ValidateRedisTLS validates the TLS material of an external Redis connection.
+// The client certificate and key must be provided together, and only with rediss:// URLs.
+func ValidateRedisTLS(redisURL, ca, cert, key string) error {
+	if ca == "" && cert == "" && key == "" {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(redisURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "rediss" {
+		return fmt.Errorf("TLS material is only used with rediss:// URLs, found '%s://'", parsedURL.Scheme)
+	}
+
+	return validateTLSMaterial(ca, cert, key)
+}
+
+// validateTLSMaterial checks the PEM encoded CA and client certificate, when provided
+func validateTLSMaterial(ca, cert, key string) error {
+	if ca != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(ca)) {
+		return fmt.Errorf("CA does not contain any PEM encoded certificate")
+	}
+
+	if (cert == "") != (key == "") {
+		return fmt.Errorf("client certificate and key must be provided together")
+	}
+	if cert != "" {
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			return fmt.Errorf("invalid client certificate and key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IsRedisTLSURL returns whether the Redis URL requests a TLS connection
+func IsRedisTLSURL(redisURL string) bool {
+	parsedURL, err := url.Parse(redisURL)
+	return err == nil && parsedURL.Scheme == "rediss"
+}
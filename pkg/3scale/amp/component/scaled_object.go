@@ -0,0 +1,77 @@
+package component
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
+)
+
+// ScaledObjectOptions holds the queue based autoscaling configuration of a component
+type ScaledObjectOptions struct {
+	MinReplicas       *int32
+	MaxReplicas       int32
+	TargetQueueLength int64
+	PollingInterval   *int32
+	CooldownPeriod    *int32
+}
+
+// scaledObject returns the KEDA ScaledObject scaling the named DeploymentConfig.
+// When options are not provided, queue based autoscaling is disabled and the object is tagged to be deleted.
+func scaledObject(name string, labels map[string]string, options *ScaledObjectOptions, triggers []kedav1alpha1.ScaleTriggers) *kedav1alpha1.ScaledObject {
+	obj := &kedav1alpha1.ScaledObject{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kedav1alpha1.ScaledObjectKind,
+			APIVersion: kedav1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				APIVersion: "apps.openshift.io/v1",
+				Kind:       "DeploymentConfig",
+				Name:       name,
+			},
+			Triggers: triggers,
+		},
+	}
+
+	if options == nil {
+		common.TagObjectToDelete(obj)
+		return obj
+	}
+
+	maxReplicas := options.MaxReplicas
+	obj.Spec.MinReplicaCount = options.MinReplicas
+	obj.Spec.MaxReplicaCount = &maxReplicas
+	obj.Spec.PollingInterval = options.PollingInterval
+	obj.Spec.CooldownPeriod = options.CooldownPeriod
+
+	return obj
+}
+
+// triggerAuthentication returns the KEDA TriggerAuthentication providing the scaler
+// parameters from secrets. When disabled, the object is tagged to be deleted.
+func triggerAuthentication(name string, labels map[string]string, enabled bool, secretTargetRefs []kedav1alpha1.AuthSecretTargetRef) *kedav1alpha1.TriggerAuthentication {
+	obj := &kedav1alpha1.TriggerAuthentication{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       kedav1alpha1.TriggerAuthenticationKind,
+			APIVersion: kedav1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: kedav1alpha1.TriggerAuthenticationSpec{
+			SecretTargetRef: secretTargetRefs,
+		},
+	}
+
+	if !enabled {
+		common.TagObjectToDelete(obj)
+	}
+
+	return obj
+}
@@ -6,12 +6,15 @@ import (
 	"strconv"
 
 	appsv1 "github.com/openshift/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/3scale/3scale-operator/apis/apps"
+	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/helper"
 )
 
@@ -169,6 +172,7 @@ func (system *System) SystemRedisEnvVars() []v1.EnvVar {
 		helper.EnvVarFromSecret("REDIS_SENTINEL_HOSTS", SystemSecretSystemRedisSecretName, SystemSecretSystemRedisSentinelHosts),
 		helper.EnvVarFromSecret("REDIS_SENTINEL_ROLE", SystemSecretSystemRedisSecretName, SystemSecretSystemRedisSentinelRole),
 	)
+	result = append(result, redisTLSEnvVars(system.Options.RedisTLS, systemRedisTLSEnvVarNames, SystemRedisTLSMountPath)...)
 
 	return result
 }
@@ -179,9 +183,10 @@ func (system *System) buildSystemBaseEnv() []v1.EnvVar {
 	baseEnvConfigMapEnvs := system.getSystemBaseEnvsFromEnvConfigMap()
 	result = append(result, baseEnvConfigMapEnvs...)
 
-	result = append(result,
-		helper.EnvVarFromSecret("DATABASE_URL", SystemSecretSystemDatabaseSecretName, SystemSecretSystemDatabaseURLFieldName),
+	result = append(result, databaseURLEnvVars(SystemSecretSystemDatabaseSecretName, SystemSecretSystemDatabaseURLFieldName,
+		system.Options.DatabaseTLS, SystemDatabaseTLSMountPath)...)
 
+	result = append(result,
 		helper.EnvVarFromSecret("MASTER_DOMAIN", SystemSecretSystemSeedSecretName, SystemSecretSystemSeedMasterDomainFieldName),
 		helper.EnvVarFromSecret("MASTER_USER", SystemSecretSystemSeedSecretName, SystemSecretSystemSeedMasterUserFieldName),
 		helper.EnvVarFromSecret("MASTER_PASSWORD", SystemSecretSystemSeedSecretName, SystemSecretSystemSeedMasterPasswordFieldName),
@@ -317,11 +322,20 @@ func (system *System) buildSystemAppPostHookEnv() []v1.EnvVar {
 }
 
 func (system *System) BackendRedisEnvVars() []v1.EnvVar {
-	return []v1.EnvVar{
+	result := []v1.EnvVar{
 		helper.EnvVarFromSecret("BACKEND_REDIS_URL", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageURLFieldName),
 		helper.EnvVarFromSecret("BACKEND_REDIS_SENTINEL_HOSTS", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageSentinelHostsFieldName),
 		helper.EnvVarFromSecret("BACKEND_REDIS_SENTINEL_ROLE", BackendSecretBackendRedisSecretName, BackendSecretBackendRedisStorageSentinelRoleFieldName),
 	}
+	result = append(result, redisTLSEnvVars(system.Options.BackendRedisTLS, systemBackendRedisTLSEnvVarNames, BackendRedisTLSMountPath)...)
+	return result
+}
+
+// addTLSVolumes mounts the TLS material of the database and the system and backend Redis connections
+func (system *System) addTLSVolumes(podSpec *v1.PodSpec) {
+	addTLSVolume(podSpec, databaseTLSVolume(SystemDatabaseTLSVolumeName, system.Options.DatabaseTLS), SystemDatabaseTLSMountPath)
+	addTLSVolume(podSpec, redisTLSVolume(SystemRedisTLSVolumeName, system.Options.RedisTLS), SystemRedisTLSMountPath)
+	addTLSVolume(podSpec, redisTLSVolume(BackendRedisTLSVolumeName, system.Options.BackendRedisTLS), BackendRedisTLSMountPath)
 }
 
 func (system *System) EnvironmentConfigMap() *v1.ConfigMap {
@@ -535,11 +549,20 @@ func (system *System) volumeNamesForSystemAppPreHookPod() []string {
 	if system.Options.S3FileStorageOptions != nil && system.Options.S3FileStorageOptions.STSEnabled {
 		res = append(res, S3StsCredentialsSecretName)
 	}
+	if databaseTLSVolume(SystemDatabaseTLSVolumeName, system.Options.DatabaseTLS) != nil {
+		res = append(res, SystemDatabaseTLSVolumeName)
+	}
+	if redisTLSVolume(SystemRedisTLSVolumeName, system.Options.RedisTLS) != nil {
+		res = append(res, SystemRedisTLSVolumeName)
+	}
+	if redisTLSVolume(BackendRedisTLSVolumeName, system.Options.BackendRedisTLS) != nil {
+		res = append(res, BackendRedisTLSVolumeName)
+	}
 	return res
 }
 
 func (system *System) AppDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -650,6 +673,8 @@ func (system *System) AppDeploymentConfig() *appsv1.DeploymentConfig {
 							Stdin:           false,
 							StdinOnce:       false,
 							TTY:             false,
+							StartupProbe:    startupProbe(v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromString("master")}}),
+							SecurityContext: system.Options.AppContainerSecurityContext,
 						}, v1.Container{
 							Name:         SystemAppProviderContainerName,
 							Image:        "amp-system:latest",
@@ -695,6 +720,8 @@ func (system *System) AppDeploymentConfig() *appsv1.DeploymentConfig {
 							Stdin:           false,
 							StdinOnce:       false,
 							TTY:             false,
+							StartupProbe:    startupProbe(v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromString("provider")}}),
+							SecurityContext: system.Options.AppContainerSecurityContext,
 						}, v1.Container{
 							Name:         SystemAppDeveloperContainerName,
 							Image:        "amp-system:latest",
@@ -737,14 +764,22 @@ func (system *System) AppDeploymentConfig() *appsv1.DeploymentConfig {
 								FailureThreshold:    10,
 							},
 							ImagePullPolicy: v1.PullIfNotPresent,
+							StartupProbe:    startupProbe(v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromString("developer")}}),
+							SecurityContext: system.Options.AppContainerSecurityContext,
 						},
 					},
 					ServiceAccountName:        "amp",
 					PriorityClassName:         system.Options.AppPriorityClassName,
 					TopologySpreadConstraints: system.Options.AppTopologySpreadConstraints,
+					SecurityContext:           system.Options.AppPodSecurityContext,
 				}},
 		},
 	}
+
+	system.addTLSVolumes(&dc.Spec.Template.Spec)
+	applyProbesOverrides(&dc.Spec.Template.Spec, system.Options.AppProbes)
+
+	return applyPodTemplateOverrides(dc, system.Options.AppPodTemplateOverrides)
 }
 
 func (system *System) FileStorageVolume() v1.Volume {
@@ -820,7 +855,7 @@ func (system *System) SidekiqPodVolumes() []v1.Volume {
 }
 
 func (system *System) SidekiqDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -877,7 +912,8 @@ func (system *System) SidekiqDeploymentConfig() *appsv1.DeploymentConfig {
 								"-c",
 								"bundle exec sh -c \"until rake boot:redis && curl --output /dev/null --silent --fail --head http://system-master:3000/status; do sleep $SLEEP_SECONDS; done\"",
 							},
-							Env: append(system.SystemRedisEnvVars(), helper.EnvVarFromValue("SLEEP_SECONDS", "1")),
+							Env:             append(system.SystemRedisEnvVars(), helper.EnvVarFromValue("SLEEP_SECONDS", "1")),
+							SecurityContext: system.Options.SideKiqContainerSecurityContext,
 						},
 					},
 					Containers: []v1.Container{
@@ -902,14 +938,22 @@ func (system *System) SidekiqDeploymentConfig() *appsv1.DeploymentConfig {
 								SuccessThreshold:    1,
 								TimeoutSeconds:      30,
 							},
+							StartupProbe:    startupProbe(v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(9394)}}),
+							SecurityContext: system.Options.SideKiqContainerSecurityContext,
 						},
 					},
 					ServiceAccountName:        "amp",
 					PriorityClassName:         system.Options.SideKiqPriorityClassName,
 					TopologySpreadConstraints: system.Options.SideKiqTopologySpreadConstraints,
+					SecurityContext:           system.Options.SideKiqPodSecurityContext,
 				}},
 		},
 	}
+
+	system.addTLSVolumes(&dc.Spec.Template.Spec)
+	applyProbesOverrides(&dc.Spec.Template.Spec, system.Options.SideKiqProbes)
+
+	return applyPodTemplateOverrides(dc, system.Options.SideKiqPodTemplateOverrides)
 }
 
 func (system *System) systemStorageVolumeMount(readOnly bool) v1.VolumeMount {
@@ -1087,8 +1131,41 @@ func (system *System) DeveloperService() *v1.Service {
 	}
 }
 
+func (system *System) MasterIngress() *networkingv1.Ingress {
+	return ingress(
+		"system-master",
+		fmt.Sprintf("%s.%s", system.Options.MasterName, system.Options.WildcardDomain),
+		"system-master",
+		"http",
+		system.Options.MasterUILabels,
+		system.Options.IngressOptions,
+	)
+}
+
+func (system *System) ProviderIngress() *networkingv1.Ingress {
+	return ingress(
+		"system-provider",
+		fmt.Sprintf("%s-admin.%s", system.Options.TenantName, system.Options.WildcardDomain),
+		"system-provider",
+		"http",
+		system.Options.ProviderUILabels,
+		system.Options.IngressOptions,
+	)
+}
+
+func (system *System) DeveloperIngress() *networkingv1.Ingress {
+	return ingress(
+		"system-developer",
+		fmt.Sprintf("%s.%s", system.Options.TenantName, system.Options.WildcardDomain),
+		"system-developer",
+		"http",
+		system.Options.DeveloperUILabels,
+		system.Options.IngressOptions,
+	)
+}
+
 func (system *System) MemcachedService() *v1.Service {
-	return &v1.Service{
+	svc := &v1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
@@ -1109,6 +1186,12 @@ func (system *System) MemcachedService() *v1.Service {
 			Selector: map[string]string{"deploymentConfig": "system-memcache"},
 		},
 	}
+
+	if system.Options.ExternalMemcached {
+		common.TagObjectToDelete(svc)
+	}
+
+	return svc
 }
 
 func (system *System) SMTPSecret() *v1.Secret {
@@ -1211,6 +1294,10 @@ func (system *System) AppPodDisruptionBudget() *policyv1.PodDisruptionBudget {
 	}
 }
 
+func (system *System) AppHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return horizontalPodAutoscaler(SystemAppDeploymentName, system.Options.CommonAppLabels, system.Options.AppHPA)
+}
+
 func (system *System) SidekiqPodDisruptionBudget() *policyv1.PodDisruptionBudget {
 	return &policyv1.PodDisruptionBudget{
 		TypeMeta: metav1.TypeMeta{
@@ -118,7 +118,7 @@ func (mysql *SystemMysql) PersistentVolumeClaim() *v1.PersistentVolumeClaim {
 }
 
 func (mysql *SystemMysql) DeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -236,14 +236,20 @@ func (mysql *SystemMysql) DeploymentConfig() *appsv1.DeploymentConfig {
 								FailureThreshold:    0,
 							},
 							ImagePullPolicy: v1.PullIfNotPresent,
+							SecurityContext: mysql.Options.ContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         mysql.Options.PriorityClassName,
 					TopologySpreadConstraints: mysql.Options.TopologySpreadConstraints,
+					SecurityContext:           mysql.Options.PodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, mysql.Options.Probes)
+
+	return dc
 }
 
 // Each database is responsible to create the needed secrets for the other components
@@ -28,6 +28,11 @@ type SystemMysqlOptions struct {
 	PriorityClassName             string                        `validate:"-"`
 	TopologySpreadConstraints     []v1.TopologySpreadConstraint `validate:"-"`
 	PodTemplateAnnotations        map[string]string             `validate:"-"`
+
+	PodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	Probes []ContainerProbes `validate:"-"`
 }
 
 func NewSystemMysqlOptions() *SystemMysqlOptions {
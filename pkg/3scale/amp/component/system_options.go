@@ -71,6 +71,10 @@ type SystemOptions struct {
 	WildcardDomain      string  `validate:"required"`
 	SmtpSecretOptions   SystemSMTPSecretOptions
 
+	RedisTLS        *RedisTLSOptions    `validate:"-"`
+	BackendRedisTLS *RedisTLSOptions    `validate:"-"`
+	DatabaseTLS     *DatabaseTLSOptions `validate:"-"`
+
 	AppAffinity        *v1.Affinity    `validate:"-"`
 	AppTolerations     []v1.Toleration `validate:"-"`
 	SidekiqAffinity    *v1.Affinity    `validate:"-"`
@@ -91,6 +95,12 @@ type SystemOptions struct {
 
 	IncludeOracleOptionalSettings bool
 
+	// ExternalMemcached is set when MemcachedServers point to an externally managed memcached
+	ExternalMemcached bool
+
+	IngressOptions *IngressOptions                 `validate:"-"`
+	AppHPA         *HorizontalPodAutoscalerOptions `validate:"-"`
+
 	AppPriorityClassName     string `validate:"-"`
 	SideKiqPriorityClassName string `validate:"-"`
 
@@ -98,6 +108,16 @@ type SystemOptions struct {
 	SideKiqTopologySpreadConstraints []v1.TopologySpreadConstraint `validate:"-"`
 	AppPodTemplateAnnotations        map[string]string             `validate:"-"`
 	SideKiqPodTemplateAnnotations    map[string]string             `validate:"-"`
+	AppPodTemplateOverrides          *PodTemplateOverrides         `validate:"-"`
+	SideKiqPodTemplateOverrides      *PodTemplateOverrides         `validate:"-"`
+
+	AppPodSecurityContext           *v1.PodSecurityContext `validate:"-"`
+	AppContainerSecurityContext     *v1.SecurityContext    `validate:"-"`
+	SideKiqPodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	SideKiqContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	AppProbes     []ContainerProbes `validate:"-"`
+	SideKiqProbes []ContainerProbes `validate:"-"`
 
 	// Used for monitoring objects
 	// Those objects are namespaced. However, objects includes labels, rules and expressions
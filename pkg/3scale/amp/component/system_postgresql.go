@@ -77,7 +77,7 @@ func (p *SystemPostgreSQL) DataPersistentVolumeClaim() *v1.PersistentVolumeClaim
 }
 
 func (p *SystemPostgreSQL) DeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -180,14 +180,20 @@ func (p *SystemPostgreSQL) DeploymentConfig() *appsv1.DeploymentConfig {
 								FailureThreshold:    0,
 							},
 							ImagePullPolicy: v1.PullIfNotPresent,
+							SecurityContext: p.Options.ContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         p.Options.PriorityClassName,
 					TopologySpreadConstraints: p.Options.TopologySpreadConstraints,
+					SecurityContext:           p.Options.PodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, p.Options.Probes)
+
+	return dc
 }
 
 // Each database is responsible to create the needed secrets for the other components
@@ -27,6 +27,11 @@ type SystemPostgreSQLOptions struct {
 	PriorityClassName             string                        `validate:"-"`
 	TopologySpreadConstraints     []v1.TopologySpreadConstraint `validate:"-"`
 	PodTemplateAnnotations        map[string]string             `validate:"-"`
+
+	PodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	Probes []ContainerProbes `validate:"-"`
 }
 
 func NewSystemPostgreSQLOptions() *SystemPostgreSQLOptions {
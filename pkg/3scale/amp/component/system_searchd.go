@@ -49,7 +49,7 @@ func (s *SystemSearchd) Service() *v1.Service {
 }
 
 func (s *SystemSearchd) DeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -135,15 +135,22 @@ func (s *SystemSearchd) DeploymentConfig() *appsv1.DeploymentConfig {
 								SuccessThreshold:    1,
 								FailureThreshold:    3,
 							},
-							Resources: s.Options.ContainerResourceRequirements,
+							StartupProbe:    startupProbe(v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(9306)}}),
+							Resources:       s.Options.ContainerResourceRequirements,
+							SecurityContext: s.Options.ContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         s.Options.PriorityClassName,
 					TopologySpreadConstraints: s.Options.TopologySpreadConstraints,
+					SecurityContext:           s.Options.PodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, s.Options.Probes)
+
+	return applyPodTemplateOverrides(dc, s.Options.PodTemplateOverrides)
 }
 
 func (s *SystemSearchd) PVC() *v1.PersistentVolumeClaim {
@@ -26,6 +26,12 @@ type SystemSearchdOptions struct {
 	PriorityClassName         string                        `validate:"-"`
 	TopologySpreadConstraints []v1.TopologySpreadConstraint `validate:"-"`
 	PodTemplateAnnotations    map[string]string             `validate:"-"`
+	PodTemplateOverrides      *PodTemplateOverrides         `validate:"-"`
+
+	PodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	Probes []ContainerProbes `validate:"-"`
 }
 
 func NewSystemSearchdOptions() *SystemSearchdOptions {
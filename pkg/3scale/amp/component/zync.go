@@ -6,6 +6,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 
 	"github.com/3scale/3scale-operator/pkg/helper"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 	appsv1 "github.com/openshift/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -32,6 +33,9 @@ const (
 	ZyncQueMetricsPort = 9394
 )
 
+// zyncQuePendingJobsQuery counts the que jobs waiting to be worked
+const zyncQuePendingJobsQuery = "SELECT count(*) FROM que_jobs WHERE finished_at IS NULL AND expired_at IS NULL AND run_at <= now()"
+
 type Zync struct {
 	Options *ZyncOptions
 }
@@ -164,7 +168,7 @@ func (zync *Zync) QueRole() *rbacv1.Role {
 }
 
 func (zync *Zync) DeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -212,23 +216,13 @@ func (zync *Zync) DeploymentConfig() *appsv1.DeploymentConfig {
 								"bash",
 								"-c",
 								"bundle exec sh -c \"until rake boot:db; do sleep $SLEEP_SECONDS; done\"",
-							}, Env: []v1.EnvVar{
+							}, Env: append([]v1.EnvVar{
 								v1.EnvVar{
 									Name:  "SLEEP_SECONDS",
 									Value: "1",
 								},
-								v1.EnvVar{
-									Name: "DATABASE_URL",
-									ValueFrom: &v1.EnvVarSource{
-										SecretKeyRef: &v1.SecretKeySelector{
-											LocalObjectReference: v1.LocalObjectReference{
-												Name: ZyncSecretName,
-											},
-											Key: ZyncSecretDatabaseURLFieldName,
-										},
-									},
-								},
-							},
+							}, zync.databaseURLEnvVars()...),
+							SecurityContext: zync.Options.ZyncContainerSecurityContext,
 						},
 					},
 					Containers: []v1.Container{
@@ -265,22 +259,42 @@ func (zync *Zync) DeploymentConfig() *appsv1.DeploymentConfig {
 								SuccessThreshold:    1,
 								FailureThreshold:    3,
 							},
-							Resources: zync.Options.ContainerResourceRequirements,
+							StartupProbe: startupProbe(v1.ProbeHandler{
+								HTTPGet: &v1.HTTPGetAction{
+									Port:   intstr.FromInt(8080),
+									Path:   "/status/live",
+									Scheme: v1.URISchemeHTTP,
+								},
+							}),
+							Resources:       zync.Options.ContainerResourceRequirements,
+							SecurityContext: zync.Options.ZyncContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         zync.Options.ZyncPriorityClassName,
 					TopologySpreadConstraints: zync.Options.ZyncTopologySpreadConstraints,
+					SecurityContext:           zync.Options.ZyncPodSecurityContext,
 				},
 			},
 		},
 	}
+
+	addTLSVolume(&dc.Spec.Template.Spec, databaseTLSVolume(ZyncDatabaseTLSVolumeName, zync.Options.DatabaseTLS), ZyncDatabaseTLSMountPath)
+	applyProbesOverrides(&dc.Spec.Template.Spec, zync.Options.ZyncProbes)
+
+	return applyPodTemplateOverrides(dc, zync.Options.ZyncPodTemplateOverrides)
+}
+
+func (zync *Zync) databaseURLEnvVars() []v1.EnvVar {
+	return databaseURLEnvVars(ZyncSecretName, ZyncSecretDatabaseURLFieldName, zync.Options.DatabaseTLS, ZyncDatabaseTLSMountPath)
 }
 
 func (zync *Zync) commonZyncEnvVars() []v1.EnvVar {
-	return []v1.EnvVar{
+	result := []v1.EnvVar{
 		helper.EnvVarFromValue("RAILS_LOG_TO_STDOUT", "true"),
 		helper.EnvVarFromValue("RAILS_ENV", "production"),
-		helper.EnvVarFromSecret("DATABASE_URL", "zync", "DATABASE_URL"),
+	}
+	result = append(result, zync.databaseURLEnvVars()...)
+	return append(result,
 		helper.EnvVarFromSecret("SECRET_KEY_BASE", "zync", "SECRET_KEY_BASE"),
 		helper.EnvVarFromSecret("ZYNC_AUTHENTICATION_TOKEN", "zync", "ZYNC_AUTHENTICATION_TOKEN"),
 		v1.EnvVar{
@@ -301,10 +315,10 @@ func (zync *Zync) commonZyncEnvVars() []v1.EnvVar {
 				},
 			},
 		},
-	}
+	)
 }
 func (zync *Zync) QueDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -382,23 +396,37 @@ func (zync *Zync) QueDeploymentConfig() *appsv1.DeploymentConfig {
 									},
 								},
 							},
+							StartupProbe: startupProbe(v1.ProbeHandler{
+								HTTPGet: &v1.HTTPGetAction{
+									Port:   intstr.FromInt(9394),
+									Path:   "/metrics",
+									Scheme: v1.URISchemeHTTP,
+								},
+							}),
 							Ports: []v1.ContainerPort{
 								v1.ContainerPort{Name: "metrics", ContainerPort: ZyncQueMetricsPort, Protocol: v1.ProtocolTCP},
 							},
-							Resources: zync.Options.QueContainerResourceRequirements,
-							Env:       zync.commonZyncEnvVars(),
+							Resources:       zync.Options.QueContainerResourceRequirements,
+							Env:             zync.commonZyncEnvVars(),
+							SecurityContext: zync.Options.ZyncQueContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         zync.Options.ZyncQuePriorityClassName,
 					TopologySpreadConstraints: zync.Options.ZyncQueTopologySpreadConstraints,
+					SecurityContext:           zync.Options.ZyncQuePodSecurityContext,
 				},
 			},
 		},
 	}
+
+	addTLSVolume(&dc.Spec.Template.Spec, databaseTLSVolume(ZyncDatabaseTLSVolumeName, zync.Options.DatabaseTLS), ZyncDatabaseTLSMountPath)
+	applyProbesOverrides(&dc.Spec.Template.Spec, zync.Options.ZyncQueProbes)
+
+	return applyPodTemplateOverrides(dc, zync.Options.ZyncQuePodTemplateOverrides)
 }
 
 func (zync *Zync) DatabaseDeploymentConfig() *appsv1.DeploymentConfig {
-	return &appsv1.DeploymentConfig{
+	dc := &appsv1.DeploymentConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DeploymentConfig",
 			APIVersion: "apps.openshift.io/v1",
@@ -494,7 +522,8 @@ func (zync *Zync) DatabaseDeploymentConfig() *appsv1.DeploymentConfig {
 									},
 								},
 							},
-							Resources: zync.Options.DatabaseContainerResourceRequirements,
+							Resources:       zync.Options.DatabaseContainerResourceRequirements,
+							SecurityContext: zync.Options.ZyncDatabaseContainerSecurityContext,
 						},
 					},
 					PriorityClassName:         zync.Options.ZyncDatabasePriorityClassName,
@@ -509,10 +538,15 @@ func (zync *Zync) DatabaseDeploymentConfig() *appsv1.DeploymentConfig {
 							},
 						},
 					},
+					SecurityContext: zync.Options.ZyncDatabasePodSecurityContext,
 				},
 			},
 		},
 	}
+
+	applyProbesOverrides(&dc.Spec.Template.Spec, zync.Options.ZyncDatabaseProbes)
+
+	return dc
 }
 
 func (zync *Zync) Service() *v1.Service {
@@ -601,6 +635,37 @@ func (zync *Zync) QuePodDisruptionBudget() *policyv1.PodDisruptionBudget {
 	}
 }
 
+func (zync *Zync) QueScaledObject() *kedav1alpha1.ScaledObject {
+	var triggers []kedav1alpha1.ScaleTriggers
+	if zync.Options.ZyncQueScaledObject != nil {
+		triggers = []kedav1alpha1.ScaleTriggers{
+			{
+				Type: "postgresql",
+				Name: "pending-jobs",
+				Metadata: map[string]string{
+					"query":            zyncQuePendingJobsQuery,
+					"targetQueryValue": fmt.Sprintf("%d", zync.Options.ZyncQueScaledObject.TargetQueueLength),
+				},
+				AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: ZyncQueDeploymentName},
+			},
+		}
+	}
+
+	return scaledObject(ZyncQueDeploymentName, zync.Options.CommonZyncQueLabels, zync.Options.ZyncQueScaledObject, triggers)
+}
+
+func (zync *Zync) QueTriggerAuthentication() *kedav1alpha1.TriggerAuthentication {
+	return triggerAuthentication(ZyncQueDeploymentName, zync.Options.CommonZyncQueLabels, zync.Options.ZyncQueScaledObject != nil,
+		[]kedav1alpha1.AuthSecretTargetRef{
+			{
+				Parameter: "connection",
+				Name:      ZyncSecretName,
+				Key:       ZyncSecretDatabaseURLFieldName,
+			},
+		},
+	)
+}
+
 func (zync *Zync) zyncPorts() []v1.ContainerPort {
 	ports := []v1.ContainerPort{
 		v1.ContainerPort{ContainerPort: 8080, Protocol: v1.ProtocolTCP},
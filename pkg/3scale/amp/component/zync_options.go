@@ -22,6 +22,8 @@ type ZyncOptions struct {
 	SecretKeyBase                         string                  `validate:"required"`
 	ZyncReplicas                          int32
 	ZyncQueReplicas                       int32
+	ZyncQueScaledObject                   *ScaledObjectOptions `validate:"-"`
+	DatabaseTLS                           *DatabaseTLSOptions  `validate:"-"`
 
 	ZyncAffinity            *v1.Affinity    `validate:"-"`
 	ZyncTolerations         []v1.Toleration `validate:"-"`
@@ -53,6 +55,20 @@ type ZyncOptions struct {
 	ZyncQuePodTemplateAnnotations      map[string]string `validate:"-"`
 	ZyncDatabasePodTemplateAnnotations map[string]string `validate:"-"`
 
+	ZyncPodTemplateOverrides    *PodTemplateOverrides `validate:"-"`
+	ZyncQuePodTemplateOverrides *PodTemplateOverrides `validate:"-"`
+
+	ZyncPodSecurityContext               *v1.PodSecurityContext `validate:"-"`
+	ZyncContainerSecurityContext         *v1.SecurityContext    `validate:"-"`
+	ZyncQuePodSecurityContext            *v1.PodSecurityContext `validate:"-"`
+	ZyncQueContainerSecurityContext      *v1.SecurityContext    `validate:"-"`
+	ZyncDatabasePodSecurityContext       *v1.PodSecurityContext `validate:"-"`
+	ZyncDatabaseContainerSecurityContext *v1.SecurityContext    `validate:"-"`
+
+	ZyncProbes         []ContainerProbes `validate:"-"`
+	ZyncQueProbes      []ContainerProbes `validate:"-"`
+	ZyncDatabaseProbes []ContainerProbes `validate:"-"`
+
 	// Used for monitoring objects
 	// Those objects are namespaced. However, objects includes labels, rules and expressions
 	// that need namespace filtering because they are "global" once imported
@@ -56,10 +56,12 @@ func (r *AMPImagesReconciler) Reconcile() (reconcile.Result, error) {
 		}
 	}
 
-	// system memcached IS
-	err = r.ReconcileImagestream(ampImages.SystemMemcachedImageStream(), reconcilers.GenericImageStreamMutator)
-	if err != nil {
-		return reconcile.Result{}, err
+	if !r.apiManager.IsExternal(appsv1alpha1.SystemMemcached) {
+		// system memcached IS
+		err = r.ReconcileImagestream(ampImages.SystemMemcachedImageStream(), reconcilers.GenericImageStreamMutator)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	// system searchd IS
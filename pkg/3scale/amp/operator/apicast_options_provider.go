@@ -53,6 +53,9 @@ func (a *ApicastOptionsProvider) GetApicastOptions() (*component.ApicastOptions,
 	a.apicastOptions.StagingPodTemplateLabels = a.stagingPodTemplateLabels()
 	a.apicastOptions.ProductionPodTemplateLabels = a.productionPodTemplateLabels()
 	a.apicastOptions.Namespace = a.apimanager.Namespace
+	a.apicastOptions.TenantName = *a.apimanager.Spec.TenantName
+	a.apicastOptions.WildcardDomain = a.apimanager.Spec.WildcardDomain
+	a.apicastOptions.IngressOptions = ingressOptions(a.apimanager)
 	a.apicastOptions.ProductionWorkers = a.apimanager.Spec.Apicast.ProductionSpec.Workers
 	a.apicastOptions.ProductionLogLevel = a.apimanager.Spec.Apicast.ProductionSpec.LogLevel
 	a.apicastOptions.StagingLogLevel = a.apimanager.Spec.Apicast.StagingSpec.LogLevel
@@ -88,8 +91,11 @@ func (a *ApicastOptionsProvider) GetApicastOptions() (*component.ApicastOptions,
 	a.setResourceRequirementsOptions()
 	a.setNodeAffinityAndTolerationsOptions()
 	a.setReplicas()
+	a.setAutoscaling()
 	a.setPriorityClassNames()
 	a.setTopologySpreadConstraints()
+	a.setSecurityContexts()
+	a.setProbes()
 	a.setPodTemplateAnnotations()
 
 	err := a.setCustomPolicies()
@@ -161,6 +167,10 @@ func (a *ApicastOptionsProvider) setReplicas() {
 	}
 }
 
+func (a *ApicastOptionsProvider) setAutoscaling() {
+	a.apicastOptions.ProductionHPA = horizontalPodAutoscalerOptions(a.apimanager.Spec.Apicast.ProductionSpec.Autoscaling)
+}
+
 func (a *ApicastOptionsProvider) commonLabels() map[string]string {
 	return map[string]string{
 		"app":                  *a.apimanager.Spec.AppLabel,
@@ -531,4 +541,20 @@ func (a *ApicastOptionsProvider) setTopologySpreadConstraints() {
 func (a *ApicastOptionsProvider) setPodTemplateAnnotations() {
 	a.apicastOptions.StagingPodTemplateAnnotations = a.apimanager.Spec.Apicast.StagingSpec.Annotations
 	a.apicastOptions.ProductionPodTemplateAnnotations = a.apimanager.Spec.Apicast.ProductionSpec.Annotations
+	a.apicastOptions.StagingPodTemplateOverrides = podTemplateOverrides(a.apimanager.Spec.Apicast.StagingSpec.PodTemplateOverrides)
+	a.apicastOptions.ProductionPodTemplateOverrides = podTemplateOverrides(a.apimanager.Spec.Apicast.ProductionSpec.PodTemplateOverrides)
+}
+
+func (a *ApicastOptionsProvider) setSecurityContexts() {
+	stagingSpec := a.apimanager.Spec.Apicast.StagingSpec
+	productionSpec := a.apimanager.Spec.Apicast.ProductionSpec
+	a.apicastOptions.StagingPodSecurityContext = podSecurityContext(a.apimanager, stagingSpec.PodSecurityContext)
+	a.apicastOptions.StagingContainerSecurityContext = containerSecurityContext(a.apimanager, stagingSpec.SecurityContext, false)
+	a.apicastOptions.ProductionPodSecurityContext = podSecurityContext(a.apimanager, productionSpec.PodSecurityContext)
+	a.apicastOptions.ProductionContainerSecurityContext = containerSecurityContext(a.apimanager, productionSpec.SecurityContext, false)
+}
+
+func (a *ApicastOptionsProvider) setProbes() {
+	a.apicastOptions.StagingProbes = containerProbes(a.apimanager.Spec.Apicast.StagingSpec.Probes)
+	a.apicastOptions.ProductionProbes = containerProbes(a.apimanager.Spec.Apicast.ProductionSpec.Probes)
 }
@@ -155,6 +155,9 @@ func defaultApicastOptions() *component.ApicastOptions {
 		StagingPodTemplateLabels:           testApicastStagingPodLabels(),
 		ProductionPodTemplateLabels:        testApicastProductionPodLabels(),
 		Namespace:                          namespace,
+		TenantName:                         tenantName,
+		WildcardDomain:                     wildcardDomain,
+		IngressOptions:                     &component.IngressOptions{},
 		ProductionTracingConfig:            &component.APIcastTracingConfig{TracingLibrary: apps.APIcastDefaultTracingLibrary},
 		StagingTracingConfig:               &component.APIcastTracingConfig{TracingLibrary: apps.APIcastDefaultTracingLibrary},
 		StagingAdditionalPodAnnotations:    map[string]string{APIcastEnvironmentCMAnnotation: "788712912"},
@@ -1,14 +1,12 @@
 package operator
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 	"strings"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -62,7 +60,19 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// The configmap read by the workloads is reconciled first, for the content
+	// hashes of their pod templates to be final on creation
+
+	// Environment ConfigMap
+	err = r.ReconcileConfigMap(apicast.EnvironmentConfigMap(), ApicastEnvCMMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	stagingMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -98,6 +108,9 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 
 	// add apicast production env var mutator
 	productionMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -122,7 +135,8 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 		reconcilers.DeploymentConfigPodTemplateAnnotationsMutator,
 	}
 
-	if r.apiManager.Spec.Apicast.ProductionSpec.Replicas != nil {
+	// Replicas are managed by the HorizontalPodAutoscaler when autoscaling is enabled
+	if r.apiManager.Spec.Apicast.ProductionSpec.Replicas != nil && r.apiManager.Spec.Apicast.ProductionSpec.Autoscaling == nil {
 		productionMutators = append(productionMutators, reconcilers.DeploymentConfigReplicasMutator)
 	}
 
@@ -148,8 +162,14 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
-	// Environment ConfigMap
-	err = r.ReconcileConfigMap(apicast.EnvironmentConfigMap(), ApicastEnvCMMutator)
+	// Staging Ingress
+	err = r.ReconcileIngress(apicast.StagingIngress(), reconcilers.IngressMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Production Ingress
+	err = r.ReconcileIngress(apicast.ProductionIngress(), reconcilers.IngressMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -166,6 +186,12 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// Production HPA
+	err = r.ReconcileHorizontalPodAutoscaler(apicast.ProductionHorizontalPodAutoscaler(), reconcilers.GenericHPAMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	sumRate, err := helper.SumRateForOpenshiftVersion(r.Context(), r.Client())
 	if err != nil {
 		return reconcile.Result{}, err
@@ -196,14 +222,6 @@ func (r *ApicastReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
-	res, err := r.reconcileAPImanagerCR(context.TODO())
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-	if res.Requeue {
-		return res, nil
-	}
-
 	return reconcile.Result{}, nil
 }
 
@@ -555,71 +573,6 @@ func apicastPodTemplateEnvConfigMapAnnotationsMutator(desired, existing *appsv1.
 	return updated, nil
 }
 
-func (r *ApicastReconciler) reconcileAPImanagerCR(ctx context.Context) (ctrl.Result, error) {
-
-	changed := false
-	changed, err := r.reconcileApimanagerSecretLabels(ctx)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
-
-	if changed {
-		err = r.Client().Update(ctx, r.apiManager)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	}
-
-	return ctrl.Result{Requeue: changed}, nil
-}
-
-func (r *ApicastReconciler) reconcileApimanagerSecretLabels(ctx context.Context) (bool, error) {
-	secretUIDs, err := r.getSecretUIDs(ctx)
-	if err != nil {
-		return false, err
-	}
-
-	return replaceAPIManagerSecretLabels(r.apiManager, secretUIDs), nil
-}
-
-func (r *ApicastReconciler) getSecretUIDs(ctx context.Context) ([]string, error) {
-	// production custom policy
-	// staging custom policy
-
-	secretKeys := []client.ObjectKey{}
-	if r.apiManager.Spec.Apicast.ProductionSpec.CustomPolicies != nil {
-		for _, customPolicy := range r.apiManager.Spec.Apicast.ProductionSpec.CustomPolicies {
-			secretKeys = append(secretKeys, client.ObjectKey{
-				Name:      customPolicy.SecretRef.Name,
-				Namespace: r.apiManager.Namespace,
-			})
-		}
-	}
-
-	if r.apiManager.Spec.Apicast.StagingSpec.CustomPolicies != nil {
-		for _, customPolicy := range r.apiManager.Spec.Apicast.StagingSpec.CustomPolicies {
-			secretKeys = append(secretKeys, client.ObjectKey{
-				Name:      customPolicy.SecretRef.Name,
-				Namespace: r.apiManager.Namespace,
-			})
-		}
-	}
-
-	uids := []string{}
-	for idx := range secretKeys {
-		secret := &v1.Secret{}
-		secretKey := secretKeys[idx]
-		err := r.Client().Get(ctx, secretKey, secret)
-		r.Logger().V(1).Info("read secret", "objectKey", secretKey, "error", err)
-		if err != nil {
-			return nil, err
-		}
-		uids = append(uids, string(secret.GetUID()))
-	}
-
-	return uids, nil
-}
-
 func Apicast(apimanager *appsv1alpha1.APIManager, cl client.Client) (*component.Apicast, error) {
 	optsProvider := NewApicastOptionsProvider(apimanager, cl)
 	opts, err := optsProvider.GetApicastOptions()
@@ -5,19 +5,31 @@ import (
 	"reflect"
 	"strings"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+)
+
+const (
+	APIManagerSecretLabelPrefix    = "secret.apimanager.apps.3scale.net/"
+	APIManagerConfigMapLabelPrefix = "configmap.apimanager.apps.3scale.net/"
+	APIManagerSecretLabelValue     = "true"
 )
 
 const (
-	APIManagerSecretLabelPrefix = "secret.apimanager.apps.3scale.net/"
-	APIManagerSecretLabelValue  = "true"
+	DefaultHPACPUTargetUtilization int32 = 80
+	DefaultTargetQueueLength       int64 = 10
 )
 
-func apimanagerSecretLabelKey(uid string) string {
-	return fmt.Sprintf("%s%s", APIManagerSecretLabelPrefix, uid)
+func apimanagerWatchedLabelKey(prefix, uid string) string {
+	return fmt.Sprintf("%s%s", prefix, uid)
 }
 
-func replaceAPIManagerSecretLabels(apimanager *appsv1alpha1.APIManager, desiredSecretUIDs []string) bool {
+// replaceAPIManagerWatchedLabels replaces the APIManager labels with the given prefix
+// by the ones of the desired object UIDs. Returns whether the labels changed.
+func replaceAPIManagerWatchedLabels(apimanager *appsv1alpha1.APIManager, prefix string, desiredUIDs []string) bool {
 
 	existingLabels := apimanager.GetLabels()
 
@@ -25,24 +37,180 @@ func replaceAPIManagerSecretLabels(apimanager *appsv1alpha1.APIManager, desiredS
 		existingLabels = map[string]string{}
 	}
 
-	existingSecretLabels := map[string]string{}
+	existingWatchedLabels := map[string]string{}
 
-	// existing Secret UIDs not included in desiredAPIUIDs are deleted
+	// existing UIDs not included in desiredUIDs are deleted
 	for k := range existingLabels {
-		if strings.HasPrefix(k, APIManagerSecretLabelPrefix) {
-			existingSecretLabels[k] = APIManagerSecretLabelValue
+		if strings.HasPrefix(k, prefix) {
+			existingWatchedLabels[k] = APIManagerSecretLabelValue
 			// it is safe to remove keys while looping in range
 			delete(existingLabels, k)
 		}
 	}
 
-	desiredSecretLabels := map[string]string{}
-	for _, uid := range desiredSecretUIDs {
-		desiredSecretLabels[apimanagerSecretLabelKey(uid)] = APIManagerSecretLabelValue
-		existingLabels[apimanagerSecretLabelKey(uid)] = APIManagerSecretLabelValue
+	desiredWatchedLabels := map[string]string{}
+	for _, uid := range desiredUIDs {
+		desiredWatchedLabels[apimanagerWatchedLabelKey(prefix, uid)] = APIManagerSecretLabelValue
+		existingLabels[apimanagerWatchedLabelKey(prefix, uid)] = APIManagerSecretLabelValue
 	}
 
 	apimanager.SetLabels(existingLabels)
 
-	return !reflect.DeepEqual(existingSecretLabels, desiredSecretLabels)
+	return !reflect.DeepEqual(existingWatchedLabels, desiredWatchedLabels)
+}
+
+// podTemplateOverrides converts the podTemplateOverrides of a component spec to component options
+func podTemplateOverrides(spec *appsv1alpha1.PodTemplateOverridesSpec) *component.PodTemplateOverrides {
+	if spec == nil {
+		return nil
+	}
+
+	return &component.PodTemplateOverrides{
+		Env:               spec.Env,
+		Volumes:           spec.Volumes,
+		VolumeMounts:      spec.VolumeMounts,
+		SidecarContainers: spec.SidecarContainers,
+		InitContainers:    spec.InitContainers,
+	}
+}
+
+// containerProbes converts the probes overrides of a component spec to component options
+func containerProbes(specs []appsv1alpha1.ContainerProbesSpec) []component.ContainerProbes {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	probes := make([]component.ContainerProbes, 0, len(specs))
+	for idx := range specs {
+		probes = append(probes, component.ContainerProbes{
+			ContainerName:  specs[idx].Container,
+			LivenessProbe:  specs[idx].LivenessProbe,
+			ReadinessProbe: specs[idx].ReadinessProbe,
+			StartupProbe:   specs[idx].StartupProbe,
+		})
+	}
+	return probes
+}
+
+// podSecurityContext returns the pod security context of a component.
+// The component setting takes precedence over the global preset.
+func podSecurityContext(apimanager *appsv1alpha1.APIManager, podSecurityContext *v1.PodSecurityContext) *v1.PodSecurityContext {
+	if podSecurityContext != nil {
+		return podSecurityContext
+	}
+
+	if !apimanager.IsSecurityContextPresetRestricted() {
+		return nil
+	}
+
+	return &v1.PodSecurityContext{
+		RunAsNonRoot:   &[]bool{true}[0],
+		SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// containerSecurityContext returns the security context of the containers of a component.
+// The component setting takes precedence over the global preset. readOnlyRootFilesystem
+// is only set by the preset when the component image does not write to its root filesystem.
+func containerSecurityContext(apimanager *appsv1alpha1.APIManager, securityContext *v1.SecurityContext, readOnlyRootFilesystem bool) *v1.SecurityContext {
+	if securityContext != nil {
+		return securityContext
+	}
+
+	if !apimanager.IsSecurityContextPresetRestricted() {
+		return nil
+	}
+
+	result := &v1.SecurityContext{
+		AllowPrivilegeEscalation: &[]bool{false}[0],
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+	}
+	if readOnlyRootFilesystem {
+		result.ReadOnlyRootFilesystem = &[]bool{true}[0]
+	}
+
+	return result
+}
+
+func ingressOptions(apimanager *appsv1alpha1.APIManager) *component.IngressOptions {
+	options := &component.IngressOptions{}
+	if apimanager.Spec.Ingress == nil {
+		return options
+	}
+
+	options.IngressClassName = apimanager.Spec.Ingress.IngressClassName
+	options.Annotations = apimanager.Spec.Ingress.Annotations
+	if apimanager.Spec.Ingress.TLSSecretRef != nil {
+		options.TLSSecretName = &apimanager.Spec.Ingress.TLSSecretRef.Name
+	}
+
+	return options
+}
+
+func horizontalPodAutoscalerOptions(spec *appsv1alpha1.AutoscalingSpec) *component.HorizontalPodAutoscalerOptions {
+	if spec == nil {
+		return nil
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if spec.CPUTargetUtilization != nil {
+		metrics = append(metrics, resourceUtilizationMetric(v1.ResourceCPU, *spec.CPUTargetUtilization))
+	}
+	if spec.MemoryTargetUtilization != nil {
+		metrics = append(metrics, resourceUtilizationMetric(v1.ResourceMemory, *spec.MemoryTargetUtilization))
+	}
+	metrics = append(metrics, spec.Metrics...)
+
+	// Same defaults applied by the API server, set explicitly to be able to reconcile them
+	if len(metrics) == 0 {
+		metrics = append(metrics, resourceUtilizationMetric(v1.ResourceCPU, DefaultHPACPUTargetUtilization))
+	}
+	minReplicas := int32(1)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+
+	return &component.HorizontalPodAutoscalerOptions{
+		MinReplicas: &minReplicas,
+		MaxReplicas: spec.MaxReplicas,
+		Metrics:     metrics,
+	}
+}
+
+func scaledObjectOptions(spec *appsv1alpha1.QueueAutoscalingSpec) *component.ScaledObjectOptions {
+	if spec == nil {
+		return nil
+	}
+
+	minReplicas := int32(1)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+	targetQueueLength := DefaultTargetQueueLength
+	if spec.TargetQueueLength != nil {
+		targetQueueLength = *spec.TargetQueueLength
+	}
+
+	return &component.ScaledObjectOptions{
+		MinReplicas:       &minReplicas,
+		MaxReplicas:       spec.MaxReplicas,
+		TargetQueueLength: targetQueueLength,
+		PollingInterval:   spec.PollingInterval,
+		CooldownPeriod:    spec.CooldownPeriod,
+	}
+}
+
+func resourceUtilizationMetric(name v1.ResourceName, averageUtilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &averageUtilization,
+			},
+		},
+	}
 }
@@ -2,6 +2,10 @@ package operator
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
@@ -43,8 +47,19 @@ func (o *OperatorBackendOptionsProvider) GetBackendOptions() (*component.Backend
 	o.setResourceRequirementsOptions()
 	o.setNodeAffinityAndTolerationsOptions()
 	o.setReplicas()
+	o.setAutoscaling()
+	err = o.setWorkerRedisQueuesOptions()
+	if err != nil {
+		return nil, fmt.Errorf("GetBackendOptions reading redis queues options: %w", err)
+	}
+	err = o.setRedisTLSOptions()
+	if err != nil {
+		return nil, fmt.Errorf("GetBackendOptions reading redis TLS options: %w", err)
+	}
 	o.setPriorityClassNames()
 	o.setTopologySpreadConstraints()
+	o.setSecurityContexts()
+	o.setProbes()
 	o.setPodTemplateAnnotations()
 
 	o.backendOptions.CommonLabels = o.commonLabels()
@@ -58,6 +73,7 @@ func (o *OperatorBackendOptionsProvider) GetBackendOptions() (*component.Backend
 	o.backendOptions.WorkerMetrics = true
 	o.backendOptions.ListenerMetrics = true
 	o.backendOptions.Namespace = o.apimanager.Namespace
+	o.backendOptions.IngressOptions = ingressOptions(o.apimanager)
 
 	err = o.backendOptions.Validate()
 	if err != nil {
@@ -161,6 +177,110 @@ func (o *OperatorBackendOptionsProvider) setReplicas() {
 	}
 }
 
+func (o *OperatorBackendOptionsProvider) setAutoscaling() {
+	o.backendOptions.ListenerHPA = horizontalPodAutoscalerOptions(o.apimanager.Spec.Backend.ListenerSpec.Autoscaling)
+	o.backendOptions.WorkerHPA = horizontalPodAutoscalerOptions(o.apimanager.Spec.Backend.WorkerSpec.Autoscaling)
+	o.backendOptions.WorkerScaledObject = scaledObjectOptions(o.apimanager.Spec.Backend.WorkerSpec.QueueAutoscaling)
+}
+
+// setRedisTLSOptions reads the TLS settings of the connections to an external Redis
+func (o *OperatorBackendOptionsProvider) setRedisTLSOptions() error {
+	if !o.apimanager.IsExternal(appsv1alpha1.BackendRedis) {
+		return nil
+	}
+
+	var err error
+	o.backendOptions.StorageRedisTLS, err = externalRedisTLSOptions(o.secretSource, backendRedisStorageTLSSecretFields)
+	if err != nil {
+		return err
+	}
+
+	o.backendOptions.QueuesRedisTLS, err = externalRedisTLSOptions(o.secretSource, backendRedisQueuesTLSSecretFields)
+	return err
+}
+
+// setWorkerRedisQueuesOptions reads the connection details of the queues
+// Redis database used by the backend-worker queue scaler
+func (o *OperatorBackendOptionsProvider) setWorkerRedisQueuesOptions() error {
+	if o.backendOptions.WorkerScaledObject == nil {
+		return nil
+	}
+
+	sentinelHosts, err := o.secretSource.FieldValue(component.BackendSecretBackendRedisSecretName,
+		component.BackendSecretBackendRedisQueuesSentinelHostsFieldName, "")
+	if err != nil {
+		return err
+	}
+
+	queuesURL, err := o.secretSource.FieldValue(component.BackendSecretBackendRedisSecretName,
+		component.BackendSecretBackendRedisQueuesURLFieldName, component.DefaultBackendRedisQueuesURL())
+	if err != nil {
+		return err
+	}
+
+	redisURL, err := url.Parse(queuesURL)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", component.BackendSecretBackendRedisQueuesURLFieldName, err)
+	}
+
+	port := redisURL.Port()
+	if port == "" {
+		port = "6379"
+	}
+	databaseIndex := strings.TrimPrefix(redisURL.Path, "/")
+	if databaseIndex == "" {
+		databaseIndex = "0"
+	}
+
+	o.backendOptions.WorkerRedisQueues = component.BackendRedisQueuesOptions{
+		Address:       net.JoinHostPort(redisURL.Hostname(), port),
+		DatabaseIndex: databaseIndex,
+		EnableTLS:     redisURL.Scheme == "rediss",
+	}
+	// Same credentials as those checked by the preflight checks
+	o.backendOptions.WorkerRedisQueues.Username, o.backendOptions.WorkerRedisQueues.Password = urlCredentials(redisURL)
+
+	// With sentinels, the host of the queues URL is the name of the master group
+	if strings.TrimSpace(sentinelHosts) != "" {
+		addresses, err := sentinelAddresses(sentinelHosts)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", component.BackendSecretBackendRedisQueuesSentinelHostsFieldName, err)
+		}
+		o.backendOptions.WorkerRedisQueues.Address = addresses
+		o.backendOptions.WorkerRedisQueues.SentinelMaster = redisURL.Hostname()
+	}
+
+	return nil
+}
+
+// sentinelAddresses converts a comma separated list of sentinel
+// hosts, as URLs or host:port pairs, to a list of host:port pairs
+func sentinelAddresses(sentinelHosts string) (string, error) {
+	var addresses []string
+	for _, host := range strings.Split(sentinelHosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if !strings.Contains(host, "://") {
+			host = "redis://" + host
+		}
+
+		sentinelURL, err := url.Parse(host)
+		if err != nil {
+			return "", err
+		}
+
+		port := sentinelURL.Port()
+		if port == "" {
+			port = strconv.Itoa(component.RedisSentinelPort)
+		}
+		addresses = append(addresses, net.JoinHostPort(sentinelURL.Hostname(), port))
+	}
+
+	return strings.Join(addresses, ","), nil
+}
+
 func (o *OperatorBackendOptionsProvider) commonLabels() map[string]string {
 	return map[string]string{
 		"app":                  *o.apimanager.Spec.AppLabel,
@@ -250,6 +370,9 @@ func (o *OperatorBackendOptionsProvider) setPodTemplateAnnotations() {
 	o.backendOptions.ListenerPodTemplateAnnotations = o.apimanager.Spec.Backend.ListenerSpec.Annotations
 	o.backendOptions.WorkerPodTemplateAnnotations = o.apimanager.Spec.Backend.WorkerSpec.Annotations
 	o.backendOptions.CronPodTemplateAnnotations = o.apimanager.Spec.Backend.CronSpec.Annotations
+	o.backendOptions.ListenerPodTemplateOverrides = podTemplateOverrides(o.apimanager.Spec.Backend.ListenerSpec.PodTemplateOverrides)
+	o.backendOptions.WorkerPodTemplateOverrides = podTemplateOverrides(o.apimanager.Spec.Backend.WorkerSpec.PodTemplateOverrides)
+	o.backendOptions.CronPodTemplateOverrides = podTemplateOverrides(o.apimanager.Spec.Backend.CronSpec.PodTemplateOverrides)
 }
 
 func (o *OperatorBackendOptionsProvider) setTopologySpreadConstraints() {
@@ -263,3 +386,19 @@ func (o *OperatorBackendOptionsProvider) setTopologySpreadConstraints() {
 		o.backendOptions.TopologySpreadConstraintsCron = o.apimanager.Spec.Backend.CronSpec.TopologySpreadConstraints
 	}
 }
+
+func (o *OperatorBackendOptionsProvider) setSecurityContexts() {
+	backendSpec := o.apimanager.Spec.Backend
+	o.backendOptions.ListenerPodSecurityContext = podSecurityContext(o.apimanager, backendSpec.ListenerSpec.PodSecurityContext)
+	o.backendOptions.ListenerContainerSecurityContext = containerSecurityContext(o.apimanager, backendSpec.ListenerSpec.SecurityContext, false)
+	o.backendOptions.WorkerPodSecurityContext = podSecurityContext(o.apimanager, backendSpec.WorkerSpec.PodSecurityContext)
+	o.backendOptions.WorkerContainerSecurityContext = containerSecurityContext(o.apimanager, backendSpec.WorkerSpec.SecurityContext, false)
+	o.backendOptions.CronPodSecurityContext = podSecurityContext(o.apimanager, backendSpec.CronSpec.PodSecurityContext)
+	o.backendOptions.CronContainerSecurityContext = containerSecurityContext(o.apimanager, backendSpec.CronSpec.SecurityContext, false)
+}
+
+func (o *OperatorBackendOptionsProvider) setProbes() {
+	o.backendOptions.ListenerProbes = containerProbes(o.apimanager.Spec.Backend.ListenerSpec.Probes)
+	o.backendOptions.WorkerProbes = containerProbes(o.apimanager.Spec.Backend.WorkerSpec.Probes)
+	o.backendOptions.CronProbes = containerProbes(o.apimanager.Spec.Backend.CronSpec.Probes)
+}
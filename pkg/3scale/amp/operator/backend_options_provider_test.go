@@ -11,6 +11,7 @@ import (
 	"github.com/3scale/3scale-operator/pkg/helper"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -207,6 +208,7 @@ func defaultBackendOptions(opts *component.BackendOptions) *component.BackendOpt
 		CronPodTemplateLabels:        testBackendCronPodLabels(),
 		WorkerMetrics:                true,
 		ListenerMetrics:              true,
+		IngressOptions:               &component.IngressOptions{},
 		Namespace:                    opts.Namespace,
 	}
 }
@@ -272,6 +274,32 @@ func TestGetBackendOptionsProvider(t *testing.T) {
 				return opts
 			},
 		},
+		{"WithAutoscaling", nil, nil,
+			func() *appsv1alpha1.APIManager {
+				apimanager := basicApimanagerTestBackendOptions()
+				apimanager.Spec.Backend.ListenerSpec.Autoscaling = &appsv1alpha1.AutoscalingSpec{
+					MinReplicas:             &[]int32{2}[0],
+					MaxReplicas:             5,
+					MemoryTargetUtilization: &[]int32{70}[0],
+				}
+				apimanager.Spec.Backend.WorkerSpec.Autoscaling = &appsv1alpha1.AutoscalingSpec{MaxReplicas: 3}
+				return apimanager
+			},
+			func(in *component.BackendOptions) *component.BackendOptions {
+				opts := defaultBackendOptions(in)
+				opts.ListenerHPA = &component.HorizontalPodAutoscalerOptions{
+					MinReplicas: &[]int32{2}[0],
+					MaxReplicas: 5,
+					Metrics:     []autoscalingv2.MetricSpec{resourceUtilizationMetric(v1.ResourceMemory, 70)},
+				}
+				opts.WorkerHPA = &component.HorizontalPodAutoscalerOptions{
+					MinReplicas: &[]int32{1}[0],
+					MaxReplicas: 3,
+					Metrics:     []autoscalingv2.MetricSpec{resourceUtilizationMetric(v1.ResourceCPU, DefaultHPACPUTargetUtilization)},
+				}
+				return opts
+			},
+		},
 		{"WithTolerations", nil, nil,
 			func() *appsv1alpha1.APIManager {
 				apimanager := basicApimanagerTestBackendOptions()
@@ -349,3 +377,24 @@ func TestGetBackendOptionsProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestSentinelAddresses(t *testing.T) {
+	cases := []struct {
+		sentinelHosts string
+		expected      string
+	}{
+		{"redis://backend-redis-sentinel:26379", "backend-redis-sentinel:26379"},
+		{"redis://sentinel-0:26380, sentinel-1:26381", "sentinel-0:26380,sentinel-1:26381"},
+		{"sentinel-0,redis://sentinel-1", "sentinel-0:26379,sentinel-1:26379"},
+	}
+
+	for _, tc := range cases {
+		addresses, err := sentinelAddresses(tc.sentinelHosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addresses != tc.expected {
+			t.Errorf("sentinel hosts '%s': expected '%s', got '%s'", tc.sentinelHosts, tc.expected, addresses)
+		}
+	}
+}
@@ -29,8 +29,35 @@ func (r *BackendReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// The secrets and configmaps read by the workloads are reconciled first,
+	// for the content hashes of their pod templates to be final on creation
+
+	// Environment ConfigMap
+	err = r.ReconcileConfigMap(backend.EnvironmentConfigMap(), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Internal API Secret
+	err = r.ReconcileSecret(backend.InternalAPISecretForSystem(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Listener Secret
+	err = r.ReconcileSecret(backend.ListenerSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// TLS connections to external Redis
+	redisTLSMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigEnvVarsMutator(component.BackendRedisTLSEnvVarNames()...),
+		reconcilers.DeploymentConfigVolumesMutator(component.BackendRedisTLSVolumeName),
+	}
+
 	// Cron DC
-	cronConfigMutator := reconcilers.GenericBackendMutators()
+	cronConfigMutator := append(reconcilers.GenericBackendMutators(), redisTLSMutators...)
 	if r.apiManager.Spec.Backend.CronSpec.Replicas != nil {
 		cronConfigMutator = append(cronConfigMutator, reconcilers.DeploymentConfigReplicasMutator)
 	}
@@ -49,12 +76,13 @@ func (r *BackendReconciler) Reconcile() (reconcile.Result, error) {
 	redisQueuesUrl := strings.TrimSuffix(string(backendRedisSecret.Data["REDIS_QUEUES_URL"]), "1")
 	redisStorageUrl := strings.TrimSuffix(string(backendRedisSecret.Data["REDIS_STORAGE_URL"]), "0")
 
-	listenerConfigMutator := reconcilers.GenericBackendMutators()
+	listenerConfigMutator := append(reconcilers.GenericBackendMutators(), redisTLSMutators...)
 	if redisStorageUrl != redisQueuesUrl {
 		listenerConfigMutator = append(listenerConfigMutator, reconcilers.DeploymentConfigListenerEnvMutator)
 		listenerConfigMutator = append(listenerConfigMutator, reconcilers.DeploymentConfigListenerArgsMutator)
 	}
-	if r.apiManager.Spec.Backend.ListenerSpec.Replicas != nil {
+	// Replicas are managed by the HorizontalPodAutoscaler when autoscaling is enabled
+	if r.apiManager.Spec.Backend.ListenerSpec.Replicas != nil && r.apiManager.Spec.Backend.ListenerSpec.Autoscaling == nil {
 		listenerConfigMutator = append(listenerConfigMutator, reconcilers.DeploymentConfigReplicasMutator)
 	}
 
@@ -75,12 +103,21 @@ func (r *BackendReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// Listener Ingress
+	err = r.ReconcileIngress(backend.ListenerIngress(), reconcilers.IngressMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Worker DC
-	workerConfigMutator := reconcilers.GenericBackendMutators()
+	workerConfigMutator := append(reconcilers.GenericBackendMutators(), redisTLSMutators...)
 	if redisStorageUrl != redisQueuesUrl {
 		workerConfigMutator = append(workerConfigMutator, reconcilers.DeploymentConfigWorkerEnvMutator)
 	}
-	if r.apiManager.Spec.Backend.WorkerSpec.Replicas != nil {
+	// Replicas are managed by the HorizontalPodAutoscaler or the KEDA ScaledObject when autoscaling is enabled
+	if r.apiManager.Spec.Backend.WorkerSpec.Replicas != nil &&
+		r.apiManager.Spec.Backend.WorkerSpec.Autoscaling == nil &&
+		r.apiManager.Spec.Backend.WorkerSpec.QueueAutoscaling == nil {
 		workerConfigMutator = append(workerConfigMutator, reconcilers.DeploymentConfigReplicasMutator)
 	}
 
@@ -89,38 +126,50 @@ func (r *BackendReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
-	// Environment ConfigMap
-	err = r.ReconcileConfigMap(backend.EnvironmentConfigMap(), reconcilers.CreateOnlyMutator)
+	// Worker PDB
+	err = r.ReconcilePodDisruptionBudget(backend.WorkerPodDisruptionBudget(), reconcilers.GenericPDBMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// Internal API Secret
-	err = r.ReconcileSecret(backend.InternalAPISecretForSystem(), reconcilers.DefaultsOnlySecretMutator)
+	// Cron PDB
+	err = r.ReconcilePodDisruptionBudget(backend.CronPodDisruptionBudget(), reconcilers.GenericPDBMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// Listener Secret
-	err = r.ReconcileSecret(backend.ListenerSecret(), reconcilers.DefaultsOnlySecretMutator)
+	// Listener PDB
+	err = r.ReconcilePodDisruptionBudget(backend.ListenerPodDisruptionBudget(), reconcilers.GenericPDBMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// Worker PDB
-	err = r.ReconcilePodDisruptionBudget(backend.WorkerPodDisruptionBudget(), reconcilers.GenericPDBMutator)
+	// Listener HPA
+	err = r.ReconcileHorizontalPodAutoscaler(backend.ListenerHorizontalPodAutoscaler(), reconcilers.GenericHPAMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// Cron PDB
-	err = r.ReconcilePodDisruptionBudget(backend.CronPodDisruptionBudget(), reconcilers.GenericPDBMutator)
+	// Worker HPA
+	err = r.ReconcileHorizontalPodAutoscaler(backend.WorkerHorizontalPodAutoscaler(), reconcilers.GenericHPAMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// Listener PDB
-	err = r.ReconcilePodDisruptionBudget(backend.ListenerPodDisruptionBudget(), reconcilers.GenericPDBMutator)
+	// Worker Redis queues credentials
+	err = r.ReconcileSecret(backend.WorkerRedisQueuesSecret(), reconcilers.SecretDataMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Worker TriggerAuthentication
+	err = r.ReconcileTriggerAuthentication(backend.WorkerTriggerAuthentication(), reconcilers.TriggerAuthenticationMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Worker ScaledObject
+	err = r.ReconcileScaledObject(backend.WorkerScaledObject(), reconcilers.GenericScaledObjectMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
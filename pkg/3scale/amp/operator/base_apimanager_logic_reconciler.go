@@ -2,10 +2,13 @@ package operator
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/helper"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
 	"github.com/go-logr/logr"
@@ -14,9 +17,13 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -26,6 +33,9 @@ type BaseAPIManagerLogicReconciler struct {
 	apiManager           *appsv1alpha1.APIManager
 	logger               logr.Logger
 	crdAvailabilityCache *baseAPIManagerLogicReconcilerCRDAvailabilityCache
+	watchedObjects       *watchedObjects
+	unmanagedObjects     []string
+	desiredObjects       map[string]bool
 }
 
 type baseAPIManagerLogicReconcilerCRDAvailabilityCache struct {
@@ -33,6 +43,7 @@ type baseAPIManagerLogicReconcilerCRDAvailabilityCache struct {
 	prometheusRuleCRDAvailable   *bool
 	podMonitorCRDAvailable       *bool
 	serviceMonitorCRDAvailable   *bool
+	scaledObjectCRDAvailable     *bool
 }
 
 func NewBaseAPIManagerLogicReconciler(b *reconcilers.BaseReconciler, apiManager *appsv1alpha1.APIManager) *BaseAPIManagerLogicReconciler {
@@ -41,6 +52,8 @@ func NewBaseAPIManagerLogicReconciler(b *reconcilers.BaseReconciler, apiManager
 		apiManager:           apiManager,
 		logger:               b.Logger().WithValues("APIManager Controller", apiManager.Name),
 		crdAvailabilityCache: &baseAPIManagerLogicReconcilerCRDAvailabilityCache{},
+		watchedObjects:       &watchedObjects{},
+		desiredObjects:       map[string]bool{},
 	}
 }
 
@@ -55,14 +68,91 @@ func (r *BaseAPIManagerLogicReconciler) ReconcilePodDisruptionBudget(desired *po
 	return r.ReconcileResource(&policyv1.PodDisruptionBudget{}, desired, mutatefn)
 }
 
+func (r *BaseAPIManagerLogicReconciler) ReconcileHorizontalPodAutoscaler(desired *autoscalingv2.HorizontalPodAutoscaler, mutatefn reconcilers.MutateFn) error {
+	// Components are deployed as Deployments instead of DeploymentConfigs
+	if r.apiManager.IsDeploymentWorkloadEnabled() {
+		desired.Spec.ScaleTargetRef.APIVersion = "apps/v1"
+		desired.Spec.ScaleTargetRef.Kind = "Deployment"
+	}
+	return r.ReconcileResource(&autoscalingv2.HorizontalPodAutoscaler{}, desired, mutatefn)
+}
+
 func (r *BaseAPIManagerLogicReconciler) ReconcileImagestream(desired *imagev1.ImageStream, mutatefn reconcilers.MutateFn) error {
+	// Deployments do not make use of image streams
+	if r.apiManager.IsDeploymentWorkloadEnabled() {
+		return nil
+	}
 	return r.ReconcileResource(&imagev1.ImageStream{}, desired, mutatefn)
 }
 
+// ReconcileDeploymentConfig reconciles the workload of a component.
+// When the Deployment workload type is selected, the DeploymentConfig is converted to
+// the equivalent Deployment and reconciled using the DeploymentConfig mutator.
+// Existing DeploymentConfigs are migrated to Deployments, and the rolling strategy pre hook
+// is run as a Job before the Deployment is rolled out.
+// The pod template is annotated with the hash of the secrets and configmaps it consumes.
 func (r *BaseAPIManagerLogicReconciler) ReconcileDeploymentConfig(desired *appsv1.DeploymentConfig, mutatefn reconcilers.MutateFn) error {
+	if !common.IsObjectTaggedToDelete(desired) && desired.Spec.Template != nil {
+		if err := r.setPodTemplateContentHashes(desired.Spec.Template); err != nil {
+			return err
+		}
+		mutatefn = contentHashesMutator(mutatefn)
+	}
+
+	if r.apiManager.IsDeploymentWorkloadEnabled() {
+		images, err := DeploymentImages(r.apiManager)
+		if err != nil {
+			return err
+		}
+
+		deployment, err := helper.DeploymentFromDeploymentConfig(desired, images)
+		if err != nil {
+			return err
+		}
+
+		if !common.IsObjectTaggedToDelete(desired) {
+			// The DeploymentConfig is deleted by the migration, not pruned. The Deployment
+			// is kept while the pre hook runs or the migration waits
+			r.desiredObjects[objectKindName(&appsv1.DeploymentConfig{}, desired.GetName())] = true
+			r.desiredObjects[objectKindName(deployment, deployment.GetName())] = true
+
+			// Rollouts wait for the pre hook, which is not part of the Deployment
+			preHookSucceeded, err := r.reconcileDeploymentPreHook(desired, images)
+			if err != nil {
+				return err
+			}
+			if !preHookSucceeded {
+				return nil
+			}
+
+			keepDeploymentConfig, err := r.reconcileDeploymentConfigMigration(deployment)
+			if err != nil {
+				return err
+			}
+			if keepDeploymentConfig {
+				return r.ReconcileResource(&appsv1.DeploymentConfig{}, desired, mutatefn)
+			}
+		}
+
+		return r.ReconcileDeployment(deployment, reconcilers.DeploymentFromDeploymentConfigMutator(mutatefn))
+	}
 	return r.ReconcileResource(&appsv1.DeploymentConfig{}, desired, mutatefn)
 }
 
+func (r *BaseAPIManagerLogicReconciler) ReconcileDeployment(desired *k8sappsv1.Deployment, mutatefn reconcilers.MutateFn) error {
+	return r.ReconcileResource(&k8sappsv1.Deployment{}, desired, mutatefn)
+}
+
+func (r *BaseAPIManagerLogicReconciler) ReconcileStatefulSet(desired *k8sappsv1.StatefulSet, mutatefn reconcilers.MutateFn) error {
+	if !common.IsObjectTaggedToDelete(desired) {
+		if err := r.setPodTemplateContentHashes(&desired.Spec.Template); err != nil {
+			return err
+		}
+		mutatefn = contentHashesMutator(mutatefn)
+	}
+	return r.ReconcileResource(&k8sappsv1.StatefulSet{}, desired, mutatefn)
+}
+
 func (r *BaseAPIManagerLogicReconciler) ReconcileService(desired *v1.Service, mutateFn reconcilers.MutateFn) error {
 	return r.ReconcileResource(&v1.Service{}, desired, mutateFn)
 }
@@ -76,7 +166,30 @@ func (r *BaseAPIManagerLogicReconciler) ReconcileServiceAccount(desired *v1.Serv
 }
 
 func (r *BaseAPIManagerLogicReconciler) ReconcileRoute(desired *routev1.Route, mutateFn reconcilers.MutateFn) error {
-	return r.ReconcileResource(&routev1.Route{}, desired, mutateFn)
+	// Components are exposed with Ingress objects instead
+	if r.apiManager.IsIngressEnabled() {
+		common.TagObjectToDelete(desired)
+	}
+	err := r.ReconcileResource(&routev1.Route{}, desired, mutateFn)
+	// Clusters without the Route API have no routes to delete
+	if r.apiManager.IsIngressEnabled() && meta.IsNoMatchError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *BaseAPIManagerLogicReconciler) ReconcileIngress(desired *networkingv1.Ingress, mutateFn reconcilers.MutateFn) error {
+	if !r.apiManager.IsIngressEnabled() {
+		common.TagObjectToDelete(desired)
+	}
+	return r.ReconcileResource(&networkingv1.Ingress{}, desired, mutateFn)
+}
+
+func (r *BaseAPIManagerLogicReconciler) ReconcileNetworkPolicy(desired *networkingv1.NetworkPolicy, mutateFn reconcilers.MutateFn) error {
+	if !r.apiManager.IsNetworkPoliciesEnabled() {
+		common.TagObjectToDelete(desired)
+	}
+	return r.ReconcileResource(&networkingv1.NetworkPolicy{}, desired, mutateFn)
 }
 
 func (r *BaseAPIManagerLogicReconciler) ReconcileSecret(desired *v1.Secret, mutateFn reconcilers.MutateFn) error {
@@ -179,6 +292,43 @@ func (r *BaseAPIManagerLogicReconciler) ReconcilePodMonitor(desired *monitoringv
 	return r.ReconcileResource(&monitoringv1.PodMonitor{}, desired, mutateFn)
 }
 
+func (r *BaseAPIManagerLogicReconciler) ReconcileScaledObject(desired *kedav1alpha1.ScaledObject, mutateFn reconcilers.MutateFn) error {
+	kindExists, err := r.HasScaledObjects()
+	if err != nil {
+		return err
+	}
+
+	if !kindExists {
+		if !common.IsObjectTaggedToDelete(desired) {
+			errToLog := fmt.Errorf("Error creating scaledobject object '%s'. Install KEDA in your cluster to create scaledobject objects", desired.Name)
+			r.EventRecorder().Eventf(r.apiManager, v1.EventTypeWarning, "ReconcileError", errToLog.Error())
+			r.logger.Error(errToLog, "ReconcileError")
+		}
+		return nil
+	}
+
+	// Components are deployed as Deployments instead of DeploymentConfigs
+	if r.apiManager.IsDeploymentWorkloadEnabled() {
+		desired.Spec.ScaleTargetRef.APIVersion = "apps/v1"
+		desired.Spec.ScaleTargetRef.Kind = "Deployment"
+	}
+	return r.ReconcileResource(&kedav1alpha1.ScaledObject{}, desired, mutateFn)
+}
+
+func (r *BaseAPIManagerLogicReconciler) ReconcileTriggerAuthentication(desired *kedav1alpha1.TriggerAuthentication, mutateFn reconcilers.MutateFn) error {
+	// TriggerAuthentication and ScaledObject CRDs are installed together by KEDA
+	kindExists, err := r.HasScaledObjects()
+	if err != nil {
+		return err
+	}
+
+	if !kindExists {
+		return nil
+	}
+
+	return r.ReconcileResource(&kedav1alpha1.TriggerAuthentication{}, desired, mutateFn)
+}
+
 func (r *BaseAPIManagerLogicReconciler) ReconcileResource(obj, desired common.KubernetesObject, mutatefn reconcilers.MutateFn) error {
 	desired.SetNamespace(r.apiManager.GetNamespace())
 
@@ -194,7 +344,42 @@ func (r *BaseAPIManagerLogicReconciler) ReconcileResource(obj, desired common.Ku
 		}
 	}
 
-	return r.BaseReconciler.ReconcileResource(obj, desired, r.APIManagerMutator(mutatefn))
+	if !common.IsObjectTaggedToDelete(desired) {
+		r.desiredObjects[objectKindName(obj, desired.GetName())] = true
+	}
+
+	err := r.BaseReconciler.ReconcileResource(obj, desired, r.APIManagerMutator(mutatefn))
+	if err != nil {
+		return err
+	}
+
+	// obj holds the existing object when it was found
+	if common.IsObjectUnmanaged(obj) {
+		r.addUnmanagedObject(obj)
+	}
+
+	return nil
+}
+
+// objectKindName returns the Kind/name of the object, obj being a pointer to a typed object
+func objectKindName(obj common.KubernetesObject, name string) string {
+	return fmt.Sprintf("%s/%s", reflect.TypeOf(obj).Elem().Name(), name)
+}
+
+func (r *BaseAPIManagerLogicReconciler) addUnmanagedObject(obj common.KubernetesObject) {
+	objInfo := objectKindName(obj, obj.GetName())
+	if !helper.ArrayContains(r.unmanagedObjects, objInfo) {
+		r.unmanagedObjects = append(r.unmanagedObjects, objInfo)
+	}
+}
+
+// SetUnmanagedObjectsStatus reports the objects opted out of the reconciliation with the
+// UnmanagedAnnotation annotation. It is expected to run once all the components have been
+// reconciled, otherwise the previously reported objects are kept.
+func (r *BaseAPIManagerLogicReconciler) SetUnmanagedObjectsStatus() {
+	unmanagedObjects := append([]string(nil), r.unmanagedObjects...)
+	sort.Strings(unmanagedObjects)
+	r.apiManager.Status.UnmanagedObjects = unmanagedObjects
 }
 
 // APIManagerMutator wraps mutator into APIManger mutator
@@ -282,3 +467,16 @@ func (b *BaseAPIManagerLogicReconciler) HasPodMonitors() (bool, error) {
 	}
 	return *b.crdAvailabilityCache.podMonitorCRDAvailable, nil
 }
+
+// HasScaledObjects checks if the KEDA ScaledObject CRD is supported in current cluster
+func (b *BaseAPIManagerLogicReconciler) HasScaledObjects() (bool, error) {
+	if b.crdAvailabilityCache.scaledObjectCRDAvailable == nil {
+		res, err := b.BaseReconciler.HasScaledObjects()
+		if err != nil {
+			return res, err
+		}
+		b.crdAvailabilityCache.scaledObjectCRDAvailable = &res
+		return res, err
+	}
+	return *b.crdAvailabilityCache.scaledObjectCRDAvailable, nil
+}
@@ -2,9 +2,12 @@ package operator
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/common"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
 	grafanav1alpha1 "github.com/grafana-operator/grafana-operator/v4/api/integreatly/v1alpha1"
@@ -502,3 +505,255 @@ func TestBaseAPIManagerLogicReconcilerHasServiceMonitors(t *testing.T) {
 		t.Fatalf("Unexpected exists value received. Expected: %t, got: %t", false, exists)
 	}
 }
+
+func TestBaseAPIManagerLogicReconcilerHasScaledObjects(t *testing.T) {
+	var (
+		apimanagerName = "example-apimanager"
+		namespace      = "operator-unittest"
+		log            = logf.Log.WithName("operator_test")
+	)
+
+	ctx := context.TODO()
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apimanagerName,
+			Namespace: namespace,
+		},
+		Spec: appsv1alpha1.APIManagerSpec{},
+	}
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	if err := kedav1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects to track in the fake client.
+	objs := []runtime.Object{apimanager}
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	kedaAPIResourceList := &metav1.APIResourceList{
+		GroupVersion: kedav1alpha1.GroupVersion.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "scaledobjects", Namespaced: true, Kind: kedav1alpha1.ScaledObjectKind},
+			{Name: "triggerauthentications", Namespaced: true, Kind: kedav1alpha1.TriggerAuthenticationKind},
+		},
+	}
+
+	clientset.Resources = []*metav1.APIResourceList{
+		kedaAPIResourceList,
+	}
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	apimanagerLogicReconciler := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+
+	// Test uncached request. Resource should exist
+	exists, err := apimanagerLogicReconciler.HasScaledObjects()
+	if err != nil {
+		t.Fatalf("Unexpected error received: %s", err)
+	}
+	if !exists {
+		t.Fatalf("Unexpected exists value received. Expected: %t, got: %t", true, exists)
+	}
+
+	// Test that we are indeed receiving cached requests by simulating
+	// the removal of CRD types
+	clientset.Resources = []*metav1.APIResourceList{}
+	exists, err = apimanagerLogicReconciler.HasScaledObjects()
+	if err != nil {
+		t.Fatalf("Unexpected error received: %s", err)
+	}
+	if !exists {
+		t.Fatalf("Unexpected exists value received. Expected: %t, got: %t", true, exists)
+	}
+
+	// Create a new APIManagerLogicReconciler to simulate a new state of cache
+	// with the resource now removed. We now should receive that it does not
+	// exist
+	apimanagerLogicReconciler = NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+	exists, err = apimanagerLogicReconciler.HasScaledObjects()
+	if err != nil {
+		t.Fatalf("Unexpected error received: %s", err)
+	}
+	if exists {
+		t.Fatalf("Unexpected exists value received. Expected: %t, got: %t", false, exists)
+	}
+}
+
+func TestBaseAPIManagerLogicReconcilerContentHashes(t *testing.T) {
+	var (
+		apimanagerName = "example-apimanager"
+		namespace      = "operator-unittest"
+		log            = logf.Log.WithName("operator_test")
+	)
+
+	ctx := context.TODO()
+
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apimanagerName,
+			Namespace: namespace,
+		},
+		Spec: appsv1alpha1.APIManagerSpec{},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: namespace, UID: "secret-uid"},
+		Data:       map[string][]byte{"password": []byte("one")},
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "myconfigmap", Namespace: namespace, UID: "configmap-uid"},
+		Data:       map[string]string{"config": "value"},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	err := appsv1.AddToScheme(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []runtime.Object{apimanager, secret, configMap}
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+
+	desiredDC := func() *appsv1.DeploymentConfig {
+		return &appsv1.DeploymentConfig{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig"},
+			ObjectMeta: metav1.ObjectMeta{Name: "mydc", Namespace: namespace},
+			Spec: appsv1.DeploymentConfigSpec{
+				Template: &v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{
+							Name: "mycontainer",
+							Env: []v1.EnvVar{{
+								Name: "PASSWORD",
+								ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+									LocalObjectReference: v1.LocalObjectReference{Name: "mysecret"},
+									Key:                  "password",
+								}},
+							}},
+						}},
+						Volumes: []v1.Volume{{
+							Name: "config",
+							VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+								LocalObjectReference: v1.LocalObjectReference{Name: "myconfigmap"},
+							}},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	reconcileDC := func() map[string]string {
+		apimanagerLogicReconciler := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+		err := apimanagerLogicReconciler.ReconcileDeploymentConfig(desiredDC(), reconcilers.DeploymentConfigMutator())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = apimanagerLogicReconciler.ReconcileWatchedObjectsLabels()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dc := &appsv1.DeploymentConfig{}
+		err = cl.Get(ctx, client.ObjectKey{Name: "mydc", Namespace: namespace}, dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dc.Spec.Template.Annotations
+	}
+
+	initialAnnotations := reconcileDC()
+	if initialAnnotations[SecretsHashAnnotation] == "" || initialAnnotations[ConfigMapsHashAnnotation] == "" {
+		t.Fatalf("content hash annotations not set: %v", initialAnnotations)
+	}
+
+	for _, label := range []string{APIManagerSecretLabelPrefix + "secret-uid", APIManagerConfigMapLabelPrefix + "configmap-uid"} {
+		if _, ok := apimanager.GetLabels()[label]; !ok {
+			t.Errorf("apimanager missing label %s: %v", label, apimanager.GetLabels())
+		}
+	}
+
+	secret.Data["password"] = []byte("two")
+	err = cl.Update(ctx, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := reconcileDC()
+	if annotations[SecretsHashAnnotation] == initialAnnotations[SecretsHashAnnotation] {
+		t.Error("secrets hash annotation not updated after the secret changed")
+	}
+	if annotations[ConfigMapsHashAnnotation] != initialAnnotations[ConfigMapsHashAnnotation] {
+		t.Error("configmaps hash annotation updated without configmap changes")
+	}
+}
+
+func TestBaseAPIManagerLogicReconcilerUnmanagedObjects(t *testing.T) {
+	var (
+		namespace = "operator-unittest"
+		log       = logf.Log.WithName("operator_test")
+	)
+
+	apimanager := &appsv1alpha1.APIManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-apimanager", Namespace: namespace},
+	}
+	unmanaged := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "apicast-environment",
+			Namespace:   namespace,
+			Annotations: map[string]string{common.UnmanagedAnnotation: "true"},
+		},
+		Data: map[string]string{"somekey": "customvalue"},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	cl := fake.NewFakeClient(apimanager, unmanaged)
+	clientset := fakeclientset.NewSimpleClientset()
+	baseReconciler := reconcilers.NewBaseReconciler(context.TODO(), cl, s, cl, log, clientset.Discovery(), record.NewFakeRecorder(10000))
+	apimanagerLogicReconciler := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+
+	mutator := func(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+		existing := existingObj.(*v1.ConfigMap)
+		desired := desiredObj.(*v1.ConfigMap)
+		existing.Data = desired.Data
+		return true, nil
+	}
+
+	for _, name := range []string{"apicast-environment", "system-environment"} {
+		desired := &v1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{"somekey": "somevalue"},
+		}
+		if err := apimanagerLogicReconciler.ReconcileConfigMap(desired, mutator); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The unmanaged configmap keeps its content, missing configmaps are still created
+	for name, expected := range map[string]string{"apicast-environment": "customvalue", "system-environment": "somevalue"} {
+		reconciled := &v1.ConfigMap{}
+		if err := cl.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: namespace}, reconciled); err != nil {
+			t.Fatal(err)
+		}
+		if reconciled.Data["somekey"] != expected {
+			t.Errorf("configmap %s: expected '%s', got '%s'", name, expected, reconciled.Data["somekey"])
+		}
+	}
+
+	apimanagerLogicReconciler.SetUnmanagedObjectsStatus()
+	expectedStatus := []string{"ConfigMap/apicast-environment"}
+	if !reflect.DeepEqual(apimanager.Status.UnmanagedObjects, expectedStatus) {
+		t.Errorf("expected unmanaged objects %v, got %v", expectedStatus, apimanager.Status.UnmanagedObjects)
+	}
+}
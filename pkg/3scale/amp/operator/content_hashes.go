@@ -0,0 +1,188 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+const (
+	// Pod template annotations holding the hash of the contents of the secrets and configmaps
+	// consumed by the pods. Any change in their contents rolls out the pods.
+	SecretsHashAnnotation    = "apps.3scale.net/secrets-hash"
+	ConfigMapsHashAnnotation = "apps.3scale.net/configmaps-hash"
+)
+
+var contentHashAnnotations = []string{SecretsHashAnnotation, ConfigMapsHashAnnotation}
+
+// watchedObjects collects the UIDs of the secrets and configmaps consumed by the
+// components during a reconciliation, to have the APIManager reconciled when they change
+type watchedObjects struct {
+	secretUIDs    []string
+	configMapUIDs []string
+}
+
+// setPodTemplateContentHashes annotates the pod template with the hash of the contents
+// of the secrets and configmaps it consumes
+func (r *BaseAPIManagerLogicReconciler) setPodTemplateContentHashes(template *v1.PodTemplateSpec) error {
+	secretsHash, err := r.secretsHash(helper.PodSpecSecretNames(&template.Spec))
+	if err != nil {
+		return err
+	}
+
+	configMapsHash, err := r.configMapsHash(helper.PodSpecConfigMapNames(&template.Spec))
+	if err != nil {
+		return err
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[SecretsHashAnnotation] = secretsHash
+	template.Annotations[ConfigMapsHashAnnotation] = configMapsHash
+	return nil
+}
+
+// Secrets and configmaps not found, either optional or not created yet, are hashed by name only.
+// The hash changes once they are created.
+func (r *BaseAPIManagerLogicReconciler) secretsHash(names []string) (string, error) {
+	h := sha256.New()
+	for _, name := range names {
+		secret := &v1.Secret{}
+		err := r.Client().Get(r.Context(), types.NamespacedName{Name: name, Namespace: r.apiManager.Namespace}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+		if err == nil {
+			r.watchedObjects.secretUIDs = appendUID(r.watchedObjects.secretUIDs, string(secret.UID))
+		}
+		writeHashEntry(h, name, secret.Data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (r *BaseAPIManagerLogicReconciler) configMapsHash(names []string) (string, error) {
+	h := sha256.New()
+	for _, name := range names {
+		configMap := &v1.ConfigMap{}
+		err := r.Client().Get(r.Context(), types.NamespacedName{Name: name, Namespace: r.apiManager.Namespace}, configMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+
+		data := map[string][]byte{}
+		if err == nil {
+			r.watchedObjects.configMapUIDs = appendUID(r.watchedObjects.configMapUIDs, string(configMap.UID))
+			for key, value := range configMap.Data {
+				data[key] = []byte(value)
+			}
+			for key, value := range configMap.BinaryData {
+				data[key] = value
+			}
+		}
+		writeHashEntry(h, name, data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeHashEntry(h hash.Hash, name string, data map[string][]byte) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write(data[key])
+		h.Write([]byte{0})
+	}
+}
+
+func appendUID(uids []string, uid string) []string {
+	if helper.ArrayContains(uids, uid) {
+		return uids
+	}
+	return append(uids, uid)
+}
+
+// contentHashesMutator wraps the mutator of a workload, reconciling the content hash annotations of the pod template.
+// Annotations not desired anymore are removed.
+func contentHashesMutator(mutateFn reconcilers.MutateFn) reconcilers.MutateFn {
+	return func(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+		updated, err := mutateFn(existingObj, desiredObj)
+		if err != nil {
+			return false, err
+		}
+
+		existing := workloadPodTemplate(existingObj)
+		desired := workloadPodTemplate(desiredObj)
+		if existing == nil || desired == nil {
+			return updated, nil
+		}
+
+		for _, key := range contentHashAnnotations {
+			desiredVal, desiredOk := desired.Annotations[key]
+			existingVal, existingOk := existing.Annotations[key]
+			if desiredOk == existingOk && desiredVal == existingVal {
+				continue
+			}
+
+			if !desiredOk {
+				delete(existing.Annotations, key)
+			} else {
+				if existing.Annotations == nil {
+					existing.Annotations = map[string]string{}
+				}
+				existing.Annotations[key] = desiredVal
+			}
+			updated = true
+		}
+
+		return updated, nil
+	}
+}
+
+func workloadPodTemplate(obj common.KubernetesObject) *v1.PodTemplateSpec {
+	switch workload := obj.(type) {
+	case *appsv1.DeploymentConfig:
+		return workload.Spec.Template
+	case *k8sappsv1.Deployment:
+		return &workload.Spec.Template
+	case *k8sappsv1.StatefulSet:
+		return &workload.Spec.Template
+	}
+	return nil
+}
+
+// ReconcileWatchedObjectsLabels labels the APIManager with the UIDs of the secrets and configmaps
+// consumed by the components, so that their events are mapped to the APIManager.
+// It is expected to run once all the components have been reconciled.
+func (r *BaseAPIManagerLogicReconciler) ReconcileWatchedObjectsLabels() (reconcile.Result, error) {
+	changed := replaceAPIManagerWatchedLabels(r.apiManager, APIManagerSecretLabelPrefix, r.watchedObjects.secretUIDs)
+	changed = replaceAPIManagerWatchedLabels(r.apiManager, APIManagerConfigMapLabelPrefix, r.watchedObjects.configMapUIDs) || changed
+
+	if changed {
+		err := r.Client().Update(r.Context(), r.apiManager)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{Requeue: changed}, nil
+}
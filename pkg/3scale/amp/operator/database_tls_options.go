@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// databaseTLSSecretFields are the fields of a database secret describing the connection
+type databaseTLSSecretFields struct {
+	secretName string
+	url        string
+	sslMode    string
+	ca         string
+	cert       string
+	key        string
+}
+
+var (
+	systemDatabaseTLSSecretFields = databaseTLSSecretFields{
+		secretName: component.SystemSecretSystemDatabaseSecretName,
+		url:        component.SystemSecretSystemDatabaseURLFieldName,
+		sslMode:    component.SystemSecretSystemDatabaseSSLModeFieldName,
+		ca:         component.SystemSecretSystemDatabaseSSLCAFieldName,
+		cert:       component.SystemSecretSystemDatabaseSSLCertFieldName,
+		key:        component.SystemSecretSystemDatabaseSSLKeyFieldName,
+	}
+	zyncDatabaseTLSSecretFields = databaseTLSSecretFields{
+		secretName: component.ZyncSecretName,
+		url:        component.ZyncSecretDatabaseURLFieldName,
+		sslMode:    component.ZyncSecretDatabaseSSLModeFieldName,
+		ca:         component.ZyncSecretDatabaseSSLCAFieldName,
+		cert:       component.ZyncSecretDatabaseSSLCertFieldName,
+		key:        component.ZyncSecretDatabaseSSLKeyFieldName,
+	}
+)
+
+// databaseURLSchemeEngines maps the schemes of the database URLs to the engines supporting TLS settings
+var databaseURLSchemeEngines = map[string]string{
+	"mysql":      component.DatabaseEngineMySQL,
+	"mysql2":     component.DatabaseEngineMySQL,
+	"postgres":   component.DatabaseEnginePostgreSQL,
+	"postgresql": component.DatabaseEnginePostgreSQL,
+}
+
+// externalDatabaseTLSOptions reads and validates the TLS settings of a connection to an external database.
+// It returns nil when no SSL mode is set.
+func externalDatabaseTLSOptions(secretSource *helper.SecretSource, fields databaseTLSSecretFields) (*component.DatabaseTLSOptions, error) {
+	values := map[string]string{}
+	for _, secretField := range []string{fields.url, fields.sslMode, fields.ca, fields.cert, fields.key} {
+		val, err := secretSource.FieldValue(fields.secretName, secretField, "")
+		if err != nil {
+			return nil, err
+		}
+		values[secretField] = val
+	}
+
+	err := component.ValidateDatabaseTLS(values[fields.sslMode], values[fields.ca], values[fields.cert], values[fields.key])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", fields.secretName, err)
+	}
+	if values[fields.sslMode] == "" {
+		return nil, nil
+	}
+
+	databaseURL, err := url.Parse(values[fields.url])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: parsing %s: %w", fields.secretName, fields.url, err)
+	}
+	engine, ok := databaseURLSchemeEngines[databaseURL.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("secret %s: TLS settings not supported with '%s' database URLs", fields.secretName, databaseURL.Scheme)
+	}
+
+	options := &component.DatabaseTLSOptions{
+		Engine:      engine,
+		SecretName:  fields.secretName,
+		SSLMode:     values[fields.sslMode],
+		URLHasQuery: databaseURL.RawQuery != "",
+	}
+	cases := []struct {
+		field       *string
+		secretField string
+	}{
+		{&options.CAField, fields.ca},
+		{&options.CertField, fields.cert},
+		{&options.KeyField, fields.key},
+	}
+	for _, option := range cases {
+		if values[option.secretField] != "" {
+			*option.field = option.secretField
+		}
+	}
+
+	return options, nil
+}
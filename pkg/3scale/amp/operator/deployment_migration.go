@@ -0,0 +1,115 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// reconcileDeploymentConfigMigration migrates the existing DeploymentConfig
+// of a component to the desired Deployment. The migration is driven by the
+// state found in the cluster, so it resumes on every reconcile loop:
+//   - The DeploymentConfig is scaled down, keeping its replicas in an annotation
+//   - The Deployment is created by the regular reconciling logic
+//   - Once the Deployment is available, the DeploymentConfig is deleted
+//   - If the Deployment does not progress, the Deployment is deleted and the
+//     DeploymentConfig is scaled up and annotated as failed
+//
+// It returns true when the DeploymentConfig has to keep being reconciled
+// because the migration of the component failed.
+func (r *BaseAPIManagerLogicReconciler) reconcileDeploymentConfigMigration(desired *k8sappsv1.Deployment) (bool, error) {
+	existingDC := &appsv1.DeploymentConfig{}
+	err := r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(desired), existingDC)
+	if err != nil {
+		// DeploymentConfig kind not available in the cluster, nothing to migrate
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !r.isOwnedByAPIManager(existingDC) {
+		return false, nil
+	}
+
+	// Unmanaged DeploymentConfigs are left untouched, no Deployment is created next to them
+	if common.IsObjectUnmanaged(existingDC) {
+		return true, nil
+	}
+
+	if _, ok := existingDC.Annotations[appsv1alpha1.MigrationFailedAnnotation]; ok {
+		return true, nil
+	}
+
+	if _, ok := existingDC.Annotations[appsv1alpha1.MigrationReplicasAnnotation]; !ok {
+		r.Logger().Info("Migrating DeploymentConfig to Deployment: scaling down", "name", existingDC.Name)
+		if existingDC.Annotations == nil {
+			existingDC.Annotations = map[string]string{}
+		}
+		existingDC.Annotations[appsv1alpha1.MigrationReplicasAnnotation] = strconv.Itoa(int(existingDC.Spec.Replicas))
+		existingDC.Spec.Replicas = 0
+		return false, r.UpdateResource(existingDC)
+	}
+
+	existingDeployment := &k8sappsv1.Deployment{}
+	err = r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(desired), existingDeployment)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The Deployment is created by the regular reconciling logic
+			return false, nil
+		}
+		return false, err
+	}
+
+	if helper.IsDeploymentAvailable(existingDeployment) {
+		r.Logger().Info("Migrating DeploymentConfig to Deployment: deleting DeploymentConfig", "name", existingDC.Name)
+		return false, r.DeleteResource(existingDC, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}
+
+	if helper.IsDeploymentProgressDeadlineExceeded(existingDeployment) {
+		return true, r.rollbackDeploymentConfigMigration(existingDC, existingDeployment)
+	}
+
+	return false, nil
+}
+
+func (r *BaseAPIManagerLogicReconciler) rollbackDeploymentConfigMigration(existingDC *appsv1.DeploymentConfig, existingDeployment *k8sappsv1.Deployment) error {
+	r.Logger().Info("Migrating DeploymentConfig to Deployment: rolling back", "name", existingDC.Name)
+	r.EventRecorder().Eventf(r.apiManager, v1.EventTypeWarning, "MigrationFailed",
+		"Deployment '%s' did not become available. Rolled back to DeploymentConfig", existingDeployment.Name)
+
+	err := r.DeleteResource(existingDeployment, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	replicas, err := strconv.ParseInt(existingDC.Annotations[appsv1alpha1.MigrationReplicasAnnotation], 10, 32)
+	if err != nil {
+		return fmt.Errorf("DeploymentConfig '%s': invalid %s annotation: %w", existingDC.Name, appsv1alpha1.MigrationReplicasAnnotation, err)
+	}
+
+	existingDC.Spec.Replicas = int32(replicas)
+	delete(existingDC.Annotations, appsv1alpha1.MigrationReplicasAnnotation)
+	existingDC.Annotations[appsv1alpha1.MigrationFailedAnnotation] = "true"
+	return r.UpdateResource(existingDC)
+}
+
+func (r *BaseAPIManagerLogicReconciler) isOwnedByAPIManager(obj metav1.Object) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.UID == r.apiManager.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+func setupDeploymentMigrationTest(t *testing.T) (client.Client, *BackendReconciler) {
+	var (
+		log              = logf.Log.WithName("operator_test")
+		twoValue   int64 = 2
+		workload         = appsv1alpha1.WorkloadTypeDeployment
+		apimanager       = backendApiManagerCreator(&twoValue, &twoValue, &twoValue)
+	)
+	apimanager.UID = "apimanager-uid"
+	apimanager.Spec.System = &appsv1alpha1.SystemSpec{}
+
+	s := scheme.Scheme
+	if err := appsv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := routev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []runtime.Object{apimanager}
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+	baseReconciler := reconcilers.NewBaseReconciler(context.TODO(), cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	backendReconciler := NewBackendReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+
+	// Deploy DeploymentConfigs first
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Switch to Deployments
+	apimanager.Spec.WorkloadType = &workload
+
+	return cl, backendReconciler
+}
+
+func TestDeploymentConfigMigration(t *testing.T) {
+	cl, backendReconciler := setupDeploymentMigrationTest(t)
+	ctx := context.TODO()
+	key := types.NamespacedName{Name: "backend-listener", Namespace: "operator-unittest"}
+
+	// First loop scales down the DeploymentConfig
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	dc := &appsv1.DeploymentConfig{}
+	if err := cl.Get(ctx, key, dc); err != nil {
+		t.Fatal(err)
+	}
+	if dc.Spec.Replicas != 0 {
+		t.Fatalf("expected DeploymentConfig scaled down, got %d replicas", dc.Spec.Replicas)
+	}
+	if dc.Annotations[appsv1alpha1.MigrationReplicasAnnotation] != "2" {
+		t.Fatalf("expected replicas annotation '2', got '%s'", dc.Annotations[appsv1alpha1.MigrationReplicasAnnotation])
+	}
+
+	deployment := &k8sappsv1.Deployment{}
+	if err := cl.Get(ctx, key, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Spec.Selector.MatchLabels["deploymentConfig"] != dc.Spec.Selector["deploymentConfig"] {
+		t.Fatalf("selector mismatch: %v, %v", deployment.Spec.Selector.MatchLabels, dc.Spec.Selector)
+	}
+
+	// Deployment not available yet, DeploymentConfig is kept
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Get(ctx, key, dc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deployment available, DeploymentConfig is deleted
+	deployment.Status.Conditions = []k8sappsv1.DeploymentCondition{
+		{Type: k8sappsv1.DeploymentAvailable, Status: v1.ConditionTrue},
+	}
+	if err := cl.Update(ctx, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Get(ctx, key, dc); !errors.IsNotFound(err) {
+		t.Fatalf("expected DeploymentConfig to be deleted: %v", err)
+	}
+	if err := cl.Get(ctx, key, deployment); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeploymentConfigMigrationRollback(t *testing.T) {
+	cl, backendReconciler := setupDeploymentMigrationTest(t)
+	ctx := context.TODO()
+	key := types.NamespacedName{Name: "backend-listener", Namespace: "operator-unittest"}
+
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	deployment := &k8sappsv1.Deployment{}
+	if err := cl.Get(ctx, key, deployment); err != nil {
+		t.Fatal(err)
+	}
+	deployment.Status.Conditions = []k8sappsv1.DeploymentCondition{
+		{Type: k8sappsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+	}
+	if err := cl.Update(ctx, deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cl.Get(ctx, key, deployment); !errors.IsNotFound(err) {
+		t.Fatalf("expected Deployment to be deleted: %v", err)
+	}
+
+	dc := &appsv1.DeploymentConfig{}
+	if err := cl.Get(ctx, key, dc); err != nil {
+		t.Fatal(err)
+	}
+	if dc.Spec.Replicas != 2 {
+		t.Fatalf("expected DeploymentConfig replicas restored to 2, got %d", dc.Spec.Replicas)
+	}
+	if _, ok := dc.Annotations[appsv1alpha1.MigrationFailedAnnotation]; !ok {
+		t.Fatal("expected migration failed annotation")
+	}
+	if _, ok := dc.Annotations[appsv1alpha1.MigrationReplicasAnnotation]; ok {
+		t.Fatal("expected migration replicas annotation to be removed")
+	}
+
+	// Rolled back component keeps being reconciled as DeploymentConfig
+	if _, err := backendReconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Get(ctx, key, deployment); !errors.IsNotFound(err) {
+		t.Fatalf("expected Deployment not to be recreated: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package operator
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+// reconcileDeploymentPreHook runs the rolling strategy pre hook of a DeploymentConfig
+// deployed as a Deployment, like the system-app database migrations, as a one-shot Job.
+// It returns true once the Job of the desired version of the hook has succeeded,
+// the Deployment is not rolled out before. Failed Jobs are kept and reported with
+// an event, deleting them runs the hook again.
+// Components without pre hook are always ready to be rolled out.
+func (r *BaseAPIManagerLogicReconciler) reconcileDeploymentPreHook(desired *appsv1.DeploymentConfig, images map[string]string) (bool, error) {
+	job, err := helper.DeploymentPreHookJob(desired, images)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return true, nil
+	}
+
+	// Allowed by the NetworkPolicies of the databases
+	job.Spec.Template.Labels[component.DatabaseClientLabelKey] = component.DatabaseClientLabelValue
+
+	err = r.ReconcileResource(&batchv1.Job{}, job, reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return false, err
+	}
+
+	existing := &batchv1.Job{}
+	err = r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(job), existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, condition := range existing.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, r.deleteStaleDeploymentPreHookJobs(desired.Name, job.Name)
+		case batchv1.JobFailed:
+			r.Logger().Info("Pre hook failed, the Deployment is not rolled out", "job", job.Name, "message", condition.Message)
+			r.EventRecorder().Eventf(r.apiManager, v1.EventTypeWarning, "PreHookFailed",
+				"Job '%s' failed, '%s' is not rolled out. Delete the Job to run the pre hook again: %s", job.Name, desired.Name, condition.Message)
+			return false, nil
+		}
+	}
+
+	r.Logger().Info("Waiting for the pre hook to finish", "job", job.Name)
+	return false, nil
+}
+
+// deleteStaleDeploymentPreHookJobs deletes the pre hook Jobs of previous versions of the hook
+func (r *BaseAPIManagerLogicReconciler) deleteStaleDeploymentPreHookJobs(deploymentName, jobName string) error {
+	jobList := &batchv1.JobList{}
+	err := r.Client().List(r.Context(), jobList,
+		client.InNamespace(r.apiManager.Namespace),
+		client.MatchingLabels{helper.DeploymentPreHookLabelKey: deploymentName})
+	if err != nil {
+		return err
+	}
+
+	for idx := range jobList.Items {
+		job := &jobList.Items[idx]
+		if job.Name == jobName || !metav1.IsControlledBy(job, r.apiManager) {
+			continue
+		}
+		err = r.Client().Delete(r.Context(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+func TestReconcileDeploymentConfigPreHook(t *testing.T) {
+	var (
+		namespace    = "operator-unittest"
+		log          = logf.Log.WithName("operator_test")
+		workloadType = appsv1alpha1.WorkloadTypeDeployment
+	)
+	ctx := context.TODO()
+	s := scheme.Scheme
+	for _, addToScheme := range []func(*runtime.Scheme) error{appsv1alpha1.AddToScheme, appsv1.AddToScheme, routev1.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apimanager := backendApiManagerCreator(nil, nil, nil)
+	apimanager.Spec.WorkloadType = &workloadType
+	apimanager.Spec.System = &appsv1alpha1.SystemSpec{}
+
+	cl := fake.NewFakeClient(apimanager)
+	clientset := fakeclientset.NewSimpleClientset()
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, cl, log, clientset.Discovery(), record.NewFakeRecorder(10000))
+
+	desiredDC := func(image string) *appsv1.DeploymentConfig {
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "system-app", Namespace: namespace},
+			Spec: appsv1.DeploymentConfigSpec{
+				Replicas: 2,
+				Selector: map[string]string{"deploymentConfig": "system-app"},
+				Strategy: appsv1.DeploymentStrategy{
+					Type: appsv1.DeploymentStrategyTypeRolling,
+					RollingParams: &appsv1.RollingDeploymentStrategyParams{
+						Pre: &appsv1.LifecycleHook{
+							FailurePolicy: appsv1.LifecycleHookFailurePolicyRetry,
+							ExecNewPod: &appsv1.ExecNewPodHook{
+								Command:       []string{"bash", "-c", "bundle exec rake boot openshift:deploy"},
+								ContainerName: "system-master",
+							},
+						},
+					},
+				},
+				Template: &v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"deploymentConfig": "system-app"}},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "system-master", Image: image}},
+					},
+				},
+			},
+		}
+	}
+	reconcile := func(dc *appsv1.DeploymentConfig) *BaseAPIManagerLogicReconciler {
+		r := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+		if err := r.ReconcileDeploymentConfig(dc, reconcilers.DeploymentConfigMutator(reconcilers.DeploymentConfigImageChangeTriggerMutator)); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	prune := func(r *BaseAPIManagerLogicReconciler) {
+		if _, err := r.PruneOrphanedObjects(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	preHookJobs := func() []batchv1.Job {
+		jobList := &batchv1.JobList{}
+		err := cl.List(ctx, jobList, client.InNamespace(namespace), client.MatchingLabels{helper.DeploymentPreHookLabelKey: "system-app"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jobList.Items
+	}
+	completeJob := func(job *batchv1.Job, conditionType batchv1.JobConditionType) {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: v1.ConditionTrue}}
+		if err := cl.Update(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deployment := func() *k8sappsv1.Deployment {
+		deployment := &k8sappsv1.Deployment{}
+		err := cl.Get(ctx, types.NamespacedName{Name: "system-app", Namespace: namespace}, deployment)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deployment
+	}
+
+	// The Deployment is not created until the pre hook has succeeded
+	reconcile(desiredDC("system:1.0"))
+	jobs := preHookJobs()
+	if len(jobs) != 1 {
+		t.Fatalf("expected one pre hook job, got %d", len(jobs))
+	}
+	if deployment() != nil {
+		t.Fatal("expected the deployment to wait for the pre hook")
+	}
+	firstJob := jobs[0]
+	completeJob(&firstJob, batchv1.JobComplete)
+
+	reconcile(desiredDC("system:1.0"))
+	current := deployment()
+	if current == nil {
+		t.Fatal("expected the deployment to be created")
+	}
+	// The hook is not run by the pods
+	if len(current.Spec.Template.Spec.InitContainers) != 0 {
+		t.Fatalf("unexpected init containers %v", current.Spec.Template.Spec.InitContainers)
+	}
+	if len(preHookJobs()) != 1 {
+		t.Fatal("expected the pre hook not to run again")
+	}
+
+	// A new version waits for a new run of the pre hook, the running pre hook
+	// does not leave the deployment to be pruned
+	prune(reconcile(desiredDC("system:1.1")))
+	jobs = preHookJobs()
+	if len(jobs) != 2 {
+		t.Fatalf("expected a new pre hook job, got %d jobs", len(jobs))
+	}
+	if deployment() == nil {
+		t.Fatal("expected the deployment not to be pruned while the pre hook runs")
+	}
+	if image := deployment().Spec.Template.Spec.Containers[0].Image; image != "system:1.0" {
+		t.Fatalf("expected the deployment to wait for the pre hook, got image %s", image)
+	}
+
+	// Failed hooks block the rollout
+	for idx := range jobs {
+		if jobs[idx].Name != firstJob.Name {
+			completeJob(&jobs[idx], batchv1.JobFailed)
+		}
+	}
+	prune(reconcile(desiredDC("system:1.1")))
+	if deployment() == nil {
+		t.Fatal("expected the deployment not to be pruned after the pre hook failed")
+	}
+	if image := deployment().Spec.Template.Spec.Containers[0].Image; image != "system:1.0" {
+		t.Fatalf("expected the deployment not to be rolled out, got image %s", image)
+	}
+
+	for idx := range jobs {
+		if jobs[idx].Name != firstJob.Name {
+			completeJob(&jobs[idx], batchv1.JobComplete)
+		}
+	}
+	reconcile(desiredDC("system:1.1"))
+	if image := deployment().Spec.Template.Spec.Containers[0].Image; image != "system:1.1" {
+		t.Fatalf("expected the deployment to be rolled out, got image %s", image)
+	}
+	// Jobs of previous versions are deleted
+	jobs = preHookJobs()
+	if len(jobs) != 1 || jobs[0].Name == firstJob.Name {
+		t.Fatalf("expected only the last pre hook job, got %v", jobs)
+	}
+}
@@ -1,15 +1,27 @@
 package operator
 
 import (
+	"context"
 	"fmt"
+	"testing"
 
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
 	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
 )
 
 const (
@@ -22,6 +34,23 @@ const (
 	trueValue            = true
 )
 
+// testBaseAPIManagerLogicReconciler returns a logic reconciler of the APIManager,
+// backed by a fake client holding the APIManager and the given objects
+func testBaseAPIManagerLogicReconciler(t *testing.T, apimanager *appsv1alpha1.APIManager, objs ...runtime.Object) (*BaseAPIManagerLogicReconciler, client.Client) {
+	t.Helper()
+	s := scheme.Scheme
+	for _, addToScheme := range []func(*runtime.Scheme) error{appsv1alpha1.AddToScheme, appsv1.AddToScheme, imagev1.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cl := fake.NewFakeClient(append([]runtime.Object{apimanager}, objs...)...)
+	clientset := fakeclientset.NewSimpleClientset()
+	baseReconciler := reconcilers.NewBaseReconciler(context.TODO(), cl, s, cl, logf.Log.WithName("operator_test"), clientset.Discovery(), record.NewFakeRecorder(10000))
+	return NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager), cl
+}
+
 func addExpectedMeteringLabels(src map[string]string, componentName string, componentType helper.ComponentType) {
 	labels := []struct {
 		k string
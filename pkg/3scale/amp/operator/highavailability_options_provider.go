@@ -110,6 +110,42 @@ func (h *HighAvailabilityOptionsProvider) setBackendRedisOptions() error {
 			component.BackendSecretBackendRedisQueuesSentinelRoleFieldName,
 			component.DefaultBackendQueuesSentinelRole(),
 		},
+		{
+			&h.options.BackendRedisStorageSSLCA,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisStorageSSLCAFieldName,
+			"",
+		},
+		{
+			&h.options.BackendRedisStorageSSLCert,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisStorageSSLCertFieldName,
+			"",
+		},
+		{
+			&h.options.BackendRedisStorageSSLKey,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisStorageSSLKeyFieldName,
+			"",
+		},
+		{
+			&h.options.BackendRedisQueuesSSLCA,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisQueuesSSLCAFieldName,
+			"",
+		},
+		{
+			&h.options.BackendRedisQueuesSSLCert,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisQueuesSSLCertFieldName,
+			"",
+		},
+		{
+			&h.options.BackendRedisQueuesSSLKey,
+			component.BackendSecretBackendRedisSecretName,
+			component.BackendSecretBackendRedisQueuesSSLKeyFieldName,
+			"",
+		},
 	}
 
 	for _, option := range casesWithDefault {
@@ -169,6 +205,24 @@ func (h *HighAvailabilityOptionsProvider) setSystemRedisOptions() error {
 			component.SystemSecretSystemRedisNamespace,
 			component.DefaultSystemRedisNamespace(),
 		},
+		{
+			&h.options.SystemRedisSSLCA,
+			component.SystemSecretSystemRedisSecretName,
+			component.SystemSecretSystemRedisSSLCAFieldName,
+			"",
+		},
+		{
+			&h.options.SystemRedisSSLCert,
+			component.SystemSecretSystemRedisSecretName,
+			component.SystemSecretSystemRedisSSLCertFieldName,
+			"",
+		},
+		{
+			&h.options.SystemRedisSSLKey,
+			component.SystemSecretSystemRedisSecretName,
+			component.SystemSecretSystemRedisSSLKeyFieldName,
+			"",
+		},
 	}
 
 	for _, option := range casesWithDefault {
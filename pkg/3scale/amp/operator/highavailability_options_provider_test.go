@@ -1,10 +1,18 @@
 package operator
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
@@ -67,6 +75,32 @@ func getBackendRedisSecret() *v1.Secret {
 	return GetTestSecret(namespace, component.BackendSecretBackendRedisSecretName, data)
 }
 
+// testTLSMaterial returns a self signed certificate and its key, PEM encoded
+func testTLSMaterial(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redis.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
 func basicApimanagerTestHA() *appsv1alpha1.APIManager {
 	apimanager := basicApimanager()
 	apimanager.Spec.HighAvailability = &appsv1alpha1.HighAvailabilitySpec{
@@ -105,7 +139,51 @@ func TestGetHighAvailabilityOptionsProvider(t *testing.T) {
 	}
 }
 
+func TestGetHighAvailabilityOptionsRedisTLS(t *testing.T) {
+	cert, key := testTLSMaterial(t)
+	backendRedisSecret := GetTestSecret(namespace, component.BackendSecretBackendRedisSecretName, map[string]string{
+		component.BackendSecretBackendRedisStorageURLFieldName:     "rediss://storage.redis.example.com",
+		component.BackendSecretBackendRedisQueuesURLFieldName:      "rediss://queue.redis.example.com",
+		component.BackendSecretBackendRedisStorageSSLCAFieldName:   cert,
+		component.BackendSecretBackendRedisStorageSSLCertFieldName: cert,
+		component.BackendSecretBackendRedisStorageSSLKeyFieldName:  key,
+	})
+	systemRedisSecret := GetTestSecret(namespace, component.SystemSecretSystemRedisSecretName, map[string]string{
+		component.SystemSecretSystemRedisURLFieldName:   "rediss://system.redis.example.com",
+		component.SystemSecretSystemRedisSSLCAFieldName: cert,
+	})
+
+	objs := []runtime.Object{backendRedisSecret, systemRedisSecret, getSystemDatabaseSecret()}
+	cl := fake.NewFakeClient(objs...)
+	optsProvider := NewHighAvailabilityOptionsProvider(basicApimanagerTestHA(), namespace, cl)
+	opts, err := optsProvider.GetHighAvailabilityOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := component.NewHighAvailability(opts).BackendRedisSecret()
+	for _, field := range []string{
+		component.BackendSecretBackendRedisStorageSSLCAFieldName,
+		component.BackendSecretBackendRedisStorageSSLCertFieldName,
+		component.BackendSecretBackendRedisStorageSSLKeyFieldName,
+	} {
+		if _, ok := secret.StringData[field]; !ok {
+			t.Errorf("backend-redis secret missing field %s", field)
+		}
+	}
+	if _, ok := secret.StringData[component.BackendSecretBackendRedisQueuesSSLCAFieldName]; ok {
+		t.Errorf("backend-redis secret has unexpected field %s", component.BackendSecretBackendRedisQueuesSSLCAFieldName)
+	}
+
+	secret = component.NewHighAvailability(opts).SystemRedisSecret()
+	if secret.StringData[component.SystemSecretSystemRedisSSLCAFieldName] != cert {
+		t.Errorf("system-redis secret missing field %s", component.SystemSecretSystemRedisSSLCAFieldName)
+	}
+}
+
 func TestGetHighAvailabilityOptionsInvalid(t *testing.T) {
+	cert, key := testTLSMaterial(t)
+
 	cases := []struct {
 		testName             string
 		backendRedisSecret   *v1.Secret
@@ -162,6 +240,38 @@ func TestGetHighAvailabilityOptionsInvalid(t *testing.T) {
 			getSystemDatabaseSecretMissingDatabaseURL(),
 			component.SystemSecretSystemDatabaseURLFieldName,
 		},
+		{
+			"BackendRedisTLSWithoutRediss",
+			GetTestSecret(namespace, component.BackendSecretBackendRedisSecretName, map[string]string{
+				component.BackendSecretBackendRedisStorageURLFieldName:   backendStorageURL,
+				component.BackendSecretBackendRedisQueuesURLFieldName:    backendQueueURL,
+				component.BackendSecretBackendRedisStorageSSLCAFieldName: cert,
+			}),
+			getSystemRedisSecretForHighAvailabilityTest(),
+			getSystemDatabaseSecret(),
+			"rediss://",
+		},
+		{
+			"BackendRedisTLSKeyWithoutCert",
+			GetTestSecret(namespace, component.BackendSecretBackendRedisSecretName, map[string]string{
+				component.BackendSecretBackendRedisStorageURLFieldName:   backendStorageURL,
+				component.BackendSecretBackendRedisQueuesURLFieldName:    "rediss://queue.redis.example.com",
+				component.BackendSecretBackendRedisQueuesSSLKeyFieldName: key,
+			}),
+			getSystemRedisSecretForHighAvailabilityTest(),
+			getSystemDatabaseSecret(),
+			component.BackendSecretBackendRedisQueuesURLFieldName,
+		},
+		{
+			"SystemRedisTLSInvalidCA",
+			getBackendRedisSecret(),
+			GetTestSecret(namespace, component.SystemSecretSystemRedisSecretName, map[string]string{
+				component.SystemSecretSystemRedisURLFieldName:   "rediss://system.redis.example.com",
+				component.SystemSecretSystemRedisSSLCAFieldName: "not a certificate",
+			}),
+			getSystemDatabaseSecret(),
+			"PEM",
+		},
 	}
 
 	for _, tc := range cases {
@@ -1,7 +1,13 @@
 package operator
 
 import (
+	"fmt"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
 	"github.com/3scale/3scale-operator/pkg/helper"
 )
 
@@ -48,3 +54,58 @@ func SystemSearchdImageURL() string {
 func ZyncPostgreSQLImageURL() string {
 	return helper.GetEnvVar("RELATED_IMAGE_ZYNC_POSTGRESQL", component.ZyncPostgreSQLImageURL())
 }
+
+// DeploymentImages returns the image URLs of the 3scale components indexed by
+// ImageStreamTag name. Deployments do not support image change triggers, so
+// the images referenced by DeploymentConfig triggers are resolved from it.
+func DeploymentImages(apimanager *appsv1alpha1.APIManager) (map[string]string, error) {
+	ampImages, err := AmpImages(apimanager)
+	if err != nil {
+		return nil, err
+	}
+
+	systemMySQLImage, err := SystemMySQLImage(apimanager)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPostgreSQLImage, err := SystemPostgreSQLImage(apimanager)
+	if err != nil {
+		return nil, err
+	}
+
+	imageStreams := []*imagev1.ImageStream{
+		ampImages.BackendImageStream(),
+		ampImages.ZyncImageStream(),
+		ampImages.APICastImageStream(),
+		ampImages.SystemImageStream(),
+		ampImages.ZyncDatabasePostgreSQLImageStream(),
+		ampImages.SystemMemcachedImageStream(),
+		ampImages.SystemSearchdImageStream(),
+		systemMySQLImage.ImageStream(),
+		systemPostgreSQLImage.ImageStream(),
+	}
+
+	images := map[string]string{}
+	for _, imageStream := range imageStreams {
+		for _, tag := range imageStream.Spec.Tags {
+			if tag.From != nil && tag.From.Kind == "DockerImage" {
+				images[fmt.Sprintf("%s:%s", imageStream.Name, tag.Name)] = tag.From.Name
+			}
+		}
+	}
+
+	backendRedisImage := BackendRedisImageURL()
+	if apimanager.Spec.Backend != nil && apimanager.Spec.Backend.RedisImage != nil {
+		backendRedisImage = *apimanager.Spec.Backend.RedisImage
+	}
+	images[fmt.Sprintf("backend-redis:%s", product.ThreescaleRelease)] = backendRedisImage
+
+	systemRedisImage := SystemRedisImageURL()
+	if apimanager.Spec.System != nil && apimanager.Spec.System.RedisImage != nil {
+		systemRedisImage = *apimanager.Spec.System.RedisImage
+	}
+	images[fmt.Sprintf("system-redis:%s", product.ThreescaleRelease)] = systemRedisImage
+
+	return images, nil
+}
@@ -28,11 +28,14 @@ func (m *MemcachedOptionsProvider) GetMemcachedOptions() (*component.MemcachedOp
 	m.memcachedOptions.DeploymentLabels = m.deploymentLabels()
 	m.memcachedOptions.PodTemplateLabels = m.podTemplateLabels()
 	m.memcachedOptions.PodTemplateAnnotations = m.apimanager.Spec.System.MemcachedAnnotations
+	m.memcachedOptions.External = m.apimanager.IsExternal(appsv1alpha1.SystemMemcached)
 
 	m.setResourceRequirementsOptions()
 	m.setNodeAffinityAndTolerationsOptions()
 	m.setPriorityClassNames()
 	m.setTopologySpreadConstraints()
+	m.setSecurityContexts()
+	m.setProbes()
 
 	err := m.memcachedOptions.Validate()
 	if err != nil {
@@ -96,3 +99,14 @@ func (m *MemcachedOptionsProvider) setTopologySpreadConstraints() {
 		m.memcachedOptions.TopologySpreadConstraints = m.apimanager.Spec.System.MemcachedTopologySpreadConstraints
 	}
 }
+
+func (m *MemcachedOptionsProvider) setSecurityContexts() {
+	systemSpec := m.apimanager.Spec.System
+	m.memcachedOptions.PodSecurityContext = podSecurityContext(m.apimanager, systemSpec.MemcachedPodSecurityContext)
+	// memcached keeps all its data in memory
+	m.memcachedOptions.ContainerSecurityContext = containerSecurityContext(m.apimanager, systemSpec.MemcachedSecurityContext, true)
+}
+
+func (m *MemcachedOptionsProvider) setProbes() {
+	m.memcachedOptions.Probes = containerProbes(m.apimanager.Spec.System.MemcachedProbes)
+}
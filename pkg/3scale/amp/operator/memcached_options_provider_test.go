@@ -134,6 +134,41 @@ func TestMemcachedOptionsProvider(t *testing.T) {
 				return opts
 			},
 		},
+		{"WithRestrictedSecurityContextPreset",
+			func() *appsv1alpha1.APIManager {
+				apimanager := basicApimanager()
+				apimanager.Spec.SecurityContextPreset = &[]appsv1alpha1.SecurityContextPreset{appsv1alpha1.SecurityContextPresetRestricted}[0]
+				return apimanager
+			},
+			func() *component.MemcachedOptions {
+				opts := defaultMemcachedOptions()
+				opts.PodSecurityContext = &v1.PodSecurityContext{
+					RunAsNonRoot:   &[]bool{true}[0],
+					SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+				}
+				opts.ContainerSecurityContext = &v1.SecurityContext{
+					AllowPrivilegeEscalation: &falseValue,
+					Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+					ReadOnlyRootFilesystem:   &[]bool{true}[0],
+				}
+				return opts
+			},
+		},
+		{"WithSecurityContextOverridingPreset",
+			func() *appsv1alpha1.APIManager {
+				apimanager := basicApimanager()
+				apimanager.Spec.SecurityContextPreset = &[]appsv1alpha1.SecurityContextPreset{appsv1alpha1.SecurityContextPresetRestricted}[0]
+				apimanager.Spec.System.MemcachedPodSecurityContext = &v1.PodSecurityContext{RunAsUser: &[]int64{1001}[0]}
+				apimanager.Spec.System.MemcachedSecurityContext = &v1.SecurityContext{Privileged: &falseValue}
+				return apimanager
+			},
+			func() *component.MemcachedOptions {
+				opts := defaultMemcachedOptions()
+				opts.PodSecurityContext = &v1.PodSecurityContext{RunAsUser: &[]int64{1001}[0]}
+				opts.ContainerSecurityContext = &v1.SecurityContext{Privileged: &falseValue}
+				return opts
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -8,6 +8,8 @@ import (
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
 	appsv1 "github.com/openshift/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
@@ -69,3 +71,44 @@ func TestMemcachedDCReconciler(t *testing.T) {
 		})
 	}
 }
+
+func TestMemcachedDCReconcilerExternalMemcached(t *testing.T) {
+	log := logf.Log.WithName("operator_test")
+	ctx := context.TODO()
+	trueValue := true
+	apimanager := basicApimanager()
+	apimanager.Spec.ExternalComponents = &appsv1alpha1.ExternalComponentsSpec{
+		System: &appsv1alpha1.ExternalSystemComponents{Memcached: &trueValue},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	err := appsv1.AddToScheme(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// memcached previously deployed by the operator
+	existingDC := &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "system-memcache", Namespace: namespace},
+	}
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClient(existingDC)
+	clientAPIReader := fake.NewFakeClient(existingDC)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	baseAPIManagerLogicReconciler := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+
+	reconciler := NewMemcachedReconciler(baseAPIManagerLogicReconciler)
+	_, err = reconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cl.Get(ctx, types.NamespacedName{Name: "system-memcache", Namespace: namespace}, &appsv1.DeploymentConfig{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("memcached DC should be deleted when memcached is external: %v", err)
+	}
+}
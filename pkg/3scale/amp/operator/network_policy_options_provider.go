@@ -0,0 +1,60 @@
+package operator
+
+import (
+	"fmt"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+)
+
+type NetworkPolicyOptionsProvider struct {
+	apimanager           *appsv1alpha1.APIManager
+	networkPolicyOptions *component.NetworkPolicyOptions
+}
+
+func NewNetworkPolicyOptionsProvider(apimanager *appsv1alpha1.APIManager) *NetworkPolicyOptionsProvider {
+	return &NetworkPolicyOptionsProvider{
+		apimanager:           apimanager,
+		networkPolicyOptions: component.NewNetworkPolicyOptions(),
+	}
+}
+
+func (n *NetworkPolicyOptionsProvider) GetNetworkPolicyOptions() (*component.NetworkPolicyOptions, error) {
+	n.networkPolicyOptions.CommonLabels = n.commonLabels()
+
+	n.networkPolicyOptions.MonitoringSources = component.DefaultNetworkPolicyMonitoringSources()
+	if n.apimanager.Spec.NetworkPolicies != nil {
+		n.networkPolicyOptions.IngressSources = n.apimanager.Spec.NetworkPolicies.IngressSources
+		if len(n.apimanager.Spec.NetworkPolicies.MonitoringSources) > 0 {
+			n.networkPolicyOptions.MonitoringSources = n.apimanager.Spec.NetworkPolicies.MonitoringSources
+		}
+	}
+
+	if n.apimanager.IsQueueAutoscalingEnabled() {
+		n.networkPolicyOptions.AutoscalerSources = component.DefaultNetworkPolicyAutoscalerSources()
+		if n.apimanager.Spec.NetworkPolicies != nil && len(n.apimanager.Spec.NetworkPolicies.AutoscalerSources) > 0 {
+			n.networkPolicyOptions.AutoscalerSources = n.apimanager.Spec.NetworkPolicies.AutoscalerSources
+		}
+	}
+
+	n.networkPolicyOptions.SystemDatabasePostgreSQL = n.apimanager.IsSystemPostgreSQLEnabled()
+	n.networkPolicyOptions.ExternalSystemDatabase = n.apimanager.IsExternal(appsv1alpha1.SystemDatabase)
+	n.networkPolicyOptions.ExternalSystemRedis = n.apimanager.IsExternal(appsv1alpha1.SystemRedis)
+	n.networkPolicyOptions.ExternalBackendRedis = n.apimanager.IsExternal(appsv1alpha1.BackendRedis)
+	n.networkPolicyOptions.ExternalZyncDatabase = n.apimanager.IsExternal(appsv1alpha1.ZyncDatabase)
+	n.networkPolicyOptions.ExternalSystemMemcached = n.apimanager.IsExternal(appsv1alpha1.SystemMemcached)
+	n.networkPolicyOptions.BackendRedisSentinel = n.apimanager.IsBackendRedisSentinelEnabled()
+	n.networkPolicyOptions.SystemRedisSentinel = n.apimanager.IsSystemRedisSentinelEnabled()
+
+	err := n.networkPolicyOptions.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("GetNetworkPolicyOptions validating: %w", err)
+	}
+	return n.networkPolicyOptions, nil
+}
+
+func (n *NetworkPolicyOptionsProvider) commonLabels() map[string]string {
+	return map[string]string{
+		"app": *n.apimanager.Spec.AppLabel,
+	}
+}
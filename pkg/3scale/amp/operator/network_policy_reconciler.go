@@ -0,0 +1,65 @@
+package operator
+
+import (
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type NetworkPolicyReconciler struct {
+	*BaseAPIManagerLogicReconciler
+}
+
+func NewNetworkPolicyReconciler(baseAPIManagerLogicReconciler *BaseAPIManagerLogicReconciler) *NetworkPolicyReconciler {
+	return &NetworkPolicyReconciler{
+		BaseAPIManagerLogicReconciler: baseAPIManagerLogicReconciler,
+	}
+}
+
+func (r *NetworkPolicyReconciler) Reconcile() (reconcile.Result, error) {
+	networkPolicy, err := NetworkPolicy(r.apiManager)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	networkPolicies := []*networkingv1.NetworkPolicy{
+		networkPolicy.ApicastStagingNetworkPolicy(),
+		networkPolicy.ApicastProductionNetworkPolicy(),
+		networkPolicy.BackendListenerNetworkPolicy(),
+		networkPolicy.BackendWorkerNetworkPolicy(),
+		networkPolicy.BackendCronNetworkPolicy(),
+		networkPolicy.BackendRedisNetworkPolicy(),
+		networkPolicy.BackendRedisSentinelNetworkPolicy(),
+		networkPolicy.SystemAppNetworkPolicy(),
+		networkPolicy.SystemSidekiqNetworkPolicy(),
+		networkPolicy.SystemRedisNetworkPolicy(),
+		networkPolicy.SystemRedisSentinelNetworkPolicy(),
+		networkPolicy.SystemMySQLNetworkPolicy(),
+		networkPolicy.SystemPostgreSQLNetworkPolicy(),
+		networkPolicy.SystemMemcachedNetworkPolicy(),
+		networkPolicy.SystemSearchdNetworkPolicy(),
+		networkPolicy.ZyncNetworkPolicy(),
+		networkPolicy.ZyncQueNetworkPolicy(),
+		networkPolicy.ZyncDatabaseNetworkPolicy(),
+	}
+
+	for _, desired := range networkPolicies {
+		err = r.ReconcileNetworkPolicy(desired, reconcilers.NetworkPolicyMutator)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func NetworkPolicy(apimanager *appsv1alpha1.APIManager) (*component.NetworkPolicy, error) {
+	optsProvider := NewNetworkPolicyOptionsProvider(apimanager)
+	opts, err := optsProvider.GetNetworkPolicyOptions()
+	if err != nil {
+		return nil, err
+	}
+	return component.NewNetworkPolicy(opts), nil
+}
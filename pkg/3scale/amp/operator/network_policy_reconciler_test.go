@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestNetworkPolicyReconciler(t *testing.T) {
+	log := logf.Log.WithName("operator_test")
+	ctx := context.TODO()
+	apimanager := basicApimanager()
+	apimanager.Spec.ExternalComponents = &appsv1alpha1.ExternalComponentsSpec{
+		Backend: &appsv1alpha1.ExternalBackendComponents{Redis: &[]bool{true}[0]},
+	}
+	routerSource := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"},
+		},
+	}
+	apimanager.Spec.NetworkPolicies = &appsv1alpha1.NetworkPoliciesSpec{
+		Enabled:        true,
+		IngressSources: []networkingv1.NetworkPolicyPeer{routerSource},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+
+	objs := []runtime.Object{}
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	baseAPIManagerLogicReconciler := NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager)
+
+	reconciler := NewNetworkPolicyReconciler(baseAPIManagerLogicReconciler)
+	_, err := reconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		objName string
+		exists  bool
+	}{
+		{"apicast-production", true},
+		{"backend-listener", true},
+		{"system-app", true},
+		{"system-mysql", true},
+		{"system-postgresql", false},
+		{"zync-database", true},
+		{"backend-redis", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.objName, func(subT *testing.T) {
+			obj := &networkingv1.NetworkPolicy{}
+			err := cl.Get(ctx, types.NamespacedName{Name: tc.objName, Namespace: namespace}, obj)
+			if tc.exists && err != nil {
+				subT.Errorf("error fetching object %s: %v", tc.objName, err)
+			}
+			if !tc.exists && !errors.IsNotFound(err) {
+				subT.Errorf("object %s should not exist: %v", tc.objName, err)
+			}
+		})
+	}
+
+	listener := &networkingv1.NetworkPolicy{}
+	err = cl.Get(ctx, types.NamespacedName{Name: "backend-listener", Namespace: namespace}, listener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// router plus apicast staging, apicast production, system-app and system-sidekiq
+	if len(listener.Spec.Ingress[0].From) != 5 {
+		t.Errorf("unexpected backend-listener ingress sources: %v", listener.Spec.Ingress[0].From)
+	}
+
+	// disabling network policies removes them
+	apimanager.Spec.NetworkPolicies.Enabled = false
+	_, err = reconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cl.Get(ctx, types.NamespacedName{Name: "backend-listener", Namespace: namespace}, listener)
+	if !errors.IsNotFound(err) {
+		t.Errorf("network policy should have been deleted: %v", err)
+	}
+}
+
+func TestNetworkPolicyReconcilerDatabaseClients(t *testing.T) {
+	log := logf.Log.WithName("operator_test")
+	ctx := context.TODO()
+	apimanager := basicApimanager()
+	apimanager.Spec.NetworkPolicies = &appsv1alpha1.NetworkPoliciesSpec{Enabled: true}
+	apimanager.Spec.Zync = &appsv1alpha1.ZyncSpec{
+		QueSpec: &appsv1alpha1.ZyncQueSpec{QueueAutoscaling: &appsv1alpha1.QueueAutoscalingSpec{MaxReplicas: 5}},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+
+	cl := fake.NewFakeClient()
+	clientset := fakeclientset.NewSimpleClientset()
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, cl, log, clientset.Discovery(), record.NewFakeRecorder(10000))
+	reconciler := NewNetworkPolicyReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+	if _, err := reconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	preflightJob := component.NewPreflight(&component.PreflightOptions{Labels: map[string]string{"app": "3scale-api-management"}}).Job(
+		&component.PreflightCheck{Name: component.PreflightCheckZyncDatabase, Image: "postgresql", Database: &component.PreflightDatabaseConnection{}})
+	kedaOperatorLabels := map[string]string{"app": "keda-operator"}
+
+	cases := []struct {
+		name      string
+		policy    string
+		podLabels map[string]string
+		allowed   bool
+	}{
+		{"PreflightToZyncDatabase", "zync-database", preflightJob.Spec.Template.Labels, true},
+		{"PreflightToSystemMySQL", "system-mysql", preflightJob.Spec.Template.Labels, true},
+		{"KEDAToZyncDatabase", "zync-database", kedaOperatorLabels, true},
+		{"KEDAToSystemMySQL", "system-mysql", kedaOperatorLabels, false},
+		{"ZyncQueToZyncDatabase", "zync-database", map[string]string{"deploymentConfig": "zync-que"}, true},
+		{"ApicastToZyncDatabase", "zync-database", map[string]string{"deploymentConfig": "apicast-production"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			policy := &networkingv1.NetworkPolicy{}
+			if err := cl.Get(ctx, types.NamespacedName{Name: tc.policy, Namespace: namespace}, policy); err != nil {
+				subT.Fatal(err)
+			}
+			allowed := false
+			for _, peer := range policy.Spec.Ingress[0].From {
+				selector, err := metav1.LabelSelectorAsSelector(peer.PodSelector)
+				if err != nil {
+					subT.Fatal(err)
+				}
+				allowed = allowed || selector.Matches(labels.Set(tc.podLabels))
+			}
+			if allowed != tc.allowed {
+				subT.Errorf("expected allowed %t, got %t", tc.allowed, allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// PreflightOptionsProvider builds the checks of the external components from their secrets
+type PreflightOptionsProvider struct {
+	apimanager   *appsv1alpha1.APIManager
+	namespace    string
+	client       client.Client
+	options      *component.PreflightOptions
+	secretSource *helper.SecretSource
+}
+
+func NewPreflightOptionsProvider(apimanager *appsv1alpha1.APIManager, namespace string, client client.Client) *PreflightOptionsProvider {
+	return &PreflightOptionsProvider{
+		apimanager:   apimanager,
+		namespace:    namespace,
+		client:       client,
+		options:      component.NewPreflightOptions(),
+		secretSource: helper.NewSecretSource(client, namespace),
+	}
+}
+
+func (p *PreflightOptionsProvider) GetPreflightOptions() (*component.PreflightOptions, error) {
+	p.options.Labels = map[string]string{
+		"app":                  *p.apimanager.Spec.AppLabel,
+		"threescale_component": component.PreflightComponentName,
+	}
+	p.options.ImagePullSecrets = component.AmpImagesDefaultImagePullSecrets()
+	if p.apimanager.Spec.ImagePullSecrets != nil {
+		p.options.ImagePullSecrets = p.apimanager.Spec.ImagePullSecrets
+	}
+	p.options.PodSecurityContext = podSecurityContext(p.apimanager, nil)
+	p.options.ContainerSecurityContext = containerSecurityContext(p.apimanager, nil, true)
+
+	err := p.setChecks()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.options.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("GetPreflightOptions validating: %w", err)
+	}
+	return p.options, nil
+}
+
+func (p *PreflightOptionsProvider) setChecks() error {
+	p.options.Checks = []component.PreflightCheck{}
+
+	if p.apimanager.IsExternal(appsv1alpha1.BackendRedis) {
+		image := BackendRedisImageURL()
+		if p.apimanager.Spec.Backend != nil && p.apimanager.Spec.Backend.RedisImage != nil {
+			image = *p.apimanager.Spec.Backend.RedisImage
+		}
+
+		storage, err := p.redisConnection(backendRedisStorageTLSSecretFields, component.BackendSecretBackendRedisStorageSentinelHostsFieldName)
+		if err != nil {
+			return err
+		}
+		queues, err := p.redisConnection(backendRedisQueuesTLSSecretFields, component.BackendSecretBackendRedisQueuesSentinelHostsFieldName)
+		if err != nil {
+			return err
+		}
+		p.options.Checks = append(p.options.Checks,
+			component.PreflightCheck{Name: component.PreflightCheckBackendRedisStorage, Image: image, Redis: storage},
+			component.PreflightCheck{Name: component.PreflightCheckBackendRedisQueues, Image: image, Redis: queues},
+		)
+	}
+
+	if p.apimanager.IsExternal(appsv1alpha1.SystemRedis) {
+		image := SystemRedisImageURL()
+		if p.apimanager.Spec.System != nil && p.apimanager.Spec.System.RedisImage != nil {
+			image = *p.apimanager.Spec.System.RedisImage
+		}
+
+		connection, err := p.redisConnection(systemRedisTLSSecretFields, component.SystemSecretSystemRedisSentinelHosts)
+		if err != nil {
+			return err
+		}
+		p.options.Checks = append(p.options.Checks,
+			component.PreflightCheck{Name: component.PreflightCheckSystemRedis, Image: image, Redis: connection})
+	}
+
+	if p.apimanager.IsExternal(appsv1alpha1.SystemDatabase) {
+		connection, err := p.databaseConnection(systemDatabaseTLSSecretFields)
+		if err != nil {
+			return err
+		}
+		// Databases without a supported client, like Oracle, are not checked
+		if connection != nil {
+			image := SystemPostgreSQLImageURL()
+			if connection.Engine == component.DatabaseEngineMySQL {
+				image = SystemMySQLImageURL()
+			}
+			p.options.Checks = append(p.options.Checks,
+				component.PreflightCheck{Name: component.PreflightCheckSystemDatabase, Image: image, Database: connection})
+		}
+	}
+
+	if p.apimanager.IsExternal(appsv1alpha1.ZyncDatabase) {
+		connection, err := p.databaseConnection(zyncDatabaseTLSSecretFields)
+		if err != nil {
+			return err
+		}
+		if connection != nil {
+			image := ZyncPostgreSQLImageURL()
+			if p.apimanager.Spec.Zync != nil && p.apimanager.Spec.Zync.PostgreSQLImage != nil {
+				image = *p.apimanager.Spec.Zync.PostgreSQLImage
+			}
+			p.options.Checks = append(p.options.Checks,
+				component.PreflightCheck{Name: component.PreflightCheckZyncDatabase, Image: image, Database: connection})
+		}
+	}
+
+	return nil
+}
+
+func (p *PreflightOptionsProvider) redisConnection(fields redisTLSSecretFields, sentinelHostsField string) (*component.PreflightRedisConnection, error) {
+	values, err := p.secretValues(fields.secretName, fields.url, sentinelHostsField, fields.ca, fields.cert, fields.key)
+	if err != nil {
+		return nil, err
+	}
+
+	redisURL, err := url.Parse(values[fields.url])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: parsing %s: %w", fields.secretName, fields.url, err)
+	}
+
+	connection := &component.PreflightRedisConnection{
+		Host: redisURL.Hostname(),
+		Port: urlPort(redisURL, "6379"),
+		TLS:  redisURL.Scheme == "rediss",
+		CA:   values[fields.ca],
+		Cert: values[fields.cert],
+		Key:  values[fields.key],
+	}
+	connection.Username, connection.Password = urlCredentials(redisURL)
+
+	for _, sentinel := range strings.Split(values[sentinelHostsField], ",") {
+		sentinel = strings.TrimSpace(sentinel)
+		if sentinel == "" {
+			continue
+		}
+		if !strings.Contains(sentinel, "://") {
+			sentinel = "redis://" + sentinel
+		}
+		sentinelURL, err := url.Parse(sentinel)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: parsing %s: %w", fields.secretName, sentinelHostsField, err)
+		}
+		connection.SentinelHosts = append(connection.SentinelHosts, net.JoinHostPort(sentinelURL.Hostname(), urlPort(sentinelURL, "26379")))
+		if _, password := urlCredentials(sentinelURL); password != "" {
+			connection.SentinelPassword = password
+		}
+		connection.SentinelTLS = connection.SentinelTLS || sentinelURL.Scheme == "rediss"
+	}
+
+	return connection, nil
+}
+
+// databaseConnection returns nil when the database URL does not use a MySQL or PostgreSQL scheme
+func (p *PreflightOptionsProvider) databaseConnection(fields databaseTLSSecretFields) (*component.PreflightDatabaseConnection, error) {
+	values, err := p.secretValues(fields.secretName, fields.url, fields.sslMode, fields.ca, fields.cert, fields.key)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseURL, err := url.Parse(values[fields.url])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: parsing %s: %w", fields.secretName, fields.url, err)
+	}
+	engine, ok := databaseURLSchemeEngines[databaseURL.Scheme]
+	if !ok {
+		return nil, nil
+	}
+
+	defaultPort := "5432"
+	if engine == component.DatabaseEngineMySQL {
+		defaultPort = "3306"
+	}
+
+	connection := &component.PreflightDatabaseConnection{
+		Engine:   engine,
+		Host:     databaseURL.Hostname(),
+		Port:     urlPort(databaseURL, defaultPort),
+		Database: strings.TrimPrefix(databaseURL.Path, "/"),
+		SSLMode:  values[fields.sslMode],
+		CA:       values[fields.ca],
+		Cert:     values[fields.cert],
+		Key:      values[fields.key],
+	}
+	connection.Username, connection.Password = urlCredentials(databaseURL)
+
+	return connection, nil
+}
+
+func (p *PreflightOptionsProvider) secretValues(secretName string, fieldNames ...string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, fieldName := range fieldNames {
+		val, err := p.secretSource.FieldValueFromRequiredSecret(secretName, fieldName, "")
+		if err != nil {
+			return nil, err
+		}
+		values[fieldName] = val
+	}
+	return values, nil
+}
+
+func urlPort(u *url.URL, defaultPort string) string {
+	if u.Port() == "" {
+		return defaultPort
+	}
+	return u.Port()
+}
+
+func urlCredentials(u *url.URL) (string, string) {
+	if u.User == nil {
+		return "", ""
+	}
+	password, _ := u.User.Password()
+	return u.User.Username(), password
+}
@@ -0,0 +1,338 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+const (
+	PreflightChecksPassedReason  common.ConditionReason = "ChecksPassed"
+	PreflightChecksFailedReason  common.ConditionReason = "ChecksFailed"
+	PreflightChecksRunningReason common.ConditionReason = "ChecksRunning"
+)
+
+const (
+	// PreflightRetryInterval is the time after which failed checks are run again
+	PreflightRetryInterval = 5 * time.Minute
+	// preflightRunningRequeueDelay bounds the wait for running checks,
+	// their Jobs being watched as well
+	preflightRunningRequeueDelay = 30 * time.Second
+)
+
+// PreflightReconciler checks the external components whenever their settings change.
+// Each connection is checked by a Job, and the outcome is reported by the Preflight condition.
+// The components are not rolled out while the checks are running or failed.
+type PreflightReconciler struct {
+	*BaseAPIManagerLogicReconciler
+}
+
+func NewPreflightReconciler(baseAPIManagerLogicReconciler *BaseAPIManagerLogicReconciler) *PreflightReconciler {
+	return &PreflightReconciler{
+		BaseAPIManagerLogicReconciler: baseAPIManagerLogicReconciler,
+	}
+}
+
+type preflightCheckResult struct {
+	name    string
+	message string
+	// complete is false while the check is running
+	complete bool
+	passed   bool
+	// retryAfter is the time left to run a failed check again
+	retryAfter time.Duration
+}
+
+// Reconcile runs the checks until all of them pass for the current settings of the
+// external components, and requeues the reconciliation to wait for running checks and
+// to retry failed ones. The rollout is blocked until then, the result requeueing after
+// a delay. Once they passed, the checks are not run again until the settings change,
+// including the connection secrets and the 3scale release.
+func (r *PreflightReconciler) Reconcile() (reconcile.Result, error) {
+	var checks []component.PreflightCheck
+	var preflight *component.Preflight
+	checksHash := ""
+	if !r.apiManager.IsPreflightSkipped() {
+		options, err := NewPreflightOptionsProvider(r.apiManager, r.apiManager.Namespace, r.Client()).GetPreflightOptions()
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		checksHash = component.PreflightChecksHash(options.Checks)
+		preflight = component.NewPreflight(options)
+		// Checks passed for the current settings are not run again
+		if checksHash != r.apiManager.Status.PreflightChecksHash {
+			checks = options.Checks
+		}
+	}
+
+	desiredSecrets := []string{}
+	desiredJobs := []string{}
+	results := []preflightCheckResult{}
+	for idx := range checks {
+		check := &checks[idx]
+
+		secret := preflight.Secret(check)
+		secret.Namespace = r.apiManager.Namespace
+		// The secret is generated by the operator, unlike the secrets provided by users
+		err := r.SetControllerOwnerReference(r.apiManager, secret)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		err = r.ReconcileSecret(secret, reconcilers.SecretDataMutator)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		desiredSecrets = append(desiredSecrets, secret.Name)
+
+		job := preflight.Job(check)
+		err = r.ReconcileResource(&batchv1.Job{}, job, reconcilers.CreateOnlyMutator)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		desiredJobs = append(desiredJobs, job.Name)
+
+		result, err := r.checkResult(check.Name, job.Name)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		results = append(results, result)
+	}
+
+	err := r.deleteStaleObjects(desiredSecrets, desiredJobs)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	res, err := r.reconcilePreflightStatus(checksHash, results)
+	if err != nil || res.Requeue {
+		return res, err
+	}
+
+	return preflightResultRequeue(results), nil
+}
+
+// checkResult reads the outcome of the check from the termination message of its pod.
+// Failed checks are deleted once PreflightRetryInterval has elapsed, to be run again.
+func (r *PreflightReconciler) checkResult(checkName, jobName string) (preflightCheckResult, error) {
+	result := preflightCheckResult{name: checkName}
+
+	job := &batchv1.Job{}
+	err := r.Client().Get(r.Context(), types.NamespacedName{Name: jobName, Namespace: r.apiManager.Namespace}, job)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return result, nil
+		}
+		return result, err
+	}
+
+	var finished *batchv1.JobCondition
+	for idx := range job.Status.Conditions {
+		condition := &job.Status.Conditions[idx]
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == v1.ConditionTrue {
+			finished = condition
+		}
+	}
+	if finished == nil {
+		return result, nil
+	}
+
+	message, err := r.jobTerminationMessage(job)
+	if err != nil {
+		return result, err
+	}
+	if message == "" {
+		message = finished.Message
+	}
+
+	result.complete = true
+	result.passed = finished.Type == batchv1.JobComplete
+	result.message = message
+	if result.passed {
+		return result, nil
+	}
+
+	result.retryAfter = PreflightRetryInterval - time.Since(finished.LastTransitionTime.Time)
+	if result.retryAfter > 0 {
+		return result, nil
+	}
+
+	r.Logger().Info("Running failed preflight check again", "check", checkName)
+	err = r.Client().Delete(r.Context(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return result, err
+	}
+	result.retryAfter = time.Second
+	return result, nil
+}
+
+// jobTerminationMessage returns the termination message of the last pod of the Job
+func (r *PreflightReconciler) jobTerminationMessage(job *batchv1.Job) (string, error) {
+	podList := &v1.PodList{}
+	err := r.Client().List(r.Context(), podList,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return "", err
+	}
+
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+				return strings.TrimSpace(status.State.Terminated.Message), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// deleteStaleObjects deletes the Jobs and secrets of the checks not desired anymore,
+// either superseded by a new version of the check or of external components no longer used
+func (r *PreflightReconciler) deleteStaleObjects(desiredSecrets, desiredJobs []string) error {
+	selector := client.MatchingLabels{"threescale_component": component.PreflightComponentName}
+
+	jobList := &batchv1.JobList{}
+	err := r.Client().List(r.Context(), jobList, client.InNamespace(r.apiManager.Namespace), selector)
+	if err != nil {
+		return err
+	}
+	for idx := range jobList.Items {
+		job := &jobList.Items[idx]
+		if !metav1.IsControlledBy(job, r.apiManager) || helper.ArrayContains(desiredJobs, job.Name) {
+			continue
+		}
+		err = r.Client().Delete(r.Context(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	secretList := &v1.SecretList{}
+	err = r.Client().List(r.Context(), secretList, client.InNamespace(r.apiManager.Namespace), selector)
+	if err != nil {
+		return err
+	}
+	for idx := range secretList.Items {
+		secret := &secretList.Items[idx]
+		if !metav1.IsControlledBy(secret, r.apiManager) || helper.ArrayContains(desiredSecrets, secret.Name) {
+			continue
+		}
+		err = r.Client().Delete(r.Context(), secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcilePreflightStatus reports the outcome of the checks, and records the hash of their settings
+// once all of them passed. The condition is kept as is once the checks of the current settings passed.
+func (r *PreflightReconciler) reconcilePreflightStatus(checksHash string, results []preflightCheckResult) (reconcile.Result, error) {
+	var changed bool
+	switch {
+	case checksHash == "":
+		// Skipped checks or no external component to check
+		changed = r.apiManager.Status.Conditions.RemoveCondition(appsv1alpha1.APIManagerPreflightConditionType)
+		if r.apiManager.Status.PreflightChecksHash != "" {
+			r.apiManager.Status.PreflightChecksHash = ""
+			changed = true
+		}
+	case len(results) > 0:
+		condition := preflightCondition(results)
+		changed = r.apiManager.Status.Conditions.SetCondition(condition)
+		if condition.Status == v1.ConditionTrue {
+			r.apiManager.Status.PreflightChecksHash = checksHash
+			changed = true
+		}
+	}
+
+	if !changed {
+		return reconcile.Result{}, nil
+	}
+
+	err := r.Client().Status().Update(r.Context(), r.apiManager)
+	if err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// preflightCondition is false when any check failed, unknown while checks are running
+// and true once all of them passed. The message reports the outcome of each check.
+func preflightCondition(results []preflightCheckResult) common.Condition {
+	failed := []string{}
+	running := []string{}
+	passed := []string{}
+	for _, result := range results {
+		switch {
+		case !result.complete:
+			running = append(running, result.name)
+		case result.passed:
+			passed = append(passed, fmt.Sprintf("%s: %s", result.name, result.message))
+		default:
+			failed = append(failed, fmt.Sprintf("%s: %s", result.name, result.message))
+		}
+	}
+
+	condition := common.Condition{Type: appsv1alpha1.APIManagerPreflightConditionType}
+	switch {
+	case len(failed) > 0:
+		condition.Status = v1.ConditionFalse
+		condition.Reason = PreflightChecksFailedReason
+		condition.Message = strings.Join(failed, "; ")
+	case len(running) > 0:
+		condition.Status = v1.ConditionUnknown
+		condition.Reason = PreflightChecksRunningReason
+		condition.Message = fmt.Sprintf("Waiting for checks: %s", strings.Join(running, ", "))
+	default:
+		condition.Status = v1.ConditionTrue
+		condition.Reason = PreflightChecksPassedReason
+		condition.Message = strings.Join(passed, "; ")
+	}
+
+	return condition
+}
+
+// preflightResultRequeue requeues the reconciliation until all the checks pass.
+// Failed checks are waited for until they are run again.
+func preflightResultRequeue(results []preflightCheckResult) reconcile.Result {
+	var requeueAfter time.Duration
+	for _, result := range results {
+		delay := result.retryAfter
+		if !result.complete {
+			delay = preflightRunningRequeueDelay
+		}
+		if result.passed || delay <= 0 {
+			continue
+		}
+		if requeueAfter == 0 || delay < requeueAfter {
+			requeueAfter = delay
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: requeueAfter}
+}
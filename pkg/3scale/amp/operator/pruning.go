@@ -0,0 +1,122 @@
+package operator
+
+import (
+	grafanav1alpha1 "github.com/grafana-operator/grafana-operator/v4/api/integreatly/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
+)
+
+// prunableObjectLists are the kinds of objects deleted by the pruning pass when
+// they are owned by the APIManager but no longer required by its spec.
+// Secrets are not owned by the APIManager, so they are never pruned.
+// DeploymentConfigs go first, ImageStreams are only pruned once no DeploymentConfig is left.
+var prunableObjectLists = []func() client.ObjectList{
+	func() client.ObjectList { return &appsv1.DeploymentConfigList{} },
+	func() client.ObjectList { return &k8sappsv1.DeploymentList{} },
+	func() client.ObjectList { return &k8sappsv1.StatefulSetList{} },
+	func() client.ObjectList { return &v1.ServiceList{} },
+	func() client.ObjectList { return &v1.ConfigMapList{} },
+	func() client.ObjectList { return &v1.PersistentVolumeClaimList{} },
+	func() client.ObjectList { return &v1.ServiceAccountList{} },
+	func() client.ObjectList { return &rbacv1.RoleList{} },
+	func() client.ObjectList { return &rbacv1.RoleBindingList{} },
+	func() client.ObjectList { return &imagev1.ImageStreamList{} },
+	func() client.ObjectList { return &routev1.RouteList{} },
+	func() client.ObjectList { return &networkingv1.IngressList{} },
+	func() client.ObjectList { return &networkingv1.NetworkPolicyList{} },
+	func() client.ObjectList { return &policyv1.PodDisruptionBudgetList{} },
+	func() client.ObjectList { return &autoscalingv2.HorizontalPodAutoscalerList{} },
+	func() client.ObjectList { return &monitoringv1.PrometheusRuleList{} },
+	func() client.ObjectList { return &monitoringv1.ServiceMonitorList{} },
+	func() client.ObjectList { return &monitoringv1.PodMonitorList{} },
+	func() client.ObjectList { return &grafanav1alpha1.GrafanaDashboardList{} },
+	func() client.ObjectList { return &kedav1alpha1.ScaledObjectList{} },
+	func() client.ObjectList { return &kedav1alpha1.TriggerAuthenticationList{} },
+}
+
+// PruneOrphanedObjects deletes the objects owned by the APIManager which have not been
+// reconciled by any component, for instance after switching the system database or moving
+// a component to the external components. PersistentVolumeClaims are kept unless the
+// pruning policy says otherwise, and unmanaged objects are never deleted.
+// It is expected to run once all the components have been reconciled.
+func (r *BaseAPIManagerLogicReconciler) PruneOrphanedObjects() (reconcile.Result, error) {
+	deploymentConfigsLeft := false
+
+	for _, newList := range prunableObjectLists {
+		list := newList()
+		err := r.Client().List(r.Context(), list, client.InNamespace(r.apiManager.Namespace))
+		if err != nil {
+			// Kind not available in the cluster
+			if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		for _, item := range items {
+			obj, ok := item.(common.KubernetesObject)
+			if !ok || !r.isOwnedByAPIManager(obj) {
+				continue
+			}
+
+			if _, ok := obj.(*imagev1.ImageStream); ok && deploymentConfigsLeft {
+				continue
+			}
+
+			if !r.isPrunable(obj) {
+				if _, ok := obj.(*appsv1.DeploymentConfig); ok {
+					deploymentConfigsLeft = true
+				}
+				continue
+			}
+
+			r.Logger().Info("Pruning object no longer required by the APIManager", "object", objectKindName(obj, obj.GetName()))
+			err := r.DeleteResource(obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			r.EventRecorder().Eventf(r.apiManager, v1.EventTypeNormal, "ObjectPruned",
+				"%s deleted, no longer required by the APIManager", objectKindName(obj, obj.GetName()))
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *BaseAPIManagerLogicReconciler) isPrunable(obj common.KubernetesObject) bool {
+	if r.desiredObjects[objectKindName(obj, obj.GetName())] {
+		return false
+	}
+
+	if obj.GetDeletionTimestamp() != nil || common.IsObjectUnmanaged(obj) {
+		return false
+	}
+
+	if _, ok := obj.(*v1.PersistentVolumeClaim); ok {
+		return r.apiManager.IsPersistentVolumeClaimPruningEnabled()
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/reconcilers"
+)
+
+func TestPruneOrphanedObjects(t *testing.T) {
+	namespace := "operator-unittest"
+
+	cases := []struct {
+		name                  string
+		pvcPolicy             *appsv1alpha1.PrunePolicy
+		expectedPrunedObjects []string
+	}{
+		{"PVCsRetainedByDefault", nil, []string{"DeploymentConfig/system-mysql", "Service/system-mysql", "ImageStream/system-mysql"}},
+		{"PVCsDeleted", &[]appsv1alpha1.PrunePolicy{appsv1alpha1.PrunePolicyDelete}[0], []string{"DeploymentConfig/system-mysql", "Service/system-mysql", "ImageStream/system-mysql", "PersistentVolumeClaim/mysql-storage"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			apimanager := &appsv1alpha1.APIManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "example-apimanager", Namespace: namespace, UID: types.UID("apimanager-uid")},
+				Spec:       appsv1alpha1.APIManagerSpec{Pruning: &appsv1alpha1.PruningSpec{PersistentVolumeClaims: tc.pvcPolicy}},
+			}
+			owned := metav1.ObjectMeta{Namespace: namespace, OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps.3scale.net/v1alpha1", Kind: "APIManager", Name: apimanager.Name, UID: apimanager.UID,
+			}}}
+			objectMeta := func(meta metav1.ObjectMeta, name string) metav1.ObjectMeta {
+				meta.Name = name
+				return meta
+			}
+			unmanaged := objectMeta(owned, "system-environment")
+			unmanaged.Annotations = map[string]string{common.UnmanagedAnnotation: "true"}
+
+			objs := []runtime.Object{
+				// Left behind after switching the system database to PostgreSQL
+				&appsv1.DeploymentConfig{ObjectMeta: objectMeta(owned, "system-mysql")},
+				&v1.Service{ObjectMeta: objectMeta(owned, "system-mysql")},
+				&imagev1.ImageStream{ObjectMeta: objectMeta(owned, "system-mysql")},
+				&v1.PersistentVolumeClaim{ObjectMeta: objectMeta(owned, "mysql-storage")},
+				// Required by the current spec
+				&v1.Service{ObjectMeta: objectMeta(owned, "system-postgresql")},
+				// Not owned by the APIManager
+				&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user-configmap", Namespace: namespace}},
+				&v1.ConfigMap{ObjectMeta: unmanaged},
+			}
+
+			apimanagerLogicReconciler, cl := testBaseAPIManagerLogicReconciler(subT, apimanager, objs...)
+
+			desired := &v1.Service{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{Name: "system-postgresql"},
+			}
+			if err := apimanagerLogicReconciler.ReconcileService(desired, reconcilers.CreateOnlyMutator); err != nil {
+				subT.Fatal(err)
+			}
+
+			if _, err := apimanagerLogicReconciler.PruneOrphanedObjects(); err != nil {
+				subT.Fatal(err)
+			}
+
+			for _, obj := range objs {
+				key := client.ObjectKeyFromObject(obj.(client.Object))
+				existing := obj.DeepCopyObject().(client.Object)
+				err := cl.Get(context.TODO(), key, existing)
+				if err != nil && !errors.IsNotFound(err) {
+					subT.Fatal(err)
+				}
+
+				kindName := objectKindName(existing, key.Name)
+				expectedPruned := false
+				for _, pruned := range tc.expectedPrunedObjects {
+					expectedPruned = expectedPruned || pruned == kindName
+				}
+				if pruned := errors.IsNotFound(err); pruned != expectedPruned {
+					subT.Errorf("%s: expected pruned %t, got %t", kindName, expectedPruned, pruned)
+				}
+			}
+		})
+	}
+}
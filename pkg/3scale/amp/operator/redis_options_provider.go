@@ -61,6 +61,8 @@ func (r *RedisOptionsProvider) GetRedisOptions() (*component.RedisOptions, error
 	r.setPersistentVolumeClaimOptions()
 	r.setPriorityClassNames()
 	r.setTopologySpreadConstraints()
+	r.setSecurityContexts()
+	r.setProbes()
 
 	// Should the operator be reading redis secrets?
 	// When HA is disabled, do we support external redis?
@@ -72,6 +74,8 @@ func (r *RedisOptionsProvider) GetRedisOptions() (*component.RedisOptions, error
 		return nil, fmt.Errorf("GetRedisOptions reading secret options: %w", err)
 	}
 
+	r.setRedisSentinelOptions()
+
 	err = r.options.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("GetRedisOptions validating: %w", err)
@@ -159,6 +163,63 @@ func (r *RedisOptionsProvider) setSecretBasedOptions() error {
 	return nil
 }
 
+// setRedisSentinelOptions wires the redis secrets to the sentinels deployed by the
+// operator. When redis sentinel gets disabled, the secrets still pointing to those
+// sentinels are reverted to the single redis instance defaults.
+func (r *RedisOptionsProvider) setRedisSentinelOptions() {
+	if r.apimanager.IsBackendRedisSentinelEnabled() {
+		r.options.BackendRedisSentinel = r.redisSentinelOptions(r.apimanager.Spec.Backend.RedisSentinel)
+		r.options.BackendRedisSentinelManagedSecret = true
+		r.options.BackendStorageURL = component.DefaultBackendRedisSentinelStorageURL()
+		r.options.BackendQueuesURL = component.DefaultBackendRedisSentinelQueuesURL()
+		r.options.BackendRedisStorageSentinelHosts = component.DefaultBackendRedisSentinelServiceHosts()
+		r.options.BackendRedisStorageSentinelRole = component.DefaultRedisSentinelRole()
+		r.options.BackendRedisQueuesSentinelHosts = component.DefaultBackendRedisSentinelServiceHosts()
+		r.options.BackendRedisQueuesSentinelRole = component.DefaultRedisSentinelRole()
+	} else if r.options.BackendRedisStorageSentinelHosts == component.DefaultBackendRedisSentinelServiceHosts() {
+		r.options.BackendRedisSentinelManagedSecret = true
+		r.options.BackendStorageURL = component.DefaultBackendRedisStorageURL()
+		r.options.BackendQueuesURL = component.DefaultBackendRedisQueuesURL()
+		r.options.BackendRedisStorageSentinelHosts = component.DefaultBackendStorageSentinelHosts()
+		r.options.BackendRedisStorageSentinelRole = component.DefaultBackendStorageSentinelRole()
+		r.options.BackendRedisQueuesSentinelHosts = component.DefaultBackendQueuesSentinelHosts()
+		r.options.BackendRedisQueuesSentinelRole = component.DefaultBackendQueuesSentinelRole()
+	}
+
+	if r.apimanager.IsSystemRedisSentinelEnabled() {
+		r.options.SystemRedisSentinel = r.redisSentinelOptions(r.apimanager.Spec.System.RedisSentinel)
+		r.options.SystemRedisSentinelManagedSecret = true
+		r.options.SystemRedisURL = component.DefaultSystemRedisSentinelURL()
+		r.options.SystemRedisSentinelsHosts = component.DefaultSystemRedisSentinelServiceHosts()
+		r.options.SystemRedisSentinelsRole = component.DefaultRedisSentinelRole()
+	} else if r.options.SystemRedisSentinelsHosts == component.DefaultSystemRedisSentinelServiceHosts() {
+		r.options.SystemRedisSentinelManagedSecret = true
+		r.options.SystemRedisURL = component.DefaultSystemRedisURL()
+		r.options.SystemRedisSentinelsHosts = component.DefaultSystemRedisSentinelHosts()
+		r.options.SystemRedisSentinelsRole = component.DefaultSystemRedisSentinelRole()
+	}
+}
+
+func (r *RedisOptionsProvider) redisSentinelOptions(spec *appsv1alpha1.RedisSentinelSpec) *component.RedisSentinelOptions {
+	options := &component.RedisSentinelOptions{
+		Replicas:                              component.DefaultRedisSentinelReplicas(),
+		SentinelContainerResourceRequirements: &v1.ResourceRequirements{},
+	}
+
+	if spec.Replicas != nil {
+		options.Replicas = *spec.Replicas
+	}
+
+	if *r.apimanager.Spec.ResourceRequirementsEnabled {
+		options.SentinelContainerResourceRequirements = component.DefaultRedisSentinelContainerResourceRequirements()
+	}
+	if spec.SentinelResources != nil {
+		options.SentinelContainerResourceRequirements = spec.SentinelResources
+	}
+
+	return options
+}
+
 func (r *RedisOptionsProvider) setResourceRequirementsOptions() {
 	if *r.apimanager.Spec.ResourceRequirementsEnabled {
 		r.options.BackendRedisContainerResourceRequirements = component.DefaultBackendRedisContainerResourceRequirements()
@@ -272,3 +333,15 @@ func (r *RedisOptionsProvider) setTopologySpreadConstraints() {
 		r.options.BackendRedisTopologySpreadConstraints = r.apimanager.Spec.Backend.RedisTopologySpreadConstraints
 	}
 }
+
+func (r *RedisOptionsProvider) setSecurityContexts() {
+	r.options.SystemRedisPodSecurityContext = podSecurityContext(r.apimanager, r.apimanager.Spec.System.RedisPodSecurityContext)
+	r.options.SystemRedisContainerSecurityContext = containerSecurityContext(r.apimanager, r.apimanager.Spec.System.RedisSecurityContext, false)
+	r.options.BackendRedisPodSecurityContext = podSecurityContext(r.apimanager, r.apimanager.Spec.Backend.RedisPodSecurityContext)
+	r.options.BackendRedisContainerSecurityContext = containerSecurityContext(r.apimanager, r.apimanager.Spec.Backend.RedisSecurityContext, false)
+}
+
+func (r *RedisOptionsProvider) setProbes() {
+	r.options.SystemRedisProbes = containerProbes(r.apimanager.Spec.System.RedisProbes)
+	r.options.BackendRedisProbes = containerProbes(r.apimanager.Spec.Backend.RedisProbes)
+}
@@ -1,14 +1,23 @@
 package operator
 
 import (
+	"fmt"
+	"time"
+
 	appsv1 "github.com/openshift/api/apps/v1"
 	imagev1 "github.com/openshift/api/image/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/common"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 	"github.com/3scale/3scale-operator/pkg/upgrade"
 )
@@ -24,10 +33,25 @@ type RedisReconciler struct {
 	PersistentVolumeClaim func(redis *component.Redis) *corev1.PersistentVolumeClaim
 	ImageStream           func(redis *component.Redis) *imagev1.ImageStream
 	Secret                func(redis *component.Redis) *corev1.Secret
+	SecretManagedFields   func(redis *component.Redis) []string
+
+	// Redis Sentinel topology
+	SentinelEnabled             func(apimanager *appsv1alpha1.APIManager) bool
+	SentinelStatefulSet         func(redis *component.Redis) *k8sappsv1.StatefulSet
+	SentinelHeadlessService     func(redis *component.Redis) *corev1.Service
+	SentinelService             func(redis *component.Redis) *corev1.Service
+	SentinelPodDisruptionBudget func(redis *component.Redis) *policyv1.PodDisruptionBudget
+	SentinelConfigMap           func(redis *component.Redis) *corev1.ConfigMap
+	SentinelMigrationPVC        func(redis *component.Redis) *corev1.PersistentVolumeClaim
+	SentinelMigrationJob        func(redis *component.Redis) *batchv1.Job
 }
 
 var _ DependencyReconciler = &RedisReconciler{}
 
+// redisSentinelMigrationRequeueDelay bounds the wait for the pods of the single
+// redis instance to terminate, and for the migration Job to finish
+const redisSentinelMigrationRequeueDelay = 10 * time.Second
+
 func NewSystemRedisDependencyReconciler(baseAPIManagerLogicReconciler *BaseAPIManagerLogicReconciler) DependencyReconciler {
 	return &RedisReconciler{
 		BaseAPIManagerLogicReconciler: baseAPIManagerLogicReconciler,
@@ -38,6 +62,16 @@ func NewSystemRedisDependencyReconciler(baseAPIManagerLogicReconciler *BaseAPIMa
 		PersistentVolumeClaim: (*component.Redis).SystemPVC,
 		ImageStream:           (*component.Redis).SystemImageStream,
 		Secret:                (*component.Redis).SystemRedisSecret,
+		SecretManagedFields:   (*component.Redis).SystemRedisSecretManagedFields,
+
+		SentinelEnabled:             (*appsv1alpha1.APIManager).IsSystemRedisSentinelEnabled,
+		SentinelStatefulSet:         (*component.Redis).SystemSentinelStatefulSet,
+		SentinelHeadlessService:     (*component.Redis).SystemSentinelHeadlessService,
+		SentinelService:             (*component.Redis).SystemSentinelService,
+		SentinelPodDisruptionBudget: (*component.Redis).SystemSentinelPodDisruptionBudget,
+		SentinelConfigMap:           (*component.Redis).SystemSentinelConfigMap,
+		SentinelMigrationPVC:        (*component.Redis).SystemSentinelMigrationPVC,
+		SentinelMigrationJob:        (*component.Redis).SystemSentinelMigrationJob,
 	}
 }
 
@@ -51,6 +85,16 @@ func NewBackendRedisDependencyReconciler(baseAPIManagerLogicReconciler *BaseAPIM
 		PersistentVolumeClaim: (*component.Redis).BackendPVC,
 		ImageStream:           (*component.Redis).BackendImageStream,
 		Secret:                (*component.Redis).BackendRedisSecret,
+		SecretManagedFields:   (*component.Redis).BackendRedisSecretManagedFields,
+
+		SentinelEnabled:             (*appsv1alpha1.APIManager).IsBackendRedisSentinelEnabled,
+		SentinelStatefulSet:         (*component.Redis).BackendSentinelStatefulSet,
+		SentinelHeadlessService:     (*component.Redis).BackendSentinelHeadlessService,
+		SentinelService:             (*component.Redis).BackendSentinelService,
+		SentinelPodDisruptionBudget: (*component.Redis).BackendSentinelPodDisruptionBudget,
+		SentinelConfigMap:           (*component.Redis).BackendSentinelConfigMap,
+		SentinelMigrationPVC:        (*component.Redis).BackendSentinelMigrationPVC,
+		SentinelMigrationJob:        (*component.Redis).BackendSentinelMigrationJob,
 	}
 }
 
@@ -65,6 +109,8 @@ func (r *RedisReconciler) Reconcile() (reconcile.Result, error) {
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
 		reconcilers.DeploymentConfigTolerationsMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigPodTemplateLabelsMutator,
 		reconcilers.DeploymentConfigPriorityClassMutator,
 		reconcilers.DeploymentConfigTopologySpreadConstraintsMutator,
@@ -92,9 +138,13 @@ func (r *RedisReconciler) Reconcile() (reconcile.Result, error) {
 	}
 
 	// PVC
-	err = r.ReconcilePersistentVolumeClaim(r.PersistentVolumeClaim(redis), reconcilers.CreateOnlyMutator)
-	if err != nil {
-		return reconcile.Result{}, err
+	// With redis sentinel, the volumes are claimed by the StatefulSet. An existing
+	// claim of the single redis instance is kept, as it holds the previous data.
+	if !r.SentinelEnabled(r.apiManager) {
+		err = r.ReconcilePersistentVolumeClaim(r.PersistentVolumeClaim(redis), reconcilers.CreateOnlyMutator)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	// IS
@@ -104,7 +154,48 @@ func (r *RedisReconciler) Reconcile() (reconcile.Result, error) {
 	}
 
 	// Redis Secret
-	err = r.ReconcileSecret(r.Secret(redis), reconcilers.DefaultsOnlySecretMutator)
+	err = r.ReconcileSecret(r.Secret(redis), redisSecretMutator(r.SecretManagedFields(redis)))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Redis Sentinel
+	err = r.ReconcileConfigMap(r.SentinelConfigMap(redis), RedisSentinelConfigMapMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if r.SentinelEnabled(r.apiManager) {
+		result, err := r.reconcileSentinelMigration(redis)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if result != nil {
+			return *result, nil
+		}
+	}
+
+	stsMutator := reconcilers.StatefulSetMutator(
+		reconcilers.StatefulSetReplicasMutator,
+		reconcilers.StatefulSetContainersMutator,
+		reconcilers.StatefulSetPodTemplateMutator,
+	)
+	err = r.ReconcileStatefulSet(r.SentinelStatefulSet(redis), stsMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.ReconcileService(r.SentinelHeadlessService(redis), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.ReconcileService(r.SentinelService(redis), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	err = r.ReconcilePodDisruptionBudget(r.SentinelPodDisruptionBudget(redis), reconcilers.GenericPDBMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -112,6 +203,141 @@ func (r *RedisReconciler) Reconcile() (reconcile.Result, error) {
 	return reconcile.Result{}, nil
 }
 
+// reconcileSentinelMigration migrates the data of the single redis instance
+// replaced by the StatefulSet. Once its pods have terminated, a Job copies its
+// data to the claim of the first replica, which bootstraps as master. The
+// StatefulSet is not created until the Job has succeeded. A failed Job is kept
+// and reported with an event, deleting it runs the migration again.
+// It returns the result to return until the StatefulSet can be reconciled, nil
+// once the data is migrated or when there is no data to migrate
+func (r *RedisReconciler) reconcileSentinelMigration(redis *component.Redis) (*reconcile.Result, error) {
+	statefulSet := r.SentinelStatefulSet(redis)
+	err := r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(statefulSet), &k8sappsv1.StatefulSet{})
+	if err == nil {
+		// Already migrated
+		job := r.SentinelMigrationJob(redis)
+		common.TagToObjectDeleteWithPropagationPolicy(job, metav1.DeletePropagationBackground)
+		err = r.ReconcileResource(&batchv1.Job{}, job, reconcilers.CreateOnlyMutator)
+		if err != nil {
+			return &reconcile.Result{}, err
+		}
+		return nil, nil
+	}
+	if !errors.IsNotFound(err) {
+		return &reconcile.Result{}, err
+	}
+
+	// The claim of the single instance has the name of the data volume of the replicas
+	singleInstancePVC := r.PersistentVolumeClaim(redis)
+	err = r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(singleInstancePVC), &corev1.PersistentVolumeClaim{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+
+	// The data is written on shutdown, and the claim is released
+	podList := &corev1.PodList{}
+	err = r.Client().List(r.Context(), podList,
+		client.InNamespace(r.apiManager.Namespace),
+		client.MatchingLabels(r.DeploymentConfig(redis).Spec.Selector))
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+	if len(podList.Items) > 0 {
+		r.Logger().Info("Waiting for the single redis instance to terminate before migrating its data", "statefulset", statefulSet.Name)
+		return &reconcile.Result{Requeue: true, RequeueAfter: redisSentinelMigrationRequeueDelay}, nil
+	}
+
+	err = r.ReconcilePersistentVolumeClaim(r.SentinelMigrationPVC(redis), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+
+	job := r.SentinelMigrationJob(redis)
+	err = r.ReconcileResource(&batchv1.Job{}, job, reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+
+	existing := &batchv1.Job{}
+	err = r.Client().Get(r.Context(), r.NamespacedNameWithAPIManagerNamespace(job), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return &reconcile.Result{}, err
+	}
+
+	for _, condition := range existing.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return nil, nil
+		case batchv1.JobFailed:
+			// The rest of the APIManager is reconciled, the Job is watched
+			r.EventRecorder().Eventf(r.apiManager, corev1.EventTypeWarning, "RedisSentinelMigrationFailed",
+				"Job '%s' failed, '%s' is not deployed. Delete the Job to migrate the redis data again: %s", job.Name, statefulSet.Name, condition.Message)
+			return &reconcile.Result{}, nil
+		}
+	}
+
+	r.Logger().Info("Waiting for the redis data to be migrated", "job", job.Name)
+	return &reconcile.Result{Requeue: true, RequeueAfter: redisSentinelMigrationRequeueDelay}, nil
+}
+
+// RedisSentinelConfigMapMutator keeps the redis sentinel startup scripts up to date
+func RedisSentinelConfigMapMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*corev1.ConfigMap)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1.ConfigMap", existingObj)
+	}
+	desired, ok := desiredObj.(*corev1.ConfigMap)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1.ConfigMap", desiredObj)
+	}
+
+	if existing.Data == nil {
+		existing.Data = map[string]string{}
+	}
+
+	update := false
+	for key := range desired.Data {
+		fieldUpdated := reconcilers.ConfigMapReconcileField(desired, existing, key)
+		update = update || fieldUpdated
+	}
+
+	return update, nil
+}
+
+// redisSecretMutator keeps the values provided by the user in the secret, except for
+// the connection fields managed by the operator for the redis sentinel topology
+func redisSecretMutator(managedFields []string) reconcilers.MutateFn {
+	if len(managedFields) == 0 {
+		return reconcilers.DefaultsOnlySecretMutator
+	}
+
+	fieldMutators := make([]reconcilers.SecretMutateFn, 0, len(managedFields))
+	for _, field := range managedFields {
+		fieldMutators = append(fieldMutators, reconcilers.SecretReconcileField(field))
+	}
+	managedFieldsMutator := reconcilers.DeploymentSecretMutator(fieldMutators...)
+
+	return func(existing, desired common.KubernetesObject) (bool, error) {
+		updated, err := reconcilers.DefaultsOnlySecretMutator(existing, desired)
+		if err != nil {
+			return false, err
+		}
+
+		tmpUpdated, err := managedFieldsMutator(existing, desired)
+		if err != nil {
+			return false, err
+		}
+
+		return updated || tmpUpdated, nil
+	}
+}
+
 func Redis(apimanager *appsv1alpha1.APIManager, client client.Client) (*component.Redis, error) {
 	optsProvider := NewRedisOptionsProvider(apimanager, apimanager.Namespace, client)
 	opts, err := optsProvider.GetRedisOptions()
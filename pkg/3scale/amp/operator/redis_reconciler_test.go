@@ -2,14 +2,20 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	imagev1 "github.com/openshift/api/image/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -128,3 +134,260 @@ func TestRedisBackendDCReconcilerCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisBackendSentinelReconciler(t *testing.T) {
+	var (
+		log = logf.Log.WithName("operator_test")
+		ctx = context.TODO()
+	)
+
+	apimanager := basicApimanager()
+	apimanager.Spec.Backend.RedisSentinel = &appsv1alpha1.RedisSentinelSpec{}
+	_, err := apimanager.SetDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backendRedisSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-redis", Namespace: namespace},
+		Data: map[string][]byte{
+			"REDIS_STORAGE_URL": []byte("redis://backend-redis:6379/0"),
+			"REDIS_QUEUES_URL":  []byte("redis://backend-redis:6379/1"),
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	if err := imagev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []runtime.Object{apimanager, backendRedisSecret}
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	reconciler := NewBackendRedisDependencyReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+
+	if _, err := reconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	statefulSet := &k8sappsv1.StatefulSet{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis-ha", Namespace: namespace}, statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	if *statefulSet.Spec.Replicas != 3 {
+		t.Errorf("expected 3 redis replicas, got %d", *statefulSet.Spec.Replicas)
+	}
+	for _, name := range []string{"backend-redis-ha", "backend-redis-sentinel"} {
+		if err := cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &v1.Service{}); err != nil {
+			t.Errorf("error fetching service %s: %v", name, err)
+		}
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis", Namespace: namespace}, &appsv1.DeploymentConfig{}); !errors.IsNotFound(err) {
+		t.Errorf("expected backend-redis DeploymentConfig not to be created: %v", err)
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis-storage", Namespace: namespace}, &v1.PersistentVolumeClaim{}); !errors.IsNotFound(err) {
+		t.Errorf("expected backend-redis-storage PVC not to be created: %v", err)
+	}
+	configMap := &v1.ConfigMap{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: component.BackendRedisSentinelConfigMapName, Namespace: namespace}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if configMap.Labels["threescale_component"] != "backend" {
+		t.Errorf("expected the backend redis labels, got %v", configMap.Labels)
+	}
+
+	// The fake client does not convert StringData into Data
+	secret := &v1.Secret{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis", Namespace: namespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	expectedSecretData := map[string]string{
+		"REDIS_STORAGE_URL":            "redis://backend-redis/0",
+		"REDIS_QUEUES_URL":             "redis://backend-redis/1",
+		"REDIS_STORAGE_SENTINEL_HOSTS": "redis://backend-redis-sentinel:26379",
+		"REDIS_STORAGE_SENTINEL_ROLE":  "master",
+		"REDIS_QUEUES_SENTINEL_HOSTS":  "redis://backend-redis-sentinel:26379",
+		"REDIS_QUEUES_SENTINEL_ROLE":   "master",
+	}
+	for key, expected := range expectedSecretData {
+		if secret.StringData[key] != expected {
+			t.Errorf("secret field %s: expected '%s', got '%s'", key, expected, secret.StringData[key])
+		}
+	}
+
+	// Disabling redis sentinel reverts the secret to the single redis instance
+	secret.Data = map[string][]byte{}
+	for key, value := range secret.StringData {
+		secret.Data[key] = []byte(value)
+	}
+	secret.StringData = nil
+	if err := cl.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	apimanager.Spec.Backend.RedisSentinel = nil
+
+	if _, err := reconciler.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis-ha", Namespace: namespace}, statefulSet); !errors.IsNotFound(err) {
+		t.Errorf("expected backend-redis-ha StatefulSet to be deleted: %v", err)
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis", Namespace: namespace}, &appsv1.DeploymentConfig{}); err != nil {
+		t.Errorf("error fetching backend-redis DeploymentConfig: %v", err)
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis", Namespace: namespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	if secret.StringData["REDIS_STORAGE_URL"] != component.DefaultBackendRedisStorageURL() {
+		t.Errorf("expected storage URL reverted to '%s', got '%s'", component.DefaultBackendRedisStorageURL(), secret.StringData["REDIS_STORAGE_URL"])
+	}
+	if secret.StringData["REDIS_STORAGE_SENTINEL_HOSTS"] != "" {
+		t.Errorf("expected storage sentinel hosts reverted, got '%s'", secret.StringData["REDIS_STORAGE_SENTINEL_HOSTS"])
+	}
+}
+
+func TestRedisBackendSentinelMigration(t *testing.T) {
+	var (
+		log = logf.Log.WithName("operator_test")
+		ctx = context.TODO()
+	)
+
+	apimanager := basicApimanager()
+	apimanager.Spec.Backend.RedisSentinel = &appsv1alpha1.RedisSentinelSpec{}
+	_, err := apimanager.SetDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Single instance deployed before enabling redis sentinel
+	singleInstancePVC := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-redis-storage", Namespace: namespace},
+	}
+	singleInstancePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-redis-1-abcde", Namespace: namespace, Labels: map[string]string{"deploymentConfig": "backend-redis"}},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	if err := imagev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []runtime.Object{apimanager, singleInstancePVC, singleInstancePod}
+	cl := fake.NewFakeClient(objs...)
+	clientAPIReader := fake.NewFakeClient(objs...)
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	reconciler := NewBackendRedisDependencyReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+
+	statefulSetKey := types.NamespacedName{Name: "backend-redis-ha", Namespace: namespace}
+	jobKey := types.NamespacedName{Name: "backend-redis-ha-migration", Namespace: namespace}
+	reconcileMigration := func(expectedRequeue bool) {
+		result, err := reconciler.Reconcile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Requeue != expectedRequeue {
+			t.Fatalf("expected requeue %t, got %v", expectedRequeue, result)
+		}
+	}
+	completeJob := func(conditionType batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		if err := cl.Get(ctx, jobKey, job); err != nil {
+			t.Fatal(err)
+		}
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: v1.ConditionTrue}}
+		if err := cl.Update(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The data is migrated once the single instance has terminated
+	reconcileMigration(true)
+	if err := cl.Get(ctx, jobKey, &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the migration to wait for the single instance: %v", err)
+	}
+	if err := cl.Delete(ctx, singleInstancePod); err != nil {
+		t.Fatal(err)
+	}
+
+	reconcileMigration(true)
+	job := &batchv1.Job{}
+	if err := cl.Get(ctx, jobKey, job); err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]string{}
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		claims[volume.Name] = volume.PersistentVolumeClaim.ClaimName
+	}
+	if claims["source"] != "backend-redis-storage" || claims["backend-redis-storage"] != "backend-redis-storage-backend-redis-ha-0" {
+		t.Errorf("unexpected migration job claims %v", claims)
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "backend-redis-storage-backend-redis-ha-0", Namespace: namespace}, &v1.PersistentVolumeClaim{}); err != nil {
+		t.Fatalf("expected the claim of the first replica to be created: %v", err)
+	}
+	if err := cl.Get(ctx, statefulSetKey, &k8sappsv1.StatefulSet{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the statefulset to wait for the migration: %v", err)
+	}
+
+	// Failed migrations are reported and do not block the rest of the APIManager
+	completeJob(batchv1.JobFailed)
+	reconcileMigration(false)
+	if err := cl.Get(ctx, statefulSetKey, &k8sappsv1.StatefulSet{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the statefulset not to be created: %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "RedisSentinelMigrationFailed") {
+			t.Errorf("unexpected event %s", event)
+		}
+	default:
+		t.Error("expected a migration failure event")
+	}
+
+	completeJob(batchv1.JobComplete)
+	reconcileMigration(false)
+	if err := cl.Get(ctx, statefulSetKey, &k8sappsv1.StatefulSet{}); err != nil {
+		t.Fatalf("expected the statefulset to be created: %v", err)
+	}
+
+	// The migration job is deleted once the statefulset exists
+	reconcileMigration(false)
+	if err := cl.Get(ctx, jobKey, &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the migration job to be deleted: %v", err)
+	}
+
+	// Errors deleting the migration job are returned
+	if err := cl.Create(ctx, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: jobKey.Name, Namespace: namespace}}); err != nil {
+		t.Fatal(err)
+	}
+	failingClient := &failingDeleteClient{Client: cl}
+	baseReconciler = reconcilers.NewBaseReconciler(ctx, failingClient, s, clientAPIReader, log, clientset.Discovery(), recorder)
+	reconciler = NewBackendRedisDependencyReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+	if _, err := reconciler.Reconcile(); err == nil {
+		t.Error("expected the error deleting the migration job to be returned")
+	}
+}
+
+// failingDeleteClient fails to delete any object
+type failingDeleteClient struct {
+	client.Client
+}
+
+func (c *failingDeleteClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return errors.NewInternalError(fmt.Errorf("delete failed"))
+}
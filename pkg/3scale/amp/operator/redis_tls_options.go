@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// redisTLSSecretFields are the fields of a Redis secret describing one connection
+type redisTLSSecretFields struct {
+	secretName string
+	url        string
+	ca         string
+	cert       string
+	key        string
+}
+
+var (
+	backendRedisStorageTLSSecretFields = redisTLSSecretFields{
+		secretName: component.BackendSecretBackendRedisSecretName,
+		url:        component.BackendSecretBackendRedisStorageURLFieldName,
+		ca:         component.BackendSecretBackendRedisStorageSSLCAFieldName,
+		cert:       component.BackendSecretBackendRedisStorageSSLCertFieldName,
+		key:        component.BackendSecretBackendRedisStorageSSLKeyFieldName,
+	}
+	backendRedisQueuesTLSSecretFields = redisTLSSecretFields{
+		secretName: component.BackendSecretBackendRedisSecretName,
+		url:        component.BackendSecretBackendRedisQueuesURLFieldName,
+		ca:         component.BackendSecretBackendRedisQueuesSSLCAFieldName,
+		cert:       component.BackendSecretBackendRedisQueuesSSLCertFieldName,
+		key:        component.BackendSecretBackendRedisQueuesSSLKeyFieldName,
+	}
+	systemRedisTLSSecretFields = redisTLSSecretFields{
+		secretName: component.SystemSecretSystemRedisSecretName,
+		url:        component.SystemSecretSystemRedisURLFieldName,
+		ca:         component.SystemSecretSystemRedisSSLCAFieldName,
+		cert:       component.SystemSecretSystemRedisSSLCertFieldName,
+		key:        component.SystemSecretSystemRedisSSLKeyFieldName,
+	}
+)
+
+// externalRedisTLSOptions reads the TLS settings of a connection to an external Redis.
+// It returns nil when the URL does not use the rediss:// scheme.
+func externalRedisTLSOptions(secretSource *helper.SecretSource, fields redisTLSSecretFields) (*component.RedisTLSOptions, error) {
+	redisURL, err := secretSource.FieldValue(fields.secretName, fields.url, "")
+	if err != nil {
+		return nil, err
+	}
+	if !component.IsRedisTLSURL(redisURL) {
+		return nil, nil
+	}
+
+	options := &component.RedisTLSOptions{SecretName: fields.secretName}
+	cases := []struct {
+		field       *string
+		secretField string
+	}{
+		{&options.CAField, fields.ca},
+		{&options.CertField, fields.cert},
+		{&options.KeyField, fields.key},
+	}
+	for _, option := range cases {
+		val, err := secretSource.FieldValue(fields.secretName, option.secretField, "")
+		if err != nil {
+			return nil, err
+		}
+		if val != "" {
+			*option.field = option.secretField
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,347 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	secretRotationRequeueDelay = 10 * time.Second
+	// Time the components of a step are waited for before the rotation fails
+	secretRotationStepTimeout = 30 * time.Minute
+)
+
+// internalCredential is a secret field generated by the operator
+type internalCredential struct {
+	secretName string
+	field      string
+	generate   func() string
+	// secretKeyBase credentials are only rotated when opted in
+	secretKeyBase bool
+}
+
+// secretRotationStep rotates credentials, then rolls out the components reading them.
+// The next step starts once all the consumers are rolled out.
+type secretRotationStep struct {
+	phase       appsv1alpha1.SecretRotationPhase
+	credentials []internalCredential
+	consumers   []string
+}
+
+// Each credential shared by two components is rotated in its own step, rolling out
+// together the component checking it and system, which sends it. Only credentials
+// read by the components on every start are rotated. The system-seed values are
+// used once, to seed the system database, and the backend-listener secret holds
+// no credentials. The SECRET_KEY_BASE of each component is rotated in a step
+// rolling it out.
+var secretRotationSteps = []secretRotationStep{
+	{
+		phase: appsv1alpha1.SecretRotationPhaseBackend,
+		credentials: []internalCredential{
+			{
+				secretName: component.BackendSecretInternalApiSecretName,
+				field:      component.BackendSecretInternalApiPasswordFieldName,
+				generate:   component.DefaultSystemBackendPassword,
+			},
+			{
+				secretName:    component.SystemSecretSystemAppSecretName,
+				field:         component.SystemSecretSystemAppSecretKeyBaseFieldName,
+				generate:      component.DefaultSystemAppSecretKeyBase,
+				secretKeyBase: true,
+			},
+		},
+		consumers: []string{component.BackendListenerName, component.SystemAppDeploymentName, component.SystemSidekiqName},
+	},
+	{
+		phase: appsv1alpha1.SecretRotationPhaseZync,
+		credentials: []internalCredential{
+			{
+				secretName: component.ZyncSecretName,
+				field:      component.ZyncSecretAuthenticationTokenFieldName,
+				generate:   component.DefaultZyncAuthenticationToken,
+			},
+			{
+				secretName:    component.ZyncSecretName,
+				field:         component.ZyncSecretKeyBaseFieldName,
+				generate:      component.DefaultZyncSecretKeyBase,
+				secretKeyBase: true,
+			},
+		},
+		consumers: []string{component.ZyncName, component.ZyncQueDeploymentName, component.SystemAppDeploymentName, component.SystemSidekiqName},
+	},
+}
+
+// SecretRotationReconciler rotates the internal credentials when requested
+// with the RotateSecretsAnnotation annotation of the APIManager
+type SecretRotationReconciler struct {
+	*BaseAPIManagerLogicReconciler
+}
+
+func NewSecretRotationReconciler(baseAPIManagerLogicReconciler *BaseAPIManagerLogicReconciler) *SecretRotationReconciler {
+	return &SecretRotationReconciler{
+		BaseAPIManagerLogicReconciler: baseAPIManagerLogicReconciler,
+	}
+}
+
+// Reconcile runs the rotation steps in order. A new generation is recorded in the
+// status before any credential is changed, and every step is recorded once done.
+// Secrets already annotated with the generation are not rotated again and the
+// rollouts are driven by the content hashes of the pod templates, so a step
+// run again, for instance after a status update conflict, has no further effect.
+// The reconciliation of the components waits for the rotation, which would
+// otherwise roll out all the consumers of the rotated secrets at once, until
+// the components of a step are not rolled out in secretRotationStepTimeout.
+// The rotation then fails and the reconciliation of the components resumes.
+func (r *SecretRotationReconciler) Reconcile() (reconcile.Result, error) {
+	request, ok := r.apiManager.SecretRotationRequest()
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	status := r.apiManager.Status.SecretRotation
+	if status == nil || status.Request != request {
+		r.Logger().Info("Rotating internal credentials", "request", request)
+
+		startTime := metav1.Now()
+		newStatus := &appsv1alpha1.SecretRotationStatus{
+			Request:        request,
+			Generation:     1,
+			Phase:          secretRotationSteps[0].phase,
+			PhaseStartTime: &startTime,
+		}
+		if status != nil {
+			newStatus.Generation = status.Generation + 1
+			newStatus.LastRotationTime = status.LastRotationTime
+		}
+		return r.updateStatus(newStatus)
+	}
+
+	for idx, step := range secretRotationSteps {
+		if step.phase != status.Phase {
+			continue
+		}
+
+		rotatedSecrets, err := r.rotateCredentials(step.credentials, status.Generation)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		rolledOut, err := r.rolloutConsumers(step.consumers)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		newStatus := status.DeepCopy()
+		for _, name := range rotatedSecrets {
+			if !helper.ArrayContains(newStatus.Secrets, name) {
+				newStatus.Secrets = append(newStatus.Secrets, name)
+			}
+		}
+
+		if !rolledOut {
+			if status.PhaseStartTime != nil && time.Since(status.PhaseStartTime.Time) > secretRotationStepTimeout {
+				newStatus.Phase = appsv1alpha1.SecretRotationPhaseFailed
+				newStatus.Message = fmt.Sprintf("%s not rolled out in %s", strings.Join(step.consumers, ", "), secretRotationStepTimeout)
+				r.EventRecorder().Eventf(r.apiManager, v1.EventTypeWarning, "SecretRotationFailed", newStatus.Message)
+				_, err := r.updateStatus(newStatus)
+				return reconcile.Result{}, err
+			}
+
+			r.Logger().Info("Waiting for the rollout of the components reading the rotated credentials", "phase", step.phase)
+			if len(newStatus.Secrets) != len(status.Secrets) {
+				return r.updateStatus(newStatus)
+			}
+			return reconcile.Result{Requeue: true, RequeueAfter: secretRotationRequeueDelay}, nil
+		}
+
+		stepTime := metav1.Now()
+		newStatus.PhaseStartTime = &stepTime
+		if idx+1 < len(secretRotationSteps) {
+			newStatus.Phase = secretRotationSteps[idx+1].phase
+		} else {
+			newStatus.Phase = appsv1alpha1.SecretRotationPhaseCompleted
+			newStatus.LastRotationTime = &stepTime
+		}
+		return r.updateStatus(newStatus)
+	}
+
+	// Unknown phase, the rotation is started again with a new generation
+	newStatus := status.DeepCopy()
+	newStatus.Request = ""
+	return r.updateStatus(newStatus)
+}
+
+// updateStatus records the rotation status. The reconciliation is requeued,
+// to run the next step with the recorded status.
+func (r *SecretRotationReconciler) updateStatus(status *appsv1alpha1.SecretRotationStatus) (reconcile.Result, error) {
+	r.apiManager.Status.SecretRotation = status
+	err := r.Client().Status().Update(r.Context(), r.apiManager)
+	if err != nil && !errors.IsConflict(err) {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// rotateCredentials updates the secrets with new credential values, and annotates
+// them with the rotation generation. Secrets already annotated are left as they are.
+// Secrets not created yet are skipped, they get new values when created.
+// It returns the secrets rotated in this generation.
+func (r *SecretRotationReconciler) rotateCredentials(credentials []internalCredential, generation int64) ([]string, error) {
+	generationValue := strconv.FormatInt(generation, 10)
+	secrets := map[string]*v1.Secret{}
+	secretNames := []string{}
+	pendingSecretNames := []string{}
+
+	for _, credential := range credentials {
+		// SECRET_KEY_BASE signs the sessions and cookies
+		if credential.secretKeyBase && !r.apiManager.IsSecretKeyBaseRotationEnabled() {
+			continue
+		}
+
+		secret, ok := secrets[credential.secretName]
+		if !ok {
+			secret = &v1.Secret{}
+			err := r.Client().Get(r.Context(), types.NamespacedName{Name: credential.secretName, Namespace: r.apiManager.Namespace}, secret)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			secrets[credential.secretName] = secret
+
+			// Unmanaged secrets keep their credentials
+			if common.IsObjectUnmanaged(secret) {
+				continue
+			}
+			secretNames = append(secretNames, credential.secretName)
+			if secret.Annotations[appsv1alpha1.SecretsRotationGenerationAnnotation] != generationValue {
+				pendingSecretNames = append(pendingSecretNames, credential.secretName)
+			}
+		}
+
+		if !helper.ArrayContains(pendingSecretNames, credential.secretName) {
+			continue
+		}
+
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[credential.field] = []byte(credential.generate())
+	}
+
+	for _, name := range pendingSecretNames {
+		secret := secrets[name]
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[appsv1alpha1.SecretsRotationGenerationAnnotation] = generationValue
+		err := r.UpdateResource(secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return secretNames, nil
+}
+
+// rolloutConsumers refreshes the content hashes of the pod templates of the components,
+// rolling them out when the contents of their secrets changed.
+// It returns whether all of them are rolled out.
+func (r *SecretRotationReconciler) rolloutConsumers(names []string) (bool, error) {
+	rolledOut := true
+	for _, name := range names {
+		consumerRolledOut, err := r.rolloutConsumer(name)
+		if err != nil {
+			return false, err
+		}
+		rolledOut = rolledOut && consumerRolledOut
+	}
+
+	return rolledOut, nil
+}
+
+// Both workload kinds are looked up, as both exist while migrating to Deployments.
+// Components not deployed, or unmanaged, are not waited for.
+func (r *SecretRotationReconciler) rolloutConsumer(name string) (bool, error) {
+	key := types.NamespacedName{Name: name, Namespace: r.apiManager.Namespace}
+	rolledOut := true
+
+	deployment := &k8sappsv1.Deployment{}
+	err := r.Client().Get(r.Context(), key, deployment)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil && !common.IsObjectUnmanaged(deployment) {
+		updated, err := r.refreshContentHashes(&deployment.Spec.Template)
+		if err != nil {
+			return false, err
+		}
+		if updated {
+			if err := r.UpdateResource(deployment); err != nil {
+				return false, err
+			}
+		}
+		rolledOut = !updated && helper.IsDeploymentRolledOut(deployment)
+	}
+
+	dc := &appsv1.DeploymentConfig{}
+	err = r.Client().Get(r.Context(), key, dc)
+	if err != nil {
+		// DeploymentConfig kind not available in the cluster
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return rolledOut, nil
+		}
+		return false, err
+	}
+	if dc.Spec.Template == nil || common.IsObjectUnmanaged(dc) {
+		return rolledOut, nil
+	}
+	updated, err := r.refreshContentHashes(dc.Spec.Template)
+	if err != nil {
+		return false, err
+	}
+	if updated {
+		if err := r.UpdateResource(dc); err != nil {
+			return false, err
+		}
+	}
+
+	return rolledOut && !updated && helper.IsDeploymentConfigRolledOut(dc), nil
+}
+
+// refreshContentHashes sets the content hash annotations of the pod template,
+// as the component reconcilers do. It returns whether they changed.
+func (r *SecretRotationReconciler) refreshContentHashes(template *v1.PodTemplateSpec) (bool, error) {
+	desired := template.DeepCopy()
+	if err := r.setPodTemplateContentHashes(desired); err != nil {
+		return false, err
+	}
+
+	updated := false
+	for _, key := range contentHashAnnotations {
+		if template.Annotations[key] != desired.Annotations[key] {
+			updated = true
+		}
+	}
+	template.Annotations = desired.Annotations
+
+	return updated, nil
+}
@@ -0,0 +1,280 @@
+package operator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+func secretRotationTestPodTemplate(secretNames ...string) v1.PodTemplateSpec {
+	env := []v1.EnvVar{}
+	for _, secretName := range secretNames {
+		env = append(env, helper.EnvVarFromSecret(secretName, secretName, "key"))
+	}
+	return v1.PodTemplateSpec{
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container", Env: env}}},
+	}
+}
+
+func secretRotationTestReconciler(t *testing.T, annotations map[string]string) (*SecretRotationReconciler, client.Client) {
+	apimanager := basicApimanager()
+	apimanager.Annotations = annotations
+
+	internalAPISecret := GetTestSecret(namespace, component.BackendSecretInternalApiSecretName, map[string]string{
+		component.BackendSecretInternalApiUsernameFieldName: "3scale_api_user",
+		component.BackendSecretInternalApiPasswordFieldName: "oldpassword",
+	})
+	zyncSecret := GetTestSecret(namespace, component.ZyncSecretName, map[string]string{
+		component.ZyncSecretAuthenticationTokenFieldName: "oldtoken",
+		component.ZyncSecretKeyBaseFieldName:             "oldkeybase",
+		component.ZyncSecretDatabasePasswordFieldName:    "dbpassword",
+	})
+	systemAppSecret := GetTestSecret(namespace, component.SystemSecretSystemAppSecretName, map[string]string{
+		component.SystemSecretSystemAppSecretKeyBaseFieldName: "oldsystemkeybase",
+	})
+	listenerTemplate := secretRotationTestPodTemplate(component.BackendSecretInternalApiSecretName)
+	listenerDeployment := &k8sappsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: component.BackendListenerName, Namespace: namespace},
+		Spec:       k8sappsv1.DeploymentSpec{Template: listenerTemplate},
+	}
+	zyncDeployment := &k8sappsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: component.ZyncName, Namespace: namespace},
+		Spec:       k8sappsv1.DeploymentSpec{Template: secretRotationTestPodTemplate(component.ZyncSecretName)},
+	}
+	systemTemplate := secretRotationTestPodTemplate(component.BackendSecretInternalApiSecretName, component.ZyncSecretName, component.SystemSecretSystemAppSecretName)
+	systemDC := &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: component.SystemAppDeploymentName, Namespace: namespace},
+		Spec:       appsv1.DeploymentConfigSpec{Replicas: 1, Template: &systemTemplate},
+	}
+
+	baseAPIManagerLogicReconciler, cl := testBaseAPIManagerLogicReconciler(t, apimanager,
+		internalAPISecret, zyncSecret, systemAppSecret, listenerDeployment, zyncDeployment, systemDC)
+
+	return NewSecretRotationReconciler(baseAPIManagerLogicReconciler), cl
+}
+
+func secretRotationTestReconcile(t *testing.T, reconciler *SecretRotationReconciler, expectedPhase appsv1alpha1.SecretRotationPhase) {
+	t.Helper()
+	result, err := reconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Requeue {
+		t.Errorf("expected the rotation to be requeued")
+	}
+	status := reconciler.apiManager.Status.SecretRotation
+	if status == nil || status.Phase != expectedPhase {
+		t.Fatalf("expected phase %s, got %v", expectedPhase, status)
+	}
+}
+
+func secretRotationTestSecretValue(t *testing.T, cl client.Client, secretName, field string) string {
+	t.Helper()
+	secret := &v1.Secret{}
+	err := cl.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: namespace}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(secret.Data[field])
+}
+
+func secretRotationTestRolledOut(t *testing.T, cl client.Client, obj client.Object) {
+	t.Helper()
+	err := cl.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch workload := obj.(type) {
+	case *k8sappsv1.Deployment:
+		workload.Status = k8sappsv1.DeploymentStatus{ObservedGeneration: workload.Generation, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	case *appsv1.DeploymentConfig:
+		workload.Status = appsv1.DeploymentConfigStatus{ObservedGeneration: workload.Generation, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	if err := cl.Update(context.TODO(), obj); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func secretRotationTestHash(t *testing.T, cl client.Client, obj client.Object) string {
+	t.Helper()
+	err := cl.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch workload := obj.(type) {
+	case *k8sappsv1.Deployment:
+		return workload.Spec.Template.Annotations[SecretsHashAnnotation]
+	case *appsv1.DeploymentConfig:
+		return workload.Spec.Template.Annotations[SecretsHashAnnotation]
+	}
+	return ""
+}
+
+func TestSecretRotationReconciler(t *testing.T) {
+	reconciler, cl := secretRotationTestReconciler(t, map[string]string{appsv1alpha1.RotateSecretsAnnotation: "2023-01-01"})
+	listener := &k8sappsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: component.BackendListenerName, Namespace: namespace}}
+	zync := &k8sappsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: component.ZyncName, Namespace: namespace}}
+	systemDC := &appsv1.DeploymentConfig{ObjectMeta: metav1.ObjectMeta{Name: component.SystemAppDeploymentName, Namespace: namespace}}
+
+	// The generation is recorded before any credential is changed
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	if generation := reconciler.apiManager.Status.SecretRotation.Generation; generation != 1 {
+		t.Fatalf("expected generation 1, got %d", generation)
+	}
+	if value := secretRotationTestSecretValue(t, cl, component.BackendSecretInternalApiSecretName, component.BackendSecretInternalApiPasswordFieldName); value != "oldpassword" {
+		t.Fatalf("credentials rotated before the generation is recorded")
+	}
+
+	// backend-listener and system are rolled out together with the new password
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	password := secretRotationTestSecretValue(t, cl, component.BackendSecretInternalApiSecretName, component.BackendSecretInternalApiPasswordFieldName)
+	if password == "oldpassword" || password == "" {
+		t.Fatalf("password not rotated: '%s'", password)
+	}
+	if secretRotationTestHash(t, cl, listener) == "" || secretRotationTestHash(t, cl, systemDC) == "" {
+		t.Errorf("backend-listener and system not rolled out together")
+	}
+	if value := secretRotationTestSecretValue(t, cl, component.ZyncSecretName, component.ZyncSecretAuthenticationTokenFieldName); value != "oldtoken" {
+		t.Errorf("zync token rotated before its step: '%s'", value)
+	}
+	if secretRotationTestHash(t, cl, zync) != "" {
+		t.Errorf("zync rolled out in the backend step")
+	}
+
+	// Running the step again, as done after a status update conflict, does not rotate again
+	listenerHash := secretRotationTestHash(t, cl, listener)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	if value := secretRotationTestSecretValue(t, cl, component.BackendSecretInternalApiSecretName, component.BackendSecretInternalApiPasswordFieldName); value != password {
+		t.Errorf("password rotated twice in the same generation")
+	}
+	if hash := secretRotationTestHash(t, cl, listener); hash != listenerHash {
+		t.Errorf("backend-listener rolled out twice in the same generation")
+	}
+
+	// zync and system are rolled out together with the new token
+	secretRotationTestRolledOut(t, cl, listener)
+	secretRotationTestRolledOut(t, cl, systemDC)
+	systemHash := secretRotationTestHash(t, cl, systemDC)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseZync)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseZync)
+	token := secretRotationTestSecretValue(t, cl, component.ZyncSecretName, component.ZyncSecretAuthenticationTokenFieldName)
+	if token == "oldtoken" || token == "" {
+		t.Fatalf("zync token not rotated: '%s'", token)
+	}
+	if secretRotationTestHash(t, cl, zync) == "" || secretRotationTestHash(t, cl, systemDC) == systemHash {
+		t.Errorf("zync and system not rolled out together")
+	}
+
+	secretCases := []struct {
+		secretName string
+		field      string
+		oldValue   string
+	}{
+		{component.BackendSecretInternalApiSecretName, component.BackendSecretInternalApiUsernameFieldName, "3scale_api_user"},
+		{component.ZyncSecretName, component.ZyncSecretKeyBaseFieldName, "oldkeybase"},
+		{component.ZyncSecretName, component.ZyncSecretDatabasePasswordFieldName, "dbpassword"},
+		{component.SystemSecretSystemAppSecretName, component.SystemSecretSystemAppSecretKeyBaseFieldName, "oldsystemkeybase"},
+	}
+	for _, tc := range secretCases {
+		t.Run(tc.secretName+"/"+tc.field, func(subT *testing.T) {
+			if value := secretRotationTestSecretValue(subT, cl, tc.secretName, tc.field); value != tc.oldValue {
+				subT.Errorf("field %s should not be rotated: '%s'", tc.field, value)
+			}
+		})
+	}
+
+	secretRotationTestRolledOut(t, cl, zync)
+	secretRotationTestRolledOut(t, cl, systemDC)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseCompleted)
+
+	existingAPIManager := &appsv1alpha1.APIManager{}
+	err := cl.Get(context.TODO(), client.ObjectKeyFromObject(reconciler.apiManager), existingAPIManager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := existingAPIManager.Status.SecretRotation
+	if status == nil || status.Request != "2023-01-01" || status.LastRotationTime == nil {
+		t.Fatalf("rotation not recorded in status: %v", status)
+	}
+	expectedSecrets := []string{component.BackendSecretInternalApiSecretName, component.ZyncSecretName}
+	if !helper.StringSliceEqualWithoutOrder(status.Secrets, expectedSecrets) {
+		t.Errorf("expected rotated secrets %v, got %v", expectedSecrets, status.Secrets)
+	}
+
+	// The same request is not rotated again
+	if _, ok := existingAPIManager.SecretRotationRequest(); ok {
+		t.Errorf("rotation request already handled")
+	}
+}
+
+func TestSecretRotationReconcilerTimeout(t *testing.T) {
+	reconciler, _ := secretRotationTestReconciler(t, map[string]string{appsv1alpha1.RotateSecretsAnnotation: "2023-01-01"})
+
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+
+	// backend-listener and system never roll out
+	startTime := metav1.NewTime(time.Now().Add(-secretRotationStepTimeout - time.Minute))
+	reconciler.apiManager.Status.SecretRotation.PhaseStartTime = &startTime
+	result, err := reconciler.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Requeue {
+		t.Errorf("expected the reconciliation of the components to resume")
+	}
+	status := reconciler.apiManager.Status.SecretRotation
+	if status.Phase != appsv1alpha1.SecretRotationPhaseFailed || !strings.Contains(status.Message, component.BackendListenerName) {
+		t.Fatalf("expected the rotation to fail, got %v", status)
+	}
+	recorder := reconciler.EventRecorder().(*record.FakeRecorder)
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "SecretRotationFailed") {
+			t.Errorf("unexpected event %s", event)
+		}
+	default:
+		t.Error("expected a rotation failure event")
+	}
+
+	// The failed request is not resumed
+	if _, ok := reconciler.apiManager.SecretRotationRequest(); ok {
+		t.Errorf("failed rotation request resumed")
+	}
+}
+
+func TestSecretRotationReconcilerSecretKeyBaseOptIn(t *testing.T) {
+	reconciler, cl := secretRotationTestReconciler(t, map[string]string{
+		appsv1alpha1.RotateSecretsAnnotation:       "2023-01-01",
+		appsv1alpha1.RotateSecretKeyBaseAnnotation: "true",
+	})
+
+	// Each SECRET_KEY_BASE is rotated in a step rolling out its component
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseBackend)
+	if value := secretRotationTestSecretValue(t, cl, component.SystemSecretSystemAppSecretName, component.SystemSecretSystemAppSecretKeyBaseFieldName); value == "oldsystemkeybase" || value == "" {
+		t.Errorf("system SECRET_KEY_BASE not rotated: '%s'", value)
+	}
+	if value := secretRotationTestSecretValue(t, cl, component.ZyncSecretName, component.ZyncSecretKeyBaseFieldName); value != "oldkeybase" {
+		t.Errorf("zync SECRET_KEY_BASE rotated before the zync step: '%s'", value)
+	}
+
+	reconciler.apiManager.Status.SecretRotation.Phase = appsv1alpha1.SecretRotationPhaseZync
+	secretRotationTestReconcile(t, reconciler, appsv1alpha1.SecretRotationPhaseZync)
+	if value := secretRotationTestSecretValue(t, cl, component.ZyncSecretName, component.ZyncSecretKeyBaseFieldName); value == "oldkeybase" || value == "" {
+		t.Errorf("zync SECRET_KEY_BASE not rotated: '%s'", value)
+	}
+}
@@ -49,6 +49,8 @@ func (s *SystemMysqlOptionsProvider) GetMysqlOptions() (*component.SystemMysqlOp
 	s.setPriorityClassNames()
 	s.setTopologySpreadConstraints()
 	s.setPodTemplateAnnotations()
+	s.setSecurityContexts()
+	s.setProbes()
 
 	err = s.mysqlOptions.Validate()
 	if err != nil {
@@ -236,3 +238,22 @@ func (s *SystemMysqlOptionsProvider) setPodTemplateAnnotations() {
 		s.mysqlOptions.PodTemplateAnnotations = s.apimanager.Spec.System.DatabaseSpec.MySQL.Annotations
 	}
 }
+
+func (s *SystemMysqlOptionsProvider) setSecurityContexts() {
+	var podSC *v1.PodSecurityContext
+	var containerSC *v1.SecurityContext
+	if s.apimanager.Spec.System.DatabaseSpec != nil &&
+		s.apimanager.Spec.System.DatabaseSpec.MySQL != nil {
+		podSC = s.apimanager.Spec.System.DatabaseSpec.MySQL.PodSecurityContext
+		containerSC = s.apimanager.Spec.System.DatabaseSpec.MySQL.SecurityContext
+	}
+	s.mysqlOptions.PodSecurityContext = podSecurityContext(s.apimanager, podSC)
+	s.mysqlOptions.ContainerSecurityContext = containerSecurityContext(s.apimanager, containerSC, false)
+}
+
+func (s *SystemMysqlOptionsProvider) setProbes() {
+	if s.apimanager.Spec.System.DatabaseSpec != nil &&
+		s.apimanager.Spec.System.DatabaseSpec.MySQL != nil {
+		s.mysqlOptions.Probes = containerProbes(s.apimanager.Spec.System.DatabaseSpec.MySQL.Probes)
+	}
+}
@@ -2,7 +2,10 @@ package operator
 
 import (
 	"fmt"
+	"net"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +40,7 @@ func (s *SystemOptionsProvider) GetSystemOptions() (*component.SystemOptions, er
 	s.options.ApicastRegistryURL = *s.apimanager.Spec.Apicast.RegistryURL
 	s.options.TenantName = *s.apimanager.Spec.TenantName
 	s.options.WildcardDomain = s.apimanager.Spec.WildcardDomain
+	s.options.IngressOptions = ingressOptions(s.apimanager)
 
 	s.options.CommonLabels = s.commonLabels()
 	s.options.CommonAppLabels = s.commonAppLabels()
@@ -61,8 +65,11 @@ func (s *SystemOptionsProvider) GetSystemOptions() (*component.SystemOptions, er
 		return nil, err
 	}
 	s.setReplicas()
+	s.setAutoscaling()
 	s.setPriorityClassNames()
 	s.setTopologySpreadConstraints()
+	s.setSecurityContexts()
+	s.setProbes()
 	s.setPodTemplateAnnotations()
 
 	s.options.SideKiqMetrics = true
@@ -115,10 +122,37 @@ func (s *SystemOptionsProvider) setSecretBasedOptions() error {
 		return fmt.Errorf("unable to create System SMTP secret options - %s", err)
 	}
 
+	err = s.setSystemRedisTLSOptions()
+	if err != nil {
+		return fmt.Errorf("unable to create System Redis TLS options - %s", err)
+	}
+
+	err = s.setSystemDatabaseTLSOptions()
+	if err != nil {
+		return fmt.Errorf("unable to create System Database TLS options - %s", err)
+	}
+
 	return nil
 }
 
 func (s *SystemOptionsProvider) setSystemMemcachedOptions() error {
+	if s.apimanager.IsExternal(appsv1alpha1.SystemMemcached) {
+		val, err := s.secretSource.RequiredFieldValueFromRequiredSecret(
+			component.SystemSecretSystemMemcachedSecretName,
+			component.SystemSecretSystemMemcachedServersFieldName)
+		if err != nil {
+			return err
+		}
+		err = validateMemcachedServers(val)
+		if err != nil {
+			return fmt.Errorf("secret %s field %s: %w", component.SystemSecretSystemMemcachedSecretName,
+				component.SystemSecretSystemMemcachedServersFieldName, err)
+		}
+		s.options.MemcachedServers = val
+		s.options.ExternalMemcached = true
+		return nil
+	}
+
 	val, err := s.secretSource.FieldValue(
 		component.SystemSecretSystemMemcachedSecretName,
 		component.SystemSecretSystemMemcachedServersFieldName,
@@ -131,6 +165,26 @@ func (s *SystemOptionsProvider) setSystemMemcachedOptions() error {
 	return nil
 }
 
+// validateMemcachedServers checks the servers are a comma separated list of host:port
+func validateMemcachedServers(servers string) error {
+	for _, server := range strings.Split(servers, ",") {
+		server = strings.TrimSpace(server)
+		host, port, err := net.SplitHostPort(server)
+		if err != nil {
+			return fmt.Errorf("invalid memcached server '%s': %w", server, err)
+		}
+		if host == "" {
+			return fmt.Errorf("invalid memcached server '%s': missing host", server)
+		}
+		portNumber, err := strconv.Atoi(port)
+		if err != nil || portNumber < 1 || portNumber > 65535 {
+			return fmt.Errorf("invalid memcached server '%s': invalid port '%s'", server, port)
+		}
+	}
+
+	return nil
+}
+
 func (s *SystemOptionsProvider) setSystemRecaptchaOptions() error {
 	recaptchaPublicKey, err := s.secretSource.FieldValue(
 		component.SystemSecretSystemRecaptchaSecretName,
@@ -360,6 +414,38 @@ func (s *SystemOptionsProvider) setSystemSMTPOptions() error {
 	return nil
 }
 
+// setSystemDatabaseTLSOptions reads the TLS settings of the connection to the external system database
+func (s *SystemOptionsProvider) setSystemDatabaseTLSOptions() error {
+	if !s.apimanager.IsExternal(appsv1alpha1.SystemDatabase) {
+		return nil
+	}
+
+	var err error
+	s.options.DatabaseTLS, err = externalDatabaseTLSOptions(s.secretSource, systemDatabaseTLSSecretFields)
+	return err
+}
+
+// setSystemRedisTLSOptions reads the TLS settings of the connections to the external
+// system and backend Redis databases
+func (s *SystemOptionsProvider) setSystemRedisTLSOptions() error {
+	var err error
+	if s.apimanager.IsExternal(appsv1alpha1.SystemRedis) {
+		s.options.RedisTLS, err = externalRedisTLSOptions(s.secretSource, systemRedisTLSSecretFields)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.apimanager.IsExternal(appsv1alpha1.BackendRedis) {
+		s.options.BackendRedisTLS, err = externalRedisTLSOptions(s.secretSource, backendRedisStorageTLSSecretFields)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *SystemOptionsProvider) setResourceRequirementsOptions() {
 	if *s.apimanager.Spec.ResourceRequirementsEnabled {
 		s.options.AppMasterContainerResourceRequirements = component.DefaultAppMasterContainerResourceRequirements()
@@ -522,6 +608,10 @@ func (s *SystemOptionsProvider) setReplicas() {
 	}
 }
 
+func (s *SystemOptionsProvider) setAutoscaling() {
+	s.options.AppHPA = horizontalPodAutoscalerOptions(s.apimanager.Spec.System.AppSpec.Autoscaling)
+}
+
 func (s *SystemOptionsProvider) commonLabels() map[string]string {
 	return map[string]string{
 		"app":                  *s.apimanager.Spec.AppLabel,
@@ -624,4 +714,20 @@ func (s *SystemOptionsProvider) setTopologySpreadConstraints() {
 func (s *SystemOptionsProvider) setPodTemplateAnnotations() {
 	s.options.AppPodTemplateAnnotations = s.apimanager.Spec.System.AppSpec.Annotations
 	s.options.SideKiqPodTemplateAnnotations = s.apimanager.Spec.System.SidekiqSpec.Annotations
+	s.options.AppPodTemplateOverrides = podTemplateOverrides(s.apimanager.Spec.System.AppSpec.PodTemplateOverrides)
+	s.options.SideKiqPodTemplateOverrides = podTemplateOverrides(s.apimanager.Spec.System.SidekiqSpec.PodTemplateOverrides)
+}
+
+func (s *SystemOptionsProvider) setSecurityContexts() {
+	appSpec := s.apimanager.Spec.System.AppSpec
+	sidekiqSpec := s.apimanager.Spec.System.SidekiqSpec
+	s.options.AppPodSecurityContext = podSecurityContext(s.apimanager, appSpec.PodSecurityContext)
+	s.options.AppContainerSecurityContext = containerSecurityContext(s.apimanager, appSpec.SecurityContext, false)
+	s.options.SideKiqPodSecurityContext = podSecurityContext(s.apimanager, sidekiqSpec.PodSecurityContext)
+	s.options.SideKiqContainerSecurityContext = containerSecurityContext(s.apimanager, sidekiqSpec.SecurityContext, false)
+}
+
+func (s *SystemOptionsProvider) setProbes() {
+	s.options.AppProbes = containerProbes(s.apimanager.Spec.System.AppSpec.Probes)
+	s.options.SideKiqProbes = containerProbes(s.apimanager.Spec.System.SidekiqSpec.Probes)
 }
@@ -48,6 +48,8 @@ func (s *SystemPostgresqlOptionsProvider) GetSystemPostgreSQLOptions() (*compone
 	s.setPriorityClassNames()
 	s.setTopologySpreadConstraints()
 	s.setPodTemplateAnnotations()
+	s.setSecurityContexts()
+	s.setProbes()
 
 	err = s.options.Validate()
 	if err != nil {
@@ -218,3 +220,20 @@ func (s *SystemPostgresqlOptionsProvider) setPodTemplateAnnotations() {
 		s.options.PodTemplateAnnotations = s.apimanager.Spec.System.DatabaseSpec.PostgreSQL.Annotations
 	}
 }
+
+func (s *SystemPostgresqlOptionsProvider) setSecurityContexts() {
+	var podSC *v1.PodSecurityContext
+	var containerSC *v1.SecurityContext
+	if s.apimanager.IsSystemPostgreSQLEnabled() {
+		podSC = s.apimanager.Spec.System.DatabaseSpec.PostgreSQL.PodSecurityContext
+		containerSC = s.apimanager.Spec.System.DatabaseSpec.PostgreSQL.SecurityContext
+	}
+	s.options.PodSecurityContext = podSecurityContext(s.apimanager, podSC)
+	s.options.ContainerSecurityContext = containerSecurityContext(s.apimanager, containerSC, false)
+}
+
+func (s *SystemPostgresqlOptionsProvider) setProbes() {
+	if s.apimanager.IsSystemPostgreSQLEnabled() {
+		s.options.Probes = containerProbes(s.apimanager.Spec.System.DatabaseSpec.PostgreSQL.Probes)
+	}
+}
@@ -41,12 +41,84 @@ func (r *SystemReconciler) reconcileFileStorage(system *component.System) error
 	return r.ReconcilePersistentVolumeClaim(system.SharedStorage(), reconcilers.CreateOnlyMutator)
 }
 
+// ReconcileSecretsAndConfigMaps reconciles the secrets and configmaps read by
+// the system workloads. Backend reads the events hook secret as well, so the
+// APIManager controller calls it ahead of the backend reconciler for the
+// content hashes of the pod templates to be final on creation.
+func (r *SystemReconciler) ReconcileSecretsAndConfigMaps() error {
+	system, err := System(r.apiManager, r.Client())
+	if err != nil {
+		return err
+	}
+
+	// System CM
+	err = r.ReconcileConfigMap(system.SystemConfigMap(), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return err
+	}
+
+	// System CM
+	err = r.ReconcileConfigMap(system.EnvironmentConfigMap(), reconcilers.CreateOnlyMutator)
+	if err != nil {
+		return err
+	}
+
+	// SMTP Secret
+	err = r.ReconcileSecret(system.SMTPSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// EventsHook Secret
+	err = r.ReconcileSecret(system.EventsHookSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// MasterApicast  Secret
+	err = r.ReconcileSecret(system.MasterApicastSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// SystemSeed Secret
+	err = r.ReconcileSecret(system.SeedSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// Recaptcha Secret
+	err = r.ReconcileSecret(system.RecaptchaSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// SystemApp Secret
+	err = r.ReconcileSecret(system.AppSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	// Memcached Secret
+	err = r.ReconcileSecret(system.MemcachedSecret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 	system, err := System(r.apiManager, r.Client())
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	err = r.ReconcileSecretsAndConfigMaps()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	err = r.reconcileFileStorage(system)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -70,14 +142,43 @@ func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// Provider Ingress
+	err = r.ReconcileIngress(system.ProviderIngress(), reconcilers.IngressMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Master Ingress
+	err = r.ReconcileIngress(system.MasterIngress(), reconcilers.IngressMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Developer Ingress
+	err = r.ReconcileIngress(system.DeveloperIngress(), reconcilers.IngressMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Memcached Service
 	err = r.ReconcileService(system.MemcachedService(), reconcilers.CreateOnlyMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// TLS connections to the external database and Redis
+	tlsMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigEnvVarsBlockMutator(component.DatabaseURLEnvVarNames()...),
+		reconcilers.DeploymentConfigEnvVarsMutator(component.SystemRedisTLSEnvVarNames()...),
+		reconcilers.DeploymentConfigVolumesMutator(component.SystemDatabaseTLSVolumeName,
+			component.SystemRedisTLSVolumeName, component.BackendRedisTLSVolumeName),
+	}
+
 	// SystemApp DC
 	systemAppDCMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
 		reconcilers.DeploymentConfigTolerationsMutator,
@@ -93,7 +194,10 @@ func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 		upgrade.SystemBackendUrls,
 	}
 
-	if r.apiManager.Spec.System.AppSpec.Replicas != nil {
+	systemAppDCMutators = append(systemAppDCMutators, tlsMutators...)
+
+	// Replicas are managed by the HorizontalPodAutoscaler when autoscaling is enabled
+	if r.apiManager.Spec.System.AppSpec.Replicas != nil && r.apiManager.Spec.System.AppSpec.Autoscaling == nil {
 		systemAppDCMutators = append(systemAppDCMutators, reconcilers.DeploymentConfigReplicasMutator)
 	}
 
@@ -104,6 +208,8 @@ func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 
 	// Sidekiq DC
 	sidekiqDCMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -119,6 +225,8 @@ func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 		reconcilers.DeploymentConfigPodTemplateAnnotationsMutator,
 	}
 
+	sidekiqDCMutators = append(sidekiqDCMutators, tlsMutators...)
+
 	if r.apiManager.Spec.System.SidekiqSpec.Replicas != nil {
 		sidekiqDCMutators = append(sidekiqDCMutators, reconcilers.DeploymentConfigReplicasMutator)
 	}
@@ -128,62 +236,14 @@ func (r *SystemReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
-	// System CM
-	err = r.ReconcileConfigMap(system.SystemConfigMap(), reconcilers.CreateOnlyMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// System CM
-	err = r.ReconcileConfigMap(system.EnvironmentConfigMap(), reconcilers.CreateOnlyMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// SMTP Secret
-	err = r.ReconcileSecret(system.SMTPSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// EventsHook Secret
-	err = r.ReconcileSecret(system.EventsHookSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// MasterApicast  Secret
-	err = r.ReconcileSecret(system.MasterApicastSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// SystemSeed Secret
-	err = r.ReconcileSecret(system.SeedSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// Recaptcha Secret
-	err = r.ReconcileSecret(system.RecaptchaSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// SystemApp Secret
-	err = r.ReconcileSecret(system.AppSecret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	// Memcached Secret
-	err = r.ReconcileSecret(system.MemcachedSecret(), reconcilers.DefaultsOnlySecretMutator)
+	// SystemApp PDB
+	err = r.ReconcilePodDisruptionBudget(system.AppPodDisruptionBudget(), reconcilers.GenericPDBMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// SystemApp PDB
-	err = r.ReconcilePodDisruptionBudget(system.AppPodDisruptionBudget(), reconcilers.GenericPDBMutator)
+	// SystemApp HPA
+	err = r.ReconcileHorizontalPodAutoscaler(system.AppHorizontalPodAutoscaler(), reconcilers.GenericHPAMutator)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -234,11 +294,11 @@ func (r *SystemReconciler) systemAppDCResourceMutator(desired, existing *appsv1.
 	//
 	// Check containers
 	//
-	if len(desired.Spec.Template.Spec.Containers) != 3 {
+	if helper.ComponentContainersCount(desired.Annotations, desired.Spec.Template.Spec.Containers) != 3 {
 		return false, fmt.Errorf(fmt.Sprintf("%s desired spec.template.spec.containers length changed to '%d', should be 3", desiredName, len(desired.Spec.Template.Spec.Containers)))
 	}
 
-	if len(existing.Spec.Template.Spec.Containers) != 3 {
+	if helper.ComponentContainersCount(existing.Annotations, existing.Spec.Template.Spec.Containers) != 3 {
 		r.Logger().Info(fmt.Sprintf("%s spec.template.spec.containers length changed to '%d', recreating dc", desiredName, len(existing.Spec.Template.Spec.Containers)))
 		existing.Spec.Template.Spec.Containers = desired.Spec.Template.Spec.Containers
 		update = true
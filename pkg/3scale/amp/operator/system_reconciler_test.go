@@ -8,6 +8,7 @@ import (
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
+	"github.com/google/go-cmp/cmp"
 	grafanav1alpha1 "github.com/grafana-operator/grafana-operator/v4/api/integreatly/v1alpha1"
 	appsv1 "github.com/openshift/api/apps/v1"
 	configv1 "github.com/openshift/api/config/v1"
@@ -25,6 +26,7 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestSystemReconcilerCreate(t *testing.T) {
@@ -243,3 +245,58 @@ func testSystemAPIManagerCreator(appReplicas, sidekiqReplicas *int64) *appsv1alp
 		},
 	}
 }
+
+func TestSystemReconcilerContentHashesFinalOnCreate(t *testing.T) {
+	apimanager := basicApimanagerSpecTestSystemOptions()
+	for _, addToScheme := range []func(*runtime.Scheme) error{routev1.AddToScheme, monitoringv1.AddToScheme, grafanav1alpha1.AddToScheme, configv1.AddToScheme} {
+		if err := addToScheme(scheme.Scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	baseAPIManagerLogicReconciler, cl := testBaseAPIManagerLogicReconciler(t, apimanager)
+
+	// Same order as the APIManager controller
+	reconcileComponents := func() {
+		t.Helper()
+		err := NewSystemReconciler(baseAPIManagerLogicReconciler).ReconcileSecretsAndConfigMaps()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, reconciler := range []interface {
+			Reconcile() (reconcile.Result, error)
+		}{
+			NewBackendReconciler(baseAPIManagerLogicReconciler),
+			NewZyncReconciler(baseAPIManagerLogicReconciler),
+			NewSystemReconciler(baseAPIManagerLogicReconciler),
+		} {
+			if _, err := reconciler.Reconcile(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	podTemplateAnnotations := func() map[string]map[string]string {
+		t.Helper()
+		annotations := map[string]map[string]string{}
+		for _, name := range []string{"system-app", "system-sidekiq", "backend-worker", "backend-listener", "zync"} {
+			dc := &appsv1.DeploymentConfig{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, dc); err != nil {
+				t.Fatal(err)
+			}
+			annotations[name] = map[string]string{
+				SecretsHashAnnotation:    dc.Spec.Template.Annotations[SecretsHashAnnotation],
+				ConfigMapsHashAnnotation: dc.Spec.Template.Annotations[ConfigMapsHashAnnotation],
+			}
+		}
+		return annotations
+	}
+
+	reconcileComponents()
+	created := podTemplateAnnotations()
+
+	// A second pass on the objects created by the first one must not roll out the workloads
+	reconcileComponents()
+	if diff := cmp.Diff(created, podTemplateAnnotations()); diff != "" {
+		t.Errorf("content hashes changed after creation (-created +reconciled):\n%s", diff)
+	}
+}
@@ -29,11 +29,14 @@ func (s *SystemSearchdOptionsProvider) GetOptions() (*component.SystemSearchdOpt
 	s.options.Labels = s.labels()
 	s.options.PodTemplateLabels = s.podTemplateLabels()
 	s.options.PodTemplateAnnotations = s.apimanager.Spec.System.SearchdSpec.Annotations
+	s.options.PodTemplateOverrides = podTemplateOverrides(s.apimanager.Spec.System.SearchdSpec.PodTemplateOverrides)
 	s.setResourceRequirementsOptions()
 	s.setNodeAffinityAndTolerationsOptions()
 	s.setPVCOptions()
 	s.setPriorityClassNames()
 	s.setTopologySpreadConstraints()
+	s.setSecurityContexts()
+	s.setProbes()
 
 	err := s.options.Validate()
 	if err != nil {
@@ -128,3 +131,13 @@ func (s *SystemSearchdOptionsProvider) setTopologySpreadConstraints() {
 		s.options.TopologySpreadConstraints = s.apimanager.Spec.System.SearchdSpec.TopologySpreadConstraints
 	}
 }
+
+func (s *SystemSearchdOptionsProvider) setSecurityContexts() {
+	searchdSpec := s.apimanager.Spec.System.SearchdSpec
+	s.options.PodSecurityContext = podSecurityContext(s.apimanager, searchdSpec.PodSecurityContext)
+	s.options.ContainerSecurityContext = containerSecurityContext(s.apimanager, searchdSpec.SecurityContext, false)
+}
+
+func (s *SystemSearchdOptionsProvider) setProbes() {
+	s.options.Probes = containerProbes(s.apimanager.Spec.System.SearchdSpec.Probes)
+}
@@ -184,6 +184,40 @@ func TestGetSystemSearchdOptionsProvider(t *testing.T) {
 				return expectedOpts
 			},
 		},
+		{"WithPodTemplateOverrides",
+			func() *appsv1alpha1.APIManager {
+				apimanager := testSearchdBasicApimanager()
+				apimanager.Spec.System.SearchdSpec.PodTemplateOverrides = &appsv1alpha1.PodTemplateOverridesSpec{
+					Env:               []v1.EnvVar{{Name: "MY_ENV", Value: "myvalue"}},
+					SidecarContainers: []v1.Container{{Name: "mysidecar", Image: "myimage"}},
+				}
+				return apimanager
+			},
+			func() *component.SystemSearchdOptions {
+				expectedOpts := testDefaultExpectedSystemSearchdOptions()
+				expectedOpts.PodTemplateOverrides = &component.PodTemplateOverrides{
+					Env:               []v1.EnvVar{{Name: "MY_ENV", Value: "myvalue"}},
+					SidecarContainers: []v1.Container{{Name: "mysidecar", Image: "myimage"}},
+				}
+				return expectedOpts
+			},
+		},
+		{"WithProbes",
+			func() *appsv1alpha1.APIManager {
+				apimanager := testSearchdBasicApimanager()
+				apimanager.Spec.System.SearchdSpec.Probes = []appsv1alpha1.ContainerProbesSpec{
+					{Container: "system-searchd", StartupProbe: &v1.Probe{FailureThreshold: 60}},
+				}
+				return apimanager
+			},
+			func() *component.SystemSearchdOptions {
+				expectedOpts := testDefaultExpectedSystemSearchdOptions()
+				expectedOpts.Probes = []component.ContainerProbes{
+					{ContainerName: "system-searchd", StartupProbe: &v1.Probe{FailureThreshold: 60}},
+				}
+				return expectedOpts
+			},
+		},
 	}
 
 	for _, tc := range cases {
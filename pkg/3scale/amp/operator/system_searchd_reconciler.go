@@ -48,6 +48,8 @@ func (r *SystemSearchdReconciler) Reconcile() (reconcile.Result, error) {
 
 	// DC
 	searchdDCmutator := reconcilers.DeploymentConfigMutator(
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -6,6 +6,7 @@ import (
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
 	"github.com/3scale/3scale-operator/pkg/reconcilers"
 
 	appsv1 "github.com/openshift/api/apps/v1"
@@ -205,3 +206,67 @@ func testsearchdDC(namespace string) *appsv1.DeploymentConfig {
 	dc.Namespace = namespace
 	return dc
 }
+
+func TestSystemSearchdReconcilerPodTemplateOverrides(t *testing.T) {
+	var (
+		log = logf.Log.WithName("operator_test")
+	)
+
+	ctx := context.TODO()
+
+	apimanager := testSearchdBasicApimanager()
+	apimanager.Spec.System.SearchdSpec.PodTemplateOverrides = &appsv1alpha1.PodTemplateOverridesSpec{
+		Env:               []v1.EnvVar{{Name: "MY_ENV", Value: "myvalue"}},
+		SidecarContainers: []v1.Container{{Name: "mysidecar", Image: "myimage"}},
+	}
+	objs := []runtime.Object{apimanager}
+	s := scheme.Scheme
+	s.AddKnownTypes(appsv1alpha1.GroupVersion, apimanager)
+	err := appsv1.AddToScheme(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cl := fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
+	clientAPIReader := fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
+	clientset := fakeclientset.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10000)
+
+	baseReconciler := reconcilers.NewBaseReconciler(ctx, cl, s, clientAPIReader, log, clientset.Discovery(), recorder)
+
+	reconcileAndGetDC := func() *appsv1.DeploymentConfig {
+		reconciler := NewSystemSearchdReconciler(NewBaseAPIManagerLogicReconciler(baseReconciler, apimanager))
+		if _, err := reconciler.Reconcile(); err != nil {
+			t.Fatal(err)
+		}
+		dc := &appsv1.DeploymentConfig{}
+		namespacedName := types.NamespacedName{Name: component.SystemSearchdDeploymentName, Namespace: namespace}
+		if err := cl.Get(ctx, namespacedName, dc); err != nil {
+			t.Fatal(err)
+		}
+		return dc
+	}
+
+	dc := reconcileAndGetDC()
+	podSpec := dc.Spec.Template.Spec
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "mysidecar" {
+		t.Fatalf("sidecar container not added: %v", podSpec.Containers)
+	}
+	if helper.FindEnvVar(podSpec.Containers[0].Env, "MY_ENV") < 0 {
+		t.Fatal("override env var not added")
+	}
+
+	// removed overrides are removed from the existing DC
+	apimanager.Spec.System.SearchdSpec.PodTemplateOverrides = nil
+	dc = reconcileAndGetDC()
+	podSpec = dc.Spec.Template.Spec
+	if len(podSpec.Containers) != 1 {
+		t.Fatalf("sidecar container not removed: %v", podSpec.Containers)
+	}
+	if helper.FindEnvVar(podSpec.Containers[0].Env, "MY_ENV") >= 0 {
+		t.Fatal("override env var not removed")
+	}
+	if _, ok := dc.Annotations[helper.PodTemplateOverridesAnnotation]; ok {
+		t.Fatal("overrides record annotation not removed")
+	}
+}
@@ -43,8 +43,11 @@ func (z *ZyncOptionsProvider) GetZyncOptions() (*component.ZyncOptions, error) {
 	z.setResourceRequirementsOptions()
 	z.setNodeAffinityAndTolerationsOptions()
 	z.setReplicas()
+	z.setAutoscaling()
 	z.setPriorityClassNames()
 	z.setTopologySpreadConstraints()
+	z.setSecurityContexts()
+	z.setProbes()
 
 	z.zyncOptions.CommonLabels = z.commonLabels()
 	z.zyncOptions.CommonZyncLabels = z.commonZyncLabels()
@@ -56,6 +59,8 @@ func (z *ZyncOptionsProvider) GetZyncOptions() (*component.ZyncOptions, error) {
 	z.zyncOptions.ZyncPodTemplateAnnotations = z.zyncPodTemplateAnnotations()
 	z.zyncOptions.ZyncQuePodTemplateAnnotations = z.zyncQuePodTemplateAnnotations()
 	z.zyncOptions.ZyncDatabasePodTemplateAnnotations = z.apimanager.Spec.Zync.DatabaseAnnotations
+	z.zyncOptions.ZyncPodTemplateOverrides = podTemplateOverrides(z.apimanager.Spec.Zync.AppSpec.PodTemplateOverrides)
+	z.zyncOptions.ZyncQuePodTemplateOverrides = podTemplateOverrides(z.apimanager.Spec.Zync.QueSpec.PodTemplateOverrides)
 
 	z.zyncOptions.ZyncMetrics = true
 
@@ -143,6 +148,13 @@ func (z *ZyncOptionsProvider) setSecretBasedOptions() error {
 		return err
 	}
 
+	if z.apimanager.IsExternal(appsv1alpha1.ZyncDatabase) {
+		z.zyncOptions.DatabaseTLS, err = externalDatabaseTLSOptions(z.secretSource, zyncDatabaseTLSSecretFields)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -213,6 +225,10 @@ func (z *ZyncOptionsProvider) setReplicas() {
 	}
 }
 
+func (z *ZyncOptionsProvider) setAutoscaling() {
+	z.zyncOptions.ZyncQueScaledObject = scaledObjectOptions(z.apimanager.Spec.Zync.QueSpec.QueueAutoscaling)
+}
+
 func (z *ZyncOptionsProvider) commonLabels() map[string]string {
 	return map[string]string{
 		"app":                  *z.apimanager.Spec.AppLabel,
@@ -337,3 +353,19 @@ func (z *ZyncOptionsProvider) zyncQuePodTemplateAnnotations() map[string]string
 	}
 	return annotations
 }
+
+func (z *ZyncOptionsProvider) setSecurityContexts() {
+	zyncSpec := z.apimanager.Spec.Zync
+	z.zyncOptions.ZyncPodSecurityContext = podSecurityContext(z.apimanager, zyncSpec.AppSpec.PodSecurityContext)
+	z.zyncOptions.ZyncContainerSecurityContext = containerSecurityContext(z.apimanager, zyncSpec.AppSpec.SecurityContext, false)
+	z.zyncOptions.ZyncQuePodSecurityContext = podSecurityContext(z.apimanager, zyncSpec.QueSpec.PodSecurityContext)
+	z.zyncOptions.ZyncQueContainerSecurityContext = containerSecurityContext(z.apimanager, zyncSpec.QueSpec.SecurityContext, false)
+	z.zyncOptions.ZyncDatabasePodSecurityContext = podSecurityContext(z.apimanager, zyncSpec.DatabasePodSecurityContext)
+	z.zyncOptions.ZyncDatabaseContainerSecurityContext = containerSecurityContext(z.apimanager, zyncSpec.DatabaseSecurityContext, false)
+}
+
+func (z *ZyncOptionsProvider) setProbes() {
+	z.zyncOptions.ZyncProbes = containerProbes(z.apimanager.Spec.Zync.AppSpec.Probes)
+	z.zyncOptions.ZyncQueProbes = containerProbes(z.apimanager.Spec.Zync.QueSpec.Probes)
+	z.zyncOptions.ZyncDatabaseProbes = containerProbes(z.apimanager.Spec.Zync.DatabaseProbes)
+}
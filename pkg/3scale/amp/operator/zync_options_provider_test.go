@@ -3,6 +3,7 @@ package operator
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -390,3 +391,62 @@ func TestGetZyncOptionsProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestGetZyncOptionsProviderDatabaseTLS(t *testing.T) {
+	ca, _ := testTLSMaterial(t)
+
+	cases := []struct {
+		testName    string
+		tlsFields   map[string]string
+		expectedURL string
+		errSubstr   string
+	}{
+		{"VerifyFull",
+			map[string]string{
+				component.ZyncSecretDatabaseSSLModeFieldName: "verify-full",
+				component.ZyncSecretDatabaseSSLCAFieldName:   ca,
+			},
+			"$(DATABASE_BASE_URL)?sslmode=verify-full&sslrootcert=/tls/zync-database/DATABASE_SSL_CA", "",
+		},
+		{"Require", map[string]string{component.ZyncSecretDatabaseSSLModeFieldName: "require"}, "$(DATABASE_BASE_URL)?sslmode=require", ""},
+		{"VerifyFullWithoutCA", map[string]string{component.ZyncSecretDatabaseSSLModeFieldName: "verify-full"}, "", "requires the CA"},
+		{"UnknownMode", map[string]string{component.ZyncSecretDatabaseSSLModeFieldName: "strict"}, "", "unknown SSL mode"},
+		{"CAWithoutMode", map[string]string{component.ZyncSecretDatabaseSSLCAFieldName: ca}, "", "SSL mode is required"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(subT *testing.T) {
+			secret := getZyncSecretExternalDatabase(namespace)
+			for field, value := range tc.tlsFields {
+				secret.Data[field] = []byte(value)
+			}
+			cl := fake.NewFakeClient(secret)
+			optsProvider := NewZyncOptionsProvider(basicApimanagerWithExternalZyncDatabaseSpecTestZyncOptions(), namespace, cl)
+			opts, err := optsProvider.GetZyncOptions()
+			if tc.errSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+					subT.Fatalf("expected error containing '%s', got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			podSpec := component.NewZync(opts).DeploymentConfig().Spec.Template.Spec
+			for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+				idx := helper.FindEnvVar(container.Env, "DATABASE_URL")
+				if idx < 0 || container.Env[idx].Value != tc.expectedURL {
+					subT.Errorf("container %s: unexpected DATABASE_URL: %v", container.Name, container.Env)
+				}
+				if helper.FindEnvVar(container.Env, component.DatabaseBaseURLEnvVarName) > idx {
+					subT.Errorf("container %s: %s defined after DATABASE_URL", container.Name, component.DatabaseBaseURLEnvVarName)
+				}
+			}
+			hasVolume := helper.FindVolumeByName(podSpec.Volumes, component.ZyncDatabaseTLSVolumeName) >= 0
+			if hasVolume != (tc.tlsFields[component.ZyncSecretDatabaseSSLCAFieldName] != "") {
+				subT.Errorf("unexpected %s volume: %v", component.ZyncDatabaseTLSVolumeName, podSpec.Volumes)
+			}
+		})
+	}
+}
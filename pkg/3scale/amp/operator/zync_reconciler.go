@@ -44,8 +44,26 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// The secret read by the workloads is reconciled first, for the content
+	// hashes of their pod templates to be final on creation
+
+	// Zync Secret
+	err = r.ReconcileSecret(zync.Secret(), reconcilers.DefaultsOnlySecretMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// TLS connection to the external database
+	databaseTLSMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigEnvVarsBlockMutator(component.DatabaseURLEnvVarNames()...),
+		reconcilers.DeploymentConfigVolumesMutator(component.ZyncDatabaseTLSVolumeName),
+	}
+
 	// Zync DC
 	zyncMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -55,6 +73,7 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 		reconcilers.DeploymentConfigTopologySpreadConstraintsMutator,
 		reconcilers.DeploymentConfigPodTemplateAnnotationsMutator,
 	}
+	zyncMutators = append(zyncMutators, databaseTLSMutators...)
 	if r.apiManager.Spec.Zync.AppSpec.Replicas != nil {
 		zyncMutators = append(zyncMutators, reconcilers.DeploymentConfigReplicasMutator)
 	}
@@ -65,6 +84,9 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 
 	// Zync Que DC
 	zyncQueMutators := []reconcilers.DCMutateFn{
+		reconcilers.DeploymentConfigPodTemplateOverridesMutator,
+		reconcilers.DeploymentConfigSecurityContextMutator,
+		reconcilers.DeploymentConfigProbesMutator,
 		reconcilers.DeploymentConfigImageChangeTriggerMutator,
 		reconcilers.DeploymentConfigContainerResourcesMutator,
 		reconcilers.DeploymentConfigAffinityMutator,
@@ -74,7 +96,9 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 		reconcilers.DeploymentConfigTopologySpreadConstraintsMutator,
 		reconcilers.DeploymentConfigPodTemplateAnnotationsMutator,
 	}
-	if r.apiManager.Spec.Zync.QueSpec.Replicas != nil {
+	zyncQueMutators = append(zyncQueMutators, databaseTLSMutators...)
+	// Replicas are managed by the KEDA ScaledObject when queue autoscaling is enabled
+	if r.apiManager.Spec.Zync.QueSpec.Replicas != nil && r.apiManager.Spec.Zync.QueSpec.QueueAutoscaling == nil {
 		zyncQueMutators = append(zyncQueMutators, reconcilers.DeploymentConfigReplicasMutator)
 	}
 	err = r.ReconcileDeploymentConfig(zync.QueDeploymentConfig(), reconcilers.DeploymentConfigMutator(zyncQueMutators...))
@@ -95,6 +119,8 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 			reconcilers.DeploymentConfigContainerResourcesMutator,
 			reconcilers.DeploymentConfigAffinityMutator,
 			reconcilers.DeploymentConfigTolerationsMutator,
+			reconcilers.DeploymentConfigSecurityContextMutator,
+			reconcilers.DeploymentConfigProbesMutator,
 			reconcilers.DeploymentConfigPodTemplateLabelsMutator,
 			reconcilers.DeploymentConfigPriorityClassMutator,
 			reconcilers.DeploymentConfigTopologySpreadConstraintsMutator,
@@ -112,12 +138,6 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 		}
 	}
 
-	// Zync Secret
-	err = r.ReconcileSecret(zync.Secret(), reconcilers.DefaultsOnlySecretMutator)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
 	// Zync PDB
 	err = r.ReconcilePodDisruptionBudget(zync.ZyncPodDisruptionBudget(), reconcilers.GenericPDBMutator)
 	if err != nil {
@@ -130,6 +150,18 @@ func (r *ZyncReconciler) Reconcile() (reconcile.Result, error) {
 		return reconcile.Result{}, err
 	}
 
+	// Zync Que TriggerAuthentication
+	err = r.ReconcileTriggerAuthentication(zync.QueTriggerAuthentication(), reconcilers.TriggerAuthenticationMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Zync Que ScaledObject
+	err = r.ReconcileScaledObject(zync.QueScaledObject(), reconcilers.GenericScaledObjectMutator)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	err = r.ReconcilePodMonitor(zync.ZyncPodMonitor(), reconcilers.CreateOnlyMutator)
 	if err != nil {
 		return reconcile.Result{}, err
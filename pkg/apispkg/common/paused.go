@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PausedAnnotation stops the reconciliation of the annotated resource
+	// when set to "true". Removing it resumes the reconciliation
+	PausedAnnotation = "3scale.net/paused"
+
+	// PausedConditionType is true while the resource is paused
+	PausedConditionType ConditionType = "Paused"
+
+	PausedByAnnotationReason ConditionReason = "PausedByAnnotation"
+)
+
+// IsPaused returns true when the reconciliation of the object is paused
+func IsPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// PausedCondition returns the condition reported by paused resources
+func PausedCondition() Condition {
+	return Condition{
+		Type:    PausedConditionType,
+		Status:  corev1.ConditionTrue,
+		Reason:  PausedByAnnotationReason,
+		Message: fmt.Sprintf("Reconciliation paused by the %s annotation", PausedAnnotation),
+	}
+}
+
+// SetPausedCondition sets or removes the Paused condition depending on the
+// annotation of the object. It returns true when the conditions changed
+func (conditions *Conditions) SetPausedCondition(obj metav1.Object) bool {
+	if IsPaused(obj) {
+		return conditions.SetCondition(PausedCondition())
+	}
+	return conditions.RemoveCondition(PausedConditionType)
+}
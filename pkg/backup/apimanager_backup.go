@@ -57,6 +57,10 @@ func (b *APIManagerBackup) APIManager() *appsv1alpha1.APIManager {
 	return b.options.APIManager
 }
 
+func (b *APIManagerBackup) hasBackupDestination() bool {
+	return b.options.APIManagerBackupPVCOptions != nil || b.options.APIManagerBackupS3Options != nil
+}
+
 func (b *APIManagerBackup) BackupDestinationPVC() *v1.PersistentVolumeClaim {
 	if b.options.APIManagerBackupPVCOptions == nil {
 		return nil
@@ -100,8 +104,8 @@ func (b *APIManagerBackup) BackupDestinationPVC() *v1.PersistentVolumeClaim {
 	return res
 }
 
-func (b *APIManagerBackup) BackupSecretsAndConfigMapsToPVCJob() *batchv1.Job {
-	if b.options.APIManagerBackupPVCOptions == nil {
+func (b *APIManagerBackup) BackupSecretsAndConfigMapsJob() *batchv1.Job {
+	if !b.hasBackupDestination() {
 		return nil
 	}
 
@@ -111,7 +115,7 @@ func (b *APIManagerBackup) BackupSecretsAndConfigMapsToPVCJob() *batchv1.Job {
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -126,7 +130,7 @@ func (b *APIManagerBackup) BackupSecretsAndConfigMapsToPVCJob() *batchv1.Job {
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.pvcBackupDestinationPodVolume(),
+						b.backupDestinationPodVolume(),
 					},
 					Containers: []v1.Container{
 						v1.Container{
@@ -142,7 +146,7 @@ func (b *APIManagerBackup) BackupSecretsAndConfigMapsToPVCJob() *batchv1.Job {
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.pvcBackupDestinationContainerVolumeMount(),
+								b.backupDestinationContainerVolumeMount(),
 							},
 						},
 					},
@@ -152,10 +156,12 @@ func (b *APIManagerBackup) BackupSecretsAndConfigMapsToPVCJob() *batchv1.Job {
 			},
 		},
 	}
+
+	return b.withBackupDestination(job)
 }
 
-func (b *APIManagerBackup) BackupAPIManagerCustomResourceToPVCJob() *batchv1.Job {
-	if b.options.APIManagerBackupPVCOptions == nil {
+func (b *APIManagerBackup) BackupAPIManagerCustomResourceJob() *batchv1.Job {
+	if !b.hasBackupDestination() {
 		return nil
 	}
 
@@ -165,7 +171,7 @@ func (b *APIManagerBackup) BackupAPIManagerCustomResourceToPVCJob() *batchv1.Job
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -180,7 +186,7 @@ func (b *APIManagerBackup) BackupAPIManagerCustomResourceToPVCJob() *batchv1.Job
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.pvcBackupDestinationPodVolume(),
+						b.backupDestinationPodVolume(),
 					},
 					Containers: []v1.Container{
 						v1.Container{
@@ -196,7 +202,7 @@ func (b *APIManagerBackup) BackupAPIManagerCustomResourceToPVCJob() *batchv1.Job
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.pvcBackupDestinationContainerVolumeMount(),
+								b.backupDestinationContainerVolumeMount(),
 							},
 						},
 					},
@@ -206,10 +212,12 @@ func (b *APIManagerBackup) BackupAPIManagerCustomResourceToPVCJob() *batchv1.Job
 			},
 		},
 	}
+
+	return b.withBackupDestination(job)
 }
 
-func (b *APIManagerBackup) BackupSystemFileStoragePVCToPVCJob() *batchv1.Job {
-	if b.options.APIManagerBackupPVCOptions == nil {
+func (b *APIManagerBackup) BackupSystemFileStoragePVCJob() *batchv1.Job {
+	if !b.hasBackupDestination() {
 		return nil
 	}
 
@@ -219,7 +227,7 @@ func (b *APIManagerBackup) BackupSystemFileStoragePVCToPVCJob() *batchv1.Job {
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -234,7 +242,7 @@ func (b *APIManagerBackup) BackupSystemFileStoragePVCToPVCJob() *batchv1.Job {
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.pvcBackupDestinationPodVolume(),
+						b.backupDestinationPodVolume(),
 						b.systemFileStoragePodVolume(),
 					},
 					Containers: []v1.Container{
@@ -251,7 +259,7 @@ func (b *APIManagerBackup) BackupSystemFileStoragePVCToPVCJob() *batchv1.Job {
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.pvcBackupDestinationContainerVolumeMount(),
+								b.backupDestinationContainerVolumeMount(),
 								b.systemFileStorageContainerVolumeMount(),
 							},
 						},
@@ -262,6 +270,8 @@ func (b *APIManagerBackup) BackupSystemFileStoragePVCToPVCJob() *batchv1.Job {
 			},
 		},
 	}
+
+	return b.withBackupDestination(job)
 }
 
 func (b *APIManagerBackup) systemFileStoragePodVolume() v1.Volume {
@@ -283,7 +293,25 @@ func (b *APIManagerBackup) systemFileStorageContainerVolumeMount() v1.VolumeMoun
 	}
 }
 
-func (b *APIManagerBackup) pvcBackupDestinationPodVolume() v1.Volume {
+// The backup data is written in the destination PVC or, when the destination
+// is S3 or the data is encrypted, in a staging volume of the job pod from
+// where it is encrypted and uploaded
+func (b *APIManagerBackup) backupDestinationPodVolume() v1.Volume {
+	if b.options.APIManagerBackupS3Options != nil || b.options.EncryptionKeySecretName != nil {
+		return StagingPodVolume(backupStagingVolumeName, b.options.StagingSizeLimit)
+	}
+
+	return b.backupDataPodVolume()
+}
+
+// backupDataPodVolume is the volume with the backup data as it is stored in the
+// destination: the destination PVC or, when the destination is S3, the
+// staging volume
+func (b *APIManagerBackup) backupDataPodVolume() v1.Volume {
+	if b.options.APIManagerBackupS3Options != nil {
+		return StagingPodVolume(backupStagingVolumeName, b.options.StagingSizeLimit)
+	}
+
 	return v1.Volume{
 		Name: b.BackupDestinationPVC().Name,
 		VolumeSource: v1.VolumeSource{
@@ -294,9 +322,16 @@ func (b *APIManagerBackup) pvcBackupDestinationPodVolume() v1.Volume {
 	}
 }
 
-func (b *APIManagerBackup) pvcBackupDestinationContainerVolumeMount() v1.VolumeMount {
+func (b *APIManagerBackup) backupDestinationContainerVolumeMount() v1.VolumeMount {
+	return v1.VolumeMount{
+		Name:      b.backupDestinationPodVolume().Name,
+		MountPath: BackupPVCMountPath,
+	}
+}
+
+func (b *APIManagerBackup) backupDataContainerVolumeMount() v1.VolumeMount {
 	return v1.VolumeMount{
-		Name:      b.BackupDestinationPVC().Name,
+		Name:      b.backupDataPodVolume().Name,
 		MountPath: BackupPVCMountPath,
 	}
 }
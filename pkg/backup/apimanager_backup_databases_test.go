@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+)
+
+func databasesTestBackup(options *APIManagerBackupOptions) *APIManagerBackup {
+	options.Namespace = "operator-unittest"
+	options.APIManagerBackupName = "example-backup"
+	options.APIManagerBackupUID = "bf4c4d80-7b5c-4b4e-8e2c-0f5a7c6a3f1d"
+	options.APIManagerName = "example-apimanager"
+	options.APIManager = &appsv1alpha1.APIManager{ObjectMeta: metav1.ObjectMeta{Name: options.APIManagerName, Namespace: options.Namespace}}
+	if _, err := options.APIManager.SetDefaults(); err != nil {
+		panic(err)
+	}
+	options.APIManagerBackupPVCOptions = &APIManagerBackupPVCOptions{BackupDestinationPVC: BackupDestinationPVC{Name: "apimanager-backup-example-backup"}}
+	return NewAPIManagerBackup(options)
+}
+
+func databaseBackupJobScript(t *testing.T, job *batchv1.Job) string {
+	t.Helper()
+	if job == nil {
+		t.Fatal("expected a backup job")
+	}
+	if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds != DatabaseBackupJobActiveDeadlineSeconds {
+		t.Fatalf("expected active deadline %d seconds, got %v", DatabaseBackupJobActiveDeadlineSeconds, job.Spec.ActiveDeadlineSeconds)
+	}
+	args := job.Spec.Template.Spec.Containers[0].Args
+	return args[len(args)-1]
+}
+
+func TestBackupRedisJobs(t *testing.T) {
+	cases := []struct {
+		name         string
+		options      *APIManagerBackupOptions
+		job          func(*APIManagerBackup) *batchv1.Job
+		host         string
+		sentinelHost string
+	}{
+		{"backend", &APIManagerBackupOptions{BackendRedisImageURL: "backend-redis:latest"},
+			(*APIManagerBackup).BackupBackendRedisJob, component.BackendRedisDeploymentName, ""},
+		{"backendSentinel", &APIManagerBackupOptions{BackendRedisImageURL: "backend-redis:latest", BackendRedisSentinelService: component.BackendRedisSentinelServiceName},
+			(*APIManagerBackup).BackupBackendRedisJob, component.BackendRedisSentinelMasterName, component.BackendRedisSentinelServiceName},
+		{"system", &APIManagerBackupOptions{SystemRedisImageURL: "system-redis:latest"},
+			(*APIManagerBackup).BackupSystemRedisJob, component.SystemRedisDeploymentName, ""},
+		{"systemSentinel", &APIManagerBackupOptions{SystemRedisImageURL: "system-redis:latest", SystemRedisSentinelService: component.SystemRedisSentinelServiceName},
+			(*APIManagerBackup).BackupSystemRedisJob, component.SystemRedisSentinelMasterName, component.SystemRedisSentinelServiceName},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			script := databaseBackupJobScript(subT, tc.job(databasesTestBackup(tc.options)))
+			for _, expected := range []string{
+				"REDIS_HOST='" + tc.host + "';",
+				"SENTINEL_HOST='" + tc.sentinelHost + "';",
+				`SENTINEL get-master-addr-by-name "${REDIS_HOST}"`,
+				`if [ "${SECONDS}" -ge "${SAVE_TIMEOUT}" ]`,
+			} {
+				if !strings.Contains(script, expected) {
+					subT.Errorf("expected script to contain %q:\n%s", expected, script)
+				}
+			}
+		})
+	}
+}
+
+func TestBackupSystemMySQLJobURLDecoding(t *testing.T) {
+	backup := databasesTestBackup(&APIManagerBackupOptions{SystemMySQLImageURL: "system-mysql:latest"})
+	script := databaseBackupJobScript(t, backup.BackupSystemMySQLJob())
+
+	for _, expected := range []string{
+		// the user info is split at the last '@', only the encoded one can be part of the credentials
+		`DB_CREDENTIALS="${DB_CREDENTIALS%@*}";`,
+		`DB_ADDRESS="${URL##*@}";`,
+		`MYSQL_PWD="$(urldecode "${DB_CREDENTIALS#*:}")"`,
+		`--user="$(urldecode "${DB_CREDENTIALS%%:*}")"`,
+		`--databases "$(urldecode "${DB_NAME}")"`,
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("expected script to contain %q:\n%s", expected, script)
+		}
+	}
+}
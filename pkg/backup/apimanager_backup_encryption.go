@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	// EncryptionKeySecretKey is the key of the encryption key in the
+	// secret referenced by the backup
+	EncryptionKeySecretKey = "ENCRYPTION_KEY"
+	// EncryptionAlgorithm identifies how the backup data is encrypted in
+	// the backup manifest
+	EncryptionAlgorithm = "aes-256-cbc-pbkdf2-sha256"
+	// EncryptionOpenSSLArgs are the arguments of "openssl enc" encrypting
+	// and, along with -d, decrypting the backup data with EncryptionAlgorithm.
+	// The key is read from the ENCRYPTION_KEY variable
+	EncryptionOpenSSLArgs = "-aes-256-cbc -pbkdf2 -iter 100000 -md sha256 -pass env:ENCRYPTION_KEY"
+	// BackupManifestMACFileName is the file next to the manifest of encrypted
+	// backups with its HMAC-SHA256, in hexadecimal. The manifest lists the
+	// checksums of the encrypted files, so the MAC authenticates all of them
+	BackupManifestMACFileName = BackupManifestFileName + ".hmac"
+	// ManifestMACPythonFunction defines the manifest_mac(path) python function
+	// returning the HMAC-SHA256 of the file with the key derived from the
+	// ENCRYPTION_KEY variable. The key is derived with PBKDF2 and a salt of
+	// its own, so it is not the key the files are encrypted with
+	ManifestMACPythonFunction = `
+def manifest_mac(path):
+  import hashlib, hmac, os
+  key=hashlib.pbkdf2_hmac('sha256', os.environ['ENCRYPTION_KEY'].encode(), b'3scale-backup-manifest-hmac', 100000)
+  with open(path, 'rb') as f:
+    return hmac.new(key, f.read(), hashlib.sha256).hexdigest()
+`
+
+	backupEncryptionPVCMountPath = "/backup-destination"
+)
+
+// EncryptionKeyEnvVar returns the ENCRYPTION_KEY variable of the containers
+// encrypting or decrypting the backup data with the key of the secret
+func EncryptionKeyEnvVar(secretName string) v1.EnvVar {
+	return helper.EnvVarFromSecret("ENCRYPTION_KEY", secretName, EncryptionKeySecretKey)
+}
+
+// withEncryption completes the job to encrypt the backup data written in the
+// staging volume by its containers, which become init containers. With a PVC
+// destination, the encrypted data is written in the PVC. With an S3
+// destination, the data is encrypted in place, before it is uploaded
+func (b *APIManagerBackup) withEncryption(job *batchv1.Job) *batchv1.Job {
+	podSpec := &job.Spec.Template.Spec
+	podSpec.InitContainers = append(podSpec.InitContainers, podSpec.Containers...)
+
+	destinationPath := BackupPVCMountPath
+	volumeMounts := []v1.VolumeMount{b.backupDestinationContainerVolumeMount()}
+	if b.options.APIManagerBackupPVCOptions != nil {
+		destinationPath = backupEncryptionPVCMountPath
+		podSpec.Volumes = append(podSpec.Volumes, b.backupDataPodVolume())
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      b.backupDataPodVolume().Name,
+			MountPath: backupEncryptionPVCMountPath,
+		})
+	}
+
+	podSpec.Containers = []v1.Container{
+		v1.Container{
+			Name:  "encrypt-backup-data",
+			Image: b.options.OCCLIImageURL,
+			Command: []string{
+				"/bin/bash",
+			},
+			Args: []string{
+				"-c",
+				"-e",
+				b.encryptBackupDataContainerArgs(destinationPath),
+			},
+			Env: []v1.EnvVar{
+				EncryptionKeyEnvVar(*b.options.EncryptionKeySecretName),
+			},
+			VolumeMounts: volumeMounts,
+		},
+	}
+
+	return job
+}
+
+// The directory tree and symbolic links are copied as they are, and every
+// regular file is encrypted. The files are listed before encrypting any of
+// them, as they might be replaced in place
+func (b *APIManagerBackup) encryptBackupDataContainerArgs(destinationPath string) string {
+	return fmt.Sprintf(`
+BASEPATH='%s';
+DESTINATION='%s';
+cd "${BASEPATH}";
+if [ "${BASEPATH}" != "${DESTINATION}" ]; then
+  find . -type d -print0 | while IFS= read -r -d '' d; do mkdir -p "${DESTINATION}/${d}"; done;
+  find . -type l -print0 | while IFS= read -r -d '' l; do cp -P -f "${l}" "${DESTINATION}/${l}"; done;
+fi;
+find . -type f -print0 > /tmp/backup-files;
+while IFS= read -r -d '' f; do
+  openssl enc -e %s -in "${f}" -out "${DESTINATION}/${f}.encrypting";
+  mv -f "${DESTINATION}/${f}.encrypting" "${DESTINATION}/${f}";
+done < /tmp/backup-files;
+`,
+		BackupPVCMountPath,
+		destinationPath,
+		EncryptionOpenSSLArgs,
+	)
+}
@@ -0,0 +1,322 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	// BackupManifestFileName is the file at the root of the backup data
+	// describing the backup and listing all the other files of the backup
+	BackupManifestFileName = "manifest.json"
+	// BackupManifestFormatVersion is increased on incompatible changes of
+	// the manifest format
+	BackupManifestFormatVersion = 1
+
+	// backupArtifactListingsDir is the directory of S3 destinations where every
+	// backup job lists the files it uploads, so the manifest is written without
+	// downloading the backup data
+	backupArtifactListingsDir = ".artifacts"
+)
+
+// BackupManifest describes the data of a backup
+type BackupManifest struct {
+	FormatVersion        int    `json:"formatVersion"`
+	APIManagerBackupName string `json:"apiManagerBackupName"`
+	// Time the manifest was written at, in RFC3339 form and in UTC
+	CreationTime string `json:"creationTime,omitempty"`
+	// 3scale release and operator version the APIManager was deployed with,
+	// from its apps.3scale.net/apimanager-threescale-version and
+	// apps.3scale.net/threescale-operator-version annotations
+	ThreescaleVersion string                   `json:"threescaleVersion"`
+	OperatorVersion   string                   `json:"operatorVersion"`
+	APIManager        BackupManifestAPIManager `json:"apiManager"`
+	// Algorithm the files of the backup are encrypted with. Empty when they
+	// are not encrypted. The manifest itself is never encrypted
+	Encryption string `json:"encryption,omitempty"`
+	// Files of the backup, with their path relative to the root of the backup.
+	// The size and checksum are those of the files as stored, encrypted or not
+	Artifacts []BackupManifestArtifact `json:"artifacts"`
+}
+
+// BackupManifestAPIManager describes the topology of the backed up APIManager
+type BackupManifestAPIManager struct {
+	Name string `json:"name"`
+	// Database of system deployed by the operator, mysql or postgresql.
+	// Empty when the database is external
+	SystemDatabase string `json:"systemDatabase,omitempty"`
+	// Storage of the system files, pvc or s3
+	SystemFileStorage string `json:"systemFileStorage"`
+	// Components not deployed by the operator, which data is not in the backup
+	ExternalComponents []string `json:"externalComponents,omitempty"`
+}
+
+type BackupManifestArtifact struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BackupManifestJob writes the manifest of the backup once the rest of the
+// backup data has been written, along with its MAC when the backup is
+// encrypted. In S3 destinations, only the listings of the files uploaded by
+// the backup jobs are downloaded, and only the manifest is uploaded
+func (b *APIManagerBackup) BackupManifestJob() *batchv1.Job {
+	if !b.hasBackupDestination() {
+		return nil
+	}
+
+	jobName, err := helper.UIDBasedJobName("backup-manifest", b.options.APIManagerBackupUID)
+	if err != nil {
+		panic(err)
+	}
+
+	serializedManifest, err := json.Marshal(b.backupManifest())
+	if err != nil {
+		panic(err)
+	}
+
+	var completions int32 = 1
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: b.options.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						b.backupDataPodVolume(),
+					},
+					Containers: []v1.Container{
+						v1.Container{
+							Name:  "backup-manifest",
+							Image: b.options.OCCLIImageURL,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args: []string{
+								"-c",
+								"-e",
+								b.backupManifestContainerArgs(),
+							},
+							Env: []v1.EnvVar{
+								helper.EnvVarFromValue("BACKUP_MANIFEST", string(serializedManifest)),
+							},
+							VolumeMounts: []v1.VolumeMount{
+								b.backupDataContainerVolumeMount(),
+							},
+						},
+					},
+					RestartPolicy:      v1.RestartPolicyNever, // Only "Never" or "OnFailure" are accepted in Kubernetes Jobs
+					ServiceAccountName: ServiceAccountName,
+				},
+			},
+		},
+	}
+
+	if b.options.EncryptionKeySecretName != nil {
+		container := &job.Spec.Template.Spec.Containers[0]
+		container.Env = append(container.Env, EncryptionKeyEnvVar(*b.options.EncryptionKeySecretName))
+	}
+
+	if b.options.APIManagerBackupS3Options != nil {
+		location := &b.options.APIManagerBackupS3Options.Location
+		podSpec := &job.Spec.Template.Spec
+		podSpec.InitContainers = []v1.Container{
+			S3ClientContainer("download-from-s3", b.options.AWSCLIImageURL, location, b.s3DownloadArtifactListingsContainerArgs(),
+				b.backupDataContainerVolumeMount(),
+			),
+			podSpec.Containers[0],
+		}
+		podSpec.Containers = []v1.Container{
+			S3ClientContainer("upload-to-s3", b.options.AWSCLIImageURL, location, b.s3UploadManifestContainerArgs(),
+				b.backupDataContainerVolumeMount(),
+			),
+		}
+		podSpec.Volumes = append(podSpec.Volumes, S3ClientPodVolumes(location)...)
+	}
+
+	return job
+}
+
+// backupManifest returns the manifest of the backup without the artifacts,
+// which are listed by the manifest job
+func (b *APIManagerBackup) backupManifest() *BackupManifest {
+	apimanager := b.options.APIManager
+
+	res := &BackupManifest{
+		FormatVersion:        BackupManifestFormatVersion,
+		APIManagerBackupName: b.options.APIManagerBackupName,
+		ThreescaleVersion:    apimanager.Annotations[appsv1alpha1.ThreescaleVersionAnnotation],
+		OperatorVersion:      apimanager.Annotations[appsv1alpha1.OperatorVersionAnnotation],
+		APIManager: BackupManifestAPIManager{
+			Name:              apimanager.Name,
+			SystemFileStorage: "pvc",
+		},
+	}
+
+	if apimanager.IsSystemMysqlEnabled() {
+		res.APIManager.SystemDatabase = "mysql"
+	} else if apimanager.IsSystemPostgreSQLEnabled() {
+		res.APIManager.SystemDatabase = "postgresql"
+	}
+
+	if apimanager.IsS3Enabled() {
+		res.APIManager.SystemFileStorage = "s3"
+	}
+
+	if b.options.EncryptionKeySecretName != nil {
+		res.Encryption = EncryptionAlgorithm
+	}
+
+	for _, component := range []struct {
+		name     string
+		selector func(*appsv1alpha1.ExternalComponentsSpec) bool
+	}{
+		{"systemDatabase", appsv1alpha1.SystemDatabase},
+		{"systemRedis", appsv1alpha1.SystemRedis},
+		{"systemMemcached", appsv1alpha1.SystemMemcached},
+		{"backendRedis", appsv1alpha1.BackendRedis},
+		{"zyncDatabase", appsv1alpha1.ZyncDatabase},
+	} {
+		if apimanager.IsExternal(component.selector) {
+			res.APIManager.ExternalComponents = append(res.APIManager.ExternalComponents, component.name)
+		}
+	}
+
+	return res
+}
+
+// The files are listed from the listings of the backup jobs in S3
+// destinations, and from the destination PVC otherwise
+func (b *APIManagerBackup) backupManifestContainerArgs() string {
+	listingsDir := ""
+	if b.options.APIManagerBackupS3Options != nil {
+		listingsDir = backupArtifactListingsDir
+	}
+
+	return fmt.Sprintf(`
+BASEPATH='%s';
+LISTINGS='%s';
+PYTHON_MANIFEST_SUBSCRIPT="%s"
+python -c "${PYTHON_MANIFEST_SUBSCRIPT}" "${BASEPATH}" "${LISTINGS}";
+`,
+		BackupPVCMountPath,
+		listingsDir,
+		b.pythonBackupManifestScript(),
+	)
+}
+
+// pythonBackupManifestScript completes the manifest found in the
+// BACKUP_MANIFEST variable with the files of the backup, and writes it at the
+// root of the backup. The files are those of the listings found in the
+// directory of the second argument when not empty, see listArtifactsContainer,
+// or the files found in the backup otherwise.
+// When the ENCRYPTION_KEY variable is set, the MAC of the manifest is written
+// next to it
+func (b *APIManagerBackup) pythonBackupManifestScript() string {
+	return fmt.Sprintf(`
+import datetime, json, os, sys
+%s
+%s
+
+basepath=sys.argv[1]
+manifest=json.loads(os.environ['BACKUP_MANIFEST'])
+manifest['creationTime']=datetime.datetime.utcnow().strftime('%%Y-%%m-%%dT%%H:%%M:%%SZ')
+
+if sys.argv[2]:
+  artifacts={}
+  listingspath=os.path.join(basepath, sys.argv[2])
+  for name in sorted(os.listdir(listingspath)):
+    with open(os.path.join(listingspath, name)) as f:
+      for artifact in json.load(f):
+        artifacts[artifact['path']]=artifact
+  artifacts=[artifacts[path] for path in sorted(artifacts)]
+else:
+  artifacts=list_artifacts(basepath)
+manifest['artifacts']=artifacts
+
+manifestpath=os.path.join(basepath, '%s')
+with open(manifestpath, 'w') as f:
+  json.dump(manifest, f, indent=4, sort_keys=True)
+
+if os.environ.get('ENCRYPTION_KEY'):
+  with open(os.path.join(basepath, '%s'), 'w') as f:
+    f.write(manifest_mac(manifestpath))
+`,
+		ManifestMACPythonFunction,
+		pythonListArtifactsFunction(),
+		BackupManifestFileName,
+		BackupManifestMACFileName,
+	)
+}
+
+// pythonListArtifactsFunction defines the list_artifacts(basepath) python
+// function returning the files of the backup found in the directory, with their
+// size and checksum. The manifest, its MAC and the listings are not backup
+// files. Symbolic links, which are restored as links, and unreadable
+// directories, like lost+found in some volumes, are skipped
+func pythonListArtifactsFunction() string {
+	return fmt.Sprintf(`
+def list_artifacts(basepath):
+  import hashlib, os
+  artifacts=[]
+  for root, dirs, files in os.walk(basepath):
+    dirs[:]=sorted([d for d in dirs if os.path.relpath(os.path.join(root, d), basepath) != '%s'])
+    for name in sorted(files):
+      filepath=os.path.join(root, name)
+      relpath=os.path.relpath(filepath, basepath)
+      if relpath in ['%s', '%s'] or os.path.islink(filepath):
+        continue
+      checksum=hashlib.sha256()
+      with open(filepath, 'rb') as f:
+        for chunk in iter(lambda: f.read(1048576), b''):
+          checksum.update(chunk)
+      artifacts.append({'path': relpath, 'size': os.path.getsize(filepath), 'sha256': checksum.hexdigest()})
+  return artifacts
+`,
+		backupArtifactListingsDir,
+		BackupManifestFileName,
+		BackupManifestMACFileName,
+	)
+}
+
+// The listings are kept in the backup, so the manifest job can be run again
+func (b *APIManagerBackup) s3DownloadArtifactListingsContainerArgs() string {
+	return fmt.Sprintf(`
+BASEPATH='%s';
+LISTINGS='%s';
+mkdir -p "${BASEPATH}/${LISTINGS}";
+aws "${S3_ARGS[@]}" s3 cp --recursive --only-show-errors "${S3_URL}/${LISTINGS}/" "${BASEPATH}/${LISTINGS}";
+`,
+		BackupPVCMountPath,
+		backupArtifactListingsDir,
+	)
+}
+
+func (b *APIManagerBackup) s3UploadManifestContainerArgs() string {
+	return fmt.Sprintf(`
+BASEPATH='%s';
+MANIFEST='%s';
+MANIFEST_MAC='%s';
+if [ -f "${BASEPATH}/${MANIFEST_MAC}" ]; then aws "${S3_ARGS[@]}" s3 cp --only-show-errors "${BASEPATH}/${MANIFEST_MAC}" "${S3_URL}/${MANIFEST_MAC}"; fi;
+aws "${S3_ARGS[@]}" s3 cp --only-show-errors "${BASEPATH}/${MANIFEST}" "${S3_URL}/${MANIFEST}";
+`,
+		BackupPVCMountPath,
+		BackupManifestFileName,
+		BackupManifestMACFileName,
+	)
+}
@@ -3,6 +3,7 @@ package backup
 import (
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	validator "github.com/go-playground/validator/v10"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -12,8 +13,30 @@ type APIManagerBackupOptions struct {
 	APIManagerBackupUID        types.UID                   `validate:"required"` // UID of the APIManagerBackup CR
 	APIManagerName             string                      `validate:"required"` // Name of the APIManager CR. NOT the APIManagerBackup cr name
 	APIManager                 *appsv1alpha1.APIManager    `validate:"required"`
-	APIManagerBackupPVCOptions *APIManagerBackupPVCOptions `validate:"required"`
+	APIManagerBackupPVCOptions *APIManagerBackupPVCOptions `validate:"required_without=APIManagerBackupS3Options"`
+	APIManagerBackupS3Options  *APIManagerBackupS3Options  `validate:"required_without=APIManagerBackupPVCOptions"`
 	OCCLIImageURL              string                      `validate:"required"`
+	AWSCLIImageURL             string                      `validate:"required"`
+
+	// Secret with the key the backup data is encrypted with. Nil when the
+	// backup data is not encrypted
+	EncryptionKeySecretName *string
+
+	// Size limit of the volume the backup data is staged in
+	StagingSizeLimit resource.Quantity
+
+	// Images of the databases and Redis instances deployed by the operator. Empty
+	// when the component is external, in which case it is not backed up
+	SystemMySQLImageURL      string
+	SystemPostgreSQLImageURL string
+	ZyncDatabaseImageURL     string
+	BackendRedisImageURL     string
+	SystemRedisImageURL      string
+
+	// Sentinel services the Redis masters are resolved through. Empty when
+	// Redis is not deployed with Redis Sentinel
+	BackendRedisSentinelService string
+	SystemRedisSentinelService  string
 }
 
 func NewAPIManagerBackupOptions() *APIManagerBackupOptions {
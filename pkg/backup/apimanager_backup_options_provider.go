@@ -6,6 +6,8 @@ import (
 
 	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
 	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/operator"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
 	"github.com/3scale/3scale-operator/pkg/helper"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -39,18 +41,38 @@ func (a *APIManagerBackupOptionsProvider) Options() (*APIManagerBackupOptions, e
 	res.APIManager = apiManager
 	res.APIManagerName = apiManager.Name
 	res.OCCLIImageURL = a.ocCLIImageURL()
+	res.AWSCLIImageURL = a.awsCLIImageURL()
+
+	if a.APIManagerBackupCR.Spec.Encryption != nil {
+		res.EncryptionKeySecretName = &a.APIManagerBackupCR.Spec.Encryption.KeySecretRef.Name
+	}
+	res.StagingSizeLimit = StagingSizeLimit(a.APIManagerBackupCR.Spec.StagingSizeLimit)
+
+	err = a.setInternalDatabasesImageURLs(res, apiManager)
+	if err != nil {
+		return nil, err
+	}
 
 	pvcOptions, err := a.pvcBackupOptions()
 	if err != nil {
 		return nil, err
 	}
 
+	s3Options, err := a.s3BackupOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO can this checks be omitted and just rely on the validator package in the APIManagerBackup struct?
-	if pvcOptions == nil {
+	if pvcOptions == nil && s3Options == nil {
 		return nil, fmt.Errorf("At least one backup destination has to be specified")
 	}
+	if pvcOptions != nil && s3Options != nil {
+		return nil, fmt.Errorf("Only one backup destination can be specified")
+	}
 
 	res.APIManagerBackupPVCOptions = pvcOptions
+	res.APIManagerBackupS3Options = s3Options
 
 	return res, res.Validate()
 }
@@ -71,6 +93,17 @@ func (a *APIManagerBackupOptionsProvider) pvcBackupOptions() (*APIManagerBackupP
 	return res, res.Validate()
 }
 
+func (a *APIManagerBackupOptionsProvider) s3BackupOptions() (*APIManagerBackupS3Options, error) {
+	if a.APIManagerBackupCR.Spec.BackupDestination.S3 == nil {
+		return nil, nil
+	}
+
+	res := NewAPIManagerBackupS3Options()
+	res.Location = *BackupS3Location(a.APIManagerBackupCR)
+
+	return res, res.Validate()
+}
+
 func (a *APIManagerBackupOptionsProvider) apiManager() (*appsv1alpha1.APIManager, error) {
 	return a.autodiscoveredAPIManager()
 }
@@ -95,6 +128,44 @@ func (a *APIManagerBackupOptionsProvider) autodiscoveredAPIManager() (*appsv1alp
 
 }
 
+func (a *APIManagerBackupOptionsProvider) setInternalDatabasesImageURLs(res *APIManagerBackupOptions, apiManager *appsv1alpha1.APIManager) error {
+	images, err := operator.DeploymentImages(apiManager)
+	if err != nil {
+		return err
+	}
+	imageURL := func(imageStreamName string) string {
+		return images[fmt.Sprintf("%s:%s", imageStreamName, product.ThreescaleRelease)]
+	}
+
+	if apiManager.IsSystemMysqlEnabled() {
+		res.SystemMySQLImageURL = imageURL("system-mysql")
+	}
+	if apiManager.IsSystemPostgreSQLEnabled() {
+		res.SystemPostgreSQLImageURL = imageURL("system-postgresql")
+	}
+	if !apiManager.IsExternal(appsv1alpha1.ZyncDatabase) {
+		res.ZyncDatabaseImageURL = imageURL("zync-database-postgresql")
+	}
+	if !apiManager.IsExternal(appsv1alpha1.BackendRedis) {
+		res.BackendRedisImageURL = imageURL("backend-redis")
+		if apiManager.IsBackendRedisSentinelEnabled() {
+			res.BackendRedisSentinelService = component.BackendRedisSentinelServiceName
+		}
+	}
+	if !apiManager.IsExternal(appsv1alpha1.SystemRedis) {
+		res.SystemRedisImageURL = imageURL("system-redis")
+		if apiManager.IsSystemRedisSentinelEnabled() {
+			res.SystemRedisSentinelService = component.SystemRedisSentinelServiceName
+		}
+	}
+
+	return nil
+}
+
 func (a *APIManagerBackupOptionsProvider) ocCLIImageURL() string {
 	return helper.GetEnvVar("OSE_CLI_IMAGE", component.OCCLIImageURL())
 }
+
+func (a *APIManagerBackupOptionsProvider) awsCLIImageURL() string {
+	return helper.GetEnvVar("RELATED_IMAGE_AWS_CLI", component.AWSCLIImageURL())
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// BackupS3Location returns the location of the data of the backup when the
+// destination is S3. Each backup is stored under its own prefix, so a bucket
+// and prefix can be shared by multiple backups
+func BackupS3Location(cr *appsv1alpha1.APIManagerBackup) *S3Location {
+	if cr.Spec.BackupDestination.S3 == nil {
+		return nil
+	}
+
+	location := NewS3Location(cr.Spec.BackupDestination.S3)
+	location.Prefix = path.Join(location.Prefix, cr.Name)
+	return &location
+}
+
+// RemoveS3BackupDataJob returns the job removing the data of the backup from
+// its S3 destination. Nil is returned when the destination is not S3
+func RemoveS3BackupDataJob(cr *appsv1alpha1.APIManagerBackup) (*batchv1.Job, error) {
+	location := BackupS3Location(cr)
+	if location == nil {
+		return nil, nil
+	}
+
+	jobName, err := helper.UIDBasedJobName("remove-backup-data", cr.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	var completions int32 = 1
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: cr.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Volumes: S3ClientPodVolumes(location),
+					Containers: []v1.Container{
+						S3ClientContainer("remove-backup-data", helper.GetEnvVar("RELATED_IMAGE_AWS_CLI", component.AWSCLIImageURL()), location, `
+aws "${S3_ARGS[@]}" s3 rm --recursive --only-show-errors "${S3_URL}/";
+`),
+					},
+					RestartPolicy: v1.RestartPolicyNever, // Only "Never" or "OnFailure" are accepted in Kubernetes Jobs
+				},
+			},
+		},
+	}, nil
+}
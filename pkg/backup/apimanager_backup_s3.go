@@ -0,0 +1,242 @@
+package backup
+
+import (
+	"fmt"
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+const (
+	backupStagingVolumeName = "backup-staging"
+
+	s3CACertificateSecretKey  = "ca.crt"
+	s3CACertificateVolumeName = "s3-ca-certificate"
+	s3CACertificateMountPath  = "/s3-ca-certificate"
+	s3DefaultRegion           = "us-east-1"
+)
+
+// DefaultStagingSizeLimit is the size limit of the staging volume when the
+// APIManagerBackup or the APIManagerRestore does not set one
+var DefaultStagingSizeLimit = resource.MustParse("20Gi")
+
+// StagingSizeLimit returns the size limit of the staging volume, the default
+// one when not set
+func StagingSizeLimit(sizeLimit *resource.Quantity) resource.Quantity {
+	if sizeLimit == nil {
+		return DefaultStagingSizeLimit.DeepCopy()
+	}
+	return sizeLimit.DeepCopy()
+}
+
+// StagingPodVolume returns the emptyDir volume the backup data is staged in,
+// before it is encrypted or uploaded, or once downloaded or decrypted. Pods
+// writing more than the size limit in it are evicted
+func StagingPodVolume(name string, sizeLimit resource.Quantity) v1.Volume {
+	return v1.Volume{
+		Name: name,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+		},
+	}
+}
+
+// ValidateStagingSizeLimit checks that the staging volume fits the source PVC,
+// whose data is staged as a whole before it is encrypted or uploaded. The size
+// of the PVC is its capacity or, while it is not bound, its storage request
+func (b *APIManagerBackup) ValidateStagingSizeLimit(sourcePVC *v1.PersistentVolumeClaim) error {
+	if b.options.APIManagerBackupS3Options == nil && b.options.EncryptionKeySecretName == nil {
+		return nil
+	}
+
+	size, ok := sourcePVC.Status.Capacity[v1.ResourceStorage]
+	if !ok {
+		size = sourcePVC.Spec.Resources.Requests[v1.ResourceStorage]
+	}
+	if size.Cmp(b.options.StagingSizeLimit) > 0 {
+		return fmt.Errorf("the staging size limit %s is smaller than the %s size of the PersistentVolumeClaim %s",
+			b.options.StagingSizeLimit.String(), size.String(), sourcePVC.Name)
+	}
+
+	return nil
+}
+
+// BackupS3URL returns the location of the backup data when the destination is S3
+func (b *APIManagerBackup) BackupS3URL() *string {
+	if b.options.APIManagerBackupS3Options == nil {
+		return nil
+	}
+
+	url := b.options.APIManagerBackupS3Options.Location.URL()
+	return &url
+}
+
+// withBackupDestination completes the job to write the backup data in its
+// destination. When the destination is S3, the containers of the job become
+// init containers writing the data in the staging volume, followed by one
+// listing the files for the manifest, and the data is uploaded by the
+// container of the job once all of them have completed
+func (b *APIManagerBackup) withBackupDestination(job *batchv1.Job) *batchv1.Job {
+	if b.options.EncryptionKeySecretName != nil {
+		job = b.withEncryption(job)
+	}
+
+	if b.options.APIManagerBackupS3Options == nil {
+		return job
+	}
+
+	location := &b.options.APIManagerBackupS3Options.Location
+	podSpec := &job.Spec.Template.Spec
+	podSpec.InitContainers = append(podSpec.InitContainers, podSpec.Containers...)
+	podSpec.InitContainers = append(podSpec.InitContainers, b.listArtifactsContainer(job.Name))
+	podSpec.Containers = []v1.Container{
+		S3ClientContainer("upload-to-s3", b.options.AWSCLIImageURL, location, b.s3UploadContainerArgs(),
+			b.backupDestinationContainerVolumeMount(),
+		),
+	}
+	podSpec.Volumes = append(podSpec.Volumes, S3ClientPodVolumes(location)...)
+
+	return job
+}
+
+// listArtifactsContainer lists the files written by the job in a listing of
+// its own, uploaded along with them and read by the manifest job
+func (b *APIManagerBackup) listArtifactsContainer(jobName string) v1.Container {
+	return v1.Container{
+		Name:  "list-backup-artifacts",
+		Image: b.options.OCCLIImageURL,
+		Command: []string{
+			"/bin/bash",
+		},
+		Args: []string{
+			"-c",
+			"-e",
+			b.listArtifactsContainerArgs(jobName),
+		},
+		VolumeMounts: []v1.VolumeMount{
+			b.backupDestinationContainerVolumeMount(),
+		},
+	}
+}
+
+func (b *APIManagerBackup) listArtifactsContainerArgs(jobName string) string {
+	return fmt.Sprintf(`
+BASEPATH='%s';
+LISTING='%s';
+PYTHON_LIST_ARTIFACTS_SUBSCRIPT="%s"
+mkdir -p "$(dirname "${BASEPATH}/${LISTING}")";
+python -c "${PYTHON_LIST_ARTIFACTS_SUBSCRIPT}" "${BASEPATH}" > "${BASEPATH}/${LISTING}";
+`,
+		BackupPVCMountPath,
+		path.Join(backupArtifactListingsDir, jobName+".json"),
+		b.pythonListArtifactsScript(),
+	)
+}
+
+func (b *APIManagerBackup) pythonListArtifactsScript() string {
+	return fmt.Sprintf(`
+import json, sys
+%s
+print(json.dumps(list_artifacts(sys.argv[1])))
+`,
+		pythonListArtifactsFunction(),
+	)
+}
+
+func (b *APIManagerBackup) s3UploadContainerArgs() string {
+	// The files keep the layout of a PVC destination under the backup prefix
+	return fmt.Sprintf(`
+BASEPATH='%s';
+aws "${S3_ARGS[@]}" s3 cp --recursive --only-show-errors "${BASEPATH}" "${S3_URL}";
+`,
+		BackupPVCMountPath,
+	)
+}
+
+// S3ClientContainer returns a container running the script with the AWS CLI
+// configured to access the S3 location. The script finds the location in
+// the S3_URL variable and the global arguments of the AWS CLI in the S3_ARGS
+// array, to be expanded quoted as "${S3_ARGS[@]}"
+func S3ClientContainer(name, image string, location *S3Location, script string, volumeMounts ...v1.VolumeMount) v1.Container {
+	region := s3DefaultRegion
+	if location.Region != nil {
+		region = *location.Region
+	}
+
+	env := []v1.EnvVar{
+		helper.EnvVarFromValue("S3_URL", location.URL()),
+		helper.EnvVarFromSecret("AWS_ACCESS_KEY_ID", location.CredentialsSecretName, "AWS_ACCESS_KEY_ID"),
+		helper.EnvVarFromSecret("AWS_SECRET_ACCESS_KEY", location.CredentialsSecretName, "AWS_SECRET_ACCESS_KEY"),
+		helper.EnvVarFromValue("AWS_DEFAULT_REGION", region),
+		// The home directory of the image is not writable by arbitrary users
+		helper.EnvVarFromValue("AWS_CONFIG_FILE", "/tmp/.aws/config"),
+	}
+
+	if location.Endpoint != nil {
+		env = append(env, helper.EnvVarFromValue("S3_ENDPOINT", *location.Endpoint))
+	}
+
+	if location.CACertificateSecretName != nil {
+		env = append(env, helper.EnvVarFromValue("AWS_CA_BUNDLE", path.Join(s3CACertificateMountPath, s3CACertificateSecretKey)))
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      s3CACertificateVolumeName,
+			MountPath: s3CACertificateMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	if location.ForcePathStyle {
+		script = "\naws configure set default.s3.addressing_style path;" + script
+	}
+	script = s3ArgsScript + script
+
+	return v1.Container{
+		Name:  name,
+		Image: image,
+		Command: []string{
+			"/bin/bash",
+		},
+		Args: []string{
+			"-c",
+			"-e",
+			script,
+		},
+		Env:          env,
+		VolumeMounts: volumeMounts,
+	}
+}
+
+// s3ArgsScript sets the S3_ARGS array of the scripts run by S3ClientContainer.
+// The endpoint is passed as a single argument, whatever its value
+const s3ArgsScript = `
+S3_ARGS=();
+if [ -n "${S3_ENDPOINT}" ]; then S3_ARGS=(--endpoint-url "${S3_ENDPOINT}"); fi;`
+
+// S3ClientPodVolumes returns the volumes required by the containers
+// returned by S3ClientContainer
+func S3ClientPodVolumes(location *S3Location) []v1.Volume {
+	if location.CACertificateSecretName == nil {
+		return nil
+	}
+
+	return []v1.Volume{
+		v1.Volume{
+			Name: s3CACertificateVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: *location.CACertificateSecretName,
+					Items: []v1.KeyToPath{
+						v1.KeyToPath{
+							Key:  s3CACertificateSecretKey,
+							Path: s3CACertificateSecretKey,
+						},
+					},
+				},
+			},
+		},
+	}
+}
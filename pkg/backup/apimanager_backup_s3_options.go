@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	validator "github.com/go-playground/validator/v10"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+)
+
+type APIManagerBackupS3Options struct {
+	Location S3Location
+}
+
+// S3Location is the location of the backup data in an S3-compatible object
+// storage. Prefix is the key prefix of the backup data
+type S3Location struct {
+	Endpoint                *string
+	Region                  *string
+	Bucket                  string `validate:"required"`
+	Prefix                  string
+	ForcePathStyle          bool
+	CredentialsSecretName   string `validate:"required"`
+	CACertificateSecretName *string
+}
+
+func NewAPIManagerBackupS3Options() *APIManagerBackupS3Options {
+	return &APIManagerBackupS3Options{}
+}
+
+func (a *APIManagerBackupS3Options) Validate() error {
+	validate := validator.New()
+	return validate.Struct(a)
+}
+
+func NewS3Location(location *appsv1alpha1.S3Location) S3Location {
+	res := S3Location{
+		Endpoint:              location.Endpoint,
+		Region:                location.Region,
+		Bucket:                location.Bucket,
+		ForcePathStyle:        location.ForcePathStyle != nil && *location.ForcePathStyle,
+		CredentialsSecretName: location.CredentialsSecretRef.Name,
+	}
+	if location.Prefix != nil {
+		res.Prefix = strings.Trim(*location.Prefix, "/")
+	}
+	if location.CACertificateSecretRef != nil {
+		res.CACertificateSecretName = &location.CACertificateSecretRef.Name
+	}
+	return res
+}
+
+// URL returns the location in the s3://<bucket>/<key prefix> form
+func (l *S3Location) URL() string {
+	if l.Prefix == "" {
+		return fmt.Sprintf("s3://%s", l.Bucket)
+	}
+	return fmt.Sprintf("s3://%s/%s", l.Bucket, l.Prefix)
+}
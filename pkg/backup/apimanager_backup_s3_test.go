@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func s3TestBackup(sizeLimit *resource.Quantity) *APIManagerBackup {
+	return databasesTestBackup(&APIManagerBackupOptions{
+		APIManagerBackupS3Options: &APIManagerBackupS3Options{
+			Location: S3Location{Bucket: "backups", Prefix: "example-backup", CredentialsSecretName: "s3-credentials"},
+		},
+		StagingSizeLimit: StagingSizeLimit(sizeLimit),
+	})
+}
+
+func TestBackupStagingVolumeSizeLimit(t *testing.T) {
+	customLimit := resource.MustParse("5Gi")
+	cases := []struct {
+		name      string
+		sizeLimit *resource.Quantity
+		expected  string
+	}{
+		{"default", nil, "20Gi"},
+		{"custom", &customLimit, "5Gi"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			job := s3TestBackup(tc.sizeLimit).BackupSecretsAndConfigMapsJob()
+			volumes := job.Spec.Template.Spec.Volumes
+			if len(volumes) != 1 || volumes[0].EmptyDir == nil {
+				subT.Fatalf("expected an emptyDir staging volume, got %v", volumes)
+			}
+			if sizeLimit := volumes[0].EmptyDir.SizeLimit; sizeLimit == nil || sizeLimit.String() != tc.expected {
+				subT.Fatalf("expected a size limit of %s, got %v", tc.expected, sizeLimit)
+			}
+		})
+	}
+}
+
+func TestBackupValidateStagingSizeLimit(t *testing.T) {
+	pvc := func(request, capacity string) *v1.PersistentVolumeClaim {
+		res := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "system-storage"}}
+		res.Spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: resource.MustParse(request)}
+		if capacity != "" {
+			res.Status.Capacity = v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)}
+		}
+		return res
+	}
+
+	cases := []struct {
+		name      string
+		backup    *APIManagerBackup
+		pvc       *v1.PersistentVolumeClaim
+		expectErr bool
+	}{
+		{"fits", s3TestBackup(nil), pvc("20Gi", ""), false},
+		{"request exceeds", s3TestBackup(nil), pvc("30Gi", ""), true},
+		{"capacity exceeds", s3TestBackup(nil), pvc("10Gi", "30Gi"), true},
+		{"capacity fits", s3TestBackup(nil), pvc("30Gi", "10Gi"), false},
+		{"no staging", databasesTestBackup(&APIManagerBackupOptions{}), pvc("30Gi", ""), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			err := tc.backup.ValidateStagingSizeLimit(tc.pvc)
+			if (err != nil) != tc.expectErr {
+				subT.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestBackupS3ClientContainerEndpointArgs(t *testing.T) {
+	requireScriptTools(t, "bash")
+
+	endpoint := "https://s3.example.com/path with spaces"
+	location := &S3Location{Bucket: "backups", Endpoint: &endpoint, CredentialsSecretName: "s3-credentials"}
+	container := S3ClientContainer("s3-client", "aws-cli", location, `
+aws() { printf '%s\n' "$@"; };
+aws "${S3_ARGS[@]}" s3 ls "${S3_URL}";
+`)
+
+	env := []string{}
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom == nil {
+			env = append(env, envVar.Name+"="+envVar.Value)
+		}
+	}
+	output := runScript(t, container.Args[len(container.Args)-1], env...)
+	if expected := "--endpoint-url\n" + endpoint + "\ns3\nls\ns3://backups\n"; output != expected {
+		t.Fatalf("expected the endpoint as a single argument, got:\n%s", output)
+	}
+}
+
+func TestBackupS3JobsListArtifacts(t *testing.T) {
+	job := s3TestBackup(nil).BackupSecretsAndConfigMapsJob()
+
+	initContainers := job.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 {
+		t.Fatalf("expected the backup and listing init containers, got %d", len(initContainers))
+	}
+	listing := initContainers[1]
+	if listing.Name != "list-backup-artifacts" {
+		t.Fatalf("expected the files to be listed once written, got %s", listing.Name)
+	}
+	script := listing.Args[len(listing.Args)-1]
+	if expected := "LISTING='.artifacts/" + job.Name + ".json';"; !strings.Contains(script, expected) {
+		t.Errorf("expected script to contain %q:\n%s", expected, script)
+	}
+	if containers := job.Spec.Template.Spec.Containers; len(containers) != 1 || containers[0].Name != "upload-to-s3" {
+		t.Fatalf("expected the listing to be uploaded with the backup data, got %v", containers)
+	}
+}
+
+func TestBackupManifestJobS3DownloadsListings(t *testing.T) {
+	job := s3TestBackup(nil).BackupManifestJob()
+
+	initContainers := job.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 {
+		t.Fatalf("expected the download and manifest init containers, got %d", len(initContainers))
+	}
+	download := initContainers[0].Args[len(initContainers[0].Args)-1]
+	if expected := `"${S3_URL}/${LISTINGS}/" "${BASEPATH}/${LISTINGS}"`; !strings.Contains(download, expected) {
+		t.Errorf("expected only the listings to be downloaded, script:\n%s", download)
+	}
+	manifest := initContainers[1].Args[len(initContainers[1].Args)-1]
+	if expected := "LISTINGS='.artifacts';"; !strings.Contains(manifest, expected) {
+		t.Errorf("expected script to contain %q:\n%s", expected, manifest)
+	}
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.EmptyDir != nil && volume.EmptyDir.SizeLimit == nil {
+			t.Errorf("expected the size of volume %s to be limited", volume.Name)
+		}
+	}
+}
+
+func TestBackupManifestJobPVCListsFiles(t *testing.T) {
+	job := databasesTestBackup(&APIManagerBackupOptions{}).BackupManifestJob()
+
+	podSpec := job.Spec.Template.Spec
+	if len(podSpec.InitContainers) != 0 {
+		t.Fatalf("unexpected init containers %v", podSpec.InitContainers)
+	}
+	if podSpec.Volumes[0].VolumeSource.PersistentVolumeClaim == nil {
+		t.Fatalf("expected the files to be listed from the destination PVC, got %v", podSpec.Volumes[0])
+	}
+	script := podSpec.Containers[0].Args[len(podSpec.Containers[0].Args)-1]
+	if expected := "LISTINGS='';"; !strings.Contains(script, expected) {
+		t.Errorf("expected script to contain %q:\n%s", expected, script)
+	}
+}
@@ -0,0 +1,292 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const scriptsTestEncryptionKey = "ENCRYPTION_KEY=backup-test-key"
+
+// requireScriptTools skips the test when the tools run by the scripts are not installed
+func requireScriptTools(t *testing.T, tools ...string) {
+	t.Helper()
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+}
+
+// runScript runs the script with bash, as the job containers do. The temporary
+// files written by the scripts in /tmp are written in a directory of the test
+func runScript(t *testing.T, script string, env ...string) string {
+	t.Helper()
+	script = strings.ReplaceAll(script, " /tmp/", " "+t.TempDir()+"/")
+	cmd := exec.Command("bash", "-e", "-c", script)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("script failed: %v\n%s\nscript:\n%s", err, stderr.String(), script)
+	}
+	return stdout.String()
+}
+
+// withScriptVariable replaces the value the script assigns to the variable
+func withScriptVariable(script, name, value string) string {
+	re := regexp.MustCompile(`(?m)^(\s*` + name + `=)'[^']*';`)
+	return re.ReplaceAllLiteralString(script, name+"='"+value+"';")
+}
+
+// scriptPythonSubscript returns the python script assigned to the variable
+// of the shell script, as evaluated by bash
+func scriptPythonSubscript(t *testing.T, script, variable string) string {
+	t.Helper()
+	start := strings.Index(script, variable+`="`)
+	end := regexp.MustCompile(`\n\s*python -c`).FindStringIndex(script[start:])
+	if start < 0 || end == nil {
+		t.Fatalf("%s not found in script:\n%s", variable, script)
+	}
+	return runScript(t, script[start:start+end[0]]+"\nprintf '%s' \"${"+variable+"}\";")
+}
+
+func writeScriptsTestFiles(t *testing.T, basepath string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filePath := filepath.Join(basepath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func scriptsTestFiles() map[string]string {
+	return map[string]string{
+		"secrets/system-app.json":         `{"kind": "Secret"}`,
+		"configmaps/it's \"quoted\".json": "$HOME `id` \\",
+		"system-mysql-backup.sql":         "CREATE TABLE accounts;",
+		"system-storage/empty":            "",
+	}
+}
+
+func TestBackupScriptsGeneration(t *testing.T) {
+	b := s3TestBackup(nil)
+	encryptionKeySecretName := "backup-encryption-key"
+	b.options.EncryptionKeySecretName = &encryptionKeySecretName
+
+	shellScripts := map[string]string{
+		"listArtifacts":          b.listArtifactsContainerArgs("example-job"),
+		"manifest":               b.backupManifestContainerArgs(),
+		"downloadListings":       b.s3DownloadArtifactListingsContainerArgs(),
+		"uploadManifest":         b.s3UploadManifestContainerArgs(),
+		"upload":                 b.s3UploadContainerArgs(),
+		"encryptInPlace":         b.encryptBackupDataContainerArgs(BackupPVCMountPath),
+		"encryptToDestination":   b.encryptBackupDataContainerArgs(backupEncryptionPVCMountPath),
+		"manifestWithoutListing": databasesTestBackup(&APIManagerBackupOptions{}).backupManifestContainerArgs(),
+	}
+	for name, script := range shellScripts {
+		t.Run(name, func(subT *testing.T) {
+			if strings.Contains(script, "%!") {
+				subT.Errorf("formatting error in script:\n%s", script)
+			}
+		})
+	}
+
+	// The python scripts are passed in double quoted shell variables
+	pythonScripts := map[string]string{
+		"listArtifacts":     b.pythonListArtifactsScript(),
+		"manifest":          b.pythonBackupManifestScript(),
+		"manifestMAC":       ManifestMACPythonFunction,
+		"listArtifactsFunc": pythonListArtifactsFunction(),
+	}
+	for name, script := range pythonScripts {
+		t.Run("python/"+name, func(subT *testing.T) {
+			if strings.ContainsAny(script, "\"$`\\") {
+				subT.Errorf("python script not embeddable in double quotes:\n%s", script)
+			}
+			if strings.Contains(script, "%!") {
+				subT.Errorf("formatting error in python script:\n%s", script)
+			}
+		})
+	}
+}
+
+func TestBackupScriptsSyntax(t *testing.T) {
+	requireScriptTools(t, "bash", "python")
+	b := s3TestBackup(nil)
+
+	for name, script := range map[string]string{
+		"listArtifacts":    b.listArtifactsContainerArgs("example-job"),
+		"manifest":         b.backupManifestContainerArgs(),
+		"downloadListings": b.s3DownloadArtifactListingsContainerArgs(),
+		"uploadManifest":   b.s3UploadManifestContainerArgs(),
+		"encrypt":          b.encryptBackupDataContainerArgs(backupEncryptionPVCMountPath),
+	} {
+		t.Run(name, func(subT *testing.T) {
+			runScript(subT, "set -n;\n"+script)
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		shellScript  string
+		variable     string
+		pythonScript string
+	}{
+		"listArtifacts": {b.listArtifactsContainerArgs("example-job"), "PYTHON_LIST_ARTIFACTS_SUBSCRIPT", b.pythonListArtifactsScript()},
+		"manifest":      {b.backupManifestContainerArgs(), "PYTHON_MANIFEST_SUBSCRIPT", b.pythonBackupManifestScript()},
+	} {
+		t.Run("python/"+name, func(subT *testing.T) {
+			pythonScript := scriptPythonSubscript(subT, tc.shellScript, tc.variable)
+			if pythonScript != tc.pythonScript {
+				subT.Fatalf("python script altered by the shell:\n%s", pythonScript)
+			}
+			if out, err := exec.Command("python", "-c", "import ast, sys; ast.parse(sys.argv[1])", pythonScript).CombinedOutput(); err != nil {
+				subT.Fatalf("invalid python script: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestBackupManifestScripts(t *testing.T) {
+	requireScriptTools(t, "bash", "python")
+	basepath := t.TempDir()
+	files := scriptsTestFiles()
+	writeScriptsTestFiles(t, basepath, files)
+	// Symbolic links are restored as links, they are not backup files
+	if err := os.Symlink("system-mysql-backup.sql", filepath.Join(basepath, "link.sql")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedArtifacts := map[string]BackupManifestArtifact{}
+	for name, content := range files {
+		checksum := sha256.Sum256([]byte(content))
+		expectedArtifacts[name] = BackupManifestArtifact{Path: name, Size: int64(len(content)), SHA256: hex.EncodeToString(checksum[:])}
+	}
+
+	s3Backup := s3TestBackup(nil)
+	manifestEnv, err := json.Marshal(s3Backup.backupManifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		backup *APIManagerBackup
+		env    []string
+	}{
+		{"S3Listings", s3Backup, nil},
+		{"PVC", databasesTestBackup(&APIManagerBackupOptions{}), nil},
+		{"Encrypted", s3Backup, []string{scriptsTestEncryptionKey}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if tc.backup == s3Backup {
+				runScript(subT, withScriptVariable(tc.backup.listArtifactsContainerArgs("example-job"), "BASEPATH", basepath))
+			}
+			env := append([]string{"BACKUP_MANIFEST=" + string(manifestEnv)}, tc.env...)
+			runScript(subT, withScriptVariable(tc.backup.backupManifestContainerArgs(), "BASEPATH", basepath), env...)
+
+			data, err := os.ReadFile(filepath.Join(basepath, BackupManifestFileName))
+			if err != nil {
+				subT.Fatal(err)
+			}
+			manifest := &BackupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				subT.Fatal(err)
+			}
+			if manifest.APIManagerBackupName != "example-backup" || manifest.CreationTime == "" {
+				subT.Errorf("unexpected manifest %s", data)
+			}
+			if len(manifest.Artifacts) != len(expectedArtifacts) {
+				subT.Fatalf("expected %d artifacts, got %v", len(expectedArtifacts), manifest.Artifacts)
+			}
+			for _, artifact := range manifest.Artifacts {
+				if artifact != expectedArtifacts[artifact.Path] {
+					subT.Errorf("expected artifact %v, got %v", expectedArtifacts[artifact.Path], artifact)
+				}
+			}
+
+			mac, err := os.ReadFile(filepath.Join(basepath, BackupManifestMACFileName))
+			if tc.env == nil {
+				if err == nil {
+					subT.Errorf("unexpected manifest MAC of a backup not encrypted")
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if !regexp.MustCompile(`^[0-9a-f]{64}$`).Match(mac) {
+				subT.Errorf("unexpected manifest MAC '%s'", mac)
+			}
+			os.Remove(filepath.Join(basepath, BackupManifestMACFileName))
+		})
+	}
+}
+
+func TestBackupEncryptionScript(t *testing.T) {
+	requireScriptTools(t, "bash", "openssl")
+	b := databasesTestBackup(&APIManagerBackupOptions{})
+	files := scriptsTestFiles()
+
+	cases := []struct {
+		name        string
+		inPlace     bool
+		destination string
+	}{
+		{"ToDestination", false, backupEncryptionPVCMountPath},
+		{"InPlace", true, BackupPVCMountPath},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			basepath := subT.TempDir()
+			destination := subT.TempDir()
+			if tc.inPlace {
+				destination = basepath
+			}
+			writeScriptsTestFiles(subT, basepath, files)
+			if err := os.Symlink("system-mysql-backup.sql", filepath.Join(basepath, "link.sql")); err != nil {
+				subT.Fatal(err)
+			}
+
+			script := b.encryptBackupDataContainerArgs(tc.destination)
+			script = withScriptVariable(script, "BASEPATH", basepath)
+			script = withScriptVariable(script, "DESTINATION", destination)
+			runScript(subT, script, scriptsTestEncryptionKey)
+
+			for name, content := range files {
+				filePath := filepath.Join(destination, name)
+				cmd := exec.Command("openssl", append(append([]string{"enc", "-d"}, strings.Fields(EncryptionOpenSSLArgs)...), "-in", filePath)...)
+				cmd.Env = append(os.Environ(), scriptsTestEncryptionKey)
+				out, err := cmd.Output()
+				if err != nil {
+					subT.Fatalf("%s: decryption failed: %v", name, err)
+				}
+				if string(out) != content {
+					subT.Errorf("%s: expected '%s', got '%s'", name, content, out)
+				}
+			}
+
+			if target, err := os.Readlink(filepath.Join(destination, "link.sql")); err != nil || target != "system-mysql-backup.sql" {
+				subT.Errorf("expected the symbolic link to be copied as is, got '%s' %v", target, err)
+			}
+			leftovers, err := filepath.Glob(filepath.Join(destination, "*", "*.encrypting"))
+			if err != nil || len(leftovers) != 0 {
+				subT.Errorf("unexpected files %v %v", leftovers, err)
+			}
+		})
+	}
+}
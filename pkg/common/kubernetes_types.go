@@ -11,6 +11,9 @@ import (
 const (
 	DeleteTagAnnotation                  = "apps.3scale.net/delete"
 	DeletePropagationPolicyTagAnnotation = "apps.3scale.net/delete-propagation-policy"
+	// UnmanagedAnnotation set to "true" on an existing object stops the operator
+	// from updating or deleting it. Missing objects are still created
+	UnmanagedAnnotation = "apps.3scale.net/unmanaged"
 )
 
 type KubernetesObject interface {
@@ -57,6 +60,11 @@ func IsObjectTaggedToDelete(obj KubernetesObject) bool {
 	return ok && annotation == "true"
 }
 
+// IsObjectUnmanaged returns true when the object has been opted out of the reconciliation
+func IsObjectUnmanaged(obj KubernetesObject) bool {
+	return obj.GetAnnotations()[UnmanagedAnnotation] == "true"
+}
+
 func GetDeletePropagationPolicyAnnotation(obj KubernetesObject) *metav1.DeletionPropagation {
 	annotations := obj.GetAnnotations()
 	if annotations == nil {
@@ -0,0 +1,199 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a schedule in the standard five fields cron format:
+// minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// When both day fields are restricted, a day matches when either
+	// of them matches, as in cron
+	dayOfMonthStar bool
+	dayOfWeekStar  bool
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinuteField     = cronField{name: "minute", min: 0, max: 59}
+	cronHourField       = cronField{name: "hour", min: 0, max: 23}
+	cronDayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	cronMonthField      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as Sunday too
+	cronDayOfWeekField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseCronSchedule parses a schedule in the standard five fields cron
+// format. Lists, ranges, steps, month and day of week names and the
+// @yearly, @monthly, @weekly, @daily and @hourly descriptors are supported
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron schedule '%s': expected 5 fields, found %d", spec, len(fields))
+	}
+
+	res := &CronSchedule{
+		dayOfMonthStar: strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[2], "?"),
+		dayOfWeekStar:  strings.HasPrefix(fields[4], "*") || strings.HasPrefix(fields[4], "?"),
+	}
+
+	var err error
+	for _, f := range []struct {
+		bits  *uint64
+		expr  string
+		field cronField
+	}{
+		{&res.minute, fields[0], cronMinuteField},
+		{&res.hour, fields[1], cronHourField},
+		{&res.dayOfMonth, fields[2], cronDayOfMonthField},
+		{&res.month, fields[3], cronMonthField},
+		{&res.dayOfWeek, fields[4], cronDayOfWeekField},
+	} {
+		*f.bits, err = f.field.parse(f.expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron schedule '%s': %w", spec, err)
+		}
+	}
+
+	// Sunday can be set as 7
+	if res.dayOfWeek&(1<<7) != 0 {
+		res.dayOfWeek |= 1
+	}
+
+	return res, nil
+}
+
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			var err error
+			rangeExpr = item[:idx]
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field '%s'", f.name, item)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			start, end = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if start, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = f.value(rangeExpr); err != nil {
+				return 0, err
+			}
+			end = start
+			// "N/step" means from N to the maximum
+			if strings.Contains(item, "/") {
+				end = f.max
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid range in %s field '%s'", f.name, item)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(expr string) (int, error) {
+	if value, ok := f.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid value in %s field '%s': expected %d-%d", f.name, expr, f.min, f.max)
+	}
+	return value, nil
+}
+
+// Next returns the first time matching the schedule strictly after the given
+// time, in the location of the given time. The zero time is returned when
+// no time matches the schedule in the next five years, like on February 30th
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dayOfMonthMatches := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeekMatches := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dayOfMonthMatches && dayOfWeekMatches
+	}
+	return dayOfMonthMatches || dayOfWeekMatches
+}
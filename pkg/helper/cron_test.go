@@ -0,0 +1,106 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		spec string
+	}{
+		{"FieldCount", "0 0 * *"},
+		{"OutOfRange", "60 0 * * *"},
+		{"InvalidStep", "*/0 * * * *"},
+		{"InvalidRange", "0 10-5 * * *"},
+		{"InvalidRangeWithStep", "0 10-5/2 * * *"},
+		{"DayOfWeekOutOfRange", "0 0 * * 8"},
+		{"InvalidName", "0 0 * foo *"},
+		{"UnknownDescriptor", "@every"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if _, err := ParseCronSchedule(tc.spec); err == nil {
+				subT.Errorf("expected error parsing '%s'", tc.spec)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2023, time.March, 15, 10, 30, 20, 0, time.UTC) // Wednesday
+
+	cases := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"EveryMinute", "* * * * *", time.Date(2023, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"Hourly", "@hourly", time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"Daily", "@daily", time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"Weekly", "@weekly", time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC)},
+		{"Monthly", "@monthly", time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"Yearly", "@yearly", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"Step", "*/20 * * * *", time.Date(2023, time.March, 15, 10, 40, 0, 0, time.UTC)},
+		{"StepFromValue", "5/20 * * * *", time.Date(2023, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{"List", "0 2,9,22 * * *", time.Date(2023, time.March, 15, 22, 0, 0, 0, time.UTC)},
+		{"RangeWithStep", "0 1-5/2 * * *", time.Date(2023, time.March, 16, 1, 0, 0, 0, time.UTC)},
+		{"MinuteRangeWithStep", "10-50/15 * * * *", time.Date(2023, time.March, 15, 10, 40, 0, 0, time.UTC)},
+		{"DayOfMonthRangeWithStep", "0 0 1-31/10 * *", time.Date(2023, time.March, 21, 0, 0, 0, 0, time.UTC)},
+		{"DayOfWeekRangeWithStep", "0 0 * * mon-fri/2", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"StepLargerThanRange", "0 0 5-10/30 * *", time.Date(2023, time.April, 5, 0, 0, 0, 0, time.UTC)},
+		{"StepPastRangeEnd", "0 20-23/5 * * *", time.Date(2023, time.March, 15, 20, 0, 0, 0, time.UTC)},
+		{"Names", "0 3 * feb-apr sat", time.Date(2023, time.March, 18, 3, 0, 0, 0, time.UTC)},
+		{"SundayAsSeven", "0 3 * * 7", time.Date(2023, time.March, 19, 3, 0, 0, 0, time.UTC)},
+		{"SundayAsSevenInList", "0 3 * * 7,mon", time.Date(2023, time.March, 19, 3, 0, 0, 0, time.UTC)},
+		{"SundayAsName", "0 3 * * sun", time.Date(2023, time.March, 19, 3, 0, 0, 0, time.UTC)},
+		{"DayOfMonthOrDayOfWeek", "0 0 17 * mon", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"DayOfWeekBeforeDayOfMonth", "0 0 31 * fri", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthBeforeDayOfWeek", "0 0 16 * mon", time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		// A day field starting with * is not restricted, both fields must match
+		{"DayOfMonthStepAndDayOfWeek", "0 0 */2 * mon", time.Date(2023, time.March, 27, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthAndDayOfWeekStep", "0 0 13 * */7", time.Date(2023, time.August, 13, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"NeverMatches", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			schedule, err := ParseCronSchedule(tc.spec)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if next := schedule.Next(from); !next.Equal(tc.expected) {
+				subT.Errorf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNextFiveYearsLimit(t *testing.T) {
+	schedule, err := ParseCronSchedule("0 0 29 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"NextYear", time.Date(2095, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2096, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// 2100 is not a leap year
+		{"FifthYear", time.Date(2099, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2104, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"SeventhYear", time.Date(2097, time.March, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if next := schedule.Next(tc.from); !next.Equal(tc.expected) {
+				subT.Errorf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentPreHookContainerName returns the name of the container
+// running the DeploymentConfig rolling strategy pre hook
+func DeploymentPreHookContainerName(containerName string) string {
+	return fmt.Sprintf("%s-pre-hook", containerName)
+}
+
+const (
+	// DeploymentPreHookLabelKey labels the pre hook Jobs with the name of their Deployment
+	DeploymentPreHookLabelKey = "apps.3scale.net/pre-hook-of"
+	// DeploymentPreHookJobBackoffLimit is the number of retries of the pre hook Job
+	// of DeploymentConfigs with the Retry hook failure policy
+	DeploymentPreHookJobBackoffLimit int32 = 3
+)
+
+// DeploymentFromDeploymentConfig builds the apps/v1 Deployment equivalent to the given DeploymentConfig.
+// Deployments do not support image change triggers, so the images of the containers
+// referenced by the triggers are resolved from the images map, keyed by ImageStreamTag name.
+// Lifecycle hooks are not supported by Deployments and are dropped. The rolling strategy
+// pre hook is run by the Job built by DeploymentPreHookJob.
+func DeploymentFromDeploymentConfig(dc *appsv1.DeploymentConfig, images map[string]string) (*k8sappsv1.Deployment, error) {
+	template, err := deploymentPodTemplate(dc, images)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := dc.Spec.Replicas
+
+	return &k8sappsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: *dc.ObjectMeta.DeepCopy(),
+		Spec: k8sappsv1.DeploymentSpec{
+			Replicas:             &replicas,
+			Selector:             &metav1.LabelSelector{MatchLabels: dc.Spec.Selector},
+			Template:             *template,
+			Strategy:             DeploymentStrategyFromDeploymentConfig(dc.Spec.Strategy),
+			MinReadySeconds:      dc.Spec.MinReadySeconds,
+			RevisionHistoryLimit: dc.Spec.RevisionHistoryLimit,
+		},
+	}, nil
+}
+
+// DeploymentPreHookJob builds the Job running the rolling strategy pre hook of the given
+// DeploymentConfig once, before its Deployment is rolled out. The name of the Job includes
+// the hash of the hook container, so the hook is run again whenever its image, command or
+// environment change, and not on every pod start.
+// It returns nil when the DeploymentConfig has no pre hook.
+func DeploymentPreHookJob(dc *appsv1.DeploymentConfig, images map[string]string) (*batchv1.Job, error) {
+	if dc.Spec.Strategy.RollingParams == nil ||
+		dc.Spec.Strategy.RollingParams.Pre == nil ||
+		dc.Spec.Strategy.RollingParams.Pre.ExecNewPod == nil {
+		return nil, nil
+	}
+
+	template, err := deploymentPodTemplate(dc, images)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := dc.Spec.Strategy.RollingParams.Pre.ExecNewPod
+	container := findContainerByName(template.Spec.Containers, hook.ContainerName)
+	if container == nil {
+		return nil, fmt.Errorf("DeploymentConfig '%s': pre hook container '%s' not found", dc.Name, hook.ContainerName)
+	}
+	hookContainer := preHookContainer(container, hook)
+
+	var volumes []corev1.Volume
+	for _, volume := range template.Spec.Volumes {
+		if ArrayContains(hook.Volumes, volume.Name) {
+			volumes = append(volumes, volume)
+		}
+	}
+
+	hash, err := preHookContainerHash(&hookContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for key, value := range dc.Labels {
+		labels[key] = value
+	}
+	labels[DeploymentPreHookLabelKey] = dc.Name
+
+	// The Retry failure policy is mapped to the Job retries. The Abort and Ignore
+	// failure policies make no sense without DeploymentConfig rollouts
+	var backoffLimit int32 = 0
+	if dc.Spec.Strategy.RollingParams.Pre.FailurePolicy == appsv1.LifecycleHookFailurePolicyRetry {
+		backoffLimit = DeploymentPreHookJobBackoffLimit
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s", DeploymentPreHookContainerName(dc.Name), hash[:10]),
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: dc.Spec.Strategy.RollingParams.TimeoutSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{DeploymentPreHookLabelKey: dc.Name},
+				},
+				Spec: corev1.PodSpec{
+					Volumes:            volumes,
+					Containers:         []corev1.Container{hookContainer},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: template.Spec.ServiceAccountName,
+					ImagePullSecrets:   template.Spec.ImagePullSecrets,
+					SecurityContext:    template.Spec.SecurityContext,
+					NodeSelector:       template.Spec.NodeSelector,
+					Affinity:           template.Spec.Affinity,
+					Tolerations:        template.Spec.Tolerations,
+					PriorityClassName:  template.Spec.PriorityClassName,
+				},
+			},
+		},
+	}, nil
+}
+
+// deploymentPodTemplate returns a copy of the pod template of the DeploymentConfig,
+// with the images of the image change triggers resolved from the images map
+func deploymentPodTemplate(dc *appsv1.DeploymentConfig, images map[string]string) (*corev1.PodTemplateSpec, error) {
+	template := &corev1.PodTemplateSpec{}
+	if dc.Spec.Template != nil {
+		dc.Spec.Template.DeepCopyInto(template)
+	}
+
+	for _, trigger := range dc.Spec.Triggers {
+		if trigger.Type != appsv1.DeploymentTriggerOnImageChange || trigger.ImageChangeParams == nil {
+			continue
+		}
+
+		image, ok := images[trigger.ImageChangeParams.From.Name]
+		if !ok {
+			return nil, fmt.Errorf("DeploymentConfig '%s': image for %s '%s' not found",
+				dc.Name, trigger.ImageChangeParams.From.Kind, trigger.ImageChangeParams.From.Name)
+		}
+
+		for _, containerName := range trigger.ImageChangeParams.ContainerNames {
+			if container := findContainerByName(template.Spec.Containers, containerName); container != nil {
+				container.Image = image
+			}
+			if container := findContainerByName(template.Spec.InitContainers, containerName); container != nil {
+				container.Image = image
+			}
+		}
+	}
+
+	return template, nil
+}
+
+// DeploymentConfigFromDeployment returns a DeploymentConfig view of the given Deployment.
+// The pod template of the view is shared with the Deployment, so changes made to it
+// are applied to the Deployment as well. The images of the containers are exposed as
+// image change triggers, one per distinct image, which allows DeploymentConfig mutators
+// reconciling image change triggers to reconcile Deployment container images.
+func DeploymentConfigFromDeployment(d *k8sappsv1.Deployment) *appsv1.DeploymentConfig {
+	var replicas int32 = 1
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+
+	var selector map[string]string
+	if d.Spec.Selector != nil {
+		selector = d.Spec.Selector.MatchLabels
+	}
+
+	return &appsv1.DeploymentConfig{
+		TypeMeta:   d.TypeMeta,
+		ObjectMeta: d.ObjectMeta,
+		Spec: appsv1.DeploymentConfigSpec{
+			Strategy:             DeploymentConfigStrategyFromDeployment(d.Spec.Strategy),
+			MinReadySeconds:      d.Spec.MinReadySeconds,
+			Triggers:             deploymentImageTriggers(&d.Spec.Template),
+			Replicas:             replicas,
+			RevisionHistoryLimit: d.Spec.RevisionHistoryLimit,
+			Selector:             selector,
+			Template:             &d.Spec.Template,
+		},
+	}
+}
+
+// DeploymentStrategyFromDeploymentConfig maps DeploymentConfig strategy to Deployment strategy
+func DeploymentStrategyFromDeploymentConfig(strategy appsv1.DeploymentStrategy) k8sappsv1.DeploymentStrategy {
+	if strategy.Type == appsv1.DeploymentStrategyTypeRecreate {
+		return k8sappsv1.DeploymentStrategy{Type: k8sappsv1.RecreateDeploymentStrategyType}
+	}
+
+	result := k8sappsv1.DeploymentStrategy{Type: k8sappsv1.RollingUpdateDeploymentStrategyType}
+	if strategy.RollingParams != nil {
+		result.RollingUpdate = &k8sappsv1.RollingUpdateDeployment{
+			MaxUnavailable: strategy.RollingParams.MaxUnavailable,
+			MaxSurge:       strategy.RollingParams.MaxSurge,
+		}
+	}
+
+	return result
+}
+
+// DeploymentConfigStrategyFromDeployment maps Deployment strategy to DeploymentConfig strategy
+func DeploymentConfigStrategyFromDeployment(strategy k8sappsv1.DeploymentStrategy) appsv1.DeploymentStrategy {
+	if strategy.Type == k8sappsv1.RecreateDeploymentStrategyType {
+		return appsv1.DeploymentStrategy{Type: appsv1.DeploymentStrategyTypeRecreate}
+	}
+
+	result := appsv1.DeploymentStrategy{Type: appsv1.DeploymentStrategyTypeRolling}
+	if strategy.RollingUpdate != nil {
+		result.RollingParams = &appsv1.RollingDeploymentStrategyParams{
+			MaxUnavailable: strategy.RollingUpdate.MaxUnavailable,
+			MaxSurge:       strategy.RollingUpdate.MaxSurge,
+		}
+	}
+
+	return result
+}
+
+// IsDeploymentAvailable returns true when the provided Deployment
+// has the "Available" condition set to true
+func IsDeploymentAvailable(d *k8sappsv1.Deployment) bool {
+	for _, condition := range d.Status.Conditions {
+		if condition.Type == k8sappsv1.DeploymentAvailable && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeploymentRolledOut returns true when all the replicas of the provided
+// Deployment run its latest pod template and are available
+func IsDeploymentRolledOut(d *k8sappsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.Replicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
+
+// IsStatefulSetAvailable returns true when all the replicas of the
+// provided StatefulSet are ready
+func IsStatefulSetAvailable(s *k8sappsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	return s.Status.ObservedGeneration >= s.Generation && s.Status.ReadyReplicas >= replicas
+}
+
+// IsDeploymentProgressDeadlineExceeded returns true when the provided Deployment
+// failed to progress within its progress deadline
+func IsDeploymentProgressDeadlineExceeded(d *k8sappsv1.Deployment) bool {
+	for _, condition := range d.Status.Conditions {
+		if condition.Type == k8sappsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+func IsDeploymentDeleting(d *k8sappsv1.Deployment) bool {
+	return d.GetDeletionTimestamp() != nil
+}
+
+func deploymentImageTriggers(template *corev1.PodTemplateSpec) appsv1.DeploymentTriggerPolicies {
+	triggers := appsv1.DeploymentTriggerPolicies{}
+	triggerByImage := map[string]*appsv1.DeploymentTriggerImageChangeParams{}
+
+	containers := append([]corev1.Container{}, template.Spec.Containers...)
+	containers = append(containers, template.Spec.InitContainers...)
+	for _, container := range containers {
+		params, ok := triggerByImage[container.Image]
+		if !ok {
+			triggers = append(triggers, appsv1.DeploymentTriggerPolicy{
+				Type: appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+					Automatic: true,
+					From: corev1.ObjectReference{
+						Kind: "DockerImage",
+						Name: container.Image,
+					},
+				},
+			})
+			params = triggers[len(triggers)-1].ImageChangeParams
+			triggerByImage[container.Image] = params
+		}
+		params.ContainerNames = append(params.ContainerNames, container.Name)
+	}
+
+	return triggers
+}
+
+func preHookContainer(container *corev1.Container, hook *appsv1.ExecNewPodHook) corev1.Container {
+	env := append([]corev1.EnvVar{}, container.Env...)
+	for _, hookEnvVar := range hook.Env {
+		if idx := FindEnvVar(env, hookEnvVar.Name); idx >= 0 {
+			env[idx] = hookEnvVar
+		} else {
+			env = append(env, hookEnvVar)
+		}
+	}
+
+	var volumeMounts []corev1.VolumeMount
+	for _, volumeMount := range container.VolumeMounts {
+		for _, volumeName := range hook.Volumes {
+			if volumeMount.Name == volumeName {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+	}
+
+	return corev1.Container{
+		Name:            DeploymentPreHookContainerName(container.Name),
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         hook.Command,
+		Env:             env,
+		VolumeMounts:    volumeMounts,
+		SecurityContext: container.SecurityContext,
+		Resources:       *container.Resources.DeepCopy(),
+	}
+}
+
+func preHookContainerHash(container *corev1.Container) (string, error) {
+	data, err := json.Marshal(container)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func findContainerByName(containers []corev1.Container, name string) *corev1.Container {
+	for idx := range containers {
+		if containers[idx].Name == name {
+			return &containers[idx]
+		}
+	}
+	return nil
+}
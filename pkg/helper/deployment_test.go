@@ -0,0 +1,242 @@
+package helper
+
+import (
+	"testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func testDeploymentConfig() *appsv1.DeploymentConfig {
+	return &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "myapp",
+			Labels: map[string]string{"app": "myapp"},
+		},
+		Spec: appsv1.DeploymentConfigSpec{
+			Replicas: 2,
+			Selector: map[string]string{"deploymentConfig": "myapp"},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.DeploymentStrategyTypeRolling,
+				RollingParams: &appsv1.RollingDeploymentStrategyParams{
+					MaxSurge:       &intstr.IntOrString{Type: intstr.String, StrVal: "25%"},
+					MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "25%"},
+					Pre: &appsv1.LifecycleHook{
+						FailurePolicy: appsv1.LifecycleHookFailurePolicyRetry,
+						ExecNewPod: &appsv1.ExecNewPodHook{
+							Command:       []string{"bash", "-c", "migrate"},
+							Env:           []corev1.EnvVar{{Name: "A", Value: "hook"}, {Name: "B", Value: "hook"}},
+							ContainerName: "main",
+							Volumes:       []string{"data"},
+						},
+					},
+				},
+			},
+			Triggers: appsv1.DeploymentTriggerPolicies{
+				{Type: appsv1.DeploymentTriggerOnConfigChange},
+				{
+					Type: appsv1.DeploymentTriggerOnImageChange,
+					ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+						Automatic:      true,
+						ContainerNames: []string{"init", "main"},
+						From:           corev1.ObjectReference{Kind: "ImageStreamTag", Name: "myapp:1.0"},
+					},
+				},
+			},
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"deploymentConfig": "myapp"},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						{Name: "init", Image: "myapp:latest"},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "main",
+							Image: "myapp:latest",
+							Env:   []corev1.EnvVar{{Name: "A", Value: "main"}, {Name: "C", Value: "main"}},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: "/data"},
+								{Name: "config", MountPath: "/config"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDeploymentFromDeploymentConfig(t *testing.T) {
+	dc := testDeploymentConfig()
+	images := map[string]string{"myapp:1.0": "registry.example.com/myapp:1.0"}
+
+	deployment, err := DeploymentFromDeploymentConfig(dc, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deployment.Name != "myapp" || deployment.Labels["app"] != "myapp" {
+		t.Fatalf("unexpected metadata: %v", deployment.ObjectMeta)
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 2 {
+		t.Fatalf("unexpected replicas: %v", deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Selector.MatchLabels["deploymentConfig"] != "myapp" {
+		t.Fatalf("unexpected selector: %v", deployment.Spec.Selector)
+	}
+	if deployment.Spec.Strategy.Type != k8sappsv1.RollingUpdateDeploymentStrategyType ||
+		deployment.Spec.Strategy.RollingUpdate.MaxSurge.StrVal != "25%" {
+		t.Fatalf("unexpected strategy: %v", deployment.Spec.Strategy)
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	if podSpec.Containers[0].Image != "registry.example.com/myapp:1.0" {
+		t.Fatalf("container image not resolved: %s", podSpec.Containers[0].Image)
+	}
+	// The pre hook is run by a Job, not on every pod start
+	if len(podSpec.InitContainers) != 1 {
+		t.Fatalf("expected only the init container, got %d", len(podSpec.InitContainers))
+	}
+	if podSpec.InitContainers[0].Image != "registry.example.com/myapp:1.0" {
+		t.Fatalf("init container image not resolved: %s", podSpec.InitContainers[0].Image)
+	}
+
+	// source DeploymentConfig must not be modified
+	if dc.Spec.Template.Spec.Containers[0].Image != "myapp:latest" {
+		t.Fatalf("source DeploymentConfig was modified")
+	}
+}
+
+func TestDeploymentPreHookJob(t *testing.T) {
+	dc := testDeploymentConfig()
+	dc.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "data"}, {Name: "config"}}
+	dc.Spec.Template.Spec.ServiceAccountName = "myapp"
+	images := map[string]string{"myapp:1.0": "registry.example.com/myapp:1.0"}
+
+	job, err := DeploymentPreHookJob(dc, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Labels[DeploymentPreHookLabelKey] != "myapp" || job.Labels["app"] != "myapp" {
+		t.Fatalf("unexpected labels: %v", job.Labels)
+	}
+	// The pods of the Job must not be selected by the services of the component
+	if _, ok := job.Spec.Template.Labels["deploymentConfig"]; ok {
+		t.Fatalf("unexpected pod labels: %v", job.Spec.Template.Labels)
+	}
+	if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != DeploymentPreHookJobBackoffLimit {
+		t.Fatalf("unexpected backoff limit: %v", job.Spec.BackoffLimit)
+	}
+
+	podSpec := job.Spec.Template.Spec
+	if podSpec.RestartPolicy != corev1.RestartPolicyNever || podSpec.ServiceAccountName != "myapp" {
+		t.Fatalf("unexpected pod spec: %v", podSpec)
+	}
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Name != "data" {
+		t.Fatalf("unexpected pre hook volumes: %v", podSpec.Volumes)
+	}
+	if len(podSpec.Containers) != 1 {
+		t.Fatalf("expected the pre hook container, got %d containers", len(podSpec.Containers))
+	}
+
+	preHook := podSpec.Containers[0]
+	if preHook.Name != DeploymentPreHookContainerName("main") {
+		t.Fatalf("unexpected pre hook container name: %s", preHook.Name)
+	}
+	if preHook.Image != "registry.example.com/myapp:1.0" {
+		t.Fatalf("unexpected pre hook image: %s", preHook.Image)
+	}
+	expectedEnv := map[string]string{"A": "hook", "B": "hook", "C": "main"}
+	if len(preHook.Env) != len(expectedEnv) {
+		t.Fatalf("unexpected pre hook env: %v", preHook.Env)
+	}
+	for _, envVar := range preHook.Env {
+		if expectedEnv[envVar.Name] != envVar.Value {
+			t.Fatalf("unexpected pre hook env var %s=%s", envVar.Name, envVar.Value)
+		}
+	}
+	if len(preHook.VolumeMounts) != 1 || preHook.VolumeMounts[0].Name != "data" {
+		t.Fatalf("unexpected pre hook volume mounts: %v", preHook.VolumeMounts)
+	}
+
+	// The hook runs again only when the hook container changes
+	sameJob, err := DeploymentPreHookJob(dc, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameJob.Name != job.Name {
+		t.Fatalf("expected the same job name, got %s and %s", job.Name, sameJob.Name)
+	}
+	dc.Spec.Replicas = 5
+	scaledJob, err := DeploymentPreHookJob(dc, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scaledJob.Name != job.Name {
+		t.Fatalf("expected scaling not to run the hook again, got %s and %s", job.Name, scaledJob.Name)
+	}
+	upgradedJob, err := DeploymentPreHookJob(dc, map[string]string{"myapp:1.0": "registry.example.com/myapp:1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgradedJob.Name == job.Name {
+		t.Fatal("expected a new job for the new image")
+	}
+
+	// No job without pre hook
+	dc.Spec.Strategy.RollingParams.Pre = nil
+	job, err = DeploymentPreHookJob(dc, images)
+	if err != nil || job != nil {
+		t.Fatalf("expected no job, got %v, %v", job, err)
+	}
+}
+
+func TestDeploymentFromDeploymentConfigMissingImage(t *testing.T) {
+	_, err := DeploymentFromDeploymentConfig(testDeploymentConfig(), map[string]string{})
+	if err == nil {
+		t.Fatal("expected error when image is not found")
+	}
+}
+
+func TestDeploymentConfigFromDeployment(t *testing.T) {
+	deployment, err := DeploymentFromDeploymentConfig(testDeploymentConfig(), map[string]string{"myapp:1.0": "registry.example.com/myapp:1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+		corev1.Container{Name: "sidecar", Image: "registry.example.com/sidecar:1.0"})
+
+	dc := DeploymentConfigFromDeployment(deployment)
+
+	if dc.Spec.Replicas != 2 {
+		t.Fatalf("unexpected replicas: %d", dc.Spec.Replicas)
+	}
+	if dc.Spec.Template != &deployment.Spec.Template {
+		t.Fatal("pod template must be shared with the deployment")
+	}
+	if dc.Spec.Strategy.Type != appsv1.DeploymentStrategyTypeRolling || dc.Spec.Strategy.RollingParams.MaxUnavailable.StrVal != "25%" {
+		t.Fatalf("unexpected strategy: %v", dc.Spec.Strategy)
+	}
+
+	triggerPos, err := FindDeploymentTriggerOnImageChange(dc.Spec.Triggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := dc.Spec.Triggers[triggerPos].ImageChangeParams
+	if params.From.Name != "registry.example.com/myapp:1.0" {
+		t.Fatalf("unexpected trigger image: %s", params.From.Name)
+	}
+	expectedContainerNames := []string{"main", "init"}
+	if !StringSliceEqualWithoutOrder(params.ContainerNames, expectedContainerNames) {
+		t.Fatalf("unexpected trigger container names: %v", params.ContainerNames)
+	}
+	if len(dc.Spec.Triggers) != 2 {
+		t.Fatalf("expected one trigger per image, got %d", len(dc.Spec.Triggers))
+	}
+}
@@ -19,6 +19,16 @@ func IsDeploymentConfigAvailable(dc *appsv1.DeploymentConfig) bool {
 	return false
 }
 
+// IsDeploymentConfigRolledOut returns true when all the replicas of the provided
+// DeploymentConfig run its latest pod template and are available
+func IsDeploymentConfigRolledOut(dc *appsv1.DeploymentConfig) bool {
+	replicas := dc.Spec.Replicas
+	return dc.Status.ObservedGeneration >= dc.Generation &&
+		dc.Status.UpdatedReplicas == replicas &&
+		dc.Status.Replicas == replicas &&
+		dc.Status.AvailableReplicas == replicas
+}
+
 func FindDeploymentTriggerOnImageChange(triggerPolicies []appsv1.DeploymentTriggerPolicy) (int, error) {
 	result := -1
 	for i := range triggerPolicies {
@@ -157,3 +157,41 @@ func EnvVarReconciler(desired []v1.EnvVar, existing *[]v1.EnvVar, envVar string)
 	}
 	return update
 }
+
+// EnvVarsBlockReconciler reconciles env vars referencing each other with $(VAR) as a block.
+// Kubernetes only expands references to env vars defined before, so when the desired block
+// differs, existing vars of the block are replaced by the desired ones, in the desired order,
+// at the position of the first existing var of the block.
+func EnvVarsBlockReconciler(desired []v1.EnvVar, existing *[]v1.EnvVar, envVars []string) bool {
+	desiredBlock := []v1.EnvVar{}
+	for _, envVar := range desired {
+		if ArrayContains(envVars, envVar.Name) {
+			desiredBlock = append(desiredBlock, envVar)
+		}
+	}
+
+	existingBlock := []v1.EnvVar{}
+	insertIdx := -1
+	result := []v1.EnvVar{}
+	for _, envVar := range *existing {
+		if ArrayContains(envVars, envVar.Name) {
+			if insertIdx < 0 {
+				insertIdx = len(result)
+			}
+			existingBlock = append(existingBlock, envVar)
+			continue
+		}
+		result = append(result, envVar)
+	}
+
+	if reflect.DeepEqual(desiredBlock, existingBlock) {
+		return false
+	}
+
+	if insertIdx < 0 {
+		insertIdx = len(result)
+	}
+	result = append(result[:insertIdx], append(desiredBlock, result[insertIdx:]...)...)
+	*existing = result
+	return true
+}
@@ -87,3 +87,36 @@ func TestEnvVarReconciler(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvVarsBlockReconciler(t *testing.T) {
+	fromSecret := EnvVarFromSecret("URL", "secret", "URL")
+	base := EnvVarFromSecret("BASE_URL", "secret", "URL")
+	rendered := EnvVarFromValue("URL", "$(BASE_URL)?sslmode=require")
+	other := EnvVarFromValue("OTHER", "value")
+
+	cases := []struct {
+		testName       string
+		desired        []corev1.EnvVar
+		existing       []corev1.EnvVar
+		expected       []corev1.EnvVar
+		expectedResult bool
+	}{
+		{"NothingToReconcile", []corev1.EnvVar{other, base, rendered}, []corev1.EnvVar{other, base, rendered}, []corev1.EnvVar{other, base, rendered}, false},
+		{"BlockAdded", []corev1.EnvVar{other, base, rendered}, []corev1.EnvVar{fromSecret, other}, []corev1.EnvVar{base, rendered, other}, true},
+		{"BlockRemoved", []corev1.EnvVar{other, fromSecret}, []corev1.EnvVar{other, base, rendered}, []corev1.EnvVar{other, fromSecret}, true},
+		{"BlockMissing", []corev1.EnvVar{other, base, rendered}, []corev1.EnvVar{other}, []corev1.EnvVar{other, base, rendered}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(subT *testing.T) {
+			existing := append(tc.existing[:0:0], tc.existing...)
+			update := EnvVarsBlockReconciler(tc.desired, &existing, []string{"BASE_URL", "URL"})
+			if update != tc.expectedResult {
+				subT.Fatalf("result failed, expected: %t, got: %t", tc.expectedResult, update)
+			}
+			if !reflect.DeepEqual(existing, tc.expected) {
+				subT.Fatal(cmp.Diff(existing, tc.expected))
+			}
+		})
+	}
+}
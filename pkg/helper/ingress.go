@@ -0,0 +1,29 @@
+package helper
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// IsIngressReady returns true when the Ingress has been assigned
+// at least one load balancer ingress point
+func IsIngressReady(ingress *networkingv1.Ingress) bool {
+	for _, lbIngress := range ingress.Status.LoadBalancer.Ingress {
+		if lbIngress.IP != "" || lbIngress.Hostname != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IngressFindByHost returns the smallest index i at which an ingress with a rule for a given host is found
+// or -1 if there is no such index.
+func IngressFindByHost(a []networkingv1.Ingress, host string) int {
+	for i, n := range a {
+		for _, rule := range n.Spec.Rules {
+			if rule.Host == host {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,90 @@
+package helper
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PodSpecSecretNames returns the sorted names of the secrets read by the containers of the pod,
+// either from environment variables or mounted as volumes
+func PodSpecSecretNames(spec *v1.PodSpec) []string {
+	names := map[string]bool{}
+
+	for _, container := range podSpecAllContainers(spec) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names[envFrom.SecretRef.Name] = true
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			names[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+// PodSpecConfigMapNames returns the sorted names of the configmaps read by the containers of the pod,
+// either from environment variables or mounted as volumes
+func PodSpecConfigMapNames(spec *v1.PodSpec) []string {
+	names := map[string]bool{}
+
+	for _, container := range podSpecAllContainers(spec) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				names[env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				names[envFrom.ConfigMapRef.Name] = true
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			names[volume.ConfigMap.Name] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					names[source.ConfigMap.Name] = true
+				}
+			}
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+func podSpecAllContainers(spec *v1.PodSpec) []v1.Container {
+	containers := make([]v1.Container, 0, len(spec.InitContainers)+len(spec.Containers))
+	containers = append(containers, spec.InitContainers...)
+	return append(containers, spec.Containers...)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodTemplateOverridesAnnotation records, on the workload object, the pod template entries
+// added from the component podTemplateOverrides. From the APIManager CR, the operator does not
+// know which entries have been removed from the overrides, so the recorded entries are used to
+// remove them from the existing workload. Entries not recorded are never removed.
+const PodTemplateOverridesAnnotation = "apps.3scale.net/pod-template-overrides"
+
+// PodTemplateOverridesRecord lists the entries added from the podTemplateOverrides.
+// Env vars, volumes and containers are identified by name, volume mounts by mount path.
+type PodTemplateOverridesRecord struct {
+	Env            []string `json:"env,omitempty"`
+	Volumes        []string `json:"volumes,omitempty"`
+	VolumeMounts   []string `json:"volumeMounts,omitempty"`
+	Containers     []string `json:"containers,omitempty"`
+	InitContainers []string `json:"initContainers,omitempty"`
+}
+
+func (r *PodTemplateOverridesRecord) IsEmpty() bool {
+	return len(r.Env) == 0 && len(r.Volumes) == 0 && len(r.VolumeMounts) == 0 &&
+		len(r.Containers) == 0 && len(r.InitContainers) == 0
+}
+
+// SetAnnotation stores the record in the given annotations. Empty records are not stored.
+func (r *PodTemplateOverridesRecord) SetAnnotation(annotations *map[string]string) {
+	if r.IsEmpty() {
+		delete(*annotations, PodTemplateOverridesAnnotation)
+		return
+	}
+
+	// marshalling a struct of string slices cannot fail
+	value, _ := json.Marshal(r)
+	if *annotations == nil {
+		*annotations = map[string]string{}
+	}
+	(*annotations)[PodTemplateOverridesAnnotation] = string(value)
+}
+
+// PodTemplateOverridesRecordFromAnnotations reads the record stored in the given annotations.
+// An empty record is returned when the annotation is not present.
+func PodTemplateOverridesRecordFromAnnotations(annotations map[string]string) (*PodTemplateOverridesRecord, error) {
+	record := &PodTemplateOverridesRecord{}
+
+	value, ok := annotations[PodTemplateOverridesAnnotation]
+	if !ok {
+		return record, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), record); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", PodTemplateOverridesAnnotation, err)
+	}
+
+	return record, nil
+}
+
+// ComponentContainersCount returns the number of containers of the pod template,
+// not counting the sidecar containers added from the podTemplateOverrides
+func ComponentContainersCount(annotations map[string]string, containers []corev1.Container) int {
+	record, err := PodTemplateOverridesRecordFromAnnotations(annotations)
+	if err != nil {
+		return len(containers)
+	}
+
+	count := 0
+	for idx := range containers {
+		if !ArrayContains(record.Containers, containers[idx].Name) {
+			count++
+		}
+	}
+	return count
+}
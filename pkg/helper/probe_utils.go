@@ -0,0 +1,42 @@
+package helper
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ProbesEqual compares two probes, taking into account the defaults set by the API server.
+// Otherwise, probes leaving fields unset would never match the existing ones.
+func ProbesEqual(a, b *v1.Probe) bool {
+	return reflect.DeepEqual(probeWithDefaults(a), probeWithDefaults(b))
+}
+
+func probeWithDefaults(probe *v1.Probe) *v1.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	result := probe.DeepCopy()
+	if result.TimeoutSeconds == 0 {
+		result.TimeoutSeconds = 1
+	}
+	if result.PeriodSeconds == 0 {
+		result.PeriodSeconds = 10
+	}
+	if result.SuccessThreshold == 0 {
+		result.SuccessThreshold = 1
+	}
+	if result.FailureThreshold == 0 {
+		result.FailureThreshold = 3
+	}
+	if result.HTTPGet != nil {
+		if result.HTTPGet.Path == "" {
+			result.HTTPGet.Path = "/"
+		}
+		if result.HTTPGet.Scheme == "" {
+			result.HTTPGet.Scheme = v1.URISchemeHTTP
+		}
+	}
+	return result
+}
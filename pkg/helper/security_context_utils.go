@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// PodSecurityContextsEqual compares two pod security contexts. The API server
+// stores an empty pod security context when none is set, so nil and empty match.
+func PodSecurityContextsEqual(a, b *v1.PodSecurityContext) bool {
+	empty := &v1.PodSecurityContext{}
+	if a == nil {
+		a = empty
+	}
+	if b == nil {
+		b = empty
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// SecurityContextsEqual compares two container security contexts, nil and empty
+// security contexts matching.
+func SecurityContextsEqual(a, b *v1.SecurityContext) bool {
+	empty := &v1.SecurityContext{}
+	if a == nil {
+		a = empty
+	}
+	if b == nil {
+		b = empty
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPodSecurityContextsEqual(t *testing.T) {
+	runAsNonRoot := true
+	cases := []struct {
+		name     string
+		a, b     *v1.PodSecurityContext
+		expected bool
+	}{
+		{"BothNil", nil, nil, true},
+		{"NilAndEmpty", nil, &v1.PodSecurityContext{}, true},
+		{"EmptyAndNil", &v1.PodSecurityContext{}, nil, true},
+		{"NilAndSet", nil, &v1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}, false},
+		{"Equal", &v1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}, &v1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if PodSecurityContextsEqual(tc.a, tc.b) != tc.expected {
+				subT.Errorf("expected %t", tc.expected)
+			}
+		})
+	}
+}
+
+func TestSecurityContextsEqual(t *testing.T) {
+	privileged := false
+	cases := []struct {
+		name     string
+		a, b     *v1.SecurityContext
+		expected bool
+	}{
+		{"BothNil", nil, nil, true},
+		{"NilAndEmpty", nil, &v1.SecurityContext{}, true},
+		{"NilAndSet", &v1.SecurityContext{Privileged: &privileged}, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if SecurityContextsEqual(tc.a, tc.b) != tc.expected {
+				subT.Errorf("expected %t", tc.expected)
+			}
+		})
+	}
+}
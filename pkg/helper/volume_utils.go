@@ -1,9 +1,14 @@
 package helper
 
 import (
+	"reflect"
+
 	v1 "k8s.io/api/core/v1"
 )
 
+// Default mode of the files of secret and configmap volumes set by the API server
+const defaultVolumeFileMode int32 = 0644
+
 // FindVolumeByName returns the smallest index i at which x.Name == a[i].Name,
 // or -1 if there is no such index.
 func FindVolumeByName(a []v1.Volume, name string) int {
@@ -22,3 +27,22 @@ func VolumeFromSecretEqual(a v1.Volume, b v1.Volume) bool {
 
 	return a.Name == b.Name && a.Secret.SecretName == b.Secret.SecretName
 }
+
+// VolumesEqual compares two volumes, taking into account the default file mode
+// set by the API server in secret and configmap volumes. Otherwise, volumes
+// leaving it unset would never match the existing ones.
+func VolumesEqual(a, b v1.Volume) bool {
+	return reflect.DeepEqual(volumeWithDefaults(a), volumeWithDefaults(b))
+}
+
+func volumeWithDefaults(volume v1.Volume) *v1.Volume {
+	result := volume.DeepCopy()
+	defaultMode := defaultVolumeFileMode
+	if result.Secret != nil && result.Secret.DefaultMode == nil {
+		result.Secret.DefaultMode = &defaultMode
+	}
+	if result.ConfigMap != nil && result.ConfigMap.DefaultMode == nil {
+		result.ConfigMap.DefaultMode = &defaultMode
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+// Package v1alpha1 contains the subset of the KEDA keda.sh/v1alpha1 API
+// types managed by the operator. The CRDs are owned by the KEDA installation.
+// +kubebuilder:object:generate=true
+// +kubebuilder:skip
+// +groupName=keda.sh
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "keda.sh", Version: "v1alpha1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"strings"
 
+	apispkgcommon "github.com/3scale/3scale-operator/pkg/apispkg/common"
 	"github.com/3scale/3scale-operator/pkg/common"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
 
 	"github.com/go-logr/logr"
 	grafanav1alpha1 "github.com/grafana-operator/grafana-operator/v4/api/integreatly/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
 	consolev1 "github.com/openshift/api/console/v1"
+	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -123,6 +127,11 @@ func (b *BaseReconciler) ReconcileResource(obj, desired common.KubernetesObject,
 	}
 
 	// item found successfully
+	if common.IsObjectUnmanaged(obj) {
+		b.Logger().V(1).Info(fmt.Sprintf("Skipping unmanaged object '%s/%s'", strings.Replace(fmt.Sprintf("%T", obj), "*", "", 1), obj.GetName()))
+		return nil
+	}
+
 	if common.IsObjectTaggedToDelete(desired) {
 		deletePropagationPolicy := common.GetDeletePropagationPolicyAnnotation(desired)
 		if deletePropagationPolicy == nil {
@@ -168,6 +177,23 @@ func (b *BaseReconciler) UpdateResourceStatus(obj common.KubernetesObject) error
 	return b.Client().Status().Update(context.TODO(), obj)
 }
 
+// ReconcilePausedCondition reports the Paused condition in the status of the object.
+// It returns true when the object is paused and must not be reconciled
+func (b *BaseReconciler) ReconcilePausedCondition(obj common.KubernetesObject, conditions *apispkgcommon.Conditions) (bool, error) {
+	if conditions.SetPausedCondition(obj) {
+		err := b.UpdateResourceStatus(obj)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	paused := apispkgcommon.IsPaused(obj)
+	if paused {
+		b.Logger().Info(fmt.Sprintf("Reconciliation of '%s/%s' paused", strings.Replace(fmt.Sprintf("%T", obj), "*", "", 1), obj.GetName()))
+	}
+	return paused, nil
+}
+
 // HasConsoleLink checks if the ConsoleLink is supported in current cluster
 func (b *BaseReconciler) HasConsoleLink() (bool, error) {
 	return resourceExists(b.DiscoveryClient(),
@@ -188,6 +214,20 @@ func (b *BaseReconciler) HasPrometheusRules() (bool, error) {
 		monitoringv1.PrometheusRuleKind)
 }
 
+// HasDeploymentConfigs checks if the DeploymentConfig kind is supported in current cluster
+func (b *BaseReconciler) HasDeploymentConfigs() (bool, error) {
+	return resourceExists(b.DiscoveryClient(),
+		appsv1.GroupVersion.String(),
+		"DeploymentConfig")
+}
+
+// HasRoutes checks if the Route kind is supported in current cluster
+func (b *BaseReconciler) HasRoutes() (bool, error) {
+	return resourceExists(b.DiscoveryClient(),
+		routev1.GroupVersion.String(),
+		"Route")
+}
+
 // HasServiceMonitors checks if the ServiceMonitors CRD is supported in current cluster
 func (b *BaseReconciler) HasServiceMonitors() (bool, error) {
 	return resourceExists(b.DiscoveryClient(),
@@ -202,6 +242,13 @@ func (b *BaseReconciler) HasPodMonitors() (bool, error) {
 		monitoringv1.PodMonitorsKind)
 }
 
+// HasScaledObjects checks if the KEDA ScaledObject CRD is supported in current cluster
+func (b *BaseReconciler) HasScaledObjects() (bool, error) {
+	return resourceExists(b.DiscoveryClient(),
+		kedav1alpha1.GroupVersion.String(),
+		kedav1alpha1.ScaledObjectKind)
+}
+
 // SetOwnerReference sets owner as a Controller OwnerReference on owned
 func (b *BaseReconciler) SetControllerOwnerReference(owner, obj common.KubernetesObject) error {
 	err := controllerutil.SetControllerReference(owner, obj, b.Scheme())
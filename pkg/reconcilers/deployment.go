@@ -0,0 +1,95 @@
+package reconcilers
+
+import (
+	"fmt"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// DeploymentFromDeploymentConfigMutator adapts a DeploymentConfig mutator to reconcile Deployments.
+// The DeploymentConfig mutator runs against DeploymentConfig views of the existing and desired Deployments
+// and the changes are written back to the existing Deployment.
+// Image change triggers of the views represent container images, so image change trigger mutators
+// reconcile the images of the Deployment containers.
+func DeploymentFromDeploymentConfigMutator(dcMutateFn MutateFn) MutateFn {
+	return func(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+		existing, ok := existingObj.(*k8sappsv1.Deployment)
+		if !ok {
+			return false, fmt.Errorf("%T is not a *k8sappsv1.Deployment", existingObj)
+		}
+		desired, ok := desiredObj.(*k8sappsv1.Deployment)
+		if !ok {
+			return false, fmt.Errorf("%T is not a *k8sappsv1.Deployment", desiredObj)
+		}
+
+		// Previous versions ran the pre hook as an init container, on every pod start
+		removedPreHook := removePreHookInitContainers(existing, desired)
+
+		existingDC := helper.DeploymentConfigFromDeployment(existing)
+		desiredDC := helper.DeploymentConfigFromDeployment(desired)
+
+		updated, err := dcMutateFn(existingDC, desiredDC)
+		if err != nil {
+			return false, err
+		}
+		updated = updated || removedPreHook
+
+		if !updated {
+			return false, nil
+		}
+
+		existing.Labels = existingDC.Labels
+		existing.Annotations = existingDC.Annotations
+		replicas := existingDC.Spec.Replicas
+		existing.Spec.Replicas = &replicas
+		existing.Spec.Strategy = helper.DeploymentStrategyFromDeploymentConfig(existingDC.Spec.Strategy)
+		if existingDC.Spec.Template != &existing.Spec.Template {
+			existingDC.Spec.Template.DeepCopyInto(&existing.Spec.Template)
+		}
+
+		for _, trigger := range existingDC.Spec.Triggers {
+			if trigger.ImageChangeParams == nil {
+				continue
+			}
+			for _, containerName := range trigger.ImageChangeParams.ContainerNames {
+				setContainerImage(existing.Spec.Template.Spec.Containers, containerName, trigger.ImageChangeParams.From.Name)
+				setContainerImage(existing.Spec.Template.Spec.InitContainers, containerName, trigger.ImageChangeParams.From.Name)
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// removePreHookInitContainers removes the existing pre hook init containers which are not desired
+func removePreHookInitContainers(existing, desired *k8sappsv1.Deployment) bool {
+	removed := false
+	initContainers := []corev1.Container{}
+	for _, container := range existing.Spec.Template.Spec.InitContainers {
+		isPreHook := false
+		for _, mainContainer := range existing.Spec.Template.Spec.Containers {
+			isPreHook = isPreHook || container.Name == helper.DeploymentPreHookContainerName(mainContainer.Name)
+		}
+		if isPreHook && findContainer(desired.Spec.Template.Spec.InitContainers, container.Name) == nil {
+			removed = true
+			continue
+		}
+		initContainers = append(initContainers, container)
+	}
+	if removed {
+		existing.Spec.Template.Spec.InitContainers = initContainers
+	}
+	return removed
+}
+
+func setContainerImage(containers []corev1.Container, name, image string) {
+	for idx := range containers {
+		if containers[idx].Name == name {
+			containers[idx].Image = image
+		}
+	}
+}
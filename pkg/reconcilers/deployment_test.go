@@ -0,0 +1,147 @@
+package reconcilers
+
+import (
+	"testing"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentFromDeploymentConfigMutator(t *testing.T) {
+	deploymentFactory := func() *k8sappsv1.Deployment {
+		return &k8sappsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Deployment",
+				APIVersion: "apps/v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myDeployment",
+				Namespace: "myNS",
+			},
+			Spec: k8sappsv1.DeploymentSpec{
+				Replicas: &[]int32{3}[0],
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{
+							{Name: "init", Image: "registry.example.com/app:1"},
+						},
+						Containers: []corev1.Container{
+							{Name: "app", Image: "registry.example.com/app:1"},
+							{Name: "sidecar", Image: "registry.example.com/sidecar:1"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	mutator := DeploymentFromDeploymentConfigMutator(DeploymentConfigMutator(
+		DeploymentConfigImageChangeTriggerMutator,
+		DeploymentConfigReplicasMutator,
+	))
+
+	t.Run("NothingToReconcile", func(subT *testing.T) {
+		existing := deploymentFactory()
+		update, err := mutator(existing, deploymentFactory())
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if update {
+			subT.Fatal("expected no update")
+		}
+	})
+
+	t.Run("PreHookInitContainerRemoved", func(subT *testing.T) {
+		existing := deploymentFactory()
+		existing.Spec.Template.Spec.InitContainers = append(existing.Spec.Template.Spec.InitContainers,
+			corev1.Container{Name: "app-pre-hook", Image: "registry.example.com/app:1"})
+		update, err := mutator(existing, deploymentFactory())
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if !update {
+			subT.Fatal("expected update")
+		}
+		initContainers := existing.Spec.Template.Spec.InitContainers
+		if len(initContainers) != 1 || initContainers[0].Name != "init" {
+			subT.Fatalf("expected the pre hook init container to be removed, got %v", initContainers)
+		}
+	})
+
+	t.Run("ReplicasReconcile", func(subT *testing.T) {
+		existing := deploymentFactory()
+		desired := deploymentFactory()
+		desired.Spec.Replicas = &[]int32{5}[0]
+		update, err := mutator(existing, desired)
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if !update {
+			subT.Fatal("expected update")
+		}
+		if *existing.Spec.Replicas != 5 {
+			subT.Fatalf("replica reconciliation failed, existing: %d, desired: 5", *existing.Spec.Replicas)
+		}
+	})
+
+	t.Run("ImageReconcile", func(subT *testing.T) {
+		existing := deploymentFactory()
+		desired := deploymentFactory()
+		desired.Spec.Template.Spec.InitContainers[0].Image = "registry.example.com/app:2"
+		desired.Spec.Template.Spec.Containers[0].Image = "registry.example.com/app:2"
+		update, err := mutator(existing, desired)
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if !update {
+			subT.Fatal("expected update")
+		}
+		if existing.Spec.Template.Spec.Containers[0].Image != "registry.example.com/app:2" {
+			subT.Fatalf("container image reconciliation failed, got: %s", existing.Spec.Template.Spec.Containers[0].Image)
+		}
+		if existing.Spec.Template.Spec.InitContainers[0].Image != "registry.example.com/app:2" {
+			subT.Fatalf("init container image reconciliation failed, got: %s", existing.Spec.Template.Spec.InitContainers[0].Image)
+		}
+		// containers not sharing the main image are left untouched
+		if existing.Spec.Template.Spec.Containers[1].Image != "registry.example.com/sidecar:1" {
+			subT.Fatalf("sidecar image should not be reconciled, got: %s", existing.Spec.Template.Spec.Containers[1].Image)
+		}
+	})
+
+	t.Run("PodTemplateReconcile", func(subT *testing.T) {
+		singleContainerFactory := func() *k8sappsv1.Deployment {
+			d := deploymentFactory()
+			d.Spec.Template.Spec.Containers = d.Spec.Template.Spec.Containers[:1]
+			return d
+		}
+		existing := singleContainerFactory()
+		desired := singleContainerFactory()
+		desired.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("100m"),
+			},
+		}
+		update, err := DeploymentFromDeploymentConfigMutator(DeploymentConfigMutator(
+			DeploymentConfigContainerResourcesMutator,
+		))(existing, desired)
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if !update {
+			subT.Fatal("expected update")
+		}
+		if !existing.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().Equal(resource.MustParse("100m")) {
+			subT.Fatalf("resources reconciliation failed, got: %v", existing.Spec.Template.Spec.Containers[0].Resources)
+		}
+	})
+
+	t.Run("WrongType", func(subT *testing.T) {
+		_, err := mutator(&appsv1.DeploymentConfig{}, deploymentFactory())
+		if err == nil {
+			subT.Fatal("expected error")
+		}
+	})
+}
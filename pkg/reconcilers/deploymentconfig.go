@@ -7,6 +7,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	appsv1 "github.com/openshift/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -46,6 +47,8 @@ func DeploymentConfigMutator(opts ...DCMutateFn) MutateFn {
 // GenericBackendMutators returns the generic mutators for backend
 func GenericBackendMutators() []DCMutateFn {
 	return []DCMutateFn{
+		DeploymentConfigPodTemplateOverridesMutator,
+		DeploymentConfigSecurityContextMutator,
 		DeploymentConfigImageChangeTriggerMutator,
 		DeploymentConfigContainerResourcesMutator,
 		DeploymentConfigAffinityMutator,
@@ -178,11 +181,11 @@ func DeploymentConfigContainerResourcesMutator(desired, existing *appsv1.Deploym
 	desiredName := common.ObjectInfo(desired)
 	update := false
 
-	if len(desired.Spec.Template.Spec.Containers) != 1 {
+	if helper.ComponentContainersCount(desired.Annotations, desired.Spec.Template.Spec.Containers) != 1 {
 		return false, fmt.Errorf("%s desired spec.template.spec.containers length changed to '%d', should be 1", desiredName, len(desired.Spec.Template.Spec.Containers))
 	}
 
-	if len(existing.Spec.Template.Spec.Containers) != 1 {
+	if helper.ComponentContainersCount(existing.Annotations, existing.Spec.Template.Spec.Containers) != 1 {
 		log.Info(fmt.Sprintf("%s spec.template.spec.containers length changed to '%d', recreating dc", desiredName, len(existing.Spec.Template.Spec.Containers)))
 		existing.Spec.Template.Spec.Containers = desired.Spec.Template.Spec.Containers
 		update = true
@@ -271,6 +274,166 @@ func DeploymentConfigEnvVarReconciler(desired, existing *appsv1.DeploymentConfig
 	return updated
 }
 
+// DeploymentConfigEnvVarsMutator returns a mutator reconciling the given env vars
+// in all the containers and hook pods
+func DeploymentConfigEnvVarsMutator(envVars ...string) DCMutateFn {
+	return func(desired, existing *appsv1.DeploymentConfig) (bool, error) {
+		updated := false
+		for _, envVar := range envVars {
+			tmpUpdated := DeploymentConfigEnvVarReconciler(desired, existing, envVar)
+			updated = updated || tmpUpdated
+		}
+		return updated, nil
+	}
+}
+
+// DeploymentConfigEnvVarsBlockMutator returns a mutator reconciling env vars referencing each other
+// as a block, in all the containers and hook pods
+func DeploymentConfigEnvVarsBlockMutator(envVars ...string) DCMutateFn {
+	return func(desired, existing *appsv1.DeploymentConfig) (bool, error) {
+		desiredSpec := &desired.Spec.Template.Spec
+		existingSpec := &existing.Spec.Template.Spec
+
+		if len(desiredSpec.Containers) != len(existingSpec.Containers) ||
+			len(desiredSpec.InitContainers) != len(existingSpec.InitContainers) {
+			log.Info("[WARNING] not reconciling deployment config",
+				"name", client.ObjectKeyFromObject(desired),
+				"reason", "existing and desired do not have same number of containers")
+			return false, nil
+		}
+
+		updated := false
+		for idx := range existingSpec.InitContainers {
+			tmpChanged := helper.EnvVarsBlockReconciler(desiredSpec.InitContainers[idx].Env, &existingSpec.InitContainers[idx].Env, envVars)
+			updated = updated || tmpChanged
+		}
+
+		for idx := range existingSpec.Containers {
+			tmpChanged := helper.EnvVarsBlockReconciler(desiredSpec.Containers[idx].Env, &existingSpec.Containers[idx].Env, envVars)
+			updated = updated || tmpChanged
+		}
+
+		if desired.Spec.Strategy.RollingParams != nil && existing.Spec.Strategy.RollingParams != nil {
+			desiredHooks := []*appsv1.LifecycleHook{desired.Spec.Strategy.RollingParams.Pre, desired.Spec.Strategy.RollingParams.Post}
+			existingHooks := []*appsv1.LifecycleHook{existing.Spec.Strategy.RollingParams.Pre, existing.Spec.Strategy.RollingParams.Post}
+			for idx := range existingHooks {
+				if desiredHooks[idx] == nil || desiredHooks[idx].ExecNewPod == nil ||
+					existingHooks[idx] == nil || existingHooks[idx].ExecNewPod == nil {
+					continue
+				}
+				tmpChanged := helper.EnvVarsBlockReconciler(desiredHooks[idx].ExecNewPod.Env, &existingHooks[idx].ExecNewPod.Env, envVars)
+				updated = updated || tmpChanged
+			}
+		}
+
+		return updated, nil
+	}
+}
+
+// DeploymentConfigVolumesMutator returns a mutator reconciling the given volumes, their mounts
+// in all the containers and their use by the pre hook pod.
+// Volumes and mounts not desired are removed. Other volumes are left untouched.
+func DeploymentConfigVolumesMutator(volumeNames ...string) DCMutateFn {
+	return func(desired, existing *appsv1.DeploymentConfig) (bool, error) {
+		updated := false
+		desiredSpec := &desired.Spec.Template.Spec
+		existingSpec := &existing.Spec.Template.Spec
+
+		for _, volumeName := range volumeNames {
+			tmpUpdated := reconcileNamedVolume(desiredSpec.Volumes, &existingSpec.Volumes, volumeName)
+			updated = updated || tmpUpdated
+
+			for _, containers := range [][]corev1.Container{existingSpec.InitContainers, existingSpec.Containers} {
+				for idx := range containers {
+					desiredContainer := findContainer(desiredSpec.InitContainers, containers[idx].Name)
+					if desiredContainer == nil {
+						desiredContainer = findContainer(desiredSpec.Containers, containers[idx].Name)
+					}
+					if desiredContainer == nil {
+						continue
+					}
+					tmpUpdated = reconcileNamedVolumeMount(desiredContainer.VolumeMounts, &containers[idx].VolumeMounts, volumeName)
+					updated = updated || tmpUpdated
+				}
+			}
+
+			tmpUpdated = reconcilePreHookVolume(desired, existing, volumeName)
+			updated = updated || tmpUpdated
+		}
+
+		if updated {
+			log.Info(fmt.Sprintf("%s spec.template volumes have changed", common.ObjectInfo(desired)))
+		}
+
+		return updated, nil
+	}
+}
+
+func reconcileNamedVolume(desired []corev1.Volume, existing *[]corev1.Volume, name string) bool {
+	desiredIdx := helper.FindVolumeByName(desired, name)
+	existingIdx := helper.FindVolumeByName(*existing, name)
+
+	switch {
+	case desiredIdx < 0 && existingIdx < 0:
+		return false
+	case desiredIdx < 0:
+		*existing = append((*existing)[:existingIdx], (*existing)[existingIdx+1:]...)
+	case existingIdx < 0:
+		*existing = append(*existing, desired[desiredIdx])
+	case !helper.VolumesEqual(desired[desiredIdx], (*existing)[existingIdx]):
+		(*existing)[existingIdx] = desired[desiredIdx]
+	default:
+		return false
+	}
+	return true
+}
+
+func reconcileNamedVolumeMount(desired []corev1.VolumeMount, existing *[]corev1.VolumeMount, name string) bool {
+	desiredIdx := helper.FindVolumeMountByName(desired, name)
+	existingIdx := helper.FindVolumeMountByName(*existing, name)
+
+	switch {
+	case desiredIdx < 0 && existingIdx < 0:
+		return false
+	case desiredIdx < 0:
+		*existing = append((*existing)[:existingIdx], (*existing)[existingIdx+1:]...)
+	case existingIdx < 0:
+		*existing = append(*existing, desired[desiredIdx])
+	case !reflect.DeepEqual(desired[desiredIdx], (*existing)[existingIdx]):
+		(*existing)[existingIdx] = desired[desiredIdx]
+	default:
+		return false
+	}
+	return true
+}
+
+func reconcilePreHookVolume(desired, existing *appsv1.DeploymentConfig, name string) bool {
+	if existing.Spec.Strategy.RollingParams == nil ||
+		existing.Spec.Strategy.RollingParams.Pre == nil ||
+		existing.Spec.Strategy.RollingParams.Pre.ExecNewPod == nil ||
+		desired.Spec.Strategy.RollingParams == nil ||
+		desired.Spec.Strategy.RollingParams.Pre == nil ||
+		desired.Spec.Strategy.RollingParams.Pre.ExecNewPod == nil {
+		return false
+	}
+
+	desiredHook := desired.Spec.Strategy.RollingParams.Pre.ExecNewPod
+	existingHook := existing.Spec.Strategy.RollingParams.Pre.ExecNewPod
+	desiredUsed := helper.ArrayContains(desiredHook.Volumes, name)
+	existingUsed := helper.ArrayContains(existingHook.Volumes, name)
+
+	if desiredUsed == existingUsed {
+		return false
+	}
+
+	if desiredUsed {
+		existingHook.Volumes = append(existingHook.Volumes, name)
+	} else {
+		existingHook.Volumes = helper.ArrayStringDifference(existingHook.Volumes, []string{name})
+	}
+	return true
+}
+
 // DeploymentConfigImageChangeTriggerMutator ensures image change triggers are reconciled
 func DeploymentConfigImageChangeTriggerMutator(desired, existing *appsv1.DeploymentConfig) (bool, error) {
 	desiredDeploymentTriggerImageChangePos, err := helper.FindDeploymentTriggerOnImageChange(desired.Spec.Triggers)
@@ -380,23 +543,257 @@ func DeploymentConfigArgsMutator(desired, existing *appsv1.DeploymentConfig) (bo
 	return updated, nil
 }
 
-// DeploymentConfigProbesMutator ensures probes are reconciled
+// DeploymentConfigProbesMutator ensures liveness, readiness and startup probes are reconciled.
+// Containers are matched by name.
 func DeploymentConfigProbesMutator(desired, existing *appsv1.DeploymentConfig) (bool, error) {
 	updated := false
 
-	for i, desiredContainer := range desired.Spec.Template.Spec.Containers {
-		existingContainer := &existing.Spec.Template.Spec.Containers[i]
+	for idx := range desired.Spec.Template.Spec.Containers {
+		desiredContainer := &desired.Spec.Template.Spec.Containers[idx]
+		existingContainer := findContainer(existing.Spec.Template.Spec.Containers, desiredContainer.Name)
+		if existingContainer == nil {
+			continue
+		}
 
-		if !reflect.DeepEqual(existingContainer.LivenessProbe, desiredContainer.LivenessProbe) {
+		if !helper.ProbesEqual(existingContainer.LivenessProbe, desiredContainer.LivenessProbe) {
+			log.Info(fmt.Sprintf("%s container %s livenessProbe has changed", common.ObjectInfo(desired), desiredContainer.Name))
 			existingContainer.LivenessProbe = desiredContainer.LivenessProbe
 			updated = true
 		}
 
-		if !reflect.DeepEqual(existingContainer.ReadinessProbe, desiredContainer.ReadinessProbe) {
+		if !helper.ProbesEqual(existingContainer.ReadinessProbe, desiredContainer.ReadinessProbe) {
+			log.Info(fmt.Sprintf("%s container %s readinessProbe has changed", common.ObjectInfo(desired), desiredContainer.Name))
 			existingContainer.ReadinessProbe = desiredContainer.ReadinessProbe
 			updated = true
 		}
+
+		if !helper.ProbesEqual(existingContainer.StartupProbe, desiredContainer.StartupProbe) {
+			log.Info(fmt.Sprintf("%s container %s startupProbe has changed", common.ObjectInfo(desired), desiredContainer.Name))
+			existingContainer.StartupProbe = desiredContainer.StartupProbe
+			updated = true
+		}
 	}
 
 	return updated, nil
-}
\ No newline at end of file
+}
+
+// DeploymentConfigSecurityContextMutator ensures the pod security context and the
+// security context of the containers are reconciled. Containers are matched by name.
+func DeploymentConfigSecurityContextMutator(desired, existing *appsv1.DeploymentConfig) (bool, error) {
+	updated := false
+
+	desiredSpec := &desired.Spec.Template.Spec
+	existingSpec := &existing.Spec.Template.Spec
+
+	if !helper.PodSecurityContextsEqual(existingSpec.SecurityContext, desiredSpec.SecurityContext) {
+		diff := cmp.Diff(existingSpec.SecurityContext, desiredSpec.SecurityContext)
+		log.Info(fmt.Sprintf("%s spec.template.spec.securityContext has changed: %s", common.ObjectInfo(desired), diff))
+		existingSpec.SecurityContext = desiredSpec.SecurityContext
+		updated = true
+	}
+
+	for _, containers := range [][]corev1.Container{desiredSpec.Containers, desiredSpec.InitContainers} {
+		for idx := range containers {
+			existingContainer := findContainer(existingSpec.Containers, containers[idx].Name)
+			if existingContainer == nil {
+				existingContainer = findContainer(existingSpec.InitContainers, containers[idx].Name)
+			}
+			if existingContainer == nil {
+				continue
+			}
+
+			if !helper.SecurityContextsEqual(existingContainer.SecurityContext, containers[idx].SecurityContext) {
+				log.Info(fmt.Sprintf("%s container '%s' securityContext has changed", common.ObjectInfo(desired), containers[idx].Name))
+				existingContainer.SecurityContext = containers[idx].SecurityContext
+				updated = true
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+// DeploymentConfigPodTemplateOverridesMutator ensures the pod template entries added from the
+// podTemplateOverrides are reconciled. Entries recorded by a previous reconciliation and no longer
+// desired are removed. Other entries of the existing pod template are kept.
+// It should be run before the mutators expecting existing and desired containers to match.
+func DeploymentConfigPodTemplateOverridesMutator(desired, existing *appsv1.DeploymentConfig) (bool, error) {
+	desiredRecord, err := helper.PodTemplateOverridesRecordFromAnnotations(desired.Annotations)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", common.ObjectInfo(desired), err)
+	}
+	existingRecord, err := helper.PodTemplateOverridesRecordFromAnnotations(existing.Annotations)
+	if err != nil {
+		// the existing record is lost, start over from the desired one
+		log.Info(fmt.Sprintf("%s: %s", common.ObjectInfo(existing), err))
+		existingRecord = &helper.PodTemplateOverridesRecord{}
+	}
+
+	if desiredRecord.IsEmpty() && existingRecord.IsEmpty() {
+		return false, nil
+	}
+
+	updated := false
+	desiredSpec := &desired.Spec.Template.Spec
+	existingSpec := &existing.Spec.Template.Spec
+
+	tmpUpdated := reconcileOverrideContainers(desiredSpec.Containers, &existingSpec.Containers,
+		append(existingRecord.Containers, desiredRecord.Containers...))
+	updated = updated || tmpUpdated
+
+	tmpUpdated = reconcileOverrideContainers(desiredSpec.InitContainers, &existingSpec.InitContainers,
+		append(existingRecord.InitContainers, desiredRecord.InitContainers...))
+	updated = updated || tmpUpdated
+
+	envVarNames := append(existingRecord.Env, desiredRecord.Env...)
+	mountPaths := append(existingRecord.VolumeMounts, desiredRecord.VolumeMounts...)
+	for _, containers := range [][]corev1.Container{existingSpec.Containers, existingSpec.InitContainers} {
+		for idx := range containers {
+			existingContainer := &containers[idx]
+			if helper.ArrayContains(desiredRecord.Containers, existingContainer.Name) ||
+				helper.ArrayContains(desiredRecord.InitContainers, existingContainer.Name) {
+				// overrides containers are already reconciled
+				continue
+			}
+
+			desiredContainer := findContainer(desiredSpec.Containers, existingContainer.Name)
+			if desiredContainer == nil {
+				desiredContainer = findContainer(desiredSpec.InitContainers, existingContainer.Name)
+			}
+			if desiredContainer == nil {
+				continue
+			}
+
+			tmpUpdated = reconcileOverrideEnvVars(desiredContainer.Env, &existingContainer.Env, envVarNames)
+			updated = updated || tmpUpdated
+
+			tmpUpdated = reconcileOverrideVolumeMounts(desiredContainer.VolumeMounts, &existingContainer.VolumeMounts, mountPaths)
+			updated = updated || tmpUpdated
+		}
+	}
+
+	tmpUpdated = reconcileOverrideVolumes(desiredSpec.Volumes, &existingSpec.Volumes,
+		append(existingRecord.Volumes, desiredRecord.Volumes...))
+	updated = updated || tmpUpdated
+
+	if existing.Annotations[helper.PodTemplateOverridesAnnotation] != desired.Annotations[helper.PodTemplateOverridesAnnotation] {
+		desiredRecord.SetAnnotation(&existing.Annotations)
+		updated = true
+	}
+
+	if updated {
+		log.Info(fmt.Sprintf("%s spec.template podTemplateOverrides have changed", common.ObjectInfo(desired)))
+	}
+
+	return updated, nil
+}
+
+// reconcileOverrideContainers reconciles the named containers, following the order of the desired containers.
+// Named containers not desired are removed. Existing containers not named and not desired are kept at the end.
+func reconcileOverrideContainers(desired []corev1.Container, existing *[]corev1.Container, names []string) bool {
+	result := make([]corev1.Container, 0, len(desired))
+
+	for idx := range desired {
+		if existingContainer := findContainer(*existing, desired[idx].Name); existingContainer != nil && !helper.ArrayContains(names, desired[idx].Name) {
+			result = append(result, *existingContainer)
+		} else {
+			result = append(result, desired[idx])
+		}
+	}
+
+	for idx := range *existing {
+		if findContainer(desired, (*existing)[idx].Name) == nil && !helper.ArrayContains(names, (*existing)[idx].Name) {
+			result = append(result, (*existing)[idx])
+		}
+	}
+
+	if reflect.DeepEqual(result, *existing) {
+		return false
+	}
+
+	*existing = result
+	return true
+}
+
+// reconcileOverrideEnvVars sets the named env vars to the desired value, removing the ones not desired
+func reconcileOverrideEnvVars(desired []corev1.EnvVar, existing *[]corev1.EnvVar, names []string) bool {
+	updated := false
+
+	for _, name := range names {
+		desiredIdx := helper.FindEnvVar(desired, name)
+		existingIdx := helper.FindEnvVar(*existing, name)
+
+		switch {
+		case desiredIdx < 0 && existingIdx >= 0:
+			*existing = append((*existing)[:existingIdx], (*existing)[existingIdx+1:]...)
+			updated = true
+		case desiredIdx >= 0 && existingIdx < 0:
+			*existing = append(*existing, desired[desiredIdx])
+			updated = true
+		case desiredIdx >= 0 && !reflect.DeepEqual((*existing)[existingIdx], desired[desiredIdx]):
+			(*existing)[existingIdx] = desired[desiredIdx]
+			updated = true
+		}
+	}
+
+	return updated
+}
+
+// reconcileOverrideVolumeMounts sets the volume mounts of the given mount paths to the desired value,
+// removing the ones not desired
+func reconcileOverrideVolumeMounts(desired []corev1.VolumeMount, existing *[]corev1.VolumeMount, mountPaths []string) bool {
+	updated := false
+
+	for _, mountPath := range mountPaths {
+		key := corev1.VolumeMount{MountPath: mountPath}
+		desiredIdx := helper.FindVolumeMountByMountPath(desired, key)
+		existingIdx := helper.FindVolumeMountByMountPath(*existing, key)
+
+		switch {
+		case desiredIdx < 0 && existingIdx >= 0:
+			*existing = append((*existing)[:existingIdx], (*existing)[existingIdx+1:]...)
+			updated = true
+		case desiredIdx >= 0 && existingIdx < 0:
+			*existing = append(*existing, desired[desiredIdx])
+			updated = true
+		case desiredIdx >= 0 && !reflect.DeepEqual((*existing)[existingIdx], desired[desiredIdx]):
+			(*existing)[existingIdx] = desired[desiredIdx]
+			updated = true
+		}
+	}
+
+	return updated
+}
+
+// reconcileOverrideVolumes sets the named volumes to the desired value, removing the ones not desired
+func reconcileOverrideVolumes(desired []corev1.Volume, existing *[]corev1.Volume, names []string) bool {
+	updated := false
+
+	for _, name := range names {
+		desiredIdx := helper.FindVolumeByName(desired, name)
+		existingIdx := helper.FindVolumeByName(*existing, name)
+
+		switch {
+		case desiredIdx < 0 && existingIdx >= 0:
+			*existing = append((*existing)[:existingIdx], (*existing)[existingIdx+1:]...)
+			updated = true
+		case desiredIdx >= 0 && existingIdx < 0:
+			*existing = append(*existing, desired[desiredIdx])
+			updated = true
+		case desiredIdx >= 0 && !reflect.DeepEqual((*existing)[existingIdx], desired[desiredIdx]):
+			(*existing)[existingIdx] = desired[desiredIdx]
+			updated = true
+		}
+	}
+
+	return updated
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for idx := range containers {
+		if containers[idx].Name == name {
+			return &containers[idx]
+		}
+	}
+	return nil
+}
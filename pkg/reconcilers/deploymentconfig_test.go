@@ -1260,6 +1260,144 @@ func TestDeploymentConfigProbesMutator(t *testing.T) {
             want:    false,
             wantErr: false,
         },
+		{
+			name: "Startup Probe Updated",
+			args: args{
+				desired: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										StartupProbe: &v1.Probe{
+											ProbeHandler: v1.ProbeHandler{
+												TCPSocket: &v1.TCPSocketAction{
+													Port: intstr.FromInt(9306),
+												},
+											},
+											FailureThreshold: 30,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				existing: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										StartupProbe: nil,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Probe With Server Defaults Not Updated",
+			args: args{
+				desired: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										ReadinessProbe: &v1.Probe{
+											ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{
+												Port: intstr.FromInt(3000),
+											}},
+											SuccessThreshold: 0,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				existing: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										ReadinessProbe: &v1.Probe{
+											ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{
+												Path:   "/",
+												Port:   intstr.FromInt(3000),
+												Scheme: v1.URISchemeHTTP,
+											}},
+											TimeoutSeconds:   1,
+											PeriodSeconds:    10,
+											SuccessThreshold: 1,
+											FailureThreshold: 3,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "Containers Matched By Name",
+			args: args{
+				desired: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "container",
+										LivenessProbe: &v1.Probe{
+											ProbeHandler: v1.ProbeHandler{
+												TCPSocket: &v1.TCPSocketAction{
+													Port: intstr.FromInt(9306),
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				existing: &appsv1.DeploymentConfig{
+					Spec: appsv1.DeploymentConfigSpec{
+						Template: &v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "sidecar",
+									},
+									{
+										Name: "container",
+										LivenessProbe: &v1.Probe{
+											ProbeHandler: v1.ProbeHandler{
+												TCPSocket: &v1.TCPSocketAction{
+													Port: intstr.FromInt(9306),
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    false,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1274,3 +1412,200 @@ func TestDeploymentConfigProbesMutator(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentConfigPodTemplateOverridesMutator(t *testing.T) {
+	dcFactory := func(record *helper.PodTemplateOverridesRecord, containers []corev1.Container, volumes []corev1.Volume) *appsv1.DeploymentConfig {
+		dc := &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myDC",
+				Namespace: "myNS",
+			},
+			Spec: appsv1.DeploymentConfigSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: containers,
+						Volumes:    volumes,
+					},
+				},
+			},
+		}
+		record.SetAnnotation(&dc.Annotations)
+		return dc
+	}
+
+	existing := dcFactory(
+		&helper.PodTemplateOverridesRecord{Env: []string{"OLD"}, Volumes: []string{"old-vol"}, Containers: []string{"old-sidecar"}},
+		[]corev1.Container{
+			{Name: "app", Env: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "MANUAL", Value: "1"}, {Name: "OLD", Value: "1"}}},
+			{Name: "old-sidecar"},
+		},
+		[]corev1.Volume{{Name: "manual-vol"}, {Name: "old-vol"}},
+	)
+	desired := dcFactory(
+		&helper.PodTemplateOverridesRecord{Env: []string{"NEW"}, Volumes: []string{"new-vol"}, Containers: []string{"new-sidecar"}},
+		[]corev1.Container{
+			{Name: "app", Env: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "NEW", Value: "1"}}},
+			{Name: "new-sidecar"},
+		},
+		[]corev1.Volume{{Name: "new-vol"}},
+	)
+
+	update, err := DeploymentConfigPodTemplateOverridesMutator(desired, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update {
+		t.Fatal("when overrides differ, mutator reported no update needed")
+	}
+
+	expectedContainers := []corev1.Container{
+		{Name: "app", Env: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "MANUAL", Value: "1"}, {Name: "NEW", Value: "1"}}},
+		{Name: "new-sidecar"},
+	}
+	if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers, expectedContainers) {
+		t.Fatal(cmp.Diff(existing.Spec.Template.Spec.Containers, expectedContainers))
+	}
+	expectedVolumes := []corev1.Volume{{Name: "manual-vol"}, {Name: "new-vol"}}
+	if !reflect.DeepEqual(existing.Spec.Template.Spec.Volumes, expectedVolumes) {
+		t.Fatal(cmp.Diff(existing.Spec.Template.Spec.Volumes, expectedVolumes))
+	}
+	if existing.Annotations[helper.PodTemplateOverridesAnnotation] != desired.Annotations[helper.PodTemplateOverridesAnnotation] {
+		t.Fatalf("record annotation not reconciled: %s", existing.Annotations[helper.PodTemplateOverridesAnnotation])
+	}
+
+	update, err = DeploymentConfigPodTemplateOverridesMutator(desired, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update {
+		t.Fatal("when overrides are reconciled, mutator reported update needed")
+	}
+}
+
+func TestDeploymentConfigSecurityContextMutator(t *testing.T) {
+	dcFactory := func(podSecurityContext *corev1.PodSecurityContext, securityContext *corev1.SecurityContext) *appsv1.DeploymentConfig {
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myDC",
+				Namespace: "myNS",
+			},
+			Spec: appsv1.DeploymentConfigSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						SecurityContext: podSecurityContext,
+						InitContainers:  []corev1.Container{{Name: "init", SecurityContext: securityContext}},
+						Containers:      []corev1.Container{{Name: "container", SecurityContext: securityContext}},
+					},
+				},
+			},
+		}
+	}
+
+	restrictedPod := &corev1.PodSecurityContext{
+		RunAsNonRoot:   &[]bool{true}[0],
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	restrictedContainer := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &[]bool{false}[0],
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+
+	cases := []struct {
+		testName       string
+		existing       *appsv1.DeploymentConfig
+		desired        *appsv1.DeploymentConfig
+		expectedResult bool
+	}{
+		{"NothingToReconcile", dcFactory(restrictedPod, restrictedContainer), dcFactory(restrictedPod, restrictedContainer), false},
+		{"SecurityContextAdded", dcFactory(nil, nil), dcFactory(restrictedPod, restrictedContainer), true},
+		{"SecurityContextRemoved", dcFactory(restrictedPod, restrictedContainer), dcFactory(nil, nil), true},
+		// The API server stores an empty pod security context when none is set
+		{"EmptySecurityContextDefaulted", dcFactory(&corev1.PodSecurityContext{}, nil), dcFactory(nil, nil), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(subT *testing.T) {
+			update, err := DeploymentConfigSecurityContextMutator(tc.desired, tc.existing)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if update != tc.expectedResult {
+				subT.Fatalf("result failed, expected: %t, got: %t", tc.expectedResult, update)
+			}
+			if update && !reflect.DeepEqual(tc.existing.Spec.Template.Spec, tc.desired.Spec.Template.Spec) {
+				subT.Fatal(cmp.Diff(tc.existing.Spec.Template.Spec, tc.desired.Spec.Template.Spec))
+			}
+		})
+	}
+}
+
+func TestDeploymentConfigVolumesMutator(t *testing.T) {
+	dcFactory := func(withTLS bool) *appsv1.DeploymentConfig {
+		volumes := []corev1.Volume{{Name: "other"}}
+		mounts := []corev1.VolumeMount{{Name: "other", MountPath: "/other"}}
+		hookVolumes := []string{"other"}
+		if withTLS {
+			volumes = append(volumes, corev1.Volume{
+				Name:         "tls",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "redis"}},
+			})
+			mounts = append(mounts, corev1.VolumeMount{Name: "tls", MountPath: "/tls", ReadOnly: true})
+			hookVolumes = append(hookVolumes, "tls")
+		}
+
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myDC",
+				Namespace: "myNS",
+			},
+			Spec: appsv1.DeploymentConfigSpec{
+				Strategy: appsv1.DeploymentStrategy{
+					RollingParams: &appsv1.RollingDeploymentStrategyParams{
+						Pre: &appsv1.LifecycleHook{
+							ExecNewPod: &appsv1.ExecNewPodHook{ContainerName: "container", Volumes: hookVolumes},
+						},
+					},
+				},
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes:        volumes,
+						InitContainers: []corev1.Container{{Name: "init", VolumeMounts: mounts}},
+						Containers:     []corev1.Container{{Name: "container", VolumeMounts: mounts}},
+					},
+				},
+			},
+		}
+	}
+
+	// The API server sets the default mode of secret volumes
+	defaultMode := int32(0644)
+	defaultedDC := dcFactory(true)
+	defaultedDC.Spec.Template.Spec.Volumes[1].Secret.DefaultMode = &defaultMode
+
+	cases := []struct {
+		testName       string
+		existing       *appsv1.DeploymentConfig
+		desired        *appsv1.DeploymentConfig
+		expectedResult bool
+	}{
+		{"NothingToReconcile", dcFactory(true), dcFactory(true), false},
+		{"VolumeAdded", dcFactory(false), dcFactory(true), true},
+		{"VolumeRemoved", dcFactory(true), dcFactory(false), true},
+		{"DefaultModeSetByServer", defaultedDC, dcFactory(true), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(subT *testing.T) {
+			update, err := DeploymentConfigVolumesMutator("tls")(tc.desired, tc.existing)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if update != tc.expectedResult {
+				subT.Fatalf("result failed, expected: %t, got: %t", tc.expectedResult, update)
+			}
+			if update && !reflect.DeepEqual(tc.existing.Spec, tc.desired.Spec) {
+				subT.Fatal(cmp.Diff(tc.existing.Spec, tc.desired.Spec))
+			}
+		})
+	}
+}
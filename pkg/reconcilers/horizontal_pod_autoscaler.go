@@ -0,0 +1,46 @@
+package reconcilers
+
+import (
+	"fmt"
+	"reflect"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+)
+
+// GenericHPAMutator reconciles the HorizontalPodAutoscaler scale target, replica limits and metrics.
+// Behavior is not managed by the operator and is left untouched.
+func GenericHPAMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*autoscalingv2.HorizontalPodAutoscaler)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *autoscalingv2.HorizontalPodAutoscaler", existingObj)
+	}
+	desired, ok := desiredObj.(*autoscalingv2.HorizontalPodAutoscaler)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *autoscalingv2.HorizontalPodAutoscaler", desiredObj)
+	}
+
+	updated := false
+	if !reflect.DeepEqual(desired.Spec.ScaleTargetRef, existing.Spec.ScaleTargetRef) {
+		existing.Spec.ScaleTargetRef = desired.Spec.ScaleTargetRef
+		updated = true
+	}
+
+	if !reflect.DeepEqual(desired.Spec.MinReplicas, existing.Spec.MinReplicas) {
+		existing.Spec.MinReplicas = desired.Spec.MinReplicas
+		updated = true
+	}
+
+	if desired.Spec.MaxReplicas != existing.Spec.MaxReplicas {
+		existing.Spec.MaxReplicas = desired.Spec.MaxReplicas
+		updated = true
+	}
+
+	if !reflect.DeepEqual(desired.Spec.Metrics, existing.Spec.Metrics) {
+		existing.Spec.Metrics = desired.Spec.Metrics
+		updated = true
+	}
+
+	return updated, nil
+}
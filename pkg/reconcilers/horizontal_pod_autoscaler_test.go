@@ -0,0 +1,72 @@
+package reconcilers
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func hpaTestFactory(maxReplicas, cpuUtilization int32) *autoscalingv2.HorizontalPodAutoscaler {
+	minReplicas := int32(1)
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myHPA",
+			Namespace: "someNs",
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "myDeployment",
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: v1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: &cpuUtilization,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenericHPAMutator(t *testing.T) {
+	existing := hpaTestFactory(3, 80)
+	existing.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	desired := hpaTestFactory(5, 50)
+
+	update, err := GenericHPAMutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update {
+		t.Fatal("when hpa differs, reconciler reported no update needed")
+	}
+
+	if existing.Spec.MaxReplicas != 5 {
+		t.Fatalf("MaxReplicas not reconciled. Expected: 5, got: %d", existing.Spec.MaxReplicas)
+	}
+	if *existing.Spec.Metrics[0].Resource.Target.AverageUtilization != 50 {
+		t.Fatalf("Metrics not reconciled. Expected: 50, got: %d", *existing.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	}
+	if existing.Spec.Behavior == nil {
+		t.Fatal("Behavior should not be reconciled")
+	}
+
+	update, err = GenericHPAMutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update {
+		t.Fatal("when hpa is reconciled, reconciler reported update needed")
+	}
+}
@@ -0,0 +1,35 @@
+package reconcilers
+
+import (
+	"fmt"
+	"reflect"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// IngressMutator reconciles the Ingress annotations and spec.
+// Existing annotations not included in the desired object are kept,
+// as they may be set by the ingress controller.
+func IngressMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*networkingv1.Ingress)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *networkingv1.Ingress", existingObj)
+	}
+	desired, ok := desiredObj.(*networkingv1.Ingress)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *networkingv1.Ingress", desiredObj)
+	}
+
+	updated := false
+	helper.MergeMapStringString(&updated, &existing.Annotations, desired.Annotations)
+
+	if !reflect.DeepEqual(desired.Spec, existing.Spec) {
+		existing.Spec = desired.Spec
+		updated = true
+	}
+
+	return updated, nil
+}
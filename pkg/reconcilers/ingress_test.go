@@ -0,0 +1,53 @@
+package reconcilers
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ingressTestFactory(className string, annotations map[string]string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "myIngress",
+			Namespace:   "someNs",
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &className,
+			Rules:            []networkingv1.IngressRule{{Host: "example.com"}},
+		},
+	}
+}
+
+func TestIngressMutator(t *testing.T) {
+	existing := ingressTestFactory("nginx", map[string]string{"a": "1", "controller": "value"})
+	desired := ingressTestFactory("haproxy", map[string]string{"a": "2"})
+
+	update, err := IngressMutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update {
+		t.Fatal("when ingress differs, reconciler reported no update needed")
+	}
+
+	if *existing.Spec.IngressClassName != "haproxy" {
+		t.Fatalf("IngressClassName not reconciled. Expected: haproxy, got: %s", *existing.Spec.IngressClassName)
+	}
+	if existing.Annotations["a"] != "2" {
+		t.Fatalf("annotation not reconciled. Expected: 2, got: %s", existing.Annotations["a"])
+	}
+	if existing.Annotations["controller"] != "value" {
+		t.Fatal("existing annotation not in desired object should be kept")
+	}
+
+	update, err = IngressMutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update {
+		t.Fatal("when ingress is reconciled, reconciler reported update needed")
+	}
+}
@@ -0,0 +1,49 @@
+package reconcilers
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	kedav1alpha1 "github.com/3scale/3scale-operator/pkg/keda/v1alpha1"
+)
+
+// GenericScaledObjectMutator reconciles the KEDA ScaledObject spec
+func GenericScaledObjectMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*kedav1alpha1.ScaledObject)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *kedav1alpha1.ScaledObject", existingObj)
+	}
+	desired, ok := desiredObj.(*kedav1alpha1.ScaledObject)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *kedav1alpha1.ScaledObject", desiredObj)
+	}
+
+	updated := false
+	if !reflect.DeepEqual(desired.Spec, existing.Spec) {
+		existing.Spec = desired.Spec
+		updated = true
+	}
+
+	return updated, nil
+}
+
+// TriggerAuthenticationMutator reconciles the KEDA TriggerAuthentication spec
+func TriggerAuthenticationMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*kedav1alpha1.TriggerAuthentication)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *kedav1alpha1.TriggerAuthentication", existingObj)
+	}
+	desired, ok := desiredObj.(*kedav1alpha1.TriggerAuthentication)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *kedav1alpha1.TriggerAuthentication", desiredObj)
+	}
+
+	updated := false
+	if !reflect.DeepEqual(desired.Spec, existing.Spec) {
+		existing.Spec = desired.Spec
+		updated = true
+	}
+
+	return updated, nil
+}
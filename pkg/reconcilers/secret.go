@@ -1,6 +1,7 @@
 package reconcilers
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/3scale/3scale-operator/pkg/common"
@@ -37,6 +38,34 @@ func DefaultsOnlySecretMutator(existingObj, desiredObj common.KubernetesObject)
 	return updated, nil
 }
 
+// SecretDataMutator reconciles the whole data of secrets generated by the operator.
+// Keys not desired are removed.
+func SecretDataMutator(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+	existing, ok := existingObj.(*v1.Secret)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1.Secret", existingObj)
+	}
+	desired, ok := desiredObj.(*v1.Secret)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1.Secret", desiredObj)
+	}
+
+	updated := len(existing.Data) != len(desired.Data)
+	for k, v := range desired.Data {
+		existingValue, ok := existing.Data[k]
+		if !ok || !bytes.Equal(existingValue, v) {
+			updated = true
+		}
+	}
+
+	if updated {
+		existing.Data = desired.Data
+		existing.StringData = nil
+	}
+
+	return updated, nil
+}
+
 // SecretMutateFn is a function which mutates the existing Secret into it's desired state.
 type SecretMutateFn func(desired, existing *v1.Secret) bool
 
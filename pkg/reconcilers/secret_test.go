@@ -76,3 +76,39 @@ func TestDefaultsOnlySecretReconciler(t *testing.T) {
 		t.Fatal("existingSecret does not have a3 data")
 	}
 }
+
+func TestSecretDataMutator(t *testing.T) {
+	desired := &v1.Secret{
+		Data: map[string][]byte{
+			"a1": []byte("a1Value"),
+			"a2": []byte("a2Value"),
+		},
+	}
+
+	cases := []struct {
+		testName       string
+		existingData   map[string][]byte
+		expectedUpdate bool
+	}{
+		{"equal", map[string][]byte{"a1": []byte("a1Value"), "a2": []byte("a2Value")}, false},
+		{"changed value", map[string][]byte{"a1": []byte("a1Value"), "a2": []byte("other")}, true},
+		{"missing key", map[string][]byte{"a1": []byte("a1Value")}, true},
+		{"extra key", map[string][]byte{"a1": []byte("a1Value"), "a2": []byte("a2Value"), "a3": []byte("a3Value")}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(subT *testing.T) {
+			existing := &v1.Secret{Data: tc.existingData}
+			update, err := SecretDataMutator(existing, desired)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if update != tc.expectedUpdate {
+				subT.Fatalf("expected update %t, got %t", tc.expectedUpdate, update)
+			}
+			if len(existing.Data) != len(desired.Data) {
+				subT.Fatalf("expected data %v, got %v", desired.Data, existing.Data)
+			}
+		})
+	}
+}
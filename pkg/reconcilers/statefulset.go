@@ -0,0 +1,161 @@
+package reconcilers
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	k8sappsv1 "k8s.io/api/apps/v1"
+
+	"github.com/3scale/3scale-operator/pkg/common"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// StatefulSetMutateFn is a function which mutates the existing StatefulSet into it's desired state.
+type StatefulSetMutateFn func(desired, existing *k8sappsv1.StatefulSet) (bool, error)
+
+func StatefulSetMutator(opts ...StatefulSetMutateFn) MutateFn {
+	return func(existingObj, desiredObj common.KubernetesObject) (bool, error) {
+		existing, ok := existingObj.(*k8sappsv1.StatefulSet)
+		if !ok {
+			return false, fmt.Errorf("%T is not a *k8sappsv1.StatefulSet", existingObj)
+		}
+		desired, ok := desiredObj.(*k8sappsv1.StatefulSet)
+		if !ok {
+			return false, fmt.Errorf("%T is not a *k8sappsv1.StatefulSet", desiredObj)
+		}
+
+		update := false
+
+		// Loop through each option
+		for _, opt := range opts {
+			tmpUpdate, err := opt(desired, existing)
+			if err != nil {
+				return false, err
+			}
+			update = update || tmpUpdate
+		}
+
+		return update, nil
+	}
+}
+
+func StatefulSetReplicasMutator(desired, existing *k8sappsv1.StatefulSet) (bool, error) {
+	update := false
+
+	if !reflect.DeepEqual(desired.Spec.Replicas, existing.Spec.Replicas) {
+		existing.Spec.Replicas = desired.Spec.Replicas
+		update = true
+	}
+
+	return update, nil
+}
+
+// StatefulSetContainersMutator ensures image, command, env, resources, security context and probes of the containers are reconciled
+func StatefulSetContainersMutator(desired, existing *k8sappsv1.StatefulSet) (bool, error) {
+	desiredName := common.ObjectInfo(desired)
+	desiredContainers := desired.Spec.Template.Spec.Containers
+	existingContainers := existing.Spec.Template.Spec.Containers
+
+	if len(desiredContainers) != len(existingContainers) {
+		log.Info(fmt.Sprintf("%s spec.template.spec.containers length changed to '%d', recreating containers", desiredName, len(existingContainers)))
+		existing.Spec.Template.Spec.Containers = desiredContainers
+		return true, nil
+	}
+
+	update := false
+	for idx := range desiredContainers {
+		if existingContainers[idx].Name != desiredContainers[idx].Name {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d] name changed to '%s', recreating containers", desiredName, idx, existingContainers[idx].Name))
+			existing.Spec.Template.Spec.Containers = desiredContainers
+			return true, nil
+		}
+
+		if existingContainers[idx].Image != desiredContainers[idx].Image {
+			existingContainers[idx].Image = desiredContainers[idx].Image
+			update = true
+		}
+
+		if !reflect.DeepEqual(existingContainers[idx].Command, desiredContainers[idx].Command) {
+			existingContainers[idx].Command = desiredContainers[idx].Command
+			update = true
+		}
+
+		if !reflect.DeepEqual(existingContainers[idx].Env, desiredContainers[idx].Env) {
+			diff := cmp.Diff(existingContainers[idx].Env, desiredContainers[idx].Env)
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].env has changed: %s", desiredName, idx, diff))
+			existingContainers[idx].Env = desiredContainers[idx].Env
+			update = true
+		}
+
+		if !helper.CmpResources(&existingContainers[idx].Resources, &desiredContainers[idx].Resources) {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].resources have changed", desiredName, idx))
+			existingContainers[idx].Resources = desiredContainers[idx].Resources
+			update = true
+		}
+
+		if !helper.SecurityContextsEqual(existingContainers[idx].SecurityContext, desiredContainers[idx].SecurityContext) {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].securityContext has changed", desiredName, idx))
+			existingContainers[idx].SecurityContext = desiredContainers[idx].SecurityContext
+			update = true
+		}
+
+		if !helper.ProbesEqual(existingContainers[idx].LivenessProbe, desiredContainers[idx].LivenessProbe) {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].livenessProbe has changed", desiredName, idx))
+			existingContainers[idx].LivenessProbe = desiredContainers[idx].LivenessProbe
+			update = true
+		}
+
+		if !helper.ProbesEqual(existingContainers[idx].ReadinessProbe, desiredContainers[idx].ReadinessProbe) {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].readinessProbe has changed", desiredName, idx))
+			existingContainers[idx].ReadinessProbe = desiredContainers[idx].ReadinessProbe
+			update = true
+		}
+
+		if !helper.ProbesEqual(existingContainers[idx].StartupProbe, desiredContainers[idx].StartupProbe) {
+			log.Info(fmt.Sprintf("%s spec.template.spec.containers[%d].startupProbe has changed", desiredName, idx))
+			existingContainers[idx].StartupProbe = desiredContainers[idx].StartupProbe
+			update = true
+		}
+	}
+
+	return update, nil
+}
+
+// StatefulSetPodTemplateMutator ensures labels, annotations, scheduling and security settings of the pod template are reconciled
+func StatefulSetPodTemplateMutator(desired, existing *k8sappsv1.StatefulSet) (bool, error) {
+	updated := false
+
+	helper.MergeMapStringString(&updated, &existing.Spec.Template.Labels, desired.Spec.Template.Labels)
+	helper.MergeMapStringString(&updated, &existing.Spec.Template.Annotations, desired.Spec.Template.Annotations)
+
+	existingSpec := &existing.Spec.Template.Spec
+	desiredSpec := &desired.Spec.Template.Spec
+
+	if !reflect.DeepEqual(existingSpec.Affinity, desiredSpec.Affinity) {
+		existingSpec.Affinity = desiredSpec.Affinity
+		updated = true
+	}
+
+	if !reflect.DeepEqual(existingSpec.Tolerations, desiredSpec.Tolerations) {
+		existingSpec.Tolerations = desiredSpec.Tolerations
+		updated = true
+	}
+
+	if existingSpec.PriorityClassName != desiredSpec.PriorityClassName {
+		existingSpec.PriorityClassName = desiredSpec.PriorityClassName
+		updated = true
+	}
+
+	if !reflect.DeepEqual(existingSpec.TopologySpreadConstraints, desiredSpec.TopologySpreadConstraints) {
+		existingSpec.TopologySpreadConstraints = desiredSpec.TopologySpreadConstraints
+		updated = true
+	}
+
+	if !helper.PodSecurityContextsEqual(existingSpec.SecurityContext, desiredSpec.SecurityContext) {
+		existingSpec.SecurityContext = desiredSpec.SecurityContext
+		updated = true
+	}
+
+	return updated, nil
+}
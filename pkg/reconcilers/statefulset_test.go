@@ -0,0 +1,76 @@
+package reconcilers
+
+import (
+	"testing"
+
+	k8sappsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func statefulSetTestFactory(replicas int32, image string) *k8sappsv1.StatefulSet {
+	return &k8sappsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myStatefulSet",
+			Namespace: "someNs",
+		},
+		Spec: k8sappsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "myapp"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "redis", Image: image},
+						{Name: "sentinel", Image: image},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestStatefulSetMutator(t *testing.T) {
+	existing := statefulSetTestFactory(3, "redis:6")
+	desired := statefulSetTestFactory(5, "redis:7")
+	desired.Spec.Template.Labels["extra"] = "label"
+	desired.Spec.Template.Spec.PriorityClassName = "high"
+
+	mutator := StatefulSetMutator(
+		StatefulSetReplicasMutator,
+		StatefulSetContainersMutator,
+		StatefulSetPodTemplateMutator,
+	)
+
+	update, err := mutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update {
+		t.Fatal("when desired state differs, reconciler reported no update needed")
+	}
+
+	if *existing.Spec.Replicas != 5 {
+		t.Errorf("replicas not reconciled. Expected: 5, got: %d", *existing.Spec.Replicas)
+	}
+	for _, container := range existing.Spec.Template.Spec.Containers {
+		if container.Image != "redis:7" {
+			t.Errorf("container %s image not reconciled. Expected: redis:7, got: %s", container.Name, container.Image)
+		}
+	}
+	if existing.Spec.Template.Labels["extra"] != "label" {
+		t.Errorf("pod template labels not reconciled: %v", existing.Spec.Template.Labels)
+	}
+	if existing.Spec.Template.Spec.PriorityClassName != "high" {
+		t.Errorf("priority class not reconciled: %s", existing.Spec.Template.Spec.PriorityClassName)
+	}
+
+	update, err = mutator(existing, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update {
+		t.Fatal("when desired state is reconciled, reconciler reported update needed")
+	}
+}
@@ -32,6 +32,8 @@ const (
 	SystemFileStoragePVCMountPath = "/system-filestorage-pvc"
 )
 
+// The secrets of the databases are restored by the user when the databases are
+// external, see databaseSecretsToRestore
 var secretsToRestore map[string]string = map[string]string{
 	"SystemSMTP": "system-smtp",
 	"SystemSeed": "system-seed",
@@ -48,19 +50,59 @@ var secretsToRestore map[string]string = map[string]string{
 	"SystemMasterAPIcast": "system-master-apicast",
 }
 
+// Secrets of the databases deployed by the operator. Unlike the secrets of
+// external databases, they are restored when the backup contains the data
+// of the database
+var databaseSecretsToRestore = []struct {
+	secretName      string
+	backupFileNames []string
+}{
+	{component.SystemSecretSystemDatabaseSecretName, []string{backup.SystemMySQLBackupFileName, backup.SystemPostgreSQLBackupFileName}},
+	{component.BackendSecretBackendRedisSecretName, []string{backup.BackendRedisBackupFileName}},
+	{component.SystemSecretSystemRedisSecretName, []string{backup.SystemRedisBackupFileName}},
+}
+
 var configMapsToRestore map[string]string = map[string]string{
 	"SystemEnvironment":  "system-environment",
 	"APIcastEnvironment": "apicast-environment",
 }
 
-func (b *APIManagerRestore) restoreSourcePVCContainerVolumeMount() v1.VolumeMount {
+func (b *APIManagerRestore) hasRestoreSource() bool {
+	return b.options.APIManagerRestorePVCOptions != nil || b.options.APIManagerRestoreS3Options != nil
+}
+
+func (b *APIManagerRestore) restoreSourceContainerVolumeMount() v1.VolumeMount {
+	return v1.VolumeMount{
+		Name:      b.restoreSourcePodVolume().Name,
+		MountPath: RestorePVCMountPath,
+	}
+}
+
+func (b *APIManagerRestore) backupDataContainerVolumeMount() v1.VolumeMount {
 	return v1.VolumeMount{
-		Name:      b.options.APIManagerRestorePVCOptions.PersistentVolumeClaimVolumeSource.ClaimName,
+		Name:      b.backupDataPodVolume().Name,
 		MountPath: RestorePVCMountPath,
 	}
 }
 
-func (b *APIManagerRestore) restoreSourcePVCPodVolume() v1.Volume {
+// The backup data is read from the source PVC or, when the source is S3 or the
+// data is encrypted, from a staging volume of the job pod where it is
+// downloaded and decrypted
+func (b *APIManagerRestore) restoreSourcePodVolume() v1.Volume {
+	if b.options.APIManagerRestoreS3Options != nil || b.options.DecryptionKeySecretName != nil {
+		return backup.StagingPodVolume(restoreStagingVolumeName, b.options.StagingSizeLimit)
+	}
+
+	return b.backupDataPodVolume()
+}
+
+// backupDataPodVolume is the volume with the backup data as it is stored in the
+// source: the source PVC or, when the source is S3, the staging volume
+func (b *APIManagerRestore) backupDataPodVolume() v1.Volume {
+	if b.options.APIManagerRestoreS3Options != nil {
+		return backup.StagingPodVolume(restoreStagingVolumeName, b.options.StagingSizeLimit)
+	}
+
 	return v1.Volume{
 		Name: b.options.APIManagerRestorePVCOptions.PersistentVolumeClaimVolumeSource.ClaimName,
 		VolumeSource: v1.VolumeSource{
@@ -87,8 +129,8 @@ func (b *APIManagerRestore) systemFileStoragePVCPodVolume() v1.Volume {
 	}
 }
 
-func (b *APIManagerRestore) RestoreSecretsAndConfigMapsFromPVCJob() *batchv1.Job {
-	if b.options.APIManagerRestorePVCOptions == nil {
+func (b *APIManagerRestore) RestoreSecretsAndConfigMapsJob() *batchv1.Job {
+	if !b.hasRestoreSource() {
 		return nil
 	}
 
@@ -98,7 +140,7 @@ func (b *APIManagerRestore) RestoreSecretsAndConfigMapsFromPVCJob() *batchv1.Job
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -113,7 +155,7 @@ func (b *APIManagerRestore) RestoreSecretsAndConfigMapsFromPVCJob() *batchv1.Job
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.restoreSourcePVCPodVolume(),
+						b.restoreSourcePodVolume(),
 					},
 					Containers: []v1.Container{
 						v1.Container{
@@ -129,7 +171,7 @@ func (b *APIManagerRestore) RestoreSecretsAndConfigMapsFromPVCJob() *batchv1.Job
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.restoreSourcePVCContainerVolumeMount(),
+								b.restoreSourceContainerVolumeMount(),
 							},
 						},
 					},
@@ -139,10 +181,12 @@ func (b *APIManagerRestore) RestoreSecretsAndConfigMapsFromPVCJob() *batchv1.Job
 			},
 		},
 	}
+
+	return b.withRestoreSource(job, b.restoreSecretsAndConfigMapsFetchArgs())
 }
 
-func (b *APIManagerRestore) RestoreSystemFileStoragePVCFromPVCJob() *batchv1.Job {
-	if b.options.APIManagerRestorePVCOptions == nil {
+func (b *APIManagerRestore) RestoreSystemFileStoragePVCJob() *batchv1.Job {
+	if !b.hasRestoreSource() {
 		return nil
 	}
 
@@ -152,7 +196,7 @@ func (b *APIManagerRestore) RestoreSystemFileStoragePVCFromPVCJob() *batchv1.Job
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -167,7 +211,7 @@ func (b *APIManagerRestore) RestoreSystemFileStoragePVCFromPVCJob() *batchv1.Job
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.restoreSourcePVCPodVolume(),
+						b.restoreSourcePodVolume(),
 						b.systemFileStoragePVCPodVolume(),
 					},
 					Containers: []v1.Container{
@@ -184,7 +228,7 @@ func (b *APIManagerRestore) RestoreSystemFileStoragePVCFromPVCJob() *batchv1.Job
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.restoreSourcePVCContainerVolumeMount(),
+								b.restoreSourceContainerVolumeMount(),
 								b.systemFileStoragePVCContainerVolumeMount(),
 							},
 						},
@@ -195,10 +239,12 @@ func (b *APIManagerRestore) RestoreSystemFileStoragePVCFromPVCJob() *batchv1.Job
 			},
 		},
 	}
+
+	return b.withRestoreSource(job, fetchDirArgs("system-filestorage-pvc"))
 }
 
 func (b *APIManagerRestore) CreateAPIManagerSharedSecretJob() *batchv1.Job {
-	if b.options.APIManagerRestorePVCOptions == nil {
+	if !b.hasRestoreSource() {
 		return nil
 	}
 
@@ -208,7 +254,7 @@ func (b *APIManagerRestore) CreateAPIManagerSharedSecretJob() *batchv1.Job {
 	}
 
 	var completions int32 = 1
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "batch/v1",
 			Kind:       "Job",
@@ -223,7 +269,7 @@ func (b *APIManagerRestore) CreateAPIManagerSharedSecretJob() *batchv1.Job {
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Volumes: []v1.Volume{
-						b.restoreSourcePVCPodVolume(),
+						b.restoreSourcePodVolume(),
 					},
 					Containers: []v1.Container{
 						v1.Container{
@@ -239,7 +285,7 @@ func (b *APIManagerRestore) CreateAPIManagerSharedSecretJob() *batchv1.Job {
 							},
 							//Env: []v1.EnvVar{},
 							VolumeMounts: []v1.VolumeMount{
-								b.restoreSourcePVCContainerVolumeMount(),
+								b.restoreSourceContainerVolumeMount(),
 							},
 						},
 					},
@@ -249,10 +295,12 @@ func (b *APIManagerRestore) CreateAPIManagerSharedSecretJob() *batchv1.Job {
 			},
 		},
 	}
+
+	return b.withRestoreSource(job, fetchDirArgs("apimanager"))
 }
 
 func (b *APIManagerRestore) ZyncResyncDomainsJob() *batchv1.Job {
-	if b.options.APIManagerRestorePVCOptions == nil {
+	if !b.hasRestoreSource() {
 		return nil
 	}
 
@@ -369,6 +417,7 @@ func (b *APIManagerRestore) restoreSecretsAndConfigMapsContainerArgs() string {
 	SECRETS='%s';
 	CONFIGMAPS='%s';
 	BASEPATH='%s';
+%s
 	for i in $(echo -n $SECRETS); do
 		res=$(oc get secret ${i} --ignore-not-found=true)
 		if [ -z "${res}" ]; then
@@ -390,9 +439,22 @@ func (b *APIManagerRestore) restoreSecretsAndConfigMapsContainerArgs() string {
 		strings.Join(helper.SortedMapStringStringValues(secretsToRestore), " "),
 		strings.Join(helper.SortedMapStringStringValues(configMapsToRestore), " "),
 		RestorePVCMountPath,
+		b.databaseSecretsToRestoreArgs(),
 	)
 }
 
+func (b *APIManagerRestore) databaseSecretsToRestoreArgs() string {
+	args := ""
+	for _, databaseSecret := range databaseSecretsToRestore {
+		conditions := []string{}
+		for _, fileName := range databaseSecret.backupFileNames {
+			conditions = append(conditions, fmt.Sprintf(`[ -f "%s" ]`, b.databaseBackupFilePath(fileName)))
+		}
+		args += fmt.Sprintf("\tif %s; then SECRETS=\"${SECRETS} %s\"; fi;\n", strings.Join(conditions, " || "), databaseSecret.secretName)
+	}
+	return args
+}
+
 func (b *APIManagerRestore) zyncResyncDomainsContainerArgs() string {
 	return `
 	dcname="system-sidekiq"
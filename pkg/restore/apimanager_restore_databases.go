@@ -0,0 +1,269 @@
+package restore
+
+import (
+	"fmt"
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/component"
+	"github.com/3scale/3scale-operator/pkg/backup"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// Data directory of the Redis images, where the Redis volumes are mounted
+const redisDataDir = "/var/lib/redis/data"
+
+// DatabasePVC returns the PVC of a database to be created before its data is
+// loaded. It is adopted by the APIManager once restored
+func (b *APIManagerRestore) DatabasePVC(databaseInfo *RuntimeDatabaseRestoreInfo) *v1.PersistentVolumeClaim {
+	if databaseInfo == nil || databaseInfo.PVC == nil {
+		return nil
+	}
+
+	pvc := databaseInfo.PVC.DeepCopy()
+	pvc.Namespace = b.options.Namespace
+	return pvc
+}
+
+// RestoreSystemMySQLJob loads the system database dump in the MySQL volume.
+// It runs a temporary MySQL server and has to complete before the APIManager is created
+func (b *APIManagerRestore) RestoreSystemMySQLJob(restoreInfo *RuntimeAPIManagerRestoreInfo) *batchv1.Job {
+	if !b.hasRestoreSource() || restoreInfo.SystemMySQL == nil {
+		return nil
+	}
+
+	return b.databaseRestoreJob("restore-system-mysql", backup.SystemMySQLBackupFileName, restoreInfo.SystemMySQL, b.restoreSystemMySQLContainerArgs())
+}
+
+// RestoreSystemPostgreSQLJob loads the system database dump in the PostgreSQL volume.
+// It runs a temporary PostgreSQL server and has to complete before the APIManager is created
+func (b *APIManagerRestore) RestoreSystemPostgreSQLJob(restoreInfo *RuntimeAPIManagerRestoreInfo) *batchv1.Job {
+	if !b.hasRestoreSource() || restoreInfo.SystemPostgreSQL == nil {
+		return nil
+	}
+
+	return b.databaseRestoreJob("restore-system-postgresql", backup.SystemPostgreSQLBackupFileName, restoreInfo.SystemPostgreSQL, b.restoreSystemPostgreSQLContainerArgs())
+}
+
+// RestoreBackendRedisJob loads the backend-redis snapshot in its volume.
+// It has to complete before the APIManager is created
+func (b *APIManagerRestore) RestoreBackendRedisJob(restoreInfo *RuntimeAPIManagerRestoreInfo) *batchv1.Job {
+	if !b.hasRestoreSource() || restoreInfo.BackendRedis == nil {
+		return nil
+	}
+
+	return b.databaseRestoreJob("restore-backend-redis", backup.BackendRedisBackupFileName, restoreInfo.BackendRedis, b.restoreRedisContainerArgs(backup.BackendRedisBackupFileName))
+}
+
+// RestoreSystemRedisJob loads the system-redis snapshot in its volume.
+// It has to complete before the APIManager is created
+func (b *APIManagerRestore) RestoreSystemRedisJob(restoreInfo *RuntimeAPIManagerRestoreInfo) *batchv1.Job {
+	if !b.hasRestoreSource() || restoreInfo.SystemRedis == nil {
+		return nil
+	}
+
+	return b.databaseRestoreJob("restore-system-redis", backup.SystemRedisBackupFileName, restoreInfo.SystemRedis, b.restoreRedisContainerArgs(backup.SystemRedisBackupFileName))
+}
+
+// RestoreZyncDatabaseJob loads the zync database dump. The zync database
+// data is not persisted, so it is loaded once the zync-database deployment is ready
+func (b *APIManagerRestore) RestoreZyncDatabaseJob(restoreInfo *RuntimeAPIManagerRestoreInfo) *batchv1.Job {
+	if !b.hasRestoreSource() || restoreInfo.ZyncDatabase == nil {
+		return nil
+	}
+
+	return b.databaseRestoreJob("restore-zync-database", backup.ZyncDatabaseBackupFileName, restoreInfo.ZyncDatabase, b.restoreZyncDatabaseContainerArgs())
+}
+
+// databaseRestoreJob returns a Job running the restore script in a container
+// based on the database one. It has the image, the environment and the persistent
+// volumes of the database, but not its configuration volumes, which are not
+// restored yet
+func (b *APIManagerRestore) databaseRestoreJob(name, backupFileName string, databaseInfo *RuntimeDatabaseRestoreInfo, containerArgs string) *batchv1.Job {
+	jobName, err := helper.UIDBasedJobName(name, b.options.APIManagerRestoreUID)
+	if err != nil {
+		panic(err)
+	}
+
+	podSpec := databaseInfo.PodTemplate.Spec
+	if len(podSpec.Containers) == 0 {
+		panic(fmt.Sprintf("no containers found in the pod template of job '%s'", name))
+	}
+	databaseContainer := podSpec.Containers[0]
+
+	volumes := []v1.Volume{b.restoreSourcePodVolume()}
+	volumeMounts := []v1.VolumeMount{b.restoreSourceContainerVolumeMount()}
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		volumes = append(volumes, volume)
+		for _, volumeMount := range databaseContainer.VolumeMounts {
+			if volumeMount.Name == volume.Name {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+	}
+
+	// The database secrets are only restored along with the database backups.
+	// Jobs of backups without them skip the restore instead of waiting for the secrets
+	env := make([]v1.EnvVar, 0, len(databaseContainer.Env))
+	for _, envVar := range databaseContainer.Env {
+		envVar := *envVar.DeepCopy()
+		if envVar.ValueFrom != nil && envVar.ValueFrom.SecretKeyRef != nil {
+			optional := true
+			envVar.ValueFrom.SecretKeyRef.Optional = &optional
+		}
+		env = append(env, envVar)
+	}
+
+	var completions int32 = 1
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: b.options.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					// Allowed by the NetworkPolicies of the databases
+					Labels: map[string]string{component.DatabaseClientLabelKey: component.DatabaseClientLabelValue},
+				},
+				Spec: v1.PodSpec{
+					Volumes: volumes,
+					Containers: []v1.Container{
+						v1.Container{
+							Name:  name,
+							Image: databaseContainer.Image,
+							// The entrypoint of the database images sets up the environment of the server and client tools
+							Command: []string{
+								"container-entrypoint",
+								"/bin/bash",
+							},
+							Args: []string{
+								"-c",
+								"-e",
+								containerArgs,
+							},
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+							SecurityContext: databaseContainer.SecurityContext,
+						},
+					},
+					Affinity:           podSpec.Affinity,
+					Tolerations:        podSpec.Tolerations,
+					SecurityContext:    podSpec.SecurityContext,
+					RestartPolicy:      v1.RestartPolicyNever, // Only "Never" or "OnFailure" are accepted in Kubernetes Jobs
+					ServiceAccountName: ServiceAccountName,
+				},
+			},
+		},
+	}
+
+	return b.withRestoreSource(job, fetchFileArgs(path.Join(backup.DatabasesBackupSubdir, backupFileName)))
+}
+
+func (b *APIManagerRestore) databaseBackupFilePath(fileName string) string {
+	return path.Join(RestorePVCMountPath, backup.DatabasesBackupSubdir, fileName)
+}
+
+func (b *APIManagerRestore) restoreSystemMySQLContainerArgs() string {
+	// The server configuration is mounted from ConfigMaps created by the APIManager.
+	// The dump creates the database and drops the existing tables, so the job can be retried
+	return fmt.Sprintf(`
+	BACKUP_FILE='%s';
+	if [ ! -f "${BACKUP_FILE}" ]; then
+		echo "System MySQL backup not found. Skipping restore of the database";
+		exit 0;
+	fi;
+	unset MYSQL_DEFAULTS_FILE;
+	run-mysqld &
+	MYSQLD_PID=$!;
+	until MYSQL_PWD="${MYSQL_ROOT_PASSWORD}" mysqladmin --host=127.0.0.1 --user=root ping > /dev/null 2>&1; do
+		kill -0 ${MYSQLD_PID};
+		sleep 2;
+	done;
+	MYSQL_PWD="${MYSQL_ROOT_PASSWORD}" mysql --host=127.0.0.1 --user=root < "${BACKUP_FILE}";
+	kill -TERM ${MYSQLD_PID};
+	wait ${MYSQLD_PID};
+`,
+		b.databaseBackupFilePath(backup.SystemMySQLBackupFileName),
+	)
+}
+
+func (b *APIManagerRestore) restoreSystemPostgreSQLContainerArgs() string {
+	return fmt.Sprintf(`
+	BACKUP_FILE='%s';
+	if [ ! -f "${BACKUP_FILE}" ]; then
+		echo "System PostgreSQL backup not found. Skipping restore of the database";
+		exit 0;
+	fi;
+	run-postgresql &
+	POSTGRESQL_PID=$!;
+	until pg_isready --host=127.0.0.1 --quiet; do
+		kill -0 ${POSTGRESQL_PID};
+		sleep 2;
+	done;
+	PGPASSWORD="${POSTGRESQL_PASSWORD}" psql --host=127.0.0.1 --username="${POSTGRESQL_USER}" --dbname="${POSTGRESQL_DATABASE}" \
+		--set=ON_ERROR_STOP=1 --single-transaction --file="${BACKUP_FILE}";
+	kill -TERM ${POSTGRESQL_PID};
+	wait ${POSTGRESQL_PID};
+`,
+		b.databaseBackupFilePath(backup.SystemPostgreSQLBackupFileName),
+	)
+}
+
+func (b *APIManagerRestore) restoreZyncDatabaseContainerArgs() string {
+	return fmt.Sprintf(`
+	BACKUP_FILE='%s';
+	if [ ! -f "${BACKUP_FILE}" ]; then
+		echo "Zync database backup not found. Skipping restore of the database";
+		exit 0;
+	fi;
+	PGPASSWORD="${POSTGRESQL_PASSWORD}" psql --host='%s' --username="${POSTGRESQL_USER}" --dbname="${POSTGRESQL_DATABASE}" \
+		--set=ON_ERROR_STOP=1 --single-transaction --file="${BACKUP_FILE}";
+`,
+		b.databaseBackupFilePath(backup.ZyncDatabaseBackupFileName),
+		component.ZyncDatabaseDeploymentName,
+	)
+}
+
+func (b *APIManagerRestore) restoreRedisContainerArgs(fileName string) string {
+	// An existing append only file takes precedence over the snapshot when Redis
+	// starts, and the 3scale Redis instances run with the append only file enabled.
+	// So the snapshot is loaded by a temporary server which then writes the append only file
+	return fmt.Sprintf(`
+	BACKUP_FILE='%s';
+	REDIS_DATA_DIR='%s';
+	if [ ! -f "${BACKUP_FILE}" ]; then
+		echo "Redis backup ${BACKUP_FILE} not found. Skipping restore of the database";
+		exit 0;
+	fi;
+	rm -rf "${REDIS_DATA_DIR}/appendonly.aof" "${REDIS_DATA_DIR}/appendonlydir";
+	cp "${BACKUP_FILE}" "${REDIS_DATA_DIR}/dump.rdb";
+	redis-server --dir "${REDIS_DATA_DIR}" --dbfilename dump.rdb --appendonly no --port 6379 &
+	REDIS_PID=$!;
+	until [ "$(redis-cli ping 2> /dev/null)" == "PONG" ]; do
+		kill -0 ${REDIS_PID};
+		sleep 1;
+	done;
+	redis-cli CONFIG SET appendonly yes;
+	until redis-cli INFO persistence | grep -q "aof_rewrite_in_progress:0" && redis-cli INFO persistence | grep -q "aof_rewrite_scheduled:0"; do
+		sleep 1;
+	done;
+	redis-cli INFO persistence | grep -q "aof_last_bgrewrite_status:ok";
+	kill -TERM ${REDIS_PID};
+	wait ${REDIS_PID};
+`,
+		b.databaseBackupFilePath(fileName),
+		redisDataDir,
+	)
+}
@@ -0,0 +1,103 @@
+package restore
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/backup"
+)
+
+const restoreDecryptionPVCMountPath = "/backup-source"
+
+// withRestoreSource completes the job to read the backup data from its source.
+// When the backup data is encrypted, an init container decrypts the data read
+// by the job in the staging volume, after copying it from the source PVC or
+// after it has been downloaded from S3
+func (b *APIManagerRestore) withRestoreSource(job *batchv1.Job, fetchArgs string) *batchv1.Job {
+	if b.options.DecryptionKeySecretName == nil {
+		return b.withBackupData(job, fetchArgs)
+	}
+
+	podSpec := &job.Spec.Template.Spec
+	volumeMounts := []v1.VolumeMount{b.restoreSourceContainerVolumeMount()}
+	copyArgs := ""
+	if b.options.APIManagerRestorePVCOptions != nil {
+		podSpec.Volumes = append(podSpec.Volumes, b.backupDataPodVolume())
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      b.backupDataPodVolume().Name,
+			MountPath: restoreDecryptionPVCMountPath,
+			ReadOnly:  true,
+		})
+		copyArgs = b.pvcCopyContainerArgs(fetchArgs)
+	}
+
+	podSpec.InitContainers = append([]v1.Container{
+		v1.Container{
+			Name:  "decrypt-backup-data",
+			Image: b.options.OCCLIImageURL,
+			Command: []string{
+				"/bin/bash",
+			},
+			Args: []string{
+				"-c",
+				"-e",
+				copyArgs + b.decryptBackupDataContainerArgs(),
+			},
+			Env: []v1.EnvVar{
+				backup.EncryptionKeyEnvVar(*b.options.DecryptionKeySecretName),
+			},
+			VolumeMounts: volumeMounts,
+		},
+	}, podSpec.InitContainers...)
+
+	// The data is downloaded before it is decrypted
+	return b.withBackupData(job, fetchArgs)
+}
+
+// pvcCopyContainerArgs copies the backup data selected by the fetch arguments
+// from the source PVC to the staging volume
+func (b *APIManagerRestore) pvcCopyContainerArgs(fetchArgs string) string {
+	return fmt.Sprintf(`
+	BASEPATH='%s';
+	SOURCE='%s';
+	object_exists() {
+		[ -e "${SOURCE}/$1" ];
+	};
+	fetch_dir() {
+		mkdir -p "${BASEPATH}/$1";
+		if [ -d "${SOURCE}/$1" ]; then cp -R -P "${SOURCE}/$1/." "${BASEPATH}/$1/"; fi;
+	};
+	fetch_file() {
+		mkdir -p "$(dirname "${BASEPATH}/$1")";
+		cp -P "${SOURCE}/$1" "${BASEPATH}/$1";
+	};
+%s`,
+		RestorePVCMountPath,
+		restoreDecryptionPVCMountPath,
+		fetchArgs,
+	)
+}
+
+// decryptBackupDataContainerArgs decrypts in place the files in the staging
+// volume. The manifest and its MAC are not encrypted, and empty files are not
+// backup files but placeholders of existing ones, see restoreSecretsAndConfigMapsFetchArgs
+func (b *APIManagerRestore) decryptBackupDataContainerArgs() string {
+	return fmt.Sprintf(`
+	BASEPATH='%s';
+	MANIFEST='%s';
+	MANIFEST_MAC='%s';
+	cd "${BASEPATH}";
+	find . -type f -size +0 ! -path "./${MANIFEST}" ! -path "./${MANIFEST_MAC}" -print0 > /tmp/backup-files;
+	while IFS= read -r -d '' f; do
+		openssl enc -d %s -in "${f}" -out "${f}.decrypting";
+		mv -f "${f}.decrypting" "${f}";
+	done < /tmp/backup-files;
+`,
+		RestorePVCMountPath,
+		backup.BackupManifestFileName,
+		backup.BackupManifestMACFileName,
+		backup.EncryptionOpenSSLArgs,
+	)
+}
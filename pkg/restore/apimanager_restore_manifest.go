@@ -0,0 +1,316 @@
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/3scale/3scale-operator/apis/apps/v1alpha1"
+	"github.com/3scale/3scale-operator/pkg/3scale/amp/product"
+	"github.com/3scale/3scale-operator/pkg/apispkg/common"
+	"github.com/3scale/3scale-operator/pkg/backup"
+	"github.com/3scale/3scale-operator/pkg/helper"
+)
+
+// BackupVerificationFileName is the key of the result of the verification
+// of the backup data in the secret shared by the verification job
+const BackupVerificationFileName = "verification.json"
+
+const (
+	// Maximum number of verification errors reported in the restore conditions
+	maxReportedVerificationErrors = 5
+	// Maximum length of each verification error and manifest field reported
+	maxReportedVerificationLength = 256
+)
+
+// BackupVerification is the result of the verification of the backup data
+// against its manifest. Only the manifest fields the restore depends on are
+// reported, so the result fits in a secret whatever the size of the backup
+type BackupVerification struct {
+	// False when the backup has no manifest
+	ManifestFound     bool   `json:"manifestFound"`
+	FormatVersion     int    `json:"formatVersion,omitempty"`
+	ThreescaleVersion string `json:"threescaleVersion,omitempty"`
+	OperatorVersion   string `json:"operatorVersion,omitempty"`
+	Encryption        string `json:"encryption,omitempty"`
+	// First errors of the verification, out of ErrorCount
+	Errors     []string `json:"errors"`
+	ErrorCount int      `json:"errorCount,omitempty"`
+	// Set when the manifest of an encrypted backup is not authenticated by
+	// its MAC with the decryption key
+	AuthenticationError string `json:"authenticationError,omitempty"`
+	// Set when some file of an encrypted backup could not be decrypted
+	// with the decryption key
+	DecryptionError string `json:"decryptionError,omitempty"`
+}
+
+// VerifyBackupJob verifies the size and checksum of all the files listed in
+// the manifest of the backup, as they are stored in the source, and that the
+// decryption key, if any, decrypts them. The verification result is shared
+// with the operator in the BackupManifestSecretName secret
+func (b *APIManagerRestore) VerifyBackupJob() *batchv1.Job {
+	if !b.hasRestoreSource() {
+		return nil
+	}
+
+	jobName, err := helper.UIDBasedJobName("restore-verify-backup", b.options.APIManagerRestoreUID)
+	if err != nil {
+		panic(err)
+	}
+
+	var completions int32 = 1
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: b.options.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						b.backupDataPodVolume(),
+					},
+					Containers: []v1.Container{
+						v1.Container{
+							Name:  "job",
+							Image: b.options.OCCLIImageURL,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args: []string{
+								"-c",
+								"-e",
+								b.verifyBackupContainerArgs(),
+							},
+							VolumeMounts: []v1.VolumeMount{
+								b.backupDataContainerVolumeMount(),
+							},
+						},
+					},
+					RestartPolicy:      v1.RestartPolicyNever, // Only "Never" or "OnFailure" are accepted in Kubernetes Jobs
+					ServiceAccountName: ServiceAccountName,
+				},
+			},
+		},
+	}
+
+	if b.options.DecryptionKeySecretName != nil {
+		container := &job.Spec.Template.Spec.Containers[0]
+		container.Env = append(container.Env, backup.EncryptionKeyEnvVar(*b.options.DecryptionKeySecretName))
+	}
+
+	return b.withBackupData(job, fetchDirArgs(""))
+}
+
+func (b *APIManagerRestore) BackupManifestSecretName() string {
+	return fmt.Sprintf("%s-backup-manifest", b.options.APIManagerRestoreName)
+}
+
+// BackupVerifiedCondition returns the BackupVerified condition of the restore
+// from the secret shared by the verification job. The backup is rejected when
+// its data does not match the manifest, when it was taken from a different
+// 3scale release than the one of the operator or when it cannot be decrypted.
+// With a decryption key, the manifest is trusted only once authenticated by
+// its MAC. Backups without manifest, like those taken by previous operator
+// versions, are rejected unless they are explicitly allowed to be restored
+// unverified and are not expected to be encrypted
+func (b *APIManagerRestore) BackupVerifiedCondition(secret *v1.Secret) common.Condition {
+	rejected := func(reason common.ConditionReason, message string) common.Condition {
+		return common.Condition{
+			Type:    appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType,
+			Status:  v1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+
+	verification := &BackupVerification{}
+	if err := json.Unmarshal(secret.Data[BackupVerificationFileName], verification); err != nil {
+		return rejected(appsv1alpha1.APIManagerRestoreBackupCorruptedReason,
+			fmt.Sprintf("Invalid backup verification result: %s", err))
+	}
+
+	if !verification.ManifestFound && b.options.DecryptionKeySecretName != nil {
+		return rejected(appsv1alpha1.APIManagerRestoreManifestNotFoundReason,
+			fmt.Sprintf("Backup manifest '%s' not found, encrypted backups cannot be restored unverified", backup.BackupManifestFileName))
+	}
+
+	if !verification.ManifestFound && !b.options.AllowUnverifiedBackup {
+		return rejected(appsv1alpha1.APIManagerRestoreManifestNotFoundReason,
+			fmt.Sprintf("Backup manifest '%s' not found", backup.BackupManifestFileName))
+	}
+
+	if !verification.ManifestFound {
+		return common.Condition{
+			Type:    appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType,
+			Status:  v1.ConditionUnknown,
+			Reason:  appsv1alpha1.APIManagerRestoreManifestNotFoundReason,
+			Message: fmt.Sprintf("Backup manifest '%s' not found, the backup is restored unverified", backup.BackupManifestFileName),
+		}
+	}
+
+	if b.options.DecryptionKeySecretName != nil && verification.AuthenticationError != "" {
+		return rejected(appsv1alpha1.APIManagerRestoreManifestNotAuthenticReason,
+			fmt.Sprintf("Backup manifest cannot be authenticated with the decryption key: %s", verification.AuthenticationError))
+	}
+
+	if len(verification.Errors) > 0 {
+		errs := verification.Errors
+		if len(errs) > maxReportedVerificationErrors {
+			errs = errs[:maxReportedVerificationErrors:maxReportedVerificationErrors]
+		}
+		if verification.ErrorCount > len(errs) {
+			errs = append(errs, fmt.Sprintf("%d more", verification.ErrorCount-len(errs)))
+		}
+		return rejected(appsv1alpha1.APIManagerRestoreBackupCorruptedReason,
+			fmt.Sprintf("Backup data does not match its manifest: %s", strings.Join(errs, "; ")))
+	}
+
+	if verification.FormatVersion < 1 || verification.FormatVersion > backup.BackupManifestFormatVersion {
+		return rejected(appsv1alpha1.APIManagerRestoreIncompatibleVersionReason,
+			fmt.Sprintf("Unsupported backup manifest format version %d", verification.FormatVersion))
+	}
+
+	// The database schemas and the APIManager are those of the 3scale release
+	// of the backup, which might not be deployable by this operator. The release
+	// has to match exactly, as the operator does not migrate the data of other
+	// releases: backups are restored by the operator of their release, which
+	// upgrades 3scale afterwards
+	if verification.ThreescaleVersion != product.ThreescaleRelease {
+		return rejected(appsv1alpha1.APIManagerRestoreIncompatibleVersionReason,
+			fmt.Sprintf("Backup of 3scale '%s' cannot be restored by the operator of 3scale '%s'", verification.ThreescaleVersion, product.ThreescaleRelease))
+	}
+
+	switch {
+	case verification.Encryption != "" && verification.Encryption != backup.EncryptionAlgorithm:
+		return rejected(appsv1alpha1.APIManagerRestoreIncompatibleVersionReason,
+			fmt.Sprintf("Unsupported backup encryption '%s'", verification.Encryption))
+	case verification.Encryption != "" && b.options.DecryptionKeySecretName == nil:
+		return rejected(appsv1alpha1.APIManagerRestoreDecryptionFailedReason,
+			"Backup is encrypted and no decryption key is set")
+	case verification.Encryption == "" && b.options.DecryptionKeySecretName != nil:
+		return rejected(appsv1alpha1.APIManagerRestoreDecryptionFailedReason,
+			"Backup is not encrypted and a decryption key is set")
+	case verification.DecryptionError != "":
+		return rejected(appsv1alpha1.APIManagerRestoreDecryptionFailedReason,
+			fmt.Sprintf("Backup cannot be decrypted with the decryption key: %s", verification.DecryptionError))
+	}
+
+	return common.Condition{
+		Type:    appsv1alpha1.APIManagerRestoreBackupVerifiedConditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  appsv1alpha1.APIManagerRestoreManifestVerifiedReason,
+		Message: fmt.Sprintf("Backup of 3scale '%s' taken by operator '%s' verified", verification.ThreescaleVersion, verification.OperatorVersion),
+	}
+}
+
+func (b *APIManagerRestore) verifyBackupContainerArgs() string {
+	return fmt.Sprintf(`
+	BASEPATH='%s';
+	VERIFICATION='%s';
+	SECRET_TO_SHARE='%s';
+	PYTHON_VERIFY_SUBSCRIPT="%s"
+	python -c "${PYTHON_VERIFY_SUBSCRIPT}" "${BASEPATH}" > /tmp/${VERIFICATION};
+	oc delete secret ${SECRET_TO_SHARE} --ignore-not-found=true;
+	oc create secret generic ${SECRET_TO_SHARE} --from-file=${VERIFICATION}=/tmp/${VERIFICATION};
+`,
+		RestorePVCMountPath,
+		BackupVerificationFileName,
+		b.BackupManifestSecretName(),
+		b.pythonVerifyBackupScript(),
+	)
+}
+
+// pythonVerifyBackupScript prints the verification result of the files listed
+// in the manifest of the backup, along with the manifest fields checked by
+// BackupVerifiedCondition. Only the first errors are kept, and the reported
+// values are truncated, so the result is bounded.
+// When the ENCRYPTION_KEY variable is set, the manifest is authenticated by its
+// MAC before its files are verified, and the smallest files are decrypted to
+// check the key, as decrypting with a wrong key fails on the padding
+func (b *APIManagerRestore) pythonVerifyBackupScript() string {
+	return fmt.Sprintf(`
+import hashlib, hmac, json, os, subprocess, sys
+%s
+basepath=os.path.realpath(sys.argv[1])
+manifestpath=os.path.join(basepath, '%s')
+manifestmacpath=os.path.join(basepath, '%s')
+maxerrors=%d
+maxlength=%d
+
+def bounded(value):
+  return str(value)[:maxlength]
+
+result={'manifestFound': os.path.isfile(manifestpath), 'errors': [], 'errorCount': 0}
+def error(message):
+  result['errorCount']+=1
+  if len(result['errors']) < maxerrors:
+    result['errors'].append(bounded(message))
+
+artifacts=[]
+if result['manifestFound'] and os.environ.get('ENCRYPTION_KEY'):
+  if not os.path.isfile(manifestmacpath):
+    result['authenticationError']='%s not found'
+  else:
+    with open(manifestmacpath) as f:
+      if not hmac.compare_digest(f.read().strip(), manifest_mac(manifestpath)):
+        result['authenticationError']='manifest does not match its MAC'
+
+if result['manifestFound'] and not result.get('authenticationError'):
+  try:
+    with open(manifestpath) as f:
+      manifest=json.load(f)
+    artifacts=manifest['artifacts']
+    result['formatVersion']=int(manifest.get('formatVersion', 0))
+    for field in ['threescaleVersion', 'operatorVersion', 'encryption']:
+      if manifest.get(field):
+        result[field]=bounded(manifest[field])
+  except Exception as e:
+    error('invalid manifest: %%s' %% e)
+    artifacts=[]
+  for artifact in artifacts:
+    filepath=os.path.realpath(os.path.join(basepath, artifact['path']))
+    if not filepath.startswith(basepath + os.sep):
+      error('%%s: path out of the backup' %% artifact['path'])
+      continue
+    if not os.path.isfile(filepath):
+      error('%%s: not found' %% artifact['path'])
+      continue
+    size=os.path.getsize(filepath)
+    if size != artifact['size']:
+      error('%%s: size %%d, expected %%d' %% (artifact['path'], size, artifact['size']))
+      continue
+    checksum=hashlib.sha256()
+    with open(filepath, 'rb') as f:
+      for chunk in iter(lambda: f.read(1048576), b''):
+        checksum.update(chunk)
+    if checksum.hexdigest() != artifact['sha256']:
+      error('%%s: checksum mismatch' %% artifact['path'])
+
+if result['manifestFound'] and not result['errors'] and not result.get('authenticationError') and os.environ.get('ENCRYPTION_KEY'):
+  for artifact in sorted([a for a in artifacts if a['size'] > 0], key=lambda a: a['size'])[:3]:
+    filepath=os.path.join(basepath, artifact['path'])
+    if subprocess.call(['openssl', 'enc', '-d'] + '%s'.split() + ['-in', filepath, '-out', os.devnull]) != 0:
+      result['decryptionError']=bounded('%%s could not be decrypted' %% artifact['path'])
+      break
+
+print(json.dumps(result))
+`,
+		backup.ManifestMACPythonFunction,
+		backup.BackupManifestFileName,
+		backup.BackupManifestMACFileName,
+		maxReportedVerificationErrors,
+		maxReportedVerificationLength,
+		backup.BackupManifestMACFileName,
+		backup.EncryptionOpenSSLArgs,
+	)
+}
@@ -2,6 +2,7 @@ package restore
 
 import (
 	validator "github.com/go-playground/validator/v10"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -10,8 +11,20 @@ type APIManagerRestoreOptions struct {
 	APIManagerRestoreName string    `validate:"required"` // Name of the APIManagerRestore CR. NOT the backup or APIManager name
 	APIManagerRestoreUID  types.UID `validate:"required"` // UID of the APIManagerRestore CR
 
-	APIManagerRestorePVCOptions *APIManagerRestorePVCOptions `validate:"required"`
+	APIManagerRestorePVCOptions *APIManagerRestorePVCOptions `validate:"required_without=APIManagerRestoreS3Options"`
+	APIManagerRestoreS3Options  *APIManagerRestoreS3Options  `validate:"required_without=APIManagerRestorePVCOptions"`
 	OCCLIImageURL               string                       `validate:"required"`
+	AWSCLIImageURL              string                       `validate:"required"`
+
+	// Secret with the key the backup data is decrypted with. Nil when the
+	// backup data is not encrypted
+	DecryptionKeySecretName *string
+
+	// Size limit of the volume the backup data is staged in
+	StagingSizeLimit resource.Quantity
+
+	// Backups without manifest are restored unverified instead of rejected
+	AllowUnverifiedBackup bool
 }
 
 func NewAPIManagerRestoreOptions() *APIManagerRestoreOptions {
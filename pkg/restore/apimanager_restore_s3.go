@@ -0,0 +1,83 @@
+package restore
+
+import (
+	"fmt"
+	"path"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/3scale/3scale-operator/pkg/backup"
+)
+
+const restoreStagingVolumeName = "backup-staging"
+
+// withBackupData completes the job to read the backup data as it is stored in
+// its source. When the source is S3, an init container downloads the data read
+// by the job in the staging volume. The fetch arguments select the data to
+// download, see fetchDirArgs and fetchFileArgs
+func (b *APIManagerRestore) withBackupData(job *batchv1.Job, fetchArgs string) *batchv1.Job {
+	if b.options.APIManagerRestoreS3Options == nil {
+		return job
+	}
+
+	location := &b.options.APIManagerRestoreS3Options.Location
+	podSpec := &job.Spec.Template.Spec
+	podSpec.InitContainers = append([]v1.Container{
+		backup.S3ClientContainer("download-from-s3", b.options.AWSCLIImageURL, location, b.s3DownloadContainerArgs(fetchArgs),
+			b.restoreSourceContainerVolumeMount(),
+		),
+	}, podSpec.InitContainers...)
+	podSpec.Volumes = append(podSpec.Volumes, backup.S3ClientPodVolumes(location)...)
+
+	return job
+}
+
+func (b *APIManagerRestore) s3DownloadContainerArgs(fetchArgs string) string {
+	// "aws s3 ls" exits with 1 when no object is found, and with a
+	// different code on any other failure
+	return fmt.Sprintf(`
+	BASEPATH='%s';
+	object_exists() {
+		RC=0;
+		aws "${S3_ARGS[@]}" s3 ls "${S3_URL}/$1" > /dev/null || RC=$?;
+		if [ ${RC} -gt 1 ]; then exit ${RC}; fi;
+		return ${RC};
+	};
+	fetch_dir() {
+		mkdir -p "${BASEPATH}/$1";
+		aws "${S3_ARGS[@]}" s3 cp --recursive --only-show-errors "${S3_URL}/$1" "${BASEPATH}/$1";
+	};
+	fetch_file() {
+		aws "${S3_ARGS[@]}" s3 cp --only-show-errors "${S3_URL}/$1" "${BASEPATH}/$1";
+	};
+%s`,
+		RestorePVCMountPath,
+		fetchArgs,
+	)
+}
+
+// The secrets of the databases are restored only when the backup has the data
+// of the database. Just the existence of the database backup files is required,
+// so empty files are created in their place instead of fetching them
+func (b *APIManagerRestore) restoreSecretsAndConfigMapsFetchArgs() string {
+	args := fetchDirArgs("secrets") + fetchDirArgs("configmaps")
+	for _, databaseSecret := range databaseSecretsToRestore {
+		for _, fileName := range databaseSecret.backupFileNames {
+			filePath := path.Join(backup.DatabasesBackupSubdir, fileName)
+			args += fmt.Sprintf("\tif object_exists '%[1]s'; then mkdir -p \"$(dirname \"${BASEPATH}/%[1]s\")\"; touch \"${BASEPATH}/%[1]s\"; fi;\n", filePath)
+		}
+	}
+	return args
+}
+
+// fetchDirArgs fetches a directory of the backup. Object storages have no
+// directories, so it is created even if empty, as it would be found in a PVC source
+func fetchDirArgs(dir string) string {
+	return fmt.Sprintf("\tfetch_dir '%s';\n", dir)
+}
+
+// fetchFileArgs fetches a file of the backup when found
+func fetchFileArgs(filePath string) string {
+	return fmt.Sprintf("\tif object_exists '%[1]s'; then fetch_file '%[1]s'; fi;\n", filePath)
+}
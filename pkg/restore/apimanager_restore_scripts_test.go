@@ -0,0 +1,493 @@
+package restore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/3scale/3scale-operator/pkg/backup"
+)
+
+const (
+	scriptsTestEncryptionKey = "ENCRYPTION_KEY=backup-test-key"
+	scriptsTestS3URL         = "s3://backups/example-backup"
+)
+
+// awsStub replaces the aws client with the objects of the OBJECTS directory.
+// Listing the FAILING_OBJECT object fails, as on a network or permission error
+const awsStub = `
+	aws() {
+		shift; cmd="$1"; shift;
+		recursive='';
+		if [ "$1" = '--recursive' ]; then recursive='true'; shift; fi;
+		if [ "$1" = '--only-show-errors' ]; then shift; fi;
+		key="${1#"${S3_URL}/"}";
+		object="${OBJECTS}/${key}";
+		case "${cmd}" in
+			ls)
+				if [ "${key}" = "${FAILING_OBJECT}" ]; then return 2; fi;
+				if [ -e "${object}" ]; then return 0; fi;
+				return 1;;
+			cp)
+				if [ -n "${recursive}" ]; then
+					mkdir -p "$2";
+					if [ -d "${object}" ]; then cp -R "${object}/." "$2/"; fi;
+				else
+					mkdir -p "$(dirname "$2")";
+					cp "${object}" "$2";
+				fi;;
+		esac;
+	};
+`
+
+func scriptsTestRestore(decryptionKeySecretName *string) *APIManagerRestore {
+	return NewAPIManagerRestore(&APIManagerRestoreOptions{
+		Namespace:             "operator-unittest",
+		APIManagerRestoreName: "example-restore",
+		APIManagerRestoreUID:  "c9ab4e88-1c2a-4c4e-9a34-5f1d3b2f9a10",
+		APIManagerRestoreS3Options: &APIManagerRestoreS3Options{
+			Location: backup.S3Location{Bucket: "backups", Prefix: "example-backup", CredentialsSecretName: "s3-credentials"},
+		},
+		DecryptionKeySecretName: decryptionKeySecretName,
+	})
+}
+
+// requireScriptTools skips the test when the tools run by the scripts are not installed
+func requireScriptTools(t *testing.T, tools ...string) {
+	t.Helper()
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+}
+
+// runScript runs the script with bash, as the job containers do. The temporary
+// files written by the scripts in /tmp are written in a directory of the test
+func runScript(t *testing.T, script string, env ...string) (string, error) {
+	t.Helper()
+	script = strings.ReplaceAll(script, " /tmp/", " "+t.TempDir()+"/")
+	cmd := exec.Command("bash", "-e", "-c", script)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%v\n%s\nscript:\n%s", err, stderr.String(), script)
+	}
+	return stdout.String(), nil
+}
+
+// withScriptVariable replaces the value the script assigns to the variable
+func withScriptVariable(script, name, value string) string {
+	re := regexp.MustCompile(`(?m)^(\s*` + name + `=)'[^']*';`)
+	return re.ReplaceAllLiteralString(script, name+"='"+value+"';")
+}
+
+func writeScriptsTestFiles(t *testing.T, basepath string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filePath := filepath.Join(basepath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readScriptsTestFiles(t *testing.T, basepath string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.Walk(basepath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(basepath, filePath)
+		files[relPath] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func encryptScriptsTestFile(t *testing.T, filePath, encryptionKey string) {
+	t.Helper()
+	cmd := exec.Command("openssl", append(append([]string{"enc", "-e"}, strings.Fields(backup.EncryptionOpenSSLArgs)...), "-in", filePath, "-out", filePath+".encrypted")...)
+	cmd.Env = append(os.Environ(), encryptionKey)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("encryption failed: %v\n%s", err, out)
+	}
+	if err := os.Rename(filePath+".encrypted", filePath); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeScriptsTestManifest writes the manifest of the files found in the
+// directory, and its MAC when an encryption key is given
+func writeScriptsTestManifest(t *testing.T, basepath string, encryptionKey string) {
+	t.Helper()
+	manifest := backup.BackupManifest{FormatVersion: 1, APIManagerBackupName: "example-backup", ThreescaleVersion: "2.14", OperatorVersion: "0.11.0"}
+	for name, content := range readScriptsTestFiles(t, basepath) {
+		if name == backup.BackupManifestFileName || name == backup.BackupManifestMACFileName {
+			continue
+		}
+		checksum := sha256.Sum256([]byte(content))
+		manifest.Artifacts = append(manifest.Artifacts, backup.BackupManifestArtifact{Path: name, Size: int64(len(content)), SHA256: hex.EncodeToString(checksum[:])})
+	}
+	if encryptionKey != "" {
+		manifest.Encryption = "aes-256-cbc"
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(basepath, backup.BackupManifestFileName)
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if encryptionKey == "" {
+		return
+	}
+
+	cmd := exec.Command("python", "-c", "import sys"+backup.ManifestMACPythonFunction+"\nprint(manifest_mac(sys.argv[1]))", manifestPath)
+	cmd.Env = append(os.Environ(), encryptionKey)
+	mac, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("manifest MAC failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(basepath, backup.BackupManifestMACFileName), mac, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func scriptsTestFiles() map[string]string {
+	return map[string]string{
+		"secrets/system-app.json":         `{"kind": "Secret"}`,
+		"configmaps/it's \"quoted\".json": "$HOME `id` \\",
+		path.Join(backup.DatabasesBackupSubdir, backup.SystemMySQLBackupFileName): "CREATE TABLE accounts;",
+	}
+}
+
+func TestRestoreScriptsGeneration(t *testing.T) {
+	decryptionKeySecretName := "backup-encryption-key"
+	r := scriptsTestRestore(&decryptionKeySecretName)
+	fetchArgs := r.restoreSecretsAndConfigMapsFetchArgs() + fetchFileArgs(path.Join(backup.DatabasesBackupSubdir, backup.BackendRedisBackupFileName))
+
+	shellScripts := map[string]string{
+		"verify":     r.verifyBackupContainerArgs(),
+		"decrypt":    r.decryptBackupDataContainerArgs(),
+		"s3Download": r.s3DownloadContainerArgs(fetchArgs),
+		"pvcCopy":    r.pvcCopyContainerArgs(fetchArgs),
+	}
+	for name, script := range shellScripts {
+		t.Run(name, func(subT *testing.T) {
+			if strings.Contains(script, "%!") {
+				subT.Errorf("formatting error in script:\n%s", script)
+			}
+		})
+	}
+
+	// The python script is passed in a double quoted shell variable
+	pythonScript := r.pythonVerifyBackupScript()
+	if strings.ContainsAny(pythonScript, "\"$`\\") {
+		t.Errorf("python script not embeddable in double quotes:\n%s", pythonScript)
+	}
+	if strings.Contains(pythonScript, "%!") {
+		t.Errorf("formatting error in python script:\n%s", pythonScript)
+	}
+}
+
+func TestRestoreVerifyScriptSyntax(t *testing.T) {
+	requireScriptTools(t, "bash", "python")
+	r := scriptsTestRestore(nil)
+	script := r.verifyBackupContainerArgs()
+
+	if _, err := runScript(t, "set -n;\n"+script); err != nil {
+		t.Fatal(err)
+	}
+
+	// The python script is read as it is by the shell
+	start := strings.Index(script, `PYTHON_VERIFY_SUBSCRIPT="`)
+	end := strings.Index(script, "\tpython -c")
+	if start < 0 || end < start {
+		t.Fatalf("python script not found:\n%s", script)
+	}
+	pythonScript, err := runScript(t, script[start:end]+"\nprintf '%s' \"${PYTHON_VERIFY_SUBSCRIPT}\";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pythonScript != r.pythonVerifyBackupScript() {
+		t.Fatalf("python script altered by the shell:\n%s", pythonScript)
+	}
+	if out, err := exec.Command("python", "-c", "import ast, sys; ast.parse(sys.argv[1])", pythonScript).CombinedOutput(); err != nil {
+		t.Fatalf("invalid python script: %v\n%s", err, out)
+	}
+}
+
+func TestRestoreVerifyScript(t *testing.T) {
+	requireScriptTools(t, "python", "openssl")
+	r := scriptsTestRestore(nil)
+	mysqlPath := path.Join(backup.DatabasesBackupSubdir, backup.SystemMySQLBackupFileName)
+
+	cases := []struct {
+		name string
+		// Changes the backup once its manifest is written
+		alter                       func(t *testing.T, basepath string)
+		encrypted                   bool
+		encryptionKey               string
+		expectedErrors              []string
+		expectedAuthenticationError string
+		expectedDecryptionError     string
+	}{
+		{"Valid", nil, false, "", nil, "", ""},
+		{"TamperedFile", func(t *testing.T, basepath string) {
+			writeScriptsTestFiles(t, basepath, map[string]string{mysqlPath: "CREATE TABLE accountz;"})
+		}, false, "", []string{mysqlPath + ": checksum mismatch"}, "", ""},
+		{"MissingFile", func(t *testing.T, basepath string) {
+			os.Remove(filepath.Join(basepath, mysqlPath))
+		}, false, "", []string{mysqlPath + ": not found"}, "", ""},
+		{"PathOutOfBackup", func(t *testing.T, basepath string) {
+			manifestPath := filepath.Join(basepath, backup.BackupManifestFileName)
+			data, _ := os.ReadFile(manifestPath)
+			data = bytes.Replace(data, []byte(`"path":"secrets/`), []byte(`"path":"../secrets/`), 1)
+			if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}, false, "", []string{"../secrets/system-app.json: path out of the backup"}, "", ""},
+		{"Encrypted", nil, true, scriptsTestEncryptionKey, nil, "", ""},
+		{"WrongKey", nil, true, "ENCRYPTION_KEY=wrong-key", nil, "manifest does not match its MAC", ""},
+		{"MissingMAC", func(t *testing.T, basepath string) {
+			os.Remove(filepath.Join(basepath, backup.BackupManifestMACFileName))
+		}, true, scriptsTestEncryptionKey, nil, backup.BackupManifestMACFileName + " not found", ""},
+		{"FileEncryptedWithAnotherKey", func(t *testing.T, basepath string) {
+			encryptScriptsTestFile(t, filepath.Join(basepath, "secrets/system-app.json"), "ENCRYPTION_KEY=wrong-key")
+			writeScriptsTestManifest(t, basepath, scriptsTestEncryptionKey)
+		}, true, scriptsTestEncryptionKey, nil, "", "secrets/system-app.json could not be decrypted"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(subT *testing.T) {
+			basepath := subT.TempDir()
+			writeScriptsTestFiles(subT, basepath, scriptsTestFiles())
+			manifestKey := ""
+			if tc.encrypted {
+				for name := range scriptsTestFiles() {
+					encryptScriptsTestFile(subT, filepath.Join(basepath, name), scriptsTestEncryptionKey)
+				}
+				manifestKey = scriptsTestEncryptionKey
+			}
+			writeScriptsTestManifest(subT, basepath, manifestKey)
+			if tc.alter != nil {
+				tc.alter(subT, basepath)
+			}
+
+			cmd := exec.Command("python", "-c", r.pythonVerifyBackupScript(), basepath)
+			if tc.encryptionKey != "" {
+				cmd.Env = append(os.Environ(), tc.encryptionKey)
+			}
+			out, err := cmd.Output()
+			if err != nil {
+				subT.Fatalf("verification failed: %v", err)
+			}
+			verification := &BackupVerification{}
+			if err := json.Unmarshal(out, verification); err != nil {
+				subT.Fatalf("invalid verification result '%s': %v", out, err)
+			}
+
+			if !verification.ManifestFound {
+				subT.Fatalf("manifest not found: %s", out)
+			}
+			// The fields of a manifest not authenticated are not trusted
+			if tc.expectedAuthenticationError == "" && (verification.ThreescaleVersion != "2.14" || verification.OperatorVersion != "0.11.0") {
+				subT.Errorf("manifest fields not reported: %s", out)
+			}
+			if len(verification.Errors) != len(tc.expectedErrors) || verification.ErrorCount != len(tc.expectedErrors) {
+				subT.Fatalf("expected errors %v, got %s", tc.expectedErrors, out)
+			}
+			for idx, expected := range tc.expectedErrors {
+				if verification.Errors[idx] != expected {
+					subT.Errorf("expected error '%s', got '%s'", expected, verification.Errors[idx])
+				}
+			}
+			if verification.AuthenticationError != tc.expectedAuthenticationError {
+				subT.Errorf("expected authentication error '%s', got '%s'", tc.expectedAuthenticationError, verification.AuthenticationError)
+			}
+			if verification.DecryptionError != tc.expectedDecryptionError {
+				subT.Errorf("expected decryption error '%s', got '%s'", tc.expectedDecryptionError, verification.DecryptionError)
+			}
+		})
+	}
+}
+
+func TestRestoreDecryptionScript(t *testing.T) {
+	requireScriptTools(t, "bash", "openssl")
+	basepath := t.TempDir()
+	files := scriptsTestFiles()
+	writeScriptsTestFiles(t, basepath, files)
+	for name := range files {
+		encryptScriptsTestFile(t, filepath.Join(basepath, name), scriptsTestEncryptionKey)
+	}
+	writeScriptsTestManifest(t, basepath, scriptsTestEncryptionKey)
+	// Placeholder of a file not fetched
+	placeholderPath := path.Join(backup.DatabasesBackupSubdir, backup.SystemRedisBackupFileName)
+	writeScriptsTestFiles(t, basepath, map[string]string{placeholderPath: ""})
+	unencryptedFiles := map[string]string{}
+	for name, content := range readScriptsTestFiles(t, basepath) {
+		if _, ok := files[name]; !ok {
+			unencryptedFiles[name] = content
+		}
+	}
+
+	script := withScriptVariable(scriptsTestRestore(nil).decryptBackupDataContainerArgs(), "BASEPATH", basepath)
+	if _, err := runScript(t, script, scriptsTestEncryptionKey); err != nil {
+		t.Fatal(err)
+	}
+
+	decryptedFiles := readScriptsTestFiles(t, basepath)
+	if len(decryptedFiles) != len(files)+len(unencryptedFiles) {
+		t.Errorf("unexpected files %v", decryptedFiles)
+	}
+	for name, content := range files {
+		if decryptedFiles[name] != content {
+			t.Errorf("%s: expected '%s', got '%s'", name, content, decryptedFiles[name])
+		}
+	}
+	for name, content := range unencryptedFiles {
+		if decryptedFiles[name] != content {
+			t.Errorf("%s: expected to be left as is, got '%s'", name, decryptedFiles[name])
+		}
+	}
+
+	// A wrong key fails the job
+	writeScriptsTestFiles(t, basepath, files)
+	encryptScriptsTestFile(t, filepath.Join(basepath, "secrets/system-app.json"), scriptsTestEncryptionKey)
+	if _, err := runScript(t, script, "ENCRYPTION_KEY=wrong-key"); err == nil {
+		t.Errorf("expected the decryption with a wrong key to fail")
+	}
+}
+
+func TestRestoreFetchScripts(t *testing.T) {
+	requireScriptTools(t, "bash")
+	r := scriptsTestRestore(nil)
+	mysqlPath := path.Join(backup.DatabasesBackupSubdir, backup.SystemMySQLBackupFileName)
+	postgresqlPath := path.Join(backup.DatabasesBackupSubdir, backup.SystemPostgreSQLBackupFileName)
+	redisPath := path.Join(backup.DatabasesBackupSubdir, backup.BackendRedisBackupFileName)
+	systemRedisPath := path.Join(backup.DatabasesBackupSubdir, backup.SystemRedisBackupFileName)
+	fetchArgs := r.restoreSecretsAndConfigMapsFetchArgs() + fetchFileArgs(redisPath) + fetchFileArgs(systemRedisPath)
+
+	sources := []struct {
+		name   string
+		script func(objects string) string
+		env    func(objects string) []string
+	}{
+		{
+			"S3",
+			func(objects string) string {
+				return awsStub + r.s3DownloadContainerArgs(fetchArgs)
+			},
+			func(objects string) []string {
+				return []string{"S3_URL=" + scriptsTestS3URL, "OBJECTS=" + objects}
+			},
+		},
+		{
+			"PVC",
+			func(objects string) string {
+				return withScriptVariable(r.pvcCopyContainerArgs(fetchArgs), "SOURCE", objects)
+			},
+			func(objects string) []string { return nil },
+		},
+	}
+
+	cases := []struct {
+		name     string
+		objects  map[string]string
+		expected map[string]string
+		// Expected directories, found even without objects
+		expectedDirs []string
+	}{
+		{
+			"Backup",
+			map[string]string{
+				"secrets/system-app.json":         `{"kind": "Secret"}`,
+				"configmaps/it's \"quoted\".json": "$HOME `id` \\",
+				mysqlPath:                         "CREATE TABLE accounts;",
+				redisPath:                         "REDIS0009",
+				"system-storage/logo.png":         "png",
+			},
+			map[string]string{
+				"secrets/system-app.json":         `{"kind": "Secret"}`,
+				"configmaps/it's \"quoted\".json": "$HOME `id` \\",
+				// Only the existence of the database files is checked by the restore of their secrets
+				mysqlPath: "",
+				// Fetched files
+				redisPath: "REDIS0009",
+			},
+			[]string{"secrets", "configmaps"},
+		},
+		{
+			"EmptyBackup",
+			map[string]string{},
+			map[string]string{},
+			[]string{"secrets", "configmaps"},
+		},
+	}
+
+	for _, source := range sources {
+		for _, tc := range cases {
+			t.Run(source.name+"/"+tc.name, func(subT *testing.T) {
+				objects := subT.TempDir()
+				basepath := subT.TempDir()
+				writeScriptsTestFiles(subT, objects, tc.objects)
+
+				script := withScriptVariable(source.script(objects), "BASEPATH", basepath)
+				if _, err := runScript(subT, script, source.env(objects)...); err != nil {
+					subT.Fatal(err)
+				}
+
+				fetched := readScriptsTestFiles(subT, basepath)
+				if len(fetched) != len(tc.expected) {
+					subT.Errorf("expected files %v, got %v", tc.expected, fetched)
+				}
+				for name, content := range tc.expected {
+					if fetchedContent, ok := fetched[name]; !ok || fetchedContent != content {
+						subT.Errorf("%s: expected '%s', got '%s'", name, content, fetchedContent)
+					}
+				}
+				if _, ok := fetched[postgresqlPath]; ok {
+					subT.Errorf("unexpected placeholder of a database not backed up")
+				}
+				for _, dir := range tc.expectedDirs {
+					if info, err := os.Stat(filepath.Join(basepath, dir)); err != nil || !info.IsDir() {
+						subT.Errorf("expected directory %s: %v", dir, err)
+					}
+				}
+			})
+		}
+	}
+
+	// Listing errors other than objects not found fail the download
+	t.Run("S3/ListingError", func(subT *testing.T) {
+		objects := subT.TempDir()
+		script := withScriptVariable(awsStub+r.s3DownloadContainerArgs(fetchArgs), "BASEPATH", subT.TempDir())
+		_, err := runScript(subT, script, "S3_URL="+scriptsTestS3URL, "OBJECTS="+objects, "FAILING_OBJECT="+redisPath)
+		if err == nil {
+			subT.Errorf("expected the download to fail")
+		}
+	})
+}
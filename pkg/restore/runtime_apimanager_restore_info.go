@@ -1,5 +1,25 @@
 package restore
 
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
 type RuntimeAPIManagerRestoreInfo struct {
 	PVCStorageClass *string
+
+	// Databases and Redis instances deployed by the operator for the restored
+	// APIManager. Nil when the component is external
+	SystemMySQL      *RuntimeDatabaseRestoreInfo
+	SystemPostgreSQL *RuntimeDatabaseRestoreInfo
+	ZyncDatabase     *RuntimeDatabaseRestoreInfo
+	BackendRedis     *RuntimeDatabaseRestoreInfo
+	SystemRedis      *RuntimeDatabaseRestoreInfo
+}
+
+type RuntimeDatabaseRestoreInfo struct {
+	// PodTemplate of the database workload. Its image, environment and persistent
+	// volumes are used by the job loading the data
+	PodTemplate v1.PodTemplateSpec
+	// PVC where the database stores its data. Nil when the data is not persisted
+	PVC *v1.PersistentVolumeClaim
 }
@@ -20,9 +20,12 @@ import (
 // Missing fields path omissions
 const (
 	backupDestinationPVCResourceRequestsPath = "/spec/backupDestination/persistentVolumeClaim/resources/requests"
+	stagingSizeLimitPath                     = "/spec/stagingSizeLimit"
 	startTimePath                            = "/status/startTime"
 	completionTimePath                       = "/status/completionTime"
 	lastTransitionTimePath                   = "/status/conditions/lastTransitionTime"
+	phaseStartTimePath                       = "/status/secretRotation/phaseStartTime"
+	lastRotationTimePath                     = "/status/secretRotation/lastRotationTime"
 	systemSharedPVCResourceRequestsPath      = "/spec/system/fileStorage/persistentVolumeClaim/resources/requests"
 	systemMySQLPVCResourceRequestsPath       = "/spec/system/database/mysql/persistentVolumeClaim/resources/requests"
 	systemPostgreSQLPVCResourceRequestsPath  = "/spec/system/database/postgresql/persistentVolumeClaim/resources/requests"
@@ -30,6 +33,42 @@ const (
 	systemSearchdPVCResourceRequestsPath     = "/spec/system/searchdSpec/persistentVolumeClaim/resources/requests"
 	productPoliciesConfigurationPath         = "/spec/policies/configuration"
 	policyConfigurationPath                  = "/spec/schema/configuration"
+	apicastProductionAutoscalingMetricsPath  = "/spec/apicast/productionSpec/autoscaling/metrics"
+	backendListenerAutoscalingMetricsPath    = "/spec/backend/listenerSpec/autoscaling/metrics"
+	backendWorkerAutoscalingMetricsPath      = "/spec/backend/workerSpec/autoscaling/metrics"
+	systemAppAutoscalingMetricsPath          = "/spec/system/appSpec/autoscaling/metrics"
+
+	// podTemplateOverrides containers and volumes are schemaless
+	apicastProductionPodTemplateOverridesPath = "/spec/apicast/productionSpec/podTemplateOverrides"
+	apicastStagingPodTemplateOverridesPath    = "/spec/apicast/stagingSpec/podTemplateOverrides"
+	backendListenerPodTemplateOverridesPath   = "/spec/backend/listenerSpec/podTemplateOverrides"
+	backendWorkerPodTemplateOverridesPath     = "/spec/backend/workerSpec/podTemplateOverrides"
+	backendCronPodTemplateOverridesPath       = "/spec/backend/cronSpec/podTemplateOverrides"
+	systemAppPodTemplateOverridesPath         = "/spec/system/appSpec/podTemplateOverrides"
+	systemSidekiqPodTemplateOverridesPath     = "/spec/system/sidekiqSpec/podTemplateOverrides"
+	systemSearchdPodTemplateOverridesPath     = "/spec/system/searchdSpec/podTemplateOverrides"
+	zyncAppPodTemplateOverridesPath           = "/spec/zync/appSpec/podTemplateOverrides"
+	zyncQuePodTemplateOverridesPath           = "/spec/zync/queSpec/podTemplateOverrides"
+)
+
+// Probe ports are IntOrString values, not described by the struct schema
+const (
+	apicastProductionProbesPath = "/spec/apicast/productionSpec/probes"
+	apicastStagingProbesPath    = "/spec/apicast/stagingSpec/probes"
+	backendListenerProbesPath   = "/spec/backend/listenerSpec/probes"
+	backendWorkerProbesPath     = "/spec/backend/workerSpec/probes"
+	backendCronProbesPath       = "/spec/backend/cronSpec/probes"
+	backendRedisProbesPath      = "/spec/backend/redisProbes"
+	systemAppProbesPath         = "/spec/system/appSpec/probes"
+	systemSidekiqProbesPath     = "/spec/system/sidekiqSpec/probes"
+	systemSearchdProbesPath     = "/spec/system/searchdSpec/probes"
+	systemMySQLProbesPath       = "/spec/system/database/mysql/probes"
+	systemPostgreSQLProbesPath  = "/spec/system/database/postgresql/probes"
+	systemRedisProbesPath       = "/spec/system/redisProbes"
+	systemMemcachedProbesPath   = "/spec/system/memcachedProbes"
+	zyncAppProbesPath           = "/spec/zync/appSpec/probes"
+	zyncQueProbesPath           = "/spec/zync/queSpec/probes"
+	zyncDatabaseProbesPath      = "/spec/zync/databaseProbes"
 )
 
 type testCRInfo struct {
@@ -174,9 +213,12 @@ func TestCompleteCRD(t *testing.T) {
 
 	pathOmissions := []string{
 		backupDestinationPVCResourceRequestsPath,
+		stagingSizeLimitPath,
 		startTimePath,
 		completionTimePath,
 		lastTransitionTimePath,
+		phaseStartTimePath,
+		lastRotationTimePath,
 		systemSharedPVCResourceRequestsPath,
 		systemMySQLPVCResourceRequestsPath,
 		systemPostgreSQLPVCResourceRequestsPath,
@@ -184,6 +226,36 @@ func TestCompleteCRD(t *testing.T) {
 		policyConfigurationPath,
 		systemSearchdResourceRequestsPath,
 		systemSearchdPVCResourceRequestsPath,
+		apicastProductionAutoscalingMetricsPath,
+		backendListenerAutoscalingMetricsPath,
+		backendWorkerAutoscalingMetricsPath,
+		systemAppAutoscalingMetricsPath,
+		apicastProductionPodTemplateOverridesPath,
+		apicastStagingPodTemplateOverridesPath,
+		backendListenerPodTemplateOverridesPath,
+		backendWorkerPodTemplateOverridesPath,
+		backendCronPodTemplateOverridesPath,
+		systemAppPodTemplateOverridesPath,
+		systemSidekiqPodTemplateOverridesPath,
+		systemSearchdPodTemplateOverridesPath,
+		zyncAppPodTemplateOverridesPath,
+		zyncQuePodTemplateOverridesPath,
+		apicastProductionProbesPath,
+		apicastStagingProbesPath,
+		backendListenerProbesPath,
+		backendWorkerProbesPath,
+		backendCronProbesPath,
+		backendRedisProbesPath,
+		systemAppProbesPath,
+		systemSidekiqProbesPath,
+		systemSearchdProbesPath,
+		systemMySQLProbesPath,
+		systemPostgreSQLProbesPath,
+		systemRedisProbesPath,
+		systemMemcachedProbesPath,
+		zyncAppProbesPath,
+		zyncQueProbesPath,
+		zyncDatabaseProbesPath,
 	}
 
 	for crd, elem := range crdStructMap {